@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/kjanat/slimacademy/internal/client"
+	"github.com/kjanat/slimacademy/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Auth command flags
+	authDir    string
+	authForget bool
+)
+
+// authCmd groups the commands that manage stored credentials
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage stored API credentials",
+	Long: `Inspect and remove the API token and credentials.
+
+Where secrets are kept is set by the auth section of the configuration file:
+  file       token in <dir>/.token_info, credentials from .env (default)
+  encrypted  token and credentials in a passphrase encrypted file
+  env        credentials from SLIM_USERNAME/SLIM_PASSWORD, token kept in memory
+  helper     an external credential helper command
+
+Examples:
+  slim auth status                    # Show the stored token
+  slim auth logout                    # Remove the token
+  slim auth logout --forget           # Also remove stored credentials`,
+}
+
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the stored login",
+	Args:  cobra.NoArgs,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		apiClient, err := newAPIClient(nil, authDir)
+		if err != nil {
+			return err
+		}
+		printAuthStatus(cmd.OutOrStdout(), apiClient, time.Now())
+		return nil
+	},
+}
+
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the stored token",
+	Args:  cobra.NoArgs,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		apiClient, err := newAPIClient(nil, authDir)
+		if err != nil {
+			return err
+		}
+		return runLogout(cmd.OutOrStdout(), apiClient, authForget)
+	},
+}
+
+// newAPIClient creates an API client using the API settings and secret store from the
+// configuration. The token file, if used, is kept in outputDir. A nil appConfig loads --config.
+func newAPIClient(appConfig *config.Config, outputDir string) (*client.SlimClient, error) {
+	if appConfig == nil {
+		var err error
+		if appConfig, err = config.NewLoader().LoadConfig(configPath); err != nil {
+			return nil, fmt.Errorf("failed to load configuration: %w", err)
+		}
+	}
+
+	apiClient := client.NewSlimClient(outputDir)
+	apiClient.ConfigureAPI(appConfig.API)
+	if err := apiClient.ConfigureSecrets(appConfig.Auth, outputDir); err != nil {
+		return nil, err
+	}
+	return apiClient, nil
+}
+
+// printAuthStatus describes the stored token
+func printAuthStatus(w io.Writer, apiClient *client.SlimClient, now time.Time) {
+	fmt.Fprintf(w, "API: %s\n", apiClient.BaseURL())
+	fmt.Fprintf(w, "Secret store: %s\n", apiClient.SecretsLocation())
+
+	info, err := apiClient.GetTokenInfo()
+	if err != nil {
+		fmt.Fprintf(w, "Not logged in: %v\n", err)
+		return
+	}
+
+	if info.Username != "" {
+		fmt.Fprintf(w, "Logged in as: %s\n", info.Username)
+	}
+	expiry := info.ExpiresAt.Format("2006-01-02 15:04:05")
+	if now.Before(info.ExpiresAt) {
+		fmt.Fprintf(w, "Token: valid until %s (%s left)\n", expiry, info.ExpiresAt.Sub(now).Round(time.Second))
+	} else {
+		fmt.Fprintf(w, "Token: expired at %s\n", expiry)
+	}
+	if info.RefreshToken != "" {
+		fmt.Fprintln(w, "Refresh token: available")
+	} else {
+		fmt.Fprintln(w, "Refresh token: none, the next request logs in again")
+	}
+}
+
+// runLogout removes the token and, with forget, the stored credentials
+func runLogout(w io.Writer, apiClient *client.SlimClient, forget bool) error {
+	if err := apiClient.Logout(); err != nil {
+		return fmt.Errorf("logout failed: %w", err)
+	}
+	fmt.Fprintln(w, "Logged out")
+
+	if forget {
+		if err := apiClient.ForgetCredentials(); err != nil {
+			return fmt.Errorf("failed to remove credentials: %w", err)
+		}
+		fmt.Fprintln(w, "Stored credentials removed")
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+	authCmd.AddCommand(authStatusCmd, authLogoutCmd)
+
+	authCmd.PersistentFlags().StringVar(&authDir, "dir", "source", "Directory holding the token file")
+	authLogoutCmd.Flags().BoolVar(&authForget, "forget", false, "Also remove credentials from the secret store")
+}
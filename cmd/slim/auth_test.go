@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kjanat/slimacademy/internal/client"
+	"github.com/kjanat/slimacademy/internal/config"
+)
+
+func TestPrintAuthStatus(t *testing.T) {
+	dir := t.TempDir()
+	apiClient, err := newAPIClient(config.DefaultConfig(), dir)
+	if err != nil {
+		t.Fatalf("newAPIClient() failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	printAuthStatus(&buf, apiClient, time.Now())
+	if !strings.Contains(buf.String(), "Not logged in") {
+		t.Errorf("Expected not logged in, got:\n%s", buf.String())
+	}
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	err = client.NewTokenStore(dir).SaveToken(&client.TokenInfo{
+		Token:        "token",
+		Username:     "user@example.com",
+		ExpiresAt:    now.Add(90 * time.Minute),
+		RefreshToken: "refresh",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf.Reset()
+	printAuthStatus(&buf, apiClient, now)
+	for _, expected := range []string{
+		"API: https://api.slimacademy.nl",
+		"Secret store: file " + dir,
+		"Logged in as: user@example.com",
+		"Token: valid until 2025-01-01 13:30:00 (1h30m0s left)",
+		"Refresh token: available",
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("Expected status to contain %q, got:\n%s", expected, buf.String())
+		}
+	}
+
+	buf.Reset()
+	printAuthStatus(&buf, apiClient, now.Add(2*time.Hour))
+	if !strings.Contains(buf.String(), "Token: expired at 2025-01-01 13:30:00") {
+		t.Errorf("Expected expired token, got:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := runLogout(&buf, apiClient, true); err != nil {
+		t.Fatalf("runLogout() failed: %v", err)
+	}
+	if _, err := apiClient.GetTokenInfo(); err == nil {
+		t.Error("Expected token to be removed by logout")
+	}
+}
+
+func TestNewAPIClient_EnvStore(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Auth.SecretStore = config.SecretStoreEnv
+
+	apiClient, err := newAPIClient(cfg, t.TempDir())
+	if err != nil {
+		t.Fatalf("newAPIClient() failed: %v", err)
+	}
+	if !strings.Contains(apiClient.SecretsLocation(), "environment") {
+		t.Errorf("Expected environment store, got %q", apiClient.SecretsLocation())
+	}
+
+	cfg.Auth.SecretStore = config.SecretStoreHelper
+	if _, err := newAPIClient(cfg, t.TempDir()); err == nil {
+		t.Error("Expected error for helper store without command")
+	}
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/kjanat/slimacademy/internal/config"
+	"github.com/kjanat/slimacademy/internal/models"
+	"github.com/kjanat/slimacademy/internal/templates"
+	"github.com/kjanat/slimacademy/internal/writers"
+)
+
+// batchManifestName is the archive entry that summarises a batch conversion
+const batchManifestName = "manifest.json"
+
+// batchManifest summarises the outcome of a batch conversion
+type batchManifest struct {
+	Formats   []string         `json:"formats"`
+	Total     int              `json:"total"`
+	Converted int              `json:"converted"`
+	Failed    int              `json:"failed"`
+	Skipped   int              `json:"skipped"` // Not converted because the batch was cancelled
+	Books     []batchBookEntry `json:"books"`
+}
+
+// batchBookEntry records the outcome of converting a single book
+type batchBookEntry struct {
+	Path   string   `json:"path"`
+	Title  string   `json:"title,omitempty"`
+	Status string   `json:"status"`
+	Files  []string `json:"files,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// bookOutcome is produced by a batch worker for each book
+type bookOutcome struct {
+	index       int
+	title       string
+	description string
+	multiWriter *writers.MultiWriter // Rendered but not yet flushed
+	err         error
+}
+
+// bookParseFunc parses the book stored in a directory
+type bookParseFunc func(bookPath string) (*models.Book, error)
+
+// convertBatch parses and renders books with a pool of jobs workers while the calling goroutine
+// flushes finished books into zipWriter in completion order. A book that fails is recorded in
+// the returned manifest instead of aborting the batch; only archive write failures are returned as errors.
+// When ctx is cancelled, the books not converted yet are recorded as skipped and the manifest is
+// still written.
+func convertBatch(ctx context.Context, bookPaths []string, parse bookParseFunc, formats []string, jobs int, zipWriter *zip.Writer, appConfig *config.Config) (*batchManifest, error) {
+	logger := slog.Default().With("command", "convert-all")
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs = max(1, min(jobs, len(bookPaths)))
+
+	indexes := make(chan int)
+	outcomes := make(chan bookOutcome, jobs)
+
+	var wg sync.WaitGroup
+	for range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				outcomes <- convertBatchBook(ctx, index, bookPaths[index], parse, formats, appConfig)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(indexes)
+		for index := range bookPaths {
+			select {
+			case indexes <- index:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(outcomes)
+	}()
+
+	manifest := &batchManifest{
+		Formats: formats,
+		Total:   len(bookPaths),
+		Books:   make([]batchBookEntry, len(bookPaths)),
+	}
+	for i, bookPath := range bookPaths {
+		manifest.Books[i] = batchBookEntry{Path: bookPath, Status: "skipped"}
+	}
+
+	usedNames := make(map[string]bool)
+	if slices.Contains(formats, siteFormat) {
+		usedNames[writers.SiteIndexFile] = true // Reserved for the library page
+	}
+	var library []templates.SiteBook
+	var writeErr error
+	for outcome := range outcomes {
+		if writeErr != nil {
+			outcome.close()
+			continue // Drain remaining workers after a fatal archive error
+		}
+
+		entry := &manifest.Books[outcome.index]
+		entry.Title = outcome.title
+
+		if outcome.err != nil && ctx.Err() != nil && errors.Is(outcome.err, context.Canceled) {
+			continue // Left skipped
+		}
+		if outcome.err != nil {
+			logger.Warn("Failed to convert book", "path", entry.Path, "error", outcome.err)
+			entry.Status = "failed"
+			entry.Error = outcome.err.Error()
+			manifest.Failed++
+			continue
+		}
+
+		files, err := writeBookToZip(zipWriter, outcome.title, outcome.multiWriter, usedNames)
+		outcome.close()
+		if err != nil {
+			writeErr = err
+			cancel()
+			continue
+		}
+
+		logger.Debug("Book converted successfully", "title", outcome.title)
+		entry.Status = "converted"
+		entry.Files = files
+		manifest.Converted++
+
+		if book, ok := siteLibraryBook(outcome.title, outcome.description, files); ok {
+			library = append(library, book)
+		}
+	}
+
+	if writeErr != nil {
+		return nil, writeErr
+	}
+
+	for _, entry := range manifest.Books {
+		if entry.Status == "skipped" {
+			manifest.Skipped++
+		}
+	}
+
+	if len(library) > 0 {
+		language := ""
+		if appConfig != nil && appConfig.HTML != nil {
+			language = appConfig.HTML.Language
+		}
+		if err := writeSiteLibrary(zipWriter, library, language); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writeBatchManifest(zipWriter, manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// convertBatchBook parses and converts a single book, turning panics into per-book failures
+func convertBatchBook(ctx context.Context, index int, bookPath string, parse bookParseFunc, formats []string, appConfig *config.Config) (outcome bookOutcome) {
+	outcome.index = index
+
+	defer func() {
+		if r := recover(); r != nil {
+			outcome.close()
+			outcome.err = fmt.Errorf("conversion panicked: %v", r)
+		}
+	}()
+
+	if err := ctx.Err(); err != nil {
+		outcome.err = err
+		return outcome
+	}
+
+	book, err := parse(bookPath)
+	if err != nil {
+		outcome.err = fmt.Errorf("failed to parse book: %w", err)
+		return outcome
+	}
+	outcome.title = book.Title
+	outcome.description = book.Description
+
+	outcome.multiWriter, outcome.err = renderBook(ctx, book, formats, appConfig)
+	return outcome
+}
+
+// close releases the rendered writers of an outcome
+func (o *bookOutcome) close() {
+	if o.multiWriter != nil {
+		o.multiWriter.Close()
+		o.multiWriter = nil
+	}
+}
+
+// writeBatchManifest writes the batch summary as the final archive entry
+func writeBatchManifest(zipWriter *zip.Writer, manifest *batchManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode batch manifest: %w", err)
+	}
+
+	fileWriter, err := zipWriter.Create(batchManifestName)
+	if err != nil {
+		return fmt.Errorf("failed to create ZIP entry %s: %w", batchManifestName, err)
+	}
+
+	if _, err := fileWriter.Write(data); err != nil {
+		return fmt.Errorf("failed to write content to ZIP entry %s: %w", batchManifestName, err)
+	}
+
+	return nil
+}
+
+// uniqueEntryName returns name, or name with a numeric suffix if it is already in use
+func uniqueEntryName(name string, usedNames map[string]bool) string {
+	if usedNames == nil {
+		return name
+	}
+
+	candidate := name
+	if usedNames[candidate] {
+		dot := strings.LastIndex(name, ".")
+		if dot < 0 {
+			dot = len(name)
+		}
+		for i := 2; usedNames[candidate]; i++ {
+			candidate = fmt.Sprintf("%s_%d%s", name[:dot], i, name[dot:])
+		}
+	}
+	usedNames[candidate] = true
+	return candidate
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/kjanat/slimacademy/internal/config"
+	"github.com/kjanat/slimacademy/internal/models"
+)
+
+// testBatchBook returns a minimal book with a single paragraph
+func testBatchBook(title string) *models.Book {
+	return &models.Book{
+		Title: title,
+		Content: &models.Content{
+			Document: &models.Document{
+				Title: title,
+				Body: models.Body{
+					Content: []models.StructuralElement{
+						{
+							Paragraph: &models.Paragraph{
+								Elements: []models.ParagraphElement{
+									{TextRun: &models.TextRun{Content: "Content of " + title}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// TestConvertBatchIsolatesFailures tests that failed books are reported without aborting the archive
+func TestConvertBatchIsolatesFailures(t *testing.T) {
+	parse := func(bookPath string) (*models.Book, error) {
+		switch bookPath {
+		case "broken":
+			return nil, fmt.Errorf("invalid metadata")
+		case "panics":
+			panic("unexpected content")
+		case "duplicate":
+			return testBatchBook("Book One"), nil
+		default:
+			return testBatchBook(bookPath), nil
+		}
+	}
+
+	bookPaths := []string{"Book One", "broken", "Book Two", "panics", "duplicate"}
+
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	manifest, err := convertBatch(context.Background(), bookPaths, parse, []string{"markdown", "html"}, 3, zipWriter, config.DefaultConfig())
+	if err != nil {
+		t.Fatalf("convertBatch failed: %v", err)
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatalf("Failed to close ZIP writer: %v", err)
+	}
+
+	if manifest.Total != 5 || manifest.Converted != 3 || manifest.Failed != 2 {
+		t.Errorf("Unexpected manifest counts: total=%d converted=%d failed=%d", manifest.Total, manifest.Converted, manifest.Failed)
+	}
+
+	for i, entry := range manifest.Books {
+		if entry.Path != bookPaths[i] {
+			t.Errorf("Manifest entry %d: expected path %s, got %s", i, bookPaths[i], entry.Path)
+		}
+	}
+	if manifest.Books[1].Status != "failed" || manifest.Books[1].Error == "" {
+		t.Errorf("Expected parse failure in manifest, got %+v", manifest.Books[1])
+	}
+	if manifest.Books[3].Status != "failed" {
+		t.Errorf("Expected panic to be recorded as failure, got %+v", manifest.Books[3])
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Archive is not a valid ZIP: %v", err)
+	}
+
+	entries := make(map[string]*zip.File)
+	for _, file := range reader.File {
+		if entries[file.Name] != nil {
+			t.Errorf("Duplicate ZIP entry %s", file.Name)
+		}
+		entries[file.Name] = file
+	}
+
+	for _, name := range []string{"Book_One.md", "Book_One.html", "Book_One_2.md", "Book_Two.md", batchManifestName} {
+		if entries[name] == nil {
+			t.Errorf("Expected ZIP entry %s", name)
+		}
+	}
+
+	manifestFile, err := entries[batchManifestName].Open()
+	if err != nil {
+		t.Fatalf("Failed to open manifest: %v", err)
+	}
+	defer manifestFile.Close()
+
+	data, err := io.ReadAll(manifestFile)
+	if err != nil {
+		t.Fatalf("Failed to read manifest: %v", err)
+	}
+
+	var archived batchManifest
+	if err := json.Unmarshal(data, &archived); err != nil {
+		t.Fatalf("Manifest is not valid JSON: %v", err)
+	}
+	if archived.Failed != 2 {
+		t.Errorf("Expected archived manifest to list 2 failures, got %d", archived.Failed)
+	}
+}
+
+// TestConvertBatchCancelled tests that a cancelled batch records its books as skipped and fails
+func TestConvertBatchCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	parse := func(bookPath string) (*models.Book, error) {
+		return testBatchBook(bookPath), nil
+	}
+
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	manifest, err := convertBatch(ctx, []string{"Book One", "Book Two", "Book Three"}, parse, []string{"markdown"}, 2, zipWriter, config.DefaultConfig())
+	if err != nil {
+		t.Fatalf("convertBatch failed: %v", err)
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatalf("Failed to close ZIP writer: %v", err)
+	}
+
+	if manifest.Converted != 0 || manifest.Failed != 0 || manifest.Skipped != 3 {
+		t.Errorf("Unexpected manifest counts: converted=%d failed=%d skipped=%d", manifest.Converted, manifest.Failed, manifest.Skipped)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Archive is not a valid ZIP: %v", err)
+	}
+	if len(reader.File) != 1 || reader.File[0].Name != batchManifestName {
+		t.Errorf("Expected only the manifest in the archive, got %d entries", len(reader.File))
+	}
+
+	if err := batchError(ctx, manifest); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected cancellation error, got %v", err)
+	}
+}
+
+// TestBatchError tests the exit status of batch conversions
+func TestBatchError(t *testing.T) {
+	ctx := context.Background()
+
+	if err := batchError(ctx, &batchManifest{Total: 2, Converted: 2}); err != nil {
+		t.Errorf("Expected no error for a complete batch, got %v", err)
+	}
+
+	var exitErr *exitError
+	err := batchError(ctx, &batchManifest{Total: 2, Converted: 1, Skipped: 1})
+	if !errors.As(err, &exitErr) || exitErr.code != exitPartialFailure {
+		t.Errorf("Expected partial failure for a skipped book, got %v", err)
+	}
+
+	err = batchError(ctx, &batchManifest{Total: 2, Failed: 1, Skipped: 1})
+	if err == nil || errors.As(err, &exitErr) {
+		t.Errorf("Expected plain failure when no book was converted, got %v", err)
+	}
+}
+
+// TestConvertBatchSiteLibrary tests that sites are unpacked per book below a library page
+func TestConvertBatchSiteLibrary(t *testing.T) {
+	parse := func(bookPath string) (*models.Book, error) {
+		return testBatchBook(bookPath), nil
+	}
+
+	var buf bytes.Buffer
+	zipWriter := zip.NewWriter(&buf)
+	manifest, err := convertBatch(context.Background(), []string{"Book One", "Book Two"}, parse, []string{"site", "markdown"}, 2, zipWriter, config.DefaultConfig())
+	if err != nil {
+		t.Fatalf("convertBatch failed: %v", err)
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatalf("Failed to close ZIP writer: %v", err)
+	}
+	if manifest.Converted != 2 {
+		t.Fatalf("Expected 2 converted books, got %+v", manifest)
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Archive is not a valid ZIP: %v", err)
+	}
+	entries := make(map[string]*zip.File)
+	for _, file := range reader.File {
+		entries[file.Name] = file
+	}
+
+	for _, name := range []string{"index.html", "Book_One/index.html", "Book_One/style.css", "Book_Two/search-index.json", "Book_Two.md", batchManifestName} {
+		if entries[name] == nil {
+			t.Errorf("Expected ZIP entry %s", name)
+		}
+	}
+	if entries["Book_One.site.zip"] != nil {
+		t.Error("Expected the site to be unpacked instead of nested")
+	}
+
+	library, err := entries["index.html"].Open()
+	if err != nil {
+		t.Fatalf("Failed to open library page: %v", err)
+	}
+	defer library.Close()
+	data, err := io.ReadAll(library)
+	if err != nil {
+		t.Fatalf("Failed to read library page: %v", err)
+	}
+	for _, expected := range []string{`<a href="Book_One/index.html">Book One</a>`, `<a href="Book_Two/index.html">Book Two</a>`} {
+		if !bytes.Contains(data, []byte(expected)) {
+			t.Errorf("Expected %s in library page:\n%s", expected, data)
+		}
+	}
+}
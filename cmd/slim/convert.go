@@ -2,13 +2,19 @@ package main
 
 import (
 	"archive/zip"
+	"cmp"
 	"context"
 	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"syscall"
 
 	"github.com/kjanat/slimacademy/internal/config"
 	"github.com/kjanat/slimacademy/internal/parser"
+	"github.com/kjanat/slimacademy/internal/pipeline"
 	"github.com/kjanat/slimacademy/internal/streaming"
 	"github.com/kjanat/slimacademy/internal/writers"
 	"github.com/spf13/cobra"
@@ -16,11 +22,38 @@ import (
 
 var (
 	// Convert command flags
-	convertAll    bool
-	outputFormats []string
-	outputPath    string
+	convertAll     bool
+	convertJobs    int
+	outputFormats  []string
+	outputPath     string
+	headersFooters bool
+	convertFromAPI string
+	convertCache   string
+	epubImages     bool
+
+	// Table of contents flags
+	tocPlaceholders []string
+	tocDepth        int
+	tocNested       bool
+	tocAuto         bool
+	tocDedupe       tocDedupeFlag
 )
 
+// tocDedupeFlag is the value of the --toc-dedupe flag
+type tocDedupeFlag streaming.TOCDedupe
+
+func (f *tocDedupeFlag) String() string { return streaming.TOCDedupe(*f).String() }
+func (f *tocDedupeFlag) Type() string   { return "mode" }
+
+func (f *tocDedupeFlag) Set(name string) error {
+	dedupe, err := streaming.ParseTOCDedupe(name)
+	if err != nil {
+		return err
+	}
+	*f = tocDedupeFlag(dedupe)
+	return nil
+}
+
 // convertCmd represents the convert command
 var convertCmd = &cobra.Command{
 	Use:   "convert [input]",
@@ -28,19 +61,35 @@ var convertCmd = &cobra.Command{
 	Long: `Convert SlimAcademy books to various output formats.
 
 Supports converting single books or batch processing all books in a directory.
-Output formats include markdown, HTML, LaTeX, EPUB, and plain text.
+Output formats include markdown, HTML, LaTeX, EPUB, plain text, and a static
+website with a page per chapter (site).
+
+Batch conversion converts books in parallel and adds a manifest.json entry
+summarising the outcome for each book. Books that fail to convert are listed
+in the manifest without aborting the archive; the command then exits with
+status 2.
 
 Examples:
   slim convert book1                                    # Convert to markdown (default)
   slim convert --format html book1                     # Convert to HTML
   slim convert --formats html,epub book1               # Convert to multiple formats
   slim convert --all > all-books.zip                   # All books as ZIP archive
+  slim convert --all --jobs 4 > all-books.zip          # Convert four books at a time
   slim convert book1 --output /tmp/output.md           # Specify output path
-  slim convert --config config.yaml book1              # Use custom configuration`,
+  slim convert --formats latex --headers-footers book1 # Include running headers and footers
+  slim convert --config config.yaml book1              # Use custom configuration
+  slim convert --from-api 3631 --formats html,epub     # Fetch and convert in one step
+  slim convert --from-api 3631 --cache source/         # Also keep the API responses
+  slim convert --formats site -o site/ book1           # Static website in site/
+  slim convert --toc-nested --toc-depth 2 book1        # Nested table of contents of two levels
+
+With --from-api the book is fetched from the Slim Academy API and converted in
+memory without writing source files. Log in first with 'slim fetch --login'; the
+token is read from the --cache directory, or source/ without --cache.`,
 
 	Args: func(cmd *cobra.Command, args []string) error {
-		if convertAll {
-			return nil // --all doesn't require input argument
+		if convertAll || convertFromAPI != "" {
+			return nil // --all and --from-api don't require input argument
 		}
 		if len(args) < 1 {
 			return fmt.Errorf("input path required when not using --all")
@@ -51,7 +100,22 @@ Examples:
 	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx := context.Background()
 
+		if convertFromAPI != "" {
+			if convertAll || len(args) > 0 {
+				return fmt.Errorf("--from-api cannot be combined with --all or an input path")
+			}
+			ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return runConvertFromAPI(ctx, convertFromAPI)
+		}
+
+		if convertCache != "" {
+			return fmt.Errorf("--cache requires --from-api")
+		}
+
 		if convertAll {
+			ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
 			return runConvertAll(ctx)
 		}
 
@@ -64,10 +128,9 @@ func runConvertAll(ctx context.Context) error {
 	logger.Info("Starting batch conversion", "formats", outputFormats)
 
 	// Load configuration
-	loader := config.NewLoader()
-	appConfig, err := loader.LoadConfig(configPath)
+	appConfig, err := loadConvertConfig()
 	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
+		return err
 	}
 
 	// Find all books
@@ -91,25 +154,69 @@ func runConvertAll(ctx context.Context) error {
 		}
 	}()
 
-	// Process each book
-	for _, bookPath := range books {
-		book, err := parser.ParseBook(bookPath)
-		if err != nil {
-			logger.Warn("Failed to parse book", "path", bookPath, "error", err)
-			continue
-		}
+	jobs := convertJobs
+	if jobs <= 0 {
+		jobs = runtime.NumCPU()
+	}
 
-		if err := convertBookToZip(ctx, book, outputFormats, zipWriter, appConfig); err != nil {
-			logger.Warn("Failed to convert book", "title", book.Title, "error", err)
-			continue
-		}
+	manifest, err := convertBatch(ctx, books, parser.ParseBook, outputFormats, jobs, zipWriter, appConfig)
+	if err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
 
-		logger.Debug("Book converted successfully", "title", book.Title)
+	logger.Info("Batch conversion completed",
+		"converted", manifest.Converted,
+		"failed", manifest.Failed,
+		"skipped", manifest.Skipped,
+		"total", manifest.Total)
+
+	return batchError(ctx, manifest)
+}
+
+// batchError returns the error a batch conversion exits with: an interrupted batch fails, and a
+// batch with failed or skipped books exits with exitPartialFailure if any book was converted
+func batchError(ctx context.Context, manifest *batchManifest) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("batch conversion interrupted after converting %d of %d books: %w", manifest.Converted, manifest.Total, err)
+	}
+
+	if manifest.Failed > 0 || manifest.Skipped > 0 {
+		failErr := fmt.Errorf("%d of %d books were not converted", manifest.Failed+manifest.Skipped, manifest.Total)
+		if manifest.Converted == 0 {
+			return failErr
+		}
+		return &exitError{code: exitPartialFailure, err: failErr}
 	}
 
 	return nil
 }
 
+// loadConvertConfig loads the configuration file and applies the convert flags that override it
+func loadConvertConfig() (*config.Config, error) {
+	appConfig, err := config.NewLoader().LoadConfig(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if epubImages {
+		appConfig.EPUB.IncludeImages = true
+	}
+	return appConfig, nil
+}
+
+// convertStreamOptions returns the stream options selected by the convert flags
+func convertStreamOptions() streaming.StreamOptions {
+	opts := streaming.DefaultStreamOptions()
+	opts.HeadersFooters = headersFooters
+	if tocPlaceholders != nil {
+		opts.TOC.Placeholders = tocPlaceholders
+	}
+	opts.TOC.MaxDepth = tocDepth
+	opts.TOC.Nested = tocNested
+	opts.TOC.AutoInsert = tocAuto
+	opts.TOC.Dedupe = streaming.TOCDedupe(tocDedupe)
+	return opts
+}
+
 func runConvertSingle(ctx context.Context, inputPath string) error {
 	logger := slog.Default().With("command", "convert-single", "input", inputPath)
 	logger.Info("Starting single book conversion", "formats", outputFormats, "output", outputPath)
@@ -124,44 +231,75 @@ func runConvertSingle(ctx context.Context, inputPath string) error {
 	logger.Debug("Book parsed successfully", "title", book.Title, "chapters", len(book.Chapters))
 
 	// Load configuration
-	loader := config.NewLoader()
-	appConfig, err := loader.LoadConfig(configPath)
+	appConfig, err := loadConvertConfig()
 	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
+		return err
 	}
 
-	// Create multi-writer with configuration
-	multiWriter, err := writers.NewMultiWriter(ctx, outputFormats, appConfig)
+	multiWriter, err := renderBook(ctx, book, outputFormats, appConfig)
 	if err != nil {
 		return err
 	}
 	defer multiWriter.Close()
 
-	// Create streamer
-	streamer := streaming.NewStreamer(streaming.DefaultStreamOptions())
-
-	// Process events
-	if err := multiWriter.ProcessEvents(func(yield func(streaming.Event) bool) {
-		for event := range streamer.Stream(ctx, book) {
-			if !yield(event) {
-				break
-			}
-		}
-	}); err != nil {
-		return fmt.Errorf("failed to process events: %w", err)
-	}
-
 	// Write output files
 	results, err := multiWriter.FlushAll()
 	if err != nil {
 		return fmt.Errorf("failed to get conversion results: %w", err)
 	}
 
+	return writeOutputFiles(logger, book.Title, results)
+}
+
+// runConvertFromAPI fetches a book from the API and converts it without writing source files
+func runConvertFromAPI(ctx context.Context, id string) error {
+	logger := slog.Default().With("command", "convert-from-api", "id", id)
+	logger.Info("Starting conversion from API", "formats", outputFormats, "output", outputPath)
+
+	// Load configuration
+	appConfig, err := loadConvertConfig()
+	if err != nil {
+		return err
+	}
+
+	apiClient, err := newAPIClient(appConfig, cmp.Or(convertCache, "source"))
+	if err != nil {
+		return err
+	}
+
+	result, err := pipeline.ConvertFromAPI(ctx, apiClient, id, pipeline.Options{
+		Formats:  outputFormats,
+		Config:   appConfig,
+		Stream:   convertStreamOptions(),
+		CacheDir: convertCache,
+	})
+	if err != nil {
+		return err
+	}
+
+	if convertCache != "" {
+		logger.Info("API responses cached", "dir", filepath.Join(convertCache, id))
+	}
+
+	return writeOutputFiles(logger, result.Book.Title, result.Outputs)
+}
+
+// writeOutputFiles writes conversion results to --output or to files named after the book title
+func writeOutputFiles(logger *slog.Logger, title string, results []writers.OutputResult) error {
 	for _, result := range results {
+		if result.Format == siteFormat && isSiteDirectory(outputPath) {
+			count, err := extractSite(result.Data, outputPath)
+			if err != nil {
+				return fmt.Errorf("failed to write %s directory: %w", result.Format, err)
+			}
+			logger.Info("Output directory written", "format", result.Format, "dir", outputPath, "files", count)
+			continue
+		}
+
 		filename := outputPath
 		if filename == "" {
 			// Generate filename based on book title and format
-			filename = fmt.Sprintf("%s%s", sanitizeFilename(book.Title), result.Extension)
+			filename = fmt.Sprintf("%s%s", sanitizeFilename(title), result.Extension)
 		}
 
 		if err := os.WriteFile(filename, result.Data, 0644); err != nil {
@@ -183,8 +321,18 @@ func init() {
 
 	// Convert-specific flags
 	convertCmd.Flags().BoolVar(&convertAll, "all", false, "Convert all books in directory to all formats as ZIP to stdout")
-	convertCmd.Flags().StringSliceVarP(&outputFormats, "formats", "f", []string{"markdown"}, "Output formats (markdown,html,latex,epub,plaintext)")
-	convertCmd.Flags().StringVarP(&outputPath, "output", "o", "", "Output file/directory path")
+	convertCmd.Flags().IntVarP(&convertJobs, "jobs", "j", 0, "Number of books to convert in parallel with --all (default: number of CPUs)")
+	convertCmd.Flags().StringSliceVarP(&outputFormats, "formats", "f", []string{"markdown"}, "Output formats (markdown,html,latex,epub,plaintext,hast,html-hast,site)")
+	convertCmd.Flags().StringVarP(&outputPath, "output", "o", "", "Output file path, or directory for the site format")
+	convertCmd.Flags().BoolVar(&headersFooters, "headers-footers", false, "Render the document's running headers and footers")
+	convertCmd.Flags().BoolVar(&epubImages, "epub-images", false, "Download the images of EPUB books and embed them")
+	convertCmd.Flags().StringVar(&convertFromAPI, "from-api", "", "Fetch the book with this ID from the API and convert it without writing source files")
+	convertCmd.Flags().StringVar(&convertCache, "cache", "", "With --from-api, also save the API responses below this directory")
+	convertCmd.Flags().StringSliceVar(&tocPlaceholders, "toc-placeholder", nil, "Heading texts followed by the table of contents (default: Inhoudsopgave, Table of Contents, Contents, Index)")
+	convertCmd.Flags().IntVar(&tocDepth, "toc-depth", 0, "Number of heading levels in the table of contents (0 for all)")
+	convertCmd.Flags().BoolVar(&tocNested, "toc-nested", false, "Nest the table of contents by heading level")
+	convertCmd.Flags().BoolVar(&tocAuto, "toc-auto", false, "Insert the table of contents at the start when no heading is a placeholder")
+	convertCmd.Flags().Var(&tocDedupe, "toc-dedupe", "Repeated headings left out of the table of contents: keep, siblings or text")
 
 	// Deprecated --format flag for backwards compatibility
 	convertCmd.Flags().String("format", "", "Single output format (deprecated, use --formats)")
@@ -189,4 +189,27 @@ func TestBinaryDataIntegrity(t *testing.T) {
 		// can corrupt binary data, especially with invalid UTF-8 sequences
 		t.Log("Warning: string conversion didn't corrupt data on this system")
 	}
-}
\ No newline at end of file
+}
+// TestLoadConvertConfigEPUBImages tests that images are only downloaded with --epub-images
+func TestLoadConvertConfigEPUBImages(t *testing.T) {
+	defer func(path string, images bool) { configPath, epubImages = path, images }(configPath, epubImages)
+	configPath = ""
+
+	epubImages = false
+	appConfig, err := loadConvertConfig()
+	if err != nil {
+		t.Fatalf("loadConvertConfig() failed: %v", err)
+	}
+	if appConfig.EPUB.IncludeImages {
+		t.Error("Expected EPUB images to be left remote by default")
+	}
+
+	epubImages = true
+	appConfig, err = loadConvertConfig()
+	if err != nil {
+		t.Fatalf("loadConvertConfig() failed: %v", err)
+	}
+	if !appConfig.EPUB.IncludeImages {
+		t.Error("Expected --epub-images to embed EPUB images")
+	}
+}
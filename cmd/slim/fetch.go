@@ -3,22 +3,32 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
-	"path/filepath"
+	"os/signal"
+	"syscall"
 
 	"github.com/kjanat/slimacademy/internal/client"
+	"github.com/kjanat/slimacademy/internal/source"
 	"github.com/spf13/cobra"
 )
 
 var (
 	// Fetch command flags
-	fetchAll    bool
-	fetchLogin  bool
-	fetchBookID string
-	fetchOutput string
-	fetchClean  bool
+	fetchAll          bool
+	fetchLogin        bool
+	fetchBookID       string
+	fetchOutput       string
+	fetchClean        bool
+	fetchRetries      int
+	fetchRate         float64
+	fetchWorkers      int
+	fetchProgressMode string
+	fetchSync         bool
+	fetchPrune        bool
 )
 
 // fetchCmd represents the fetch command
@@ -34,19 +44,37 @@ Examples:
   slim fetch --login                          # Login and save authentication
   slim fetch --all                            # Fetch all books to source/
   slim fetch --id 3631                        # Fetch specific book by ID
-  slim fetch --all --output data/ --clean     # Fetch all books to data/, clean first`,
+  slim fetch --all --output data/ --clean     # Fetch all books to data/, clean first
+  slim fetch --all --workers 8 --progress json  # Eight books at a time, JSON lines progress
+  slim fetch --sync                           # Fetch only new and changed books
+  slim fetch --sync --prune                   # Also delete books removed from the library`,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runFetch(context.Background())
+		// Ctrl-C stops starting new books; books already written stay on disk
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return runFetch(ctx)
 	},
 }
 
 func runFetch(ctx context.Context) error {
 	logger := slog.Default().With("command", "fetch")
 
+	// --sync is an incremental --all
+	if fetchSync {
+		if fetchClean {
+			return fmt.Errorf("--sync cannot be combined with --clean")
+		}
+		fetchAll = true
+	}
+
+	if fetchPrune && !fetchSync {
+		return fmt.Errorf("--prune requires --sync")
+	}
+
 	// Validate options
 	if !fetchLogin && !fetchAll && fetchBookID == "" {
-		return fmt.Errorf("specify --login, --all, or --id <id>")
+		return fmt.Errorf("specify --login, --all, --sync, or --id <id>")
 	}
 
 	if fetchLogin && (fetchAll || fetchBookID != "") {
@@ -64,7 +92,14 @@ func runFetch(ctx context.Context) error {
 	}
 
 	// Create API client
-	apiClient := client.NewSlimClient(outputDir)
+	apiClient, err := newAPIClient(nil, outputDir)
+	if err != nil {
+		return err
+	}
+	policy := client.DefaultRetryPolicy()
+	policy.MaxAttempts = fetchRetries + 1
+	apiClient.SetRetryPolicy(policy)
+	apiClient.SetRateLimiter(client.NewRateLimiter(fetchRate, max(int(fetchRate), 1)))
 
 	// Handle login-only mode
 	if fetchLogin {
@@ -100,30 +135,7 @@ func runFetch(ctx context.Context) error {
 
 	// Handle fetch all books
 	if fetchAll {
-		logger.Info("Fetching all books from library")
-		books, err := apiClient.FetchLibraryBooks(ctx)
-		if err != nil {
-			return fmt.Errorf("failed to fetch library books: %w", err)
-		}
-
-		logger.Info("Found books in library", "count", len(books))
-		fmt.Printf("Found %d books in library\n", len(books))
-
-		for i, book := range books {
-			logger.Info("Processing book", "index", i+1, "total", len(books), "id", book.ID, "title", book.Title)
-			fmt.Printf("(%d/%d) Fetching book: %s\n", i+1, len(books), book.Title)
-
-			if err := writeBookFiles(outputDir, book, logger); err != nil {
-				logger.Warn("Failed to write book files", "id", book.ID, "title", book.Title, "error", err)
-				fmt.Printf("  ⚠️  Failed to write files for book %s: %v\n", book.Title, err)
-				continue
-			}
-
-			fmt.Printf("  ✅ Successfully saved book %s\n", book.Title)
-		}
-
-		fmt.Printf("\n✅ Successfully fetched %d books to %s/\n", len(books), outputDir)
-		return nil
+		return fetchLibrary(ctx, apiClient, outputDir, logger)
 	}
 
 	// Handle fetch single book by ID
@@ -138,7 +150,7 @@ func runFetch(ctx context.Context) error {
 
 		logger.Info("Book fetched successfully", "id", book.ID, "title", book.Title)
 
-		if err := writeBookFiles(outputDir, book, logger); err != nil {
+		if _, err := source.NewSourceManager(outputDir).SaveBook(book.ID, book, ""); err != nil {
 			return fmt.Errorf("failed to write book files: %w", err)
 		}
 
@@ -150,70 +162,137 @@ func runFetch(ctx context.Context) error {
 	return fmt.Errorf("internal error: no valid fetch mode selected")
 }
 
-// writeBookFiles writes the book data to the expected file structure
-func writeBookFiles(outputDir string, book *client.BookData, logger *slog.Logger) error {
-	// Create book directory
-	bookDir := filepath.Join(outputDir, book.ID)
-	if err := os.MkdirAll(bookDir, 0755); err != nil {
-		return fmt.Errorf("failed to create book directory: %w", err)
+// fetchLibrary fetches library books with a pool of workers, writing each book to disk as
+// soon as it is complete so that an interrupted fetch keeps the books finished so far. With
+// --sync only new and changed books are fetched and a sync report is printed.
+func fetchLibrary(ctx context.Context, apiClient *client.SlimClient, outputDir string, logger *slog.Logger) error {
+	progress, err := newFetchProgress(fetchProgressMode, os.Stdout)
+	if err != nil {
+		return err
 	}
 
-	// Write summary file ({id}.json)
-	summaryFile := filepath.Join(bookDir, book.ID+".json")
-	summaryData, err := json.MarshalIndent(book.Summary, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal summary: %w", err)
+	if err := apiClient.EnsureAuthenticated(ctx); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
 	}
-	if err := os.WriteFile(summaryFile, summaryData, 0644); err != nil {
-		return fmt.Errorf("failed to write summary file: %w", err)
+
+	library, err := apiClient.GetLibrary(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch library: %w", err)
 	}
-	logger.Debug("Wrote summary file", "path", summaryFile, "size", len(summaryData))
 
-	// Write chapters file
-	chaptersFile := filepath.Join(bookDir, "chapters.json")
-	chaptersData, err := json.MarshalIndent(book.Chapters, "", "  ")
+	plan, err := source.NewSourceManager(outputDir).PlanSync(library.Summaries)
 	if err != nil {
-		return fmt.Errorf("failed to marshal chapters: %w", err)
+		return fmt.Errorf("failed to compare library with %s: %w", outputDir, err)
 	}
-	if err := os.WriteFile(chaptersFile, chaptersData, 0644); err != nil {
-		return fmt.Errorf("failed to write chapters file: %w", err)
+
+	books := library.Summaries
+	if fetchSync {
+		books = plan.Fetch
+		logger.Info("Synchronising library", "library", len(library.Summaries), "fetch", len(books))
+	}
+	if books == nil {
+		books = []client.LibrarySummary{} // Nil would fetch the whole library
 	}
-	logger.Debug("Wrote chapters file", "path", chaptersFile, "size", len(chaptersData))
 
-	// Write content file
-	contentFile := filepath.Join(bookDir, "content.json")
-	contentData, err := json.MarshalIndent(book.Content, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal content: %w", err)
+	logger.Info("Fetching books from library", "count", len(books), "workers", fetchWorkers)
+
+	saved := 0
+	err = apiClient.FetchLibrary(ctx, client.LibraryFetchOptions{
+		Workers:  fetchWorkers,
+		Books:    books,
+		Progress: progress.Event,
+		OnBook: func(index int, book *client.BookData) error {
+			status, err := plan.SaveBook(book)
+			if err != nil {
+				return fmt.Errorf("failed to write book files: %w", err)
+			}
+			logger.Debug("Saved book", "id", book.ID, "title", book.Title, "status", status)
+			saved++
+			return nil
+		},
+	})
+	progress.Finish()
+
+	var libErr *client.LibraryFetchError
+	if errors.As(err, &libErr) {
+		for _, failure := range libErr.Failures {
+			logger.Warn("Failed to fetch book", "id", failure.ID, "title", failure.Title, "error", failure.Err)
+		}
 	}
-	if err := os.WriteFile(contentFile, contentData, 0644); err != nil {
-		return fmt.Errorf("failed to write content file: %w", err)
+
+	if ctx.Err() != nil {
+		logger.Warn("Fetch interrupted", "saved", saved)
+		return fmt.Errorf("fetch interrupted after saving %d books to %s/: %w", saved, outputDir, ctx.Err())
 	}
-	logger.Debug("Wrote content file", "path", contentFile, "size", len(contentData))
 
-	// Write notes file (optional, may be empty)
-	if book.Notes != nil {
-		notesFile := filepath.Join(bookDir, "notes.json")
-		notesData, err := json.MarshalIndent(book.Notes, "", "  ")
-		if err != nil {
-			return fmt.Errorf("failed to marshal notes: %w", err)
+	if err != nil && libErr == nil {
+		return fmt.Errorf("failed to fetch library books: %w", err)
+	}
+
+	logger.Info("Library fetch completed", "saved", saved, "output", outputDir)
+
+	if fetchSync {
+		if fetchPrune && libErr == nil {
+			if err := plan.Prune(); err != nil {
+				return err
+			}
 		}
-		if err := os.WriteFile(notesFile, notesData, 0644); err != nil {
-			return fmt.Errorf("failed to write notes file: %w", err)
+		_, jsonOutput := progress.(*jsonProgress)
+		printSyncReport(os.Stdout, plan.Report(), jsonOutput)
+	}
+
+	if libErr != nil {
+		failErr := fmt.Errorf("%d of %d books could not be fetched", len(libErr.Failures), libErr.Total)
+		if saved == 0 {
+			return failErr
 		}
-		logger.Debug("Wrote notes file", "path", notesFile, "size", len(notesData))
+		return &exitError{code: exitPartialFailure, err: failErr}
 	}
 
 	return nil
 }
 
+// printSyncReport prints the books added, updated and removed by a sync, as a JSON line when
+// progress is reported as JSON
+func printSyncReport(w io.Writer, report *source.SyncReport, jsonOutput bool) {
+	if jsonOutput {
+		_ = json.NewEncoder(w).Encode(struct {
+			Event string `json:"event"`
+			*source.SyncReport
+		}{"sync", report})
+		return
+	}
+
+	fmt.Fprintf(w, "Sync complete: %d added, %d updated, %d unchanged, %d removed\n",
+		len(report.Added), len(report.Updated), len(report.Unchanged), len(report.Removed))
+	for _, entry := range report.Added {
+		fmt.Fprintf(w, "  + %s (%s)\n", entry.Title, entry.ID)
+	}
+	for _, entry := range report.Updated {
+		fmt.Fprintf(w, "  ~ %s (%s)\n", entry.Title, entry.ID)
+	}
+	for _, entry := range report.Removed {
+		if report.Pruned {
+			fmt.Fprintf(w, "  - %s (%s) deleted\n", entry.Title, entry.ID)
+		} else {
+			fmt.Fprintf(w, "  - %s (%s) no longer in library, kept in %s (use --prune to delete)\n", entry.Title, entry.ID, entry.Dir)
+		}
+	}
+}
+
 func init() {
 	rootCmd.AddCommand(fetchCmd)
 
 	// Fetch-specific flags
 	fetchCmd.Flags().BoolVar(&fetchLogin, "login", false, "Login only (authenticate and save token)")
 	fetchCmd.Flags().BoolVar(&fetchAll, "all", false, "Fetch all books from library")
+	fetchCmd.Flags().BoolVar(&fetchSync, "sync", false, "Fetch only new and changed library books and report the differences")
+	fetchCmd.Flags().BoolVar(&fetchPrune, "prune", false, "With --sync, delete books that are no longer in the library")
 	fetchCmd.Flags().StringVar(&fetchBookID, "id", "", "Fetch specific book by ID")
 	fetchCmd.Flags().StringVarP(&fetchOutput, "output", "o", "source", "Output directory")
 	fetchCmd.Flags().BoolVar(&fetchClean, "clean", false, "Clean output directory before fetching")
+	fetchCmd.Flags().IntVar(&fetchRetries, "retries", client.DefaultRetryPolicy().MaxAttempts-1, "Retries for rate-limited or failed requests")
+	fetchCmd.Flags().Float64Var(&fetchRate, "rate", 10, "Maximum requests per second (0 for unlimited)")
+	fetchCmd.Flags().IntVar(&fetchWorkers, "workers", client.DefaultFetchWorkers, "Number of books fetched in parallel with --all")
+	fetchCmd.Flags().StringVar(&fetchProgressMode, "progress", progressAuto, "Progress display with --all: auto, text, json or none (auto uses JSON lines when stdout is not a terminal)")
 }
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/kjanat/slimacademy/internal/client"
+)
+
+// Progress display modes for fetch --all
+const (
+	progressAuto = "auto"
+	progressText = "text"
+	progressJSON = "json"
+	progressNone = "none"
+)
+
+// fetchProgress renders library fetch progress events
+type fetchProgress interface {
+	Event(event client.ProgressEvent)
+	Finish()
+}
+
+// newFetchProgress creates the renderer for the given mode. Auto renders a live display on a
+// terminal and JSON lines otherwise.
+func newFetchProgress(mode string, out *os.File) (fetchProgress, error) {
+	switch mode {
+	case progressAuto:
+		if isTerminal(out) {
+			return newTextProgress(out, true), nil
+		}
+		return newJSONProgress(out), nil
+	case progressText:
+		return newTextProgress(out, isTerminal(out)), nil
+	case progressJSON:
+		return newJSONProgress(out), nil
+	case progressNone:
+		return noProgress{}, nil
+	default:
+		return nil, fmt.Errorf("unknown progress mode %q (use auto, text, json or none)", mode)
+	}
+}
+
+// isTerminal reports whether f is a character device such as a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// noProgress discards progress events
+type noProgress struct{}
+
+func (noProgress) Event(client.ProgressEvent) {}
+func (noProgress) Finish()                    {}
+
+// jsonProgress writes every progress event as a JSON line
+type jsonProgress struct {
+	enc *json.Encoder
+}
+
+func newJSONProgress(w io.Writer) *jsonProgress {
+	return &jsonProgress{enc: json.NewEncoder(w)}
+}
+
+func (p *jsonProgress) Event(event client.ProgressEvent) {
+	_ = p.enc.Encode(event)
+}
+
+func (p *jsonProgress) Finish() {}
+
+// activeBook is a book currently being fetched
+type activeBook struct {
+	index int
+	title string
+	parts int
+}
+
+// textProgress prints a line per finished book and, on a terminal, keeps a status line with
+// the books in flight below them
+type textProgress struct {
+	w    io.Writer
+	live bool
+
+	total  int
+	done   int
+	failed int
+	active map[int]*activeBook
+	status bool // A status line is currently displayed
+}
+
+func newTextProgress(w io.Writer, live bool) *textProgress {
+	return &textProgress{w: w, live: live, active: make(map[int]*activeBook)}
+}
+
+func (p *textProgress) Event(event client.ProgressEvent) {
+	p.total = event.Total
+
+	switch event.Kind {
+	case client.ProgressLibrary:
+		p.println(fmt.Sprintf("Fetching %d books", event.Total))
+	case client.ProgressBookStart:
+		p.active[event.Index] = &activeBook{index: event.Index, title: bookLabel(event)}
+	case client.ProgressBookPart:
+		if book := p.active[event.Index]; book != nil {
+			book.parts = event.Parts
+		}
+	case client.ProgressBookDone:
+		delete(p.active, event.Index)
+		p.done++
+		p.println(fmt.Sprintf("(%d/%d) ✅ %s", p.done+p.failed, p.total, bookLabel(event)))
+	case client.ProgressBookFailed:
+		delete(p.active, event.Index)
+		p.failed++
+		p.println(fmt.Sprintf("(%d/%d) ⚠️  %s: %s", p.done+p.failed, p.total, bookLabel(event), event.Error))
+	}
+
+	p.drawStatus()
+}
+
+func (p *textProgress) Finish() {
+	p.clearStatus()
+}
+
+// println prints a permanent line above the status line
+func (p *textProgress) println(line string) {
+	p.clearStatus()
+	fmt.Fprintln(p.w, line)
+}
+
+// drawStatus redraws the status line with the books in flight
+func (p *textProgress) drawStatus() {
+	if !p.live || len(p.active) == 0 {
+		p.clearStatus()
+		return
+	}
+
+	books := make([]*activeBook, 0, len(p.active))
+	for _, book := range p.active {
+		books = append(books, book)
+	}
+	slices.SortFunc(books, func(a, b *activeBook) int { return a.index - b.index })
+
+	var fetching []string
+	for _, book := range books {
+		fetching = append(fetching, fmt.Sprintf("%s %s", truncate(book.title, 30), partsBar(book.parts)))
+	}
+
+	fmt.Fprintf(p.w, "\r\033[K[%d/%d] %s", p.done+p.failed, p.total, strings.Join(fetching, "  "))
+	p.status = true
+}
+
+// clearStatus removes the status line
+func (p *textProgress) clearStatus() {
+	if p.status {
+		fmt.Fprint(p.w, "\r\033[K")
+		p.status = false
+	}
+}
+
+// bookLabel returns the title of the book in an event, falling back to its ID
+func bookLabel(event client.ProgressEvent) string {
+	if event.Title != "" {
+		return event.Title
+	}
+	return "book " + event.BookID
+}
+
+// partsBar renders the fetched parts of a book, e.g. [##..]
+func partsBar(parts int) string {
+	parts = min(max(parts, 0), client.BookParts)
+	return "[" + strings.Repeat("#", parts) + strings.Repeat(".", client.BookParts-parts) + "]"
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
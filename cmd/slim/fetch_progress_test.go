@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kjanat/slimacademy/internal/client"
+)
+
+func progressEvents() []client.ProgressEvent {
+	return []client.ProgressEvent{
+		{Kind: client.ProgressLibrary, Total: 2},
+		{Kind: client.ProgressBookStart, Index: 0, Total: 2, BookID: "1", Title: "Anatomy"},
+		{Kind: client.ProgressBookStart, Index: 1, Total: 2, BookID: "2"},
+		{Kind: client.ProgressBookPart, Index: 0, Total: 2, BookID: "1", Part: client.PartSummary, Parts: 1},
+		{Kind: client.ProgressBookDone, Index: 0, Total: 2, BookID: "1", Title: "Anatomy", Parts: 4},
+		{Kind: client.ProgressBookFailed, Index: 1, Total: 2, BookID: "2", Error: "API request failed with status 500"},
+	}
+}
+
+func TestTextProgress(t *testing.T) {
+	t.Run("plain", func(t *testing.T) {
+		var buf bytes.Buffer
+		progress := newTextProgress(&buf, false)
+		for _, event := range progressEvents() {
+			progress.Event(event)
+		}
+		progress.Finish()
+
+		expected := "Fetching 2 books\n" +
+			"(1/2) ✅ Anatomy\n" +
+			"(2/2) ⚠️  book 2: API request failed with status 500\n"
+		if buf.String() != expected {
+			t.Errorf("Expected:\n%q\nGot:\n%q", expected, buf.String())
+		}
+	})
+
+	t.Run("live", func(t *testing.T) {
+		var buf bytes.Buffer
+		progress := newTextProgress(&buf, true)
+		for _, event := range progressEvents() {
+			progress.Event(event)
+		}
+		progress.Finish()
+
+		output := buf.String()
+		if !strings.Contains(output, "[0/2] Anatomy [#...]  book 2 [....]") {
+			t.Errorf("Expected status line with books in flight, got %q", output)
+		}
+		if !strings.HasSuffix(output, "\n") {
+			t.Errorf("Expected status line to be cleared at the end, got %q", output)
+		}
+	})
+}
+
+func TestJSONProgress(t *testing.T) {
+	var buf bytes.Buffer
+	progress := newJSONProgress(&buf)
+	for _, event := range progressEvents() {
+		progress.Event(event)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(progressEvents()) {
+		t.Fatalf("Expected one JSON line per event, got %d", len(lines))
+	}
+
+	var last map[string]any
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &last); err != nil {
+		t.Fatalf("Invalid JSON line %q: %v", lines[len(lines)-1], err)
+	}
+	if last["event"] != "failed" || last["id"] != "2" || last["error"] == nil {
+		t.Errorf("Unexpected failed event %v", last)
+	}
+}
+
+func TestNewFetchProgress_UnknownMode(t *testing.T) {
+	if _, err := newFetchProgress("fancy", nil); err == nil {
+		t.Error("Expected error for unknown progress mode")
+	}
+}
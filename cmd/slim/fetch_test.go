@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kjanat/slimacademy/internal/source"
+)
+
+func TestPrintSyncReport(t *testing.T) {
+	report := &source.SyncReport{
+		Added:     []source.SyncEntry{{ID: "4", Title: "Dentistry", Dir: "source/4"}},
+		Updated:   []source.SyncEntry{{ID: "2", Title: "Biology", Dir: "source/2"}},
+		Unchanged: []source.SyncEntry{{ID: "1", Title: "Anatomy", Dir: "source/1"}},
+		Removed:   []source.SyncEntry{{ID: "3", Title: "Chemistry", Dir: "source/3"}},
+	}
+
+	t.Run("text", func(t *testing.T) {
+		var buf bytes.Buffer
+		printSyncReport(&buf, report, false)
+
+		output := buf.String()
+		for _, expected := range []string{
+			"Sync complete: 1 added, 1 updated, 1 unchanged, 1 removed",
+			"  + Dentistry (4)",
+			"  ~ Biology (2)",
+			"  - Chemistry (3) no longer in library, kept in source/3 (use --prune to delete)",
+		} {
+			if !strings.Contains(output, expected) {
+				t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+			}
+		}
+		if strings.Contains(output, "Anatomy") {
+			t.Error("Expected unchanged books to be counted but not listed")
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var buf bytes.Buffer
+		printSyncReport(&buf, report, true)
+
+		var decoded map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+			t.Fatalf("Invalid JSON %q: %v", buf.String(), err)
+		}
+		if decoded["event"] != "sync" {
+			t.Errorf("Expected sync event, got %v", decoded["event"])
+		}
+		if added, _ := decoded["added"].([]any); len(added) != 1 {
+			t.Errorf("Expected 1 added book, got %v", decoded["added"])
+		}
+	})
+}
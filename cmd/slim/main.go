@@ -5,6 +5,7 @@ package main
 
 import (
 	"archive/zip"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -12,7 +13,7 @@ import (
 
 	"github.com/kjanat/slimacademy/internal/config"
 	"github.com/kjanat/slimacademy/internal/models"
-	"github.com/kjanat/slimacademy/internal/streaming"
+	"github.com/kjanat/slimacademy/internal/pipeline"
 	"github.com/kjanat/slimacademy/internal/writers"
 )
 
@@ -25,52 +26,61 @@ func main() {
 // Each file is named using a sanitized version of the book's title and the appropriate file extension.
 // Returns an error if conversion or writing to the ZIP archive fails.
 func convertBookToZip(ctx context.Context, book *models.Book, formats []string, zipWriter *zip.Writer, appConfig *config.Config) error {
-	// Create multi-writer with configuration
-	multiWriter, err := writers.NewMultiWriter(ctx, formats, appConfig)
+	multiWriter, err := renderBook(ctx, book, formats, appConfig)
 	if err != nil {
 		return err
 	}
 	defer multiWriter.Close()
 
-	// Create streamer
-	streamer := streaming.NewStreamer(streaming.DefaultStreamOptions())
+	_, err = writeBookToZip(zipWriter, book.Title, multiWriter, nil)
+	return err
+}
 
-	// Process events
-	if err := multiWriter.ProcessEvents(func(yield func(streaming.Event) bool) {
-		for event := range streamer.Stream(ctx, book) {
-			if !yield(event) {
-				break
-			}
-		}
-	}); err != nil {
-		return fmt.Errorf("failed to process events: %w", err)
-	}
+// renderBook streams a book through writers for all formats. The caller flushes the returned
+// MultiWriter and must close it.
+func renderBook(ctx context.Context, book *models.Book, formats []string, appConfig *config.Config) (*writers.MultiWriter, error) {
+	return pipeline.Render(ctx, book, formats, appConfig, convertStreamOptions())
+}
 
-	// Get results and write to ZIP
-	results, err := multiWriter.FlushAll()
-	if err != nil {
-		return fmt.Errorf("failed to get conversion results: %w", err)
-	}
+// writeBookToZip flushes each writer into a ZIP entry named after the book title and returns the entry names.
+// Static sites are unpacked into a directory named after the book title instead.
+// When usedNames is non-nil, names already present in the archive get a numeric suffix.
+func writeBookToZip(zipWriter *zip.Writer, title string, multiWriter *writers.MultiWriter, usedNames map[string]bool) ([]string, error) {
+	baseTitle := sanitizeFilename(title)
+	var filenames []string
+	var site *bytes.Buffer
+
+	_, err := multiWriter.FlushAllTo(func(result writers.OutputResult) (io.Writer, error) {
+		if result.Format == siteFormat {
+			site = &bytes.Buffer{}
+			return site, nil
+		}
 
-	// Write each format to ZIP
-	for _, result := range results {
 		// Generate filename
-		baseTitle := sanitizeFilename(book.Title)
-		filename := fmt.Sprintf("%s%s", baseTitle, result.Extension)
+		filename := uniqueEntryName(fmt.Sprintf("%s%s", baseTitle, result.Extension), usedNames)
 
 		// Create file in ZIP
 		fileWriter, err := zipWriter.Create(filename)
 		if err != nil {
-			return fmt.Errorf("failed to create ZIP entry %s: %w", filename, err)
+			return nil, fmt.Errorf("failed to create ZIP entry %s: %w", filename, err)
 		}
 
-		// Write content
-		if _, err := fileWriter.Write(result.Data); err != nil {
-			return fmt.Errorf("failed to write content to ZIP entry %s: %w", filename, err)
+		filenames = append(filenames, filename)
+		return fileWriter, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to write conversion results: %w", err)
+	}
+
+	if site != nil {
+		names, err := copySiteToZip(zipWriter, uniqueSiteDir(baseTitle, usedNames), site.Bytes())
+		if err != nil {
+			return nil, err
 		}
+		filenames = append(filenames, names...)
 	}
 
-	return nil
+	return filenames, nil
 }
 
 // sanitizeFilename creates a safe filename from a book title
@@ -109,6 +119,12 @@ func getFormatExtension(format string) string {
 		return "epub"
 	case "plaintext":
 		return "txt"
+	case "hast":
+		return "hast.json"
+	case "html-hast":
+		return "hast.html"
+	case "site":
+		return "site.zip"
 	default:
 		return format
 	}
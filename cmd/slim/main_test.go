@@ -40,6 +40,9 @@ func TestGetFormatExtension(t *testing.T) {
 		{"latex", "tex"},
 		{"epub", "epub"},
 		{"plaintext", "txt"},
+		{"hast", "hast.json"},
+		{"html-hast", "hast.html"},
+		{"site", "site.zip"},
 		{"unknown", "unknown"},
 	}
 
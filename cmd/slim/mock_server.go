@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kjanat/slimacademy/internal/mockapi"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Mock server command flags
+	mockSource      string
+	mockAddr        string
+	mockUsername    string
+	mockPassword    string
+	mockTokenTTL    time.Duration
+	mockLatency     time.Duration
+	mockErrorRate   float64
+	mockErrorStatus int
+	mockRetryAfter  time.Duration
+	mockFailBooks   []string
+	mockSeed        uint64
+)
+
+// mockServerCmd represents the mock-server command
+var mockServerCmd = &cobra.Command{
+	Use:   "mock-server",
+	Short: "Serve book directories through a local mock API",
+	Long: `Serve fetched book directories through the SlimAcademy API endpoints.
+
+The mock server answers login, refresh, library, summary, chapters, content and
+list-notes requests from the books in --source, so fetch and sync can be run
+without network access. Point the client at it with SLIM_API_URL or the api
+section of the configuration file.
+
+Examples:
+  slim mock-server --source ./source                  # Serve on 127.0.0.1:8080
+  slim mock-server --latency 200ms --error-rate 0.1   # Slow and unreliable API
+  slim mock-server --token-ttl 10s                    # Exercise token refreshes
+  slim mock-server --fail-book 3631                   # Book 3631 always fails
+
+  SLIM_API_URL=http://127.0.0.1:8080 slim fetch --all --output mirror/`,
+
+	Args: cobra.NoArgs,
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if mockErrorRate < 0 || mockErrorRate > 1 {
+			return fmt.Errorf("--error-rate must be between 0 and 1")
+		}
+		if mockPassword != "" && mockUsername == "" {
+			return fmt.Errorf("--password requires --username")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		listener, err := net.Listen("tcp", mockAddr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %w", mockAddr, err)
+		}
+		return runMockServer(ctx, cmd.OutOrStdout(), listener)
+	},
+}
+
+func runMockServer(ctx context.Context, w io.Writer, listener net.Listener) error {
+	logger := slog.Default().With("command", "mock-server", "source", mockSource)
+
+	server, err := mockapi.New(mockapi.Options{
+		SourceDir:   mockSource,
+		Username:    mockUsername,
+		Password:    mockPassword,
+		TokenTTL:    mockTokenTTL,
+		Latency:     mockLatency,
+		ErrorRate:   mockErrorRate,
+		ErrorStatus: mockErrorStatus,
+		RetryAfter:  mockRetryAfter,
+		FailBooks:   mockFailBooks,
+		Seed:        mockSeed,
+	})
+	if err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to start mock server: %w", err)
+	}
+
+	url := "http://" + listener.Addr().String()
+	logger.Info("Mock API listening", "url", url, "books", len(server.Books()))
+	fmt.Fprintf(w, "Serving %d books from %s at %s\n", len(server.Books()), mockSource, url)
+	fmt.Fprintf(w, "Use it with: SLIM_API_URL=%s slim fetch --all\n", url)
+
+	httpServer := &http.Server{Handler: server, ReadHeaderTimeout: 10 * time.Second}
+	done := make(chan error, 1)
+	go func() { done <- httpServer.Serve(listener) }()
+
+	select {
+	case err := <-done:
+		return fmt.Errorf("mock server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to stop mock server: %w", err)
+	}
+	if err := <-done; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("mock server failed: %w", err)
+	}
+
+	stats := server.Stats()
+	logger.Info("Mock API stopped", "requests", stats.Requests, "logins", stats.Logins,
+		"refreshes", stats.Refreshes, "unauthorized", stats.Unauthorized, "injected_errors", stats.InjectedErrors)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(mockServerCmd)
+
+	mockServerCmd.Flags().StringVar(&mockSource, "source", "source", "Directory with the book directories to serve")
+	mockServerCmd.Flags().StringVar(&mockAddr, "addr", "127.0.0.1:8080", "Address to listen on")
+	mockServerCmd.Flags().StringVar(&mockUsername, "username", "", "Accepted username (default: accept any login)")
+	mockServerCmd.Flags().StringVar(&mockPassword, "password", "", "Accepted password, with --username")
+	mockServerCmd.Flags().DurationVar(&mockTokenTTL, "token-ttl", time.Hour, "Lifetime of issued access tokens")
+	mockServerCmd.Flags().DurationVar(&mockLatency, "latency", 0, "Delay added to every response")
+	mockServerCmd.Flags().Float64Var(&mockErrorRate, "error-rate", 0, "Fraction of library and book requests that fail (0-1)")
+	mockServerCmd.Flags().IntVar(&mockErrorStatus, "error-status", http.StatusServiceUnavailable, "Status of injected errors")
+	mockServerCmd.Flags().DurationVar(&mockRetryAfter, "retry-after", 0, "Retry-After sent with injected errors")
+	mockServerCmd.Flags().StringSliceVar(&mockFailBooks, "fail-book", nil, "Book IDs whose requests always fail (repeatable)")
+	mockServerCmd.Flags().Uint64Var(&mockSeed, "seed", 0, "Seed for reproducible error injection (default: random)")
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunMockServer(t *testing.T) {
+	mockSource = t.TempDir()
+	bookDir := filepath.Join(mockSource, "Anatomy")
+	if err := os.MkdirAll(bookDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{
+		"7.json":          `{"id": 7, "title": "Anatomy"}`,
+		"chapters.json":   `[]`,
+		"content.json":    `{}`,
+		"list-notes.json": `[]`,
+	} {
+		if err := os.WriteFile(filepath.Join(bookDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var output bytes.Buffer
+	done := make(chan error, 1)
+	go func() { done <- runMockServer(ctx, &output, listener) }()
+
+	// Requests without a token are rejected once the server is up
+	url := "http://" + listener.Addr().String() + "/api/summary/library"
+	var resp *http.Response
+	for range 50 {
+		if resp, err = http.Get(url); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("Mock server did not answer: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without token, got %d", resp.StatusCode)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("runMockServer() failed: %v", err)
+	}
+	if !strings.Contains(output.String(), "Serving 1 books from "+mockSource) {
+		t.Errorf("Unexpected output:\n%s", output.String())
+	}
+}
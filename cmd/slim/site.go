@@ -0,0 +1,145 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/kjanat/slimacademy/internal/templates"
+	"github.com/kjanat/slimacademy/internal/writers"
+)
+
+// siteFormat is the format whose output is a ZIP archive of a static site
+const siteFormat = "site"
+
+// isSiteDirectory reports whether the site output goes to a directory instead of a ZIP file:
+// any --output path that does not end in .zip
+func isSiteDirectory(outputPath string) bool {
+	return outputPath != "" && !strings.EqualFold(filepath.Ext(outputPath), ".zip")
+}
+
+// extractSite writes the files of a site archive below dir and returns the number of files
+func extractSite(data []byte, dir string) (int, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read site archive: %w", err)
+	}
+
+	for _, file := range reader.File {
+		if !filepath.IsLocal(file.Name) {
+			return 0, fmt.Errorf("invalid file name in site archive: %s", file.Name)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(file.Name))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return 0, fmt.Errorf("failed to create directory for %s: %w", target, err)
+		}
+
+		content, err := readZipFile(file)
+		if err != nil {
+			return 0, err
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			return 0, fmt.Errorf("failed to write %s: %w", target, err)
+		}
+	}
+
+	return len(reader.File), nil
+}
+
+// copySiteToZip copies the files of a site archive into zipWriter below dir and returns the
+// entry names
+func copySiteToZip(zipWriter *zip.Writer, dir string, data []byte) ([]string, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read site archive: %w", err)
+	}
+
+	names := make([]string, 0, len(reader.File))
+	for _, file := range reader.File {
+		name := path.Join(dir, file.Name)
+		content, err := readZipFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		fileWriter, err := zipWriter.Create(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create ZIP entry %s: %w", name, err)
+		}
+		if _, err := fileWriter.Write(content); err != nil {
+			return nil, fmt.Errorf("failed to write content to ZIP entry %s: %w", name, err)
+		}
+		names = append(names, name)
+	}
+
+	return names, nil
+}
+
+// readZipFile returns the content of a file in a ZIP archive
+func readZipFile(file *zip.File) ([]byte, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s in site archive: %w", file.Name, err)
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s in site archive: %w", file.Name, err)
+	}
+	return content, nil
+}
+
+// uniqueSiteDir returns a directory name for the site of a book that is not yet in usedNames
+func uniqueSiteDir(baseTitle string, usedNames map[string]bool) string {
+	dir := baseTitle
+	if usedNames == nil {
+		return dir
+	}
+	for i := 2; usedNames[dir+"/"]; i++ {
+		dir = fmt.Sprintf("%s_%d", baseTitle, i)
+	}
+	usedNames[dir+"/"] = true
+	return dir
+}
+
+// siteLibraryBook returns the library entry for a book whose site was written as files, or
+// false if files holds no site
+func siteLibraryBook(title, description string, files []string) (templates.SiteBook, bool) {
+	book := templates.SiteBook{Title: title, Description: description}
+	for _, file := range files {
+		dir, name := path.Split(file)
+		if dir == "" {
+			continue
+		}
+		switch {
+		case name == writers.SiteIndexFile:
+			book.Href = file
+		case path.Ext(name) == ".html":
+			book.Pages++
+		}
+	}
+	return book, book.Href != ""
+}
+
+// writeSiteLibrary writes the library landing page linking to the site of every book
+func writeSiteLibrary(zipWriter *zip.Writer, books []templates.SiteBook, language string) error {
+	html, err := templates.RenderSiteLibrary(templates.SiteLibraryData{Language: language, Books: books})
+	if err != nil {
+		return fmt.Errorf("failed to render library page: %w", err)
+	}
+
+	fileWriter, err := zipWriter.Create(writers.SiteIndexFile)
+	if err != nil {
+		return fmt.Errorf("failed to create ZIP entry %s: %w", writers.SiteIndexFile, err)
+	}
+	if _, err := io.WriteString(fileWriter, html); err != nil {
+		return fmt.Errorf("failed to write content to ZIP entry %s: %w", writers.SiteIndexFile, err)
+	}
+	return nil
+}
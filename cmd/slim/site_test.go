@@ -0,0 +1,56 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsSiteDirectory(t *testing.T) {
+	tests := map[string]bool{
+		"":             false,
+		"site.zip":     false,
+		"out/Book.ZIP": false,
+		"out":          true,
+		"out/site/":    true,
+	}
+	for path, expected := range tests {
+		if got := isSiteDirectory(path); got != expected {
+			t.Errorf("isSiteDirectory(%q) = %v, expected %v", path, got, expected)
+		}
+	}
+}
+
+func TestExtractSite(t *testing.T) {
+	archive := func(names ...string) []byte {
+		var buf bytes.Buffer
+		zipWriter := zip.NewWriter(&buf)
+		for _, name := range names {
+			w, err := zipWriter.Create(name)
+			if err != nil {
+				t.Fatalf("Failed to create %s: %v", name, err)
+			}
+			w.Write([]byte("content of " + name))
+		}
+		zipWriter.Close()
+		return buf.Bytes()
+	}
+
+	dir := filepath.Join(t.TempDir(), "site")
+	count, err := extractSite(archive("index.html", "01-bones.html"), dir)
+	if err != nil {
+		t.Fatalf("extractSite failed: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 files, got %d", count)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "01-bones.html")); err != nil || string(data) != "content of 01-bones.html" {
+		t.Errorf("Unexpected extracted file: %q, %v", data, err)
+	}
+
+	if _, err := extractSite(archive("../escape.html"), dir); err == nil {
+		t.Error("Expected an error for a file outside the directory")
+	}
+}
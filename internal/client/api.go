@@ -3,29 +3,15 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-)
 
-// extractStringFromMap safely extracts a string value from a map, handling type assertions
-func extractStringFromMap(data any, key string) string {
-	if dataMap, ok := data.(map[string]any); ok {
-		if val, exists := dataMap[key]; exists {
-			if str, ok := val.(string); ok {
-				return str
-			}
-		}
-	}
-	return ""
-}
+	"github.com/kjanat/slimacademy/internal/models"
+)
 
 // GetProfile fetches the user profile
 func (c *SlimClient) GetProfile(ctx context.Context) (*UserProfile, error) {
-	req, err := c.newAuthenticatedRequest(ctx, "GET", "/api/user/profile", nil)
-	if err != nil {
-		return nil, err
-	}
-
-	respBody, err := c.doRequest(req)
+	respBody, err := c.doAuthenticated(ctx, "GET", "/api/user/profile")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get profile: %w", err)
 	}
@@ -41,12 +27,7 @@ func (c *SlimClient) GetProfile(ctx context.Context) (*UserProfile, error) {
 // GetLibrary fetches the library summary (matching bash script)
 func (c *SlimClient) GetLibrary(ctx context.Context) (*LibraryResponse, error) {
 	endpoint := "/api/summary/library?sortBy=lastOpenedAt&sortOrder=DESC&onlyRecentlyRead=0"
-	req, err := c.newAuthenticatedRequest(ctx, "GET", endpoint, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	respBody, err := c.doRequest(req)
+	respBody, err := c.doAuthenticated(ctx, "GET", endpoint)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get library: %w", err)
 	}
@@ -59,155 +40,139 @@ func (c *SlimClient) GetLibrary(ctx context.Context) (*LibraryResponse, error) {
 	return &library, nil
 }
 
-// GetSummary fetches summary data for a specific ID
-func (c *SlimClient) GetSummary(ctx context.Context, id string) (any, error) {
-	endpoint := fmt.Sprintf("/api/summary/%s", id)
-	req, err := c.newAuthenticatedRequest(ctx, "GET", endpoint, nil)
-	if err != nil {
-		return nil, err
-	}
+// bookPartEndpoints maps every book part to its endpoint, formatted with the book ID
+var bookPartEndpoints = map[string]string{
+	PartSummary:  "/api/summary/%s",
+	PartChapters: "/api/summary/%s/chapters",
+	PartContent:  "/api/summary/%s/content",
+	PartNotes:    "/api/summary/%s/list-notes",
+}
 
-	respBody, err := c.doRequest(req)
+// getBookPart fetches one part of a book, decodes it into v and reports schema drift. The
+// response body is returned for archival.
+func (c *SlimClient) getBookPart(ctx context.Context, id, part string, v any) (json.RawMessage, error) {
+	respBody, err := c.doAuthenticated(ctx, "GET", fmt.Sprintf(bookPartEndpoints[part], id))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get summary for ID %s: %w", id, err)
+		return nil, fmt.Errorf("failed to get %s for ID %s: %w", part, id, err)
 	}
 
-	var summary any
-	if err := json.Unmarshal(respBody, &summary); err != nil {
-		return nil, fmt.Errorf("failed to parse summary response: %w", err)
+	if err := json.Unmarshal(respBody, v); err != nil {
+		return nil, fmt.Errorf("failed to parse %s response: %w", part, err)
 	}
 
-	return summary, nil
+	c.checkDrift(id, part, respBody, v)
+	return respBody, nil
 }
 
-// GetChapters fetches chapters data for a specific ID
-func (c *SlimClient) GetChapters(ctx context.Context, id string) (any, error) {
-	endpoint := fmt.Sprintf("/api/summary/%s/chapters", id)
-	req, err := c.newAuthenticatedRequest(ctx, "GET", endpoint, nil)
-	if err != nil {
+// GetSummary fetches summary data for a specific ID
+func (c *SlimClient) GetSummary(ctx context.Context, id string) (*models.Book, error) {
+	var summary models.Book
+	if _, err := c.getBookPart(ctx, id, PartSummary, &summary); err != nil {
 		return nil, err
 	}
+	return &summary, nil
+}
 
-	respBody, err := c.doRequest(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get chapters for ID %s: %w", id, err)
-	}
-
-	var chapters any
-	if err := json.Unmarshal(respBody, &chapters); err != nil {
-		return nil, fmt.Errorf("failed to parse chapters response: %w", err)
+// GetChapters fetches chapters data for a specific ID
+func (c *SlimClient) GetChapters(ctx context.Context, id string) ([]models.Chapter, error) {
+	var chapters []models.Chapter
+	if _, err := c.getBookPart(ctx, id, PartChapters, &chapters); err != nil {
+		return nil, err
 	}
-
 	return chapters, nil
 }
 
 // GetContent fetches content data for a specific ID
-func (c *SlimClient) GetContent(ctx context.Context, id string) (any, error) {
-	endpoint := fmt.Sprintf("/api/summary/%s/content", id)
-	req, err := c.newAuthenticatedRequest(ctx, "GET", endpoint, nil)
-	if err != nil {
+func (c *SlimClient) GetContent(ctx context.Context, id string) (*models.Content, error) {
+	var content models.Content
+	if _, err := c.getBookPart(ctx, id, PartContent, &content); err != nil {
 		return nil, err
 	}
-
-	respBody, err := c.doRequest(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get content for ID %s: %w", id, err)
-	}
-
-	var content any
-	if err := json.Unmarshal(respBody, &content); err != nil {
-		return nil, fmt.Errorf("failed to parse content response: %w", err)
-	}
-
-	return content, nil
+	return &content, nil
 }
 
 // GetNotes fetches notes data for a specific ID
-func (c *SlimClient) GetNotes(ctx context.Context, id string) (any, error) {
-	endpoint := fmt.Sprintf("/api/summary/%s/list-notes", id)
-	req, err := c.newAuthenticatedRequest(ctx, "GET", endpoint, nil)
-	if err != nil {
+func (c *SlimClient) GetNotes(ctx context.Context, id string) ([]models.Note, error) {
+	var notes []models.Note
+	if _, err := c.getBookPart(ctx, id, PartNotes, &notes); err != nil {
 		return nil, err
 	}
-
-	respBody, err := c.doRequest(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get notes for ID %s: %w", id, err)
-	}
-
-	var notes any
-	if err := json.Unmarshal(respBody, &notes); err != nil {
-		return nil, fmt.Errorf("failed to parse notes response: %w", err)
-	}
-
 	return notes, nil
 }
 
-// FetchAllBookData fetches all data for a specific book ID (matching bash script "all" command)
-func (c *SlimClient) FetchAllBookData(ctx context.Context, id string) (*BookData, error) {
-	// Ensure we're authenticated
-	if err := c.EnsureAuthenticated(ctx); err != nil {
-		return nil, fmt.Errorf("authentication failed: %w", err)
-	}
-
-	// Fetch summary to get title
-	summary, err := c.GetSummary(ctx, id)
-	if err != nil {
-		return nil, err
+// DecodeBookData decodes previously fetched API responses of a book, e.g. from an archive
+func DecodeBookData(id string, raw RawBookData) (*BookData, error) {
+	book := newBookData(id)
+	book.Raw = raw
+	for _, p := range book.parts() {
+		if len(*p.raw) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(*p.raw, p.dest); err != nil {
+			return nil, fmt.Errorf("failed to parse %s of book %s: %w", p.part, id, err)
+		}
 	}
+	book.Title = book.Summary.Title
+	return book, nil
+}
 
-	// Extract title from summary
-	title := extractStringFromMap(summary, "title")
-
-	// Fetch all other data
-	chapters, err := c.GetChapters(ctx, id)
-	if err != nil {
-		return nil, err
-	}
+// newBookData returns an empty book ready to be decoded into
+func newBookData(id string) *BookData {
+	return &BookData{ID: id, Summary: &models.Book{}, Content: &models.Content{}}
+}
 
-	content, err := c.GetContent(ctx, id)
-	if err != nil {
-		return nil, err
-	}
+// bookPart links a book part to its typed field and raw response
+type bookPart struct {
+	part string
+	dest any
+	raw  *json.RawMessage
+}
 
-	notes, err := c.GetNotes(ctx, id)
-	if err != nil {
-		return nil, err
+// parts returns the parts of the book in fetch order
+func (b *BookData) parts() []bookPart {
+	return []bookPart{
+		{PartSummary, b.Summary, &b.Raw.Summary},
+		{PartChapters, &b.Chapters, &b.Raw.Chapters},
+		{PartContent, b.Content, &b.Raw.Content},
+		{PartNotes, &b.Notes, &b.Raw.Notes},
 	}
-
-	return &BookData{
-		ID:       id,
-		Title:    title,
-		Summary:  summary,
-		Chapters: chapters,
-		Content:  content,
-		Notes:    notes,
-	}, nil
 }
 
-// FetchLibraryBooks fetches all books from the library
-func (c *SlimClient) FetchLibraryBooks(ctx context.Context) ([]*BookData, error) {
+// FetchAllBookData fetches all data for a specific book ID (matching bash script "all" command).
+// The summary, chapters, content and notes are requested in parallel.
+func (c *SlimClient) FetchAllBookData(ctx context.Context, id string) (*BookData, error) {
 	// Ensure we're authenticated
 	if err := c.EnsureAuthenticated(ctx); err != nil {
 		return nil, fmt.Errorf("authentication failed: %w", err)
 	}
 
-	// Get library
-	library, err := c.GetLibrary(ctx)
-	if err != nil {
-		return nil, err
-	}
+	return c.fetchBookData(ctx, id, nil)
+}
+
+// FetchLibraryBooks fetches all books from the library in library order. Books that fail are
+// skipped and reported through a *LibraryFetchError returned alongside the books that were fetched.
+func (c *SlimClient) FetchLibraryBooks(ctx context.Context) ([]*BookData, error) {
+	var indexed []*BookData
+	err := c.FetchLibrary(ctx, LibraryFetchOptions{
+		OnBook: func(index int, book *BookData) error {
+			if index >= len(indexed) {
+				indexed = append(indexed, make([]*BookData, index+1-len(indexed))...)
+			}
+			indexed[index] = book
+			return nil
+		},
+	})
 
 	var books []*BookData
-	for _, summary := range library.Summaries {
-		id := fmt.Sprintf("%d", summary.ID)
-		bookData, err := c.FetchAllBookData(ctx, id)
-		if err != nil {
-			fmt.Printf("Warning: failed to fetch book %s: %v\n", id, err)
-			continue
+	for _, book := range indexed {
+		if book != nil {
+			books = append(books, book)
 		}
-		books = append(books, bookData)
 	}
 
-	return books, nil
+	var libErr *LibraryFetchError
+	if err != nil && !errors.As(err, &libErr) {
+		return nil, err
+	}
+	return books, err
 }
@@ -3,6 +3,7 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"path/filepath"
@@ -17,6 +18,7 @@ func createAuthenticatedClient(t *testing.T, serverURL string) *SlimClient {
 	tempDir := createTempDir(t)
 	client := NewSlimClient(tempDir)
 	client.baseURL = serverURL
+	client.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
 
 	// Create valid token
 	tokenInfo := &TokenInfo{
@@ -345,11 +347,8 @@ func TestSlimClient_GetSummary(t *testing.T) {
 		t.Fatal("Expected summary to be non-nil")
 	}
 
-	// Convert to map for comparison
-	summaryMap := summary.(map[string]interface{}) //nolint:gocritic
-
-	if title := summaryMap["title"].(string); title != "Test Summary" { //nolint:gocritic
-		t.Errorf("Expected title 'Test Summary', got %q", title)
+	if summary.ID != 123 || summary.Title != "Test Summary" {
+		t.Errorf("Expected book 123 'Test Summary', got %d %q", summary.ID, summary.Title)
 	}
 }
 
@@ -396,11 +395,8 @@ func TestSlimClient_GetChapters(t *testing.T) {
 		t.Fatal("Expected chapters to be non-nil")
 	}
 
-	// Convert to slice for comparison
-	chaptersSlice := chapters.([]interface{}) //nolint:gocritic
-
-	if len(chaptersSlice) != 2 {
-		t.Errorf("Expected 2 chapters, got %d", len(chaptersSlice))
+	if len(chapters) != 2 || chapters[1].Title != "Chapter 2" {
+		t.Errorf("Expected 2 chapters, got %+v", chapters)
 	}
 }
 
@@ -448,11 +444,8 @@ func TestSlimClient_GetContent(t *testing.T) {
 		t.Fatal("Expected content to be non-nil")
 	}
 
-	// Convert to map for comparison
-	contentMap := content.(map[string]interface{}) //nolint:gocritic
-
-	if docID := contentMap["documentId"].(string); docID != testID { //nolint:gocritic
-		t.Errorf("Expected documentId %q, got %q", testID, docID)
+	if content.Document == nil || content.Document.DocumentID != testID {
+		t.Errorf("Expected document %q, got %+v", testID, content)
 	}
 }
 
@@ -501,11 +494,8 @@ func TestSlimClient_GetNotes(t *testing.T) {
 		t.Fatal("Expected notes to be non-nil")
 	}
 
-	// Convert to slice for comparison
-	notesSlice := notes.([]interface{}) //nolint:gocritic
-
-	if len(notesSlice) != 2 {
-		t.Errorf("Expected 2 notes, got %d", len(notesSlice))
+	if len(notes) != 2 || notes[0].ID != 1 {
+		t.Errorf("Expected 2 notes, got %+v", notes)
 	}
 }
 
@@ -580,6 +570,11 @@ func TestSlimClient_FetchAllBookData(t *testing.T) {
 	if bookData.Notes == nil {
 		t.Error("Expected notes to be non-nil")
 	}
+
+	var archived map[string]any
+	if err := json.Unmarshal(bookData.Raw.Content, &archived); err != nil || archived["documentId"] != testID {
+		t.Errorf("Expected raw content response to be kept, got %s", bookData.Raw.Content)
+	}
 }
 
 func TestSlimClient_FetchAllBookData_WithoutAuthentication(t *testing.T) {
@@ -600,7 +595,11 @@ func TestSlimClient_FetchAllBookData_WithoutAuthentication(t *testing.T) {
 		} else if strings.HasPrefix(r.URL.Path, "/api/summary/") {
 			// Mock API responses - verify we got the new token
 			verifyAuthHeader(t, r, "auto-login-token")
-			encodeJSON(t, w, map[string]interface{}{"id": 123, "title": "Auto Login Book"})
+			if r.URL.Path == "/api/summary/123" {
+				encodeJSON(t, w, map[string]interface{}{"id": 123, "title": "Auto Login Book"})
+				return
+			}
+			encodeJSON(t, w, []interface{}{})
 		}
 	}))
 	defer server.Close()
@@ -718,7 +717,7 @@ func TestSlimClient_FetchLibraryBooks_PartialFailure(t *testing.T) {
 			encodeJSON(t, w, map[string]interface{}{"documentId": "123"})
 		case "/api/summary/123/list-notes":
 			encodeJSON(t, w, []interface{}{})
-		case "/api/summary/456":
+		case "/api/summary/456", "/api/summary/456/chapters", "/api/summary/456/content", "/api/summary/456/list-notes":
 			// Simulate failure for book 456
 			w.WriteHeader(http.StatusInternalServerError)
 			writeResponse(t, w, []byte(`{"error": "internal server error"}`))
@@ -733,8 +732,22 @@ func TestSlimClient_FetchLibraryBooks_PartialFailure(t *testing.T) {
 	ctx := context.Background()
 
 	books, err := client.FetchLibraryBooks(ctx)
-	if err != nil {
-		t.Fatalf("FetchLibraryBooks() failed: %v", err)
+	var libErr *LibraryFetchError
+	if !errors.As(err, &libErr) {
+		t.Fatalf("Expected LibraryFetchError, got %v", err)
+	}
+	if libErr.Total != 2 || len(libErr.Failures) != 1 {
+		t.Fatalf("Expected 1 of 2 books to fail, got %d of %d", len(libErr.Failures), libErr.Total)
+	}
+	failure := libErr.Failures[0]
+	if failure.ID != "456" || failure.Title != "Bad Book" {
+		t.Errorf("Expected failure for book 456 'Bad Book', got %s %q", failure.ID, failure.Title)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError {
+		t.Errorf("Expected wrapped 500 APIError, got %v", failure.Err)
+	} else if apiErr.Attempts != 3 {
+		t.Errorf("Expected 3 attempts for the failing book, got %d", apiErr.Attempts)
 	}
 
 	// Should only get the successful book
@@ -849,52 +862,37 @@ func TestSlimClient_API_MalformedResponses(t *testing.T) {
 	}
 }
 
-func TestSlimClient_extractStringFromMap(t *testing.T) {
-	tests := []struct {
-		name     string
-		data     any
-		key      string
-		expected string
-	}{
-		{
-			name:     "valid string value",
-			data:     map[string]any{"title": "Test Title"},
-			key:      "title",
-			expected: "Test Title",
-		},
-		{
-			name:     "missing key",
-			data:     map[string]any{"other": "value"},
-			key:      "title",
-			expected: "",
-		},
-		{
-			name:     "non-string value",
-			data:     map[string]any{"title": 123},
-			key:      "title",
-			expected: "",
-		},
-		{
-			name:     "non-map data",
-			data:     "not a map",
-			key:      "title",
-			expected: "",
-		},
-		{
-			name:     "nil data",
-			data:     nil,
-			key:      "title",
-			expected: "",
-		},
+func TestDecodeBookData(t *testing.T) {
+	raw := RawBookData{
+		Summary:  json.RawMessage(`{"id": 42, "title": "Archived Book"}`),
+		Chapters: json.RawMessage(`[{"id": 1, "title": "Intro", "subChapters": [{"id": 2, "title": "Scope"}]}]`),
+		Content:  json.RawMessage(`{"documentId": "doc-42", "revisionId": "rev-7"}`),
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := extractStringFromMap(tt.data, tt.key)
-			if result != tt.expected {
-				t.Errorf("Expected %q, got %q", tt.expected, result)
-			}
-		})
+	book, err := DecodeBookData("42", raw)
+	if err != nil {
+		t.Fatalf("DecodeBookData() failed: %v", err)
+	}
+
+	if book.Title != "Archived Book" || book.Summary.ID != 42 {
+		t.Errorf("Unexpected summary %+v", book.Summary)
+	}
+	if len(book.Chapters) != 1 || len(book.Chapters[0].SubChapters) != 1 {
+		t.Errorf("Unexpected chapters %+v", book.Chapters)
+	}
+	if book.Content.Document == nil || book.Content.Document.RevisionID != "rev-7" {
+		t.Errorf("Unexpected content %+v", book.Content)
+	}
+	if book.Notes != nil {
+		t.Errorf("Expected no notes without a notes response, got %+v", book.Notes)
+	}
+	if string(book.Raw.Summary) != string(raw.Summary) {
+		t.Error("Expected raw responses to be kept")
+	}
+
+	raw.Notes = json.RawMessage(`{"not": "a list"}`)
+	if _, err := DecodeBookData("42", raw); err == nil || !strings.Contains(err.Error(), "notes") {
+		t.Errorf("Expected notes decode error, got %v", err)
 	}
 }
 
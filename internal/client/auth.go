@@ -2,8 +2,11 @@ package client
 
 import (
 	"bufio"
+	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -13,6 +16,9 @@ import (
 // TokenStore handles persistence of authentication tokens
 type TokenStore struct {
 	tokenFile string
+
+	// secrets, when set, holds the token instead of tokenFile
+	secrets SecretStore
 }
 
 // NewTokenStore creates a new token store
@@ -22,8 +28,32 @@ func NewTokenStore(outputDir string) *TokenStore {
 	}
 }
 
-// SaveToken persists the token information to disk
+// NewSecretTokenStore creates a token store that keeps the token in a secret store
+func NewSecretTokenStore(secrets SecretStore) *TokenStore {
+	return &TokenStore{secrets: secrets}
+}
+
+// Describe tells where the token is kept
+func (ts *TokenStore) Describe() string {
+	if ts.secrets != nil {
+		return ts.secrets.Describe()
+	}
+	return "file " + ts.tokenFile
+}
+
+// SaveToken persists the token information to disk or the secret store
 func (ts *TokenStore) SaveToken(info *TokenInfo) error {
+	if ts.secrets != nil {
+		data, err := json.Marshal(info)
+		if err != nil {
+			return fmt.Errorf("failed to marshal token info: %w", err)
+		}
+		if err := ts.secrets.Set(SecretToken, string(data)); err != nil {
+			return fmt.Errorf("failed to store token: %w", err)
+		}
+		return nil
+	}
+
 	// Ensure directory exists
 	if err := os.MkdirAll(filepath.Dir(ts.tokenFile), 0755); err != nil {
 		return fmt.Errorf("failed to create token directory: %w", err)
@@ -34,18 +64,24 @@ func (ts *TokenStore) SaveToken(info *TokenInfo) error {
 		return fmt.Errorf("failed to marshal token info: %w", err)
 	}
 
-	if err := os.WriteFile(ts.tokenFile, data, 0600); err != nil {
+	// Write to a temporary file and rename it so concurrent readers never see a partial token
+	tmpFile := ts.tokenFile + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to write token file: %w", err)
+	}
+	if err := os.Rename(tmpFile, ts.tokenFile); err != nil {
+		os.Remove(tmpFile)
 		return fmt.Errorf("failed to write token file: %w", err)
 	}
 
 	return nil
 }
 
-// LoadToken loads the token information from disk
+// LoadToken loads the token information from disk or the secret store
 func (ts *TokenStore) LoadToken() (*TokenInfo, error) {
-	data, err := os.ReadFile(ts.tokenFile)
+	data, err := ts.readToken()
 	if err != nil {
-		if os.IsNotExist(err) {
+		if isNotExist(err) {
 			return nil, fmt.Errorf("no token found, please login first")
 		}
 		return nil, fmt.Errorf("failed to read token file: %w", err)
@@ -59,6 +95,23 @@ func (ts *TokenStore) LoadToken() (*TokenInfo, error) {
 	return &info, nil
 }
 
+// readToken returns the stored token JSON, refusing token files readable by other users
+func (ts *TokenStore) readToken() ([]byte, error) {
+	if ts.secrets != nil {
+		value, err := ts.secrets.Get(SecretToken)
+		return []byte(value), err
+	}
+
+	data, err := os.ReadFile(ts.tokenFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkPrivate(ts.tokenFile); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
 // IsTokenValid checks if the stored token is still valid
 func (ts *TokenStore) IsTokenValid() bool {
 	info, err := ts.LoadToken()
@@ -72,12 +125,71 @@ func (ts *TokenStore) IsTokenValid() bool {
 
 // ClearToken removes the stored token
 func (ts *TokenStore) ClearToken() error {
+	if ts.secrets != nil {
+		if err := ts.secrets.Delete(SecretToken); err != nil {
+			return fmt.Errorf("failed to remove token: %w", err)
+		}
+		return nil
+	}
+
 	if err := os.Remove(ts.tokenFile); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to remove token file: %w", err)
 	}
 	return nil
 }
 
+// DeviceID returns the device ID of this installation, generating a random one on first use.
+// It is kept next to the token and survives logging out.
+func (ts *TokenStore) DeviceID() (string, error) {
+	if ts.secrets != nil {
+		id, err := ts.secrets.Get(SecretDeviceID)
+		if err == nil && id != "" {
+			return id, nil
+		}
+		if err != nil && !errors.Is(err, ErrSecretNotFound) {
+			return "", err
+		}
+
+		id, err = newDeviceID()
+		if err != nil {
+			return "", err
+		}
+		return id, ts.secrets.Set(SecretDeviceID, id)
+	}
+
+	deviceFile := filepath.Join(filepath.Dir(ts.tokenFile), ".device_id")
+	if data, err := os.ReadFile(deviceFile); err == nil {
+		if id := strings.TrimSpace(string(data)); id != "" {
+			return id, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read device ID: %w", err)
+	}
+
+	id, err := newDeviceID()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(deviceFile), 0755); err != nil {
+		return "", fmt.Errorf("failed to create token directory: %w", err)
+	}
+	if err := os.WriteFile(deviceFile, []byte(id+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("failed to write device ID: %w", err)
+	}
+	return id, nil
+}
+
+// newDeviceID returns a random version 4 UUID
+func newDeviceID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate device ID: %w", err)
+	}
+	b[6] = b[6]&0x0f | 0x40 // Version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
+
 // GetValidToken returns a valid token or an error if none exists
 func (ts *TokenStore) GetValidToken() (string, error) {
 	if !ts.IsTokenValid() {
@@ -92,9 +204,12 @@ func (ts *TokenStore) GetValidToken() (string, error) {
 	return info.Token, nil
 }
 
-// CredentialManager handles loading credentials from .env file
+// CredentialManager handles loading credentials from a secret store and the .env file
 type CredentialManager struct {
 	envFile string
+
+	// secrets, when set, is consulted before the environment and .env file
+	secrets SecretStore
 }
 
 // NewCredentialManager creates a new credential manager
@@ -105,11 +220,74 @@ func NewCredentialManager(envFile string) *CredentialManager {
 	return &CredentialManager{envFile: envFile}
 }
 
-// LoadCredentials loads credentials from .env file with environment variable fallback
+// NewSecretCredentialManager creates a credential manager that prefers credentials from a
+// secret store. An empty envFile disables the .env file.
+func NewSecretCredentialManager(secrets SecretStore, envFile string) *CredentialManager {
+	return &CredentialManager{envFile: envFile, secrets: secrets}
+}
+
+// StoreCredentials saves credentials in the secret store, if there is one
+func (cm *CredentialManager) StoreCredentials(creds *Credentials) error {
+	if cm.secrets == nil {
+		return nil
+	}
+	if err := cm.secrets.Set(SecretUsername, creds.Username); err != nil {
+		return fmt.Errorf("failed to store username: %w", err)
+	}
+	if err := cm.secrets.Set(SecretPassword, creds.Password); err != nil {
+		return fmt.Errorf("failed to store password: %w", err)
+	}
+	return nil
+}
+
+// ForgetCredentials removes credentials from the secret store, if there is one
+func (cm *CredentialManager) ForgetCredentials() error {
+	if cm.secrets == nil {
+		return nil
+	}
+	return errors.Join(cm.secrets.Delete(SecretUsername), cm.secrets.Delete(SecretPassword))
+}
+
+// loadStoredCredentials returns the credentials in the secret store, or nil if there are none
+func (cm *CredentialManager) loadStoredCredentials() (*Credentials, error) {
+	if cm.secrets == nil {
+		return nil, nil
+	}
+
+	username, err := cm.secrets.Get(SecretUsername)
+	if err != nil {
+		if errors.Is(err, ErrSecretNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	password, err := cm.secrets.Get(SecretPassword)
+	if err != nil {
+		if errors.Is(err, ErrSecretNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if username == "" || password == "" {
+		return nil, nil
+	}
+	return &Credentials{Username: username, Password: password}, nil
+}
+
+// LoadCredentials loads credentials from the secret store, falling back to the .env file and
+// the SLIM_USERNAME and SLIM_PASSWORD environment variables
 func (cm *CredentialManager) LoadCredentials() (*Credentials, error) {
+	creds, err := cm.loadStoredCredentials()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials from %s: %w", cm.secrets.Describe(), err)
+	}
+	if creds != nil {
+		return creds, nil
+	}
+
 	// Get environment variables as fallback
-	envUsername := os.Getenv("USERNAME")
-	envPassword := os.Getenv("PASSWORD")
+	envUsername := os.Getenv(envSecretVariables[SecretUsername])
+	envPassword := os.Getenv(envSecretVariables[SecretPassword])
 
 	// Try to read .env file first (it takes precedence)
 	file, err := os.Open(cm.envFile)
@@ -124,16 +302,20 @@ func (cm *CredentialManager) LoadCredentials() (*Credentials, error) {
 
 		// If no .env file and no complete env vars, provide helpful error
 		if envUsername == "" && envPassword == "" {
-			return nil, fmt.Errorf("credentials not found. Either:\n1. Set environment variables: USERNAME=your@email.com PASSWORD=yourpassword\n2. Create a .env file with:\n   USERNAME=your@email.com\n   PASSWORD=yourpassword")
+			return nil, fmt.Errorf("credentials not found. Either:\n1. Set environment variables: SLIM_USERNAME=your@email.com SLIM_PASSWORD=yourpassword\n2. Create a .env file with:\n   USERNAME=your@email.com\n   PASSWORD=yourpassword")
 		}
 		// Partial credentials from env vars
 		if envUsername == "" {
-			return nil, fmt.Errorf("USERNAME not found in environment variables or .env file")
+			return nil, fmt.Errorf("SLIM_USERNAME not found in environment variables or .env file")
 		}
-		return nil, fmt.Errorf("PASSWORD not found in environment variables or .env file")
+		return nil, fmt.Errorf("SLIM_PASSWORD not found in environment variables or .env file")
 	}
 	defer file.Close()
 
+	if err := checkPrivate(cm.envFile); err != nil {
+		slog.Warn("credentials file is readable by other users", "error", err)
+	}
+
 	// Start with environment variables as defaults, .env file will override
 	username := envUsername
 	password := envPassword
@@ -8,29 +8,29 @@ import (
 
 func TestCredentialManager_EnvironmentVariableFallback(t *testing.T) {
 	// Save original environment variables
-	originalUsername := os.Getenv("USERNAME")
-	originalPassword := os.Getenv("PASSWORD")
+	originalUsername := os.Getenv("SLIM_USERNAME")
+	originalPassword := os.Getenv("SLIM_PASSWORD")
 	defer func() {
 		if originalUsername != "" {
-			os.Setenv("USERNAME", originalUsername)
+			os.Setenv("SLIM_USERNAME", originalUsername)
 		} else {
-			os.Unsetenv("USERNAME")
+			os.Unsetenv("SLIM_USERNAME")
 		}
 		if originalPassword != "" {
-			os.Setenv("PASSWORD", originalPassword)
+			os.Setenv("SLIM_PASSWORD", originalPassword)
 		} else {
-			os.Unsetenv("PASSWORD")
+			os.Unsetenv("SLIM_PASSWORD")
 		}
 	}()
 
 	t.Run("environment variables only", func(t *testing.T) {
 		// Clear env vars first
-		os.Unsetenv("USERNAME")
-		os.Unsetenv("PASSWORD")
+		os.Unsetenv("SLIM_USERNAME")
+		os.Unsetenv("SLIM_PASSWORD")
 
 		// Set test credentials in environment
-		os.Setenv("USERNAME", "env@example.com")
-		os.Setenv("PASSWORD", "envpassword")
+		os.Setenv("SLIM_USERNAME", "env@example.com")
+		os.Setenv("SLIM_PASSWORD", "envpassword")
 
 		// Use non-existent .env file
 		cm := NewCredentialManager("/nonexistent/.env")
@@ -52,11 +52,11 @@ func TestCredentialManager_EnvironmentVariableFallback(t *testing.T) {
 
 	t.Run("partial environment variables", func(t *testing.T) {
 		// Clear env vars first
-		os.Unsetenv("USERNAME")
-		os.Unsetenv("PASSWORD")
+		os.Unsetenv("SLIM_USERNAME")
+		os.Unsetenv("SLIM_PASSWORD")
 
 		// Set only username in environment
-		os.Setenv("USERNAME", "partial@example.com")
+		os.Setenv("SLIM_USERNAME", "partial@example.com")
 
 		cm := NewCredentialManager("/nonexistent/.env")
 		_, err := cm.LoadCredentials()
@@ -64,15 +64,29 @@ func TestCredentialManager_EnvironmentVariableFallback(t *testing.T) {
 		if err == nil {
 			t.Error("Expected error with partial credentials")
 		}
-		if err.Error() != "PASSWORD not found in environment variables or .env file" {
+		if err.Error() != "SLIM_PASSWORD not found in environment variables or .env file" {
 			t.Errorf("Unexpected error message: %v", err.Error())
 		}
 	})
 
+	t.Run("unprefixed variables are ignored", func(t *testing.T) {
+		os.Unsetenv("SLIM_USERNAME")
+		os.Unsetenv("SLIM_PASSWORD")
+
+		// Windows sets USERNAME to the account name
+		t.Setenv("USERNAME", "os-account")
+		t.Setenv("PASSWORD", "os-password")
+
+		cm := NewCredentialManager("/nonexistent/.env")
+		if creds, err := cm.LoadCredentials(); err == nil {
+			t.Errorf("Expected unprefixed variables to be ignored, got %+v", creds)
+		}
+	})
+
 	t.Run("no credentials anywhere", func(t *testing.T) {
 		// Clear env vars
-		os.Unsetenv("USERNAME")
-		os.Unsetenv("PASSWORD")
+		os.Unsetenv("SLIM_USERNAME")
+		os.Unsetenv("SLIM_PASSWORD")
 
 		cm := NewCredentialManager("/nonexistent/.env")
 		_, err := cm.LoadCredentials()
@@ -80,7 +94,7 @@ func TestCredentialManager_EnvironmentVariableFallback(t *testing.T) {
 		if err == nil {
 			t.Error("Expected error with no credentials")
 		}
-		expectedError := "credentials not found. Either:\n1. Set environment variables: USERNAME=your@email.com PASSWORD=yourpassword\n2. Create a .env file with:\n   USERNAME=your@email.com\n   PASSWORD=yourpassword"
+		expectedError := "credentials not found. Either:\n1. Set environment variables: SLIM_USERNAME=your@email.com SLIM_PASSWORD=yourpassword\n2. Create a .env file with:\n   USERNAME=your@email.com\n   PASSWORD=yourpassword"
 		if err.Error() != expectedError {
 			t.Errorf("Expected helpful error message, got: %v", err.Error())
 		}
@@ -88,12 +102,12 @@ func TestCredentialManager_EnvironmentVariableFallback(t *testing.T) {
 
 	t.Run("env file overrides environment variables", func(t *testing.T) {
 		// Clear environment variables first
-		os.Unsetenv("USERNAME")
-		os.Unsetenv("PASSWORD")
+		os.Unsetenv("SLIM_USERNAME")
+		os.Unsetenv("SLIM_PASSWORD")
 
 		// Set environment variables
-		os.Setenv("USERNAME", "env@example.com")
-		os.Setenv("PASSWORD", "envpassword")
+		os.Setenv("SLIM_USERNAME", "env@example.com")
+		os.Setenv("SLIM_PASSWORD", "envpassword")
 
 		// Create temporary .env file
 		tempDir := t.TempDir()
@@ -126,12 +140,12 @@ PASSWORD=filepassword`
 
 	t.Run("partial env file with env var fallback", func(t *testing.T) {
 		// Clear environment variables first
-		os.Unsetenv("USERNAME")
-		os.Unsetenv("PASSWORD")
+		os.Unsetenv("SLIM_USERNAME")
+		os.Unsetenv("SLIM_PASSWORD")
 
 		// Set environment variables
-		os.Setenv("USERNAME", "env@example.com")
-		os.Setenv("PASSWORD", "envpassword")
+		os.Setenv("SLIM_USERNAME", "env@example.com")
+		os.Setenv("SLIM_PASSWORD", "envpassword")
 
 		// Create temporary .env file with only PASSWORD
 		tempDir := t.TempDir()
@@ -163,8 +177,8 @@ PASSWORD=filepassword`
 
 	t.Run("quoted values in env file", func(t *testing.T) {
 		// Clear environment variables
-		os.Unsetenv("USERNAME")
-		os.Unsetenv("PASSWORD")
+		os.Unsetenv("SLIM_USERNAME")
+		os.Unsetenv("SLIM_PASSWORD")
 
 		// Create temporary .env file with quoted values
 		tempDir := t.TempDir()
@@ -198,8 +212,8 @@ PASSWORD='singlequoted'`
 
 func TestCredentialManager_ErrorMessages(t *testing.T) {
 	// Clear environment variables
-	os.Unsetenv("USERNAME")
-	os.Unsetenv("PASSWORD")
+	os.Unsetenv("SLIM_USERNAME")
+	os.Unsetenv("SLIM_PASSWORD")
 
 	tests := []struct {
 		name        string
@@ -254,23 +268,23 @@ func TestCredentialManager_ErrorMessages(t *testing.T) {
 func TestCredentialManager_ValidateCredentialsEnhanced(t *testing.T) {
 	t.Run("valid credentials from environment", func(t *testing.T) {
 		// Save original environment variables
-		originalUsername := os.Getenv("USERNAME")
-		originalPassword := os.Getenv("PASSWORD")
+		originalUsername := os.Getenv("SLIM_USERNAME")
+		originalPassword := os.Getenv("SLIM_PASSWORD")
 		defer func() {
 			if originalUsername != "" {
-				os.Setenv("USERNAME", originalUsername)
+				os.Setenv("SLIM_USERNAME", originalUsername)
 			} else {
-				os.Unsetenv("USERNAME")
+				os.Unsetenv("SLIM_USERNAME")
 			}
 			if originalPassword != "" {
-				os.Setenv("PASSWORD", originalPassword)
+				os.Setenv("SLIM_PASSWORD", originalPassword)
 			} else {
-				os.Unsetenv("PASSWORD")
+				os.Unsetenv("SLIM_PASSWORD")
 			}
 		}()
 
-		os.Setenv("USERNAME", "valid@example.com")
-		os.Setenv("PASSWORD", "validpassword")
+		os.Setenv("SLIM_USERNAME", "valid@example.com")
+		os.Setenv("SLIM_PASSWORD", "validpassword")
 
 		cm := NewCredentialManager("/nonexistent/.env")
 		err := cm.ValidateCredentials()
@@ -282,8 +296,8 @@ func TestCredentialManager_ValidateCredentialsEnhanced(t *testing.T) {
 
 	t.Run("invalid credentials", func(t *testing.T) {
 		// Clear environment variables
-		os.Unsetenv("USERNAME")
-		os.Unsetenv("PASSWORD")
+		os.Unsetenv("SLIM_USERNAME")
+		os.Unsetenv("SLIM_PASSWORD")
 
 		cm := NewCredentialManager("/nonexistent/.env")
 		err := cm.ValidateCredentials()
@@ -0,0 +1,279 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// saveTestToken stores a token that expires at the given time
+func saveTestToken(t *testing.T, client *SlimClient, token, refreshToken string, expiresAt time.Time) {
+	t.Helper()
+	info := &TokenInfo{
+		Token:        token,
+		TokenType:    "Bearer",
+		CreatedAt:    time.Now(),
+		ExpiresIn:    3600,
+		ExpiresAt:    expiresAt,
+		Username:     "test@example.com",
+		RefreshToken: refreshToken,
+	}
+	if err := client.tokenStore.SaveToken(info); err != nil {
+		t.Fatalf("Failed to save test token: %v", err)
+	}
+}
+
+// decodeGrant reads the grant type and refresh token from a login request body
+func decodeGrant(t *testing.T, r *http.Request) (string, string) {
+	var body map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		t.Errorf("Failed to decode login request: %v", err)
+		return "", ""
+	}
+	grant, _ := body["grant_type"].(string)
+	refresh, _ := body["refresh_token"].(string)
+	return grant, refresh
+}
+
+func TestSlimClient_RefreshToken(t *testing.T) {
+	t.Run("rotates access token and keeps refresh token", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			grant, refresh := decodeGrant(t, r)
+			if grant != "refresh_token" {
+				t.Errorf("Expected refresh_token grant, got %q", grant)
+			}
+			if refresh != "refresh-1" {
+				t.Errorf("Expected refresh token 'refresh-1', got %q", refresh)
+			}
+			encodeJSON(t, w, LoginResponse{AccessToken: "new-token", TokenType: "Bearer", ExpiresIn: 3600})
+		}))
+		defer server.Close()
+
+		client := NewSlimClient(t.TempDir())
+		client.baseURL = server.URL
+		saveTestToken(t, client, "old-token", "refresh-1", time.Now().Add(-time.Hour))
+
+		if err := client.RefreshToken(context.Background()); err != nil {
+			t.Fatalf("RefreshToken() failed: %v", err)
+		}
+
+		info, err := client.GetTokenInfo()
+		if err != nil {
+			t.Fatalf("GetTokenInfo() failed: %v", err)
+		}
+		if info.Token != "new-token" {
+			t.Errorf("Expected token 'new-token', got %q", info.Token)
+		}
+		if info.RefreshToken != "refresh-1" {
+			t.Errorf("Expected refresh token to be kept, got %q", info.RefreshToken)
+		}
+		if info.Username != "test@example.com" {
+			t.Errorf("Expected username to be kept, got %q", info.Username)
+		}
+		if !client.IsLoggedIn() {
+			t.Error("Expected to be logged in after refresh")
+		}
+	})
+
+	t.Run("no refresh token", func(t *testing.T) {
+		client := NewSlimClient(t.TempDir())
+		saveTestToken(t, client, "old-token", "", time.Now().Add(-time.Hour))
+
+		if err := client.RefreshToken(context.Background()); err == nil {
+			t.Error("Expected RefreshToken() to fail without refresh token")
+		}
+	})
+}
+
+func TestSlimClient_ExpiredTokenIsRefreshed(t *testing.T) {
+	var logins atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/auth/login" {
+			logins.Add(1)
+			if grant, _ := decodeGrant(t, r); grant != "refresh_token" {
+				t.Errorf("Expected refresh_token grant, got %q", grant)
+			}
+			encodeJSON(t, w, LoginResponse{AccessToken: "refreshed-token", TokenType: "Bearer", ExpiresIn: 3600, RefreshToken: "refresh-2"})
+			return
+		}
+		verifyAuthHeader(t, r, "refreshed-token")
+		encodeJSON(t, w, UserProfile{ID: 1, Username: "test"})
+	}))
+	defer server.Close()
+
+	tempDir := t.TempDir()
+	client := NewSlimClient(tempDir)
+	client.baseURL = server.URL
+	client.credManager = NewCredentialManager(filepath.Join(tempDir, ".env"))
+	saveTestToken(t, client, "expired-token", "refresh-1", time.Now().Add(-time.Minute))
+
+	if _, err := client.GetProfile(context.Background()); err != nil {
+		t.Fatalf("GetProfile() failed: %v", err)
+	}
+	if got := logins.Load(); got != 1 {
+		t.Errorf("Expected 1 refresh call, got %d", got)
+	}
+
+	info, err := client.GetTokenInfo()
+	if err != nil {
+		t.Fatalf("GetTokenInfo() failed: %v", err)
+	}
+	if info.RefreshToken != "refresh-2" {
+		t.Errorf("Expected rotated refresh token 'refresh-2', got %q", info.RefreshToken)
+	}
+}
+
+func TestSlimClient_UnauthorizedRetriesOnce(t *testing.T) {
+	var requests, refreshes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/auth/login" {
+			refreshes.Add(1)
+			encodeJSON(t, w, LoginResponse{AccessToken: "fresh-token", TokenType: "Bearer", ExpiresIn: 3600})
+			return
+		}
+		requests.Add(1)
+		if r.Header.Get("authorization") != "Bearer fresh-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		encodeJSON(t, w, UserProfile{ID: 1, Username: "test"})
+	}))
+	defer server.Close()
+
+	client := NewSlimClient(t.TempDir())
+	client.baseURL = server.URL
+	saveTestToken(t, client, "revoked-token", "refresh-1", time.Now().Add(time.Hour))
+
+	profile, err := client.GetProfile(context.Background())
+	if err != nil {
+		t.Fatalf("GetProfile() failed: %v", err)
+	}
+	if profile.ID != 1 {
+		t.Errorf("Expected profile ID 1, got %d", profile.ID)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("Expected 2 API requests, got %d", got)
+	}
+	if got := refreshes.Load(); got != 1 {
+		t.Errorf("Expected 1 refresh, got %d", got)
+	}
+}
+
+func TestSlimClient_UnauthorizedAfterRetryFails(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/auth/login" {
+			encodeJSON(t, w, LoginResponse{AccessToken: "fresh-token", TokenType: "Bearer", ExpiresIn: 3600})
+			return
+		}
+		requests.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := NewSlimClient(t.TempDir())
+	client.baseURL = server.URL
+	saveTestToken(t, client, "revoked-token", "refresh-1", time.Now().Add(time.Hour))
+
+	if _, err := client.GetProfile(context.Background()); err == nil {
+		t.Fatal("Expected GetProfile() to fail when the API keeps answering 401")
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("Expected exactly one retry (2 requests), got %d", got)
+	}
+}
+
+func TestSlimClient_ConcurrentRefreshIsSerialised(t *testing.T) {
+	var refreshes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/auth/login" {
+			refreshes.Add(1)
+			time.Sleep(20 * time.Millisecond)
+			encodeJSON(t, w, LoginResponse{AccessToken: "fresh-token", TokenType: "Bearer", ExpiresIn: 3600})
+			return
+		}
+		if r.Header.Get("authorization") != "Bearer fresh-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		encodeJSON(t, w, UserProfile{ID: 1})
+	}))
+	defer server.Close()
+
+	client := NewSlimClient(t.TempDir())
+	client.baseURL = server.URL
+	client.SetRateLimiter(nil)
+	saveTestToken(t, client, "revoked-token", "refresh-1", time.Now().Add(time.Hour))
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetProfile(context.Background()); err != nil {
+				t.Errorf("GetProfile() failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := refreshes.Load(); got != 1 {
+		t.Errorf("Expected a single refresh for concurrent requests, got %d", got)
+	}
+}
+
+func TestSlimClient_RefreshFailureFallsBackToLogin(t *testing.T) {
+	var grants []string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/auth/login" {
+			verifyAuthHeader(t, r, "login-token")
+			encodeJSON(t, w, UserProfile{ID: 1})
+			return
+		}
+		grant, _ := decodeGrant(t, r)
+		mu.Lock()
+		grants = append(grants, grant)
+		mu.Unlock()
+		if grant == "refresh_token" {
+			w.WriteHeader(http.StatusBadRequest)
+			writeResponse(t, w, []byte(`{"error": "invalid_grant"}`))
+			return
+		}
+		encodeJSON(t, w, LoginResponse{AccessToken: "login-token", TokenType: "Bearer", ExpiresIn: 3600, RefreshToken: "refresh-new"})
+	}))
+	defer server.Close()
+
+	tempDir := t.TempDir()
+	createCredentialsFile(t, tempDir, "test@example.com", "password123")
+
+	client := NewSlimClient(tempDir)
+	client.baseURL = server.URL
+	client.credManager = NewCredentialManager(filepath.Join(tempDir, ".env"))
+	saveTestToken(t, client, "expired-token", "refresh-old", time.Now().Add(-time.Hour))
+
+	if err := client.EnsureAuthenticated(context.Background()); err != nil {
+		t.Fatalf("EnsureAuthenticated() failed: %v", err)
+	}
+	if _, err := client.GetProfile(context.Background()); err != nil {
+		t.Fatalf("GetProfile() failed: %v", err)
+	}
+
+	if len(grants) != 2 || grants[0] != "refresh_token" || grants[1] != "external_password" {
+		t.Errorf("Expected refresh then password grant, got %v", grants)
+	}
+
+	info, err := client.GetTokenInfo()
+	if err != nil {
+		t.Fatalf("GetTokenInfo() failed: %v", err)
+	}
+	if info.RefreshToken != "refresh-new" {
+		t.Errorf("Expected refresh token from login, got %q", info.RefreshToken)
+	}
+}
@@ -7,10 +7,17 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"os"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/kjanat/slimacademy/internal/config"
 )
 
 // SlimClient represents the Slim Academy API client
@@ -20,12 +27,27 @@ type SlimClient struct {
 	tokenStore  *TokenStore
 	credManager *CredentialManager
 
+	// refreshMu serialises token refreshes and fallback logins
+	refreshMu sync.Mutex
+
+	retryPolicy RetryPolicy
+	limiter     *RateLimiter
+
+	// driftMu guards the drift handler and the drift already reported
+	driftMu      sync.Mutex
+	driftHandler func(*SchemaDrift)
+	driftSeen    map[string]bool
+
 	// API Configuration (matching bash script)
 	clientID     string
 	clientSecret string
-	deviceID     string
 	origin       string
 	referer      string
+	userAgent    string
+
+	// deviceMu guards deviceID; an empty deviceID is generated per install on first login
+	deviceMu sync.Mutex
+	deviceID string
 }
 
 // NewSlimClient creates a new Slim Academy API client
@@ -35,6 +57,10 @@ func NewSlimClient(outputDir string) *SlimClient {
 		baseURL:     "https://api.slimacademy.nl",
 		tokenStore:  NewTokenStore(outputDir),
 		credManager: NewCredentialManager(".env"),
+		retryPolicy: DefaultRetryPolicy(),
+		limiter:     NewRateLimiter(10, 10),
+
+		driftHandler: logDrift,
 
 		// Match bash script configuration
 		clientID:     "slim_api",
@@ -42,7 +68,98 @@ func NewSlimClient(outputDir string) *SlimClient {
 		deviceID:     "4b3c1096-114f-423b-822f-22785cc3f05e",
 		origin:       "https://app.slimacademy.nl",
 		referer:      "https://app.slimacademy.nl/",
+		userAgent:    "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/137.0.0.0 Safari/537.36",
+	}
+}
+
+// ConfigureAPI sets the API server and client identity. Without a configured device ID a
+// per-install ID is generated at the first login and stored next to the token.
+func (c *SlimClient) ConfigureAPI(cfg *config.APIConfig) {
+	if cfg == nil {
+		cfg = config.DefaultAPIConfig()
+	}
+
+	c.SetBaseURL(cfg.BaseURL)
+	c.clientID = cfg.ClientID
+	c.origin = cfg.Origin
+	c.referer = cfg.Referer
+	c.userAgent = cfg.UserAgent
+
+	c.deviceMu.Lock()
+	c.deviceID = cfg.DeviceID
+	c.deviceMu.Unlock()
+}
+
+// BaseURL returns the API server
+func (c *SlimClient) BaseURL() string {
+	return c.baseURL
+}
+
+// DeviceID returns the device ID sent at login, generating and storing one if needed
+func (c *SlimClient) DeviceID() (string, error) {
+	c.deviceMu.Lock()
+	defer c.deviceMu.Unlock()
+
+	if c.deviceID == "" {
+		id, err := c.tokenStore.DeviceID()
+		if err != nil {
+			return "", fmt.Errorf("failed to get device ID: %w", err)
+		}
+		c.deviceID = id
 	}
+	return c.deviceID, nil
+}
+
+// SetBaseURL sets the API server, e.g. a mock server in tests
+func (c *SlimClient) SetBaseURL(baseURL string) {
+	c.baseURL = strings.TrimSuffix(baseURL, "/")
+}
+
+// SetSecretStore keeps the token and credentials in a secret store; credentials missing from
+// the store are still read from envFile and the environment. A nil store restores the token
+// file in outputDir.
+func (c *SlimClient) SetSecretStore(store SecretStore, outputDir, envFile string) {
+	if store == nil {
+		c.tokenStore = NewTokenStore(outputDir)
+		c.credManager = NewCredentialManager(envFile)
+		return
+	}
+	c.tokenStore = NewSecretTokenStore(store)
+	c.credManager = NewSecretCredentialManager(store, envFile)
+}
+
+// ConfigureSecrets selects the secret store from the authentication configuration
+func (c *SlimClient) ConfigureSecrets(cfg *config.AuthConfig, outputDir string) error {
+	if cfg == nil {
+		cfg = config.DefaultAuthConfig()
+	}
+
+	store, err := NewSecretStore(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to open secret store: %w", err)
+	}
+
+	envFile := cfg.EnvFile
+	if cfg.SecretStore == config.SecretStoreEnv {
+		envFile = ""
+	}
+	c.SetSecretStore(store, outputDir, envFile)
+	return nil
+}
+
+// SecretsLocation tells where the token is kept
+func (c *SlimClient) SecretsLocation() string {
+	return c.tokenStore.Describe()
+}
+
+// SetRetryPolicy sets the policy used to retry failed requests
+func (c *SlimClient) SetRetryPolicy(policy RetryPolicy) {
+	c.retryPolicy = policy
+}
+
+// SetRateLimiter sets the limiter shared by all requests; nil disables rate limiting
+func (c *SlimClient) SetRateLimiter(limiter *RateLimiter) {
+	c.limiter = limiter
 }
 
 // newRequest creates a new HTTP request with proper headers (matching bash script)
@@ -67,7 +184,7 @@ func (c *SlimClient) newRequest(ctx context.Context, method, endpoint string, bo
 	req.Header.Set("sec-fetch-dest", "empty")
 	req.Header.Set("sec-fetch-mode", "cors")
 	req.Header.Set("sec-fetch-site", "same-site")
-	req.Header.Set("user-agent", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/137.0.0.0 Safari/537.36")
+	req.Header.Set("user-agent", c.userAgent)
 
 	return req, nil
 }
@@ -89,28 +206,121 @@ func (c *SlimClient) newAuthenticatedRequest(ctx context.Context, method, endpoi
 	return req, nil
 }
 
-// doRequest executes an HTTP request and handles the response
+// doAuthenticated executes an authenticated request, refreshing an expired token first
+// and retrying once after re-authentication when the API answers 401
+func (c *SlimClient) doAuthenticated(ctx context.Context, method, endpoint string) ([]byte, error) {
+	if !c.tokenStore.IsTokenValid() && c.hasRefreshToken() {
+		if err := c.reauthenticate(ctx, c.currentToken()); err != nil {
+			return nil, fmt.Errorf("authentication required: %w", err)
+		}
+	}
+
+	req, err := c.newAuthenticatedRequest(ctx, method, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.doRequest(req)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		return body, err
+	}
+
+	stale := strings.TrimPrefix(req.Header.Get("authorization"), "Bearer ")
+	if authErr := c.reauthenticate(ctx, stale); authErr != nil {
+		return nil, fmt.Errorf("%w (re-authentication failed: %v)", err, authErr)
+	}
+
+	req, err = c.newAuthenticatedRequest(ctx, method, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.doRequest(req)
+}
+
+// doRequest executes an HTTP request, retrying network errors and retryable statuses
+// according to the retry policy, and handles the response
 func (c *SlimClient) doRequest(req *http.Request) ([]byte, error) {
+	ctx := req.Context()
+	attempts := c.retryPolicy.attempts()
+
+	for attempt := 1; ; attempt++ {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
+
+		body, retryAfter, err := c.doAttempt(req)
+		if err == nil {
+			return body, nil
+		}
+
+		var apiErr *APIError
+		isAPIErr := errors.As(err, &apiErr)
+		if isAPIErr {
+			apiErr.Attempts = attempt
+		}
+
+		delay, canWait := c.retryPolicy.retryDelay(attempt, retryAfter)
+		retryable := ctx.Err() == nil && canWait && (!isAPIErr || isRetryableStatus(apiErr.StatusCode))
+		if !retryable || attempt >= attempts {
+			if !isAPIErr && attempt > 1 {
+				return nil, fmt.Errorf("request failed after %d attempts: %w", attempt, err)
+			}
+			return nil, err
+		}
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, fmt.Errorf("request failed: %w", err)
+		}
+
+		if req, err = rewindRequest(req); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// doAttempt performs a single request, returning the Retry-After delay for failed responses
+func (c *SlimClient) doAttempt(req *http.Request) ([]byte, time.Duration, error) {
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, 0, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
+		return nil, 0, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, &APIError{
+		retryAfter, _ := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		return nil, retryAfter, &APIError{
 			StatusCode: resp.StatusCode,
 			Message:    fmt.Sprintf("API request failed with status %d", resp.StatusCode),
 			Response:   string(body),
+			RetryAfter: retryAfter,
 		}
 	}
 
-	return body, nil
+	return body, 0, nil
+}
+
+// rewindRequest returns a copy of the request with a fresh body for another attempt
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	next := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return next, nil
+	}
+	if req.GetBody == nil {
+		return nil, fmt.Errorf("request failed: body of %s %s cannot be replayed", req.Method, req.URL.Path)
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("failed to rewind request body: %w", err)
+	}
+	next.Body = body
+	return next, nil
 }
 
 // Login authenticates with the Slim Academy API
@@ -121,12 +331,17 @@ func (c *SlimClient) Login(ctx context.Context) error {
 		return fmt.Errorf("failed to load credentials: %w", err)
 	}
 
+	deviceID, err := c.DeviceID()
+	if err != nil {
+		return err
+	}
+
 	// Build login request (matching bash script)
 	loginReq := LoginRequest{
 		GrantType:    "external_password",
 		ClientID:     c.clientID,
 		ClientSecret: c.clientSecret,
-		DeviceID:     c.deviceID,
+		DeviceID:     deviceID,
 		Username:     creds.Username,
 		Password:     creds.Password,
 	}
@@ -148,12 +363,99 @@ func (c *SlimClient) Login(ctx context.Context) error {
 		return fmt.Errorf("login request failed: %w", err)
 	}
 
-	// Parse response
+	if err := c.saveLoginResponse(respBody, creds.Username, ""); err != nil {
+		return err
+	}
+
+	// Credentials that worked are kept in the secret store for later logins
+	if err := c.credManager.StoreCredentials(creds); err != nil {
+		slog.Warn("failed to store credentials", "error", err)
+	}
+	return nil
+}
+
+// RefreshToken exchanges the stored refresh token for a new access token
+func (c *SlimClient) RefreshToken(ctx context.Context) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	return c.refreshToken(ctx)
+}
+
+// refreshToken performs the refresh grant; callers must hold refreshMu
+func (c *SlimClient) refreshToken(ctx context.Context) error {
+	info, err := c.tokenStore.LoadToken()
+	if err != nil {
+		return err
+	}
+	if info.RefreshToken == "" {
+		return fmt.Errorf("no refresh token available")
+	}
+
+	deviceID, err := c.DeviceID()
+	if err != nil {
+		return err
+	}
+
+	refreshReq := RefreshRequest{
+		GrantType:    "refresh_token",
+		ClientID:     c.clientID,
+		ClientSecret: c.clientSecret,
+		DeviceID:     deviceID,
+		RefreshToken: info.RefreshToken,
+	}
+
+	jsonData, err := json.Marshal(refreshReq)
+	if err != nil {
+		return fmt.Errorf("failed to marshal refresh request: %w", err)
+	}
+
+	req, err := c.newRequest(ctx, "POST", "/api/auth/login", bytes.NewReader(jsonData))
+	if err != nil {
+		return err
+	}
+
+	respBody, err := c.doRequest(req)
+	if err != nil {
+		return fmt.Errorf("refresh request failed: %w", err)
+	}
+
+	return c.saveLoginResponse(respBody, info.Username, info.RefreshToken)
+}
+
+// reauthenticate replaces the stale token, preferring the refresh grant and falling back to
+// a credential login. A token that was replaced by a concurrent caller is reused as is.
+func (c *SlimClient) reauthenticate(ctx context.Context, stale string) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	if current := c.currentToken(); current != "" && current != stale && c.tokenStore.IsTokenValid() {
+		return nil
+	}
+
+	if c.hasRefreshToken() {
+		if err := c.refreshToken(ctx); err == nil {
+			return nil
+		}
+	}
+
+	// Keep stdout clean for machine-readable output such as fetch progress JSON lines
+	fmt.Fprintln(os.Stderr, "Authentication required, logging in...")
+	return c.Login(ctx)
+}
+
+// saveLoginResponse parses a token response and persists it, keeping the previous refresh
+// token when the response does not rotate it
+func (c *SlimClient) saveLoginResponse(respBody []byte, username, refreshToken string) error {
 	var loginResp LoginResponse
 	if err := json.Unmarshal(respBody, &loginResp); err != nil {
 		return fmt.Errorf("failed to parse login response: %w", err)
 	}
 
+	if loginResp.RefreshToken != "" {
+		refreshToken = loginResp.RefreshToken
+	}
+
 	// Calculate expiry time
 	expiresAt := time.Now().Add(time.Duration(loginResp.ExpiresIn) * time.Second)
 
@@ -164,8 +466,8 @@ func (c *SlimClient) Login(ctx context.Context) error {
 		CreatedAt:    time.Now(),
 		ExpiresIn:    loginResp.ExpiresIn,
 		ExpiresAt:    expiresAt,
-		Username:     creds.Username,
-		RefreshToken: loginResp.RefreshToken,
+		Username:     username,
+		RefreshToken: refreshToken,
 	}
 
 	if err := c.tokenStore.SaveToken(tokenInfo); err != nil {
@@ -175,6 +477,21 @@ func (c *SlimClient) Login(ctx context.Context) error {
 	return nil
 }
 
+// currentToken returns the stored access token, valid or not
+func (c *SlimClient) currentToken() string {
+	info, err := c.tokenStore.LoadToken()
+	if err != nil {
+		return ""
+	}
+	return info.Token
+}
+
+// hasRefreshToken reports whether a refresh token is stored
+func (c *SlimClient) hasRefreshToken() bool {
+	info, err := c.tokenStore.LoadToken()
+	return err == nil && info.RefreshToken != ""
+}
+
 // IsLoggedIn checks if the client has a valid authentication token
 func (c *SlimClient) IsLoggedIn() bool {
 	return c.tokenStore.IsTokenValid()
@@ -190,12 +507,17 @@ func (c *SlimClient) Logout() error {
 	return c.tokenStore.ClearToken()
 }
 
-// EnsureAuthenticated ensures the client is authenticated, logging in if necessary
+// ForgetCredentials removes the credentials kept in the secret store
+func (c *SlimClient) ForgetCredentials() error {
+	return c.credManager.ForgetCredentials()
+}
+
+// EnsureAuthenticated ensures the client is authenticated, refreshing an expired token or
+// logging in if necessary
 func (c *SlimClient) EnsureAuthenticated(ctx context.Context) error {
 	if c.IsLoggedIn() {
 		return nil
 	}
 
-	fmt.Println("Authentication required, logging in...")
-	return c.Login(ctx)
+	return c.reauthenticate(ctx, c.currentToken())
 }
@@ -11,6 +11,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/kjanat/slimacademy/internal/config"
 )
 
 // Test helper to create temporary directory
@@ -296,6 +298,7 @@ func TestSlimClient_doRequest(t *testing.T) {
 
 			tempDir := createTempDir(t)
 			client := NewSlimClient(tempDir)
+			client.SetRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond})
 
 			// Create request to test server
 			req, err := http.NewRequest("GET", server.URL, nil)
@@ -426,6 +429,7 @@ func TestSlimClient_Login(t *testing.T) {
 			client := NewSlimClient(tempDir)
 			// Override base URL to use test server
 			client.baseURL = server.URL
+			client.SetRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond})
 			// Set credential manager to use temp dir
 			client.credManager = NewCredentialManager(filepath.Join(tempDir, ".env"))
 
@@ -462,6 +466,94 @@ func TestSlimClient_Login(t *testing.T) {
 	}
 }
 
+func TestSlimClient_ConfigureAPI(t *testing.T) {
+	var deviceIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ua := r.Header.Get("user-agent"); ua != "slim-tests" {
+			t.Errorf("Expected configured user agent, got %q", ua)
+		}
+		if origin := r.Header.Get("origin"); origin != "http://localhost" {
+			t.Errorf("Expected configured origin, got %q", origin)
+		}
+
+		var loginReq LoginRequest
+		if err := json.NewDecoder(r.Body).Decode(&loginReq); err != nil {
+			t.Errorf("Failed to decode login request: %v", err)
+		}
+		if loginReq.ClientID != "staging" {
+			t.Errorf("Expected configured client_id, got %q", loginReq.ClientID)
+		}
+		deviceIDs = append(deviceIDs, loginReq.DeviceID)
+
+		encodeJSON(t, w, LoginResponse{AccessToken: "token", TokenType: "Bearer", ExpiresIn: 3600})
+	}))
+	defer server.Close()
+
+	tempDir := createTempDir(t)
+	envFile := createCredentialsFile(t, tempDir, "test@example.com", "password123")
+
+	newClient := func(deviceID string) *SlimClient {
+		client := NewSlimClient(tempDir)
+		client.credManager = NewCredentialManager(envFile)
+		client.ConfigureAPI(&config.APIConfig{
+			BaseURL:   server.URL + "/",
+			ClientID:  "staging",
+			DeviceID:  deviceID,
+			Origin:    "http://localhost",
+			Referer:   "http://localhost/",
+			UserAgent: "slim-tests",
+		})
+		return client
+	}
+
+	for _, deviceID := range []string{"", "", "fixed-device"} {
+		if err := newClient(deviceID).Login(context.Background()); err != nil {
+			t.Fatalf("Login() failed: %v", err)
+		}
+	}
+
+	// Without a configured ID a device ID is generated once and reused by later clients
+	if len(deviceIDs[0]) != 36 || deviceIDs[0] == "4b3c1096-114f-423b-822f-22785cc3f05e" {
+		t.Errorf("Expected a generated device ID, got %q", deviceIDs[0])
+	}
+	if deviceIDs[1] != deviceIDs[0] {
+		t.Errorf("Expected the device ID to persist, got %q and %q", deviceIDs[0], deviceIDs[1])
+	}
+	if deviceIDs[2] != "fixed-device" {
+		t.Errorf("Expected the configured device ID, got %q", deviceIDs[2])
+	}
+
+	data, err := os.ReadFile(filepath.Join(tempDir, ".device_id"))
+	if err != nil || strings.TrimSpace(string(data)) != deviceIDs[0] {
+		t.Errorf("Expected device ID next to the token, got %q, %v", data, err)
+	}
+
+	// Logging out keeps the device ID
+	client := newClient("")
+	if err := client.Logout(); err != nil {
+		t.Fatal(err)
+	}
+	if id, err := client.DeviceID(); err != nil || id != deviceIDs[0] {
+		t.Errorf("Expected device ID to survive logout, got %q, %v", id, err)
+	}
+}
+
+func TestTokenStore_DeviceID_SecretStore(t *testing.T) {
+	store := NewEnvSecretStore()
+	ts := NewSecretTokenStore(store)
+
+	id, err := ts.DeviceID()
+	if err != nil {
+		t.Fatalf("DeviceID() failed: %v", err)
+	}
+	if stored, _ := store.Get(SecretDeviceID); stored != id {
+		t.Errorf("Expected device ID in the secret store, got %q", stored)
+	}
+	if again, _ := ts.DeviceID(); again != id {
+		t.Errorf("Expected the same device ID, got %q and %q", id, again)
+	}
+}
+
 func TestSlimClient_Login_CredentialErrors(t *testing.T) {
 	tempDir := createTempDir(t)
 	client := NewSlimClient(tempDir)
@@ -0,0 +1,228 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/kjanat/slimacademy/internal/models"
+)
+
+// SchemaDrift lists the differences between an API response and the model it was decoded
+// into. Fields are JSON paths such as "body.content[].paragraph", where [] stands for any
+// array element and * for any map value.
+type SchemaDrift struct {
+	BookID  string   `json:"id"`
+	Part    string   `json:"part"`
+	Unknown []string `json:"unknown,omitempty"` // Fields in the response that the model lacks
+	Missing []string `json:"missing,omitempty"` // Model fields absent from every object in the response
+}
+
+// Empty reports whether the response matched the model
+func (d *SchemaDrift) Empty() bool {
+	return len(d.Unknown) == 0 && len(d.Missing) == 0
+}
+
+// DetectDrift compares a JSON document with the json tags of model, a value or pointer of the
+// type the document is decoded into. Fields tagged omitempty are optional and never missing.
+// Structs without json fields, such as types with custom unmarshalling, are not inspected.
+func DetectDrift(data []byte, model any) (*SchemaDrift, error) {
+	var doc any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	w := &driftWalker{
+		unknown: make(map[string]bool),
+		present: make(map[string]map[string]bool),
+		objects: make(map[string][]jsonField),
+	}
+	w.walk("", doc, reflect.TypeOf(model))
+
+	drift := &SchemaDrift{}
+	for path := range w.unknown {
+		drift.Unknown = append(drift.Unknown, path)
+	}
+	for path, fields := range w.objects {
+		for _, f := range fields {
+			if !f.optional && !w.present[path][f.name] {
+				drift.Missing = append(drift.Missing, joinPath(path, f.name))
+			}
+		}
+	}
+	slices.Sort(drift.Unknown)
+	slices.Sort(drift.Missing)
+	return drift, nil
+}
+
+// jsonField is a field of a struct as seen by encoding/json
+type jsonField struct {
+	name     string
+	typ      reflect.Type
+	optional bool
+}
+
+// driftWalker walks a decoded JSON document alongside the model type, counting the keys seen
+// at every object path like examples/analyze_core_keys.go does for a whole file
+type driftWalker struct {
+	unknown map[string]bool
+	present map[string]map[string]bool // Keys seen per object path
+	objects map[string][]jsonField     // Model fields per object path
+}
+
+func (w *driftWalker) walk(path string, value any, t reflect.Type) {
+	if t == nil {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]any)
+		fields := structFields(t)
+		if !ok || len(fields) == 0 {
+			return
+		}
+
+		w.objects[path] = fields
+		if w.present[path] == nil {
+			w.present[path] = make(map[string]bool)
+		}
+		for key, v := range object {
+			w.present[path][key] = true
+			i := slices.IndexFunc(fields, func(f jsonField) bool { return f.name == key })
+			if i < 0 {
+				w.unknown[joinPath(path, key)] = true
+				continue
+			}
+			w.walk(joinPath(path, key), v, fields[i].typ)
+		}
+	case reflect.Slice, reflect.Array:
+		items, _ := value.([]any)
+		for _, item := range items {
+			w.walk(path+"[]", item, t.Elem())
+		}
+	case reflect.Map:
+		object, _ := value.(map[string]any)
+		for _, v := range object {
+			w.walk(joinPath(path, "*"), v, t.Elem())
+		}
+	}
+}
+
+// structFields returns the JSON fields of a struct type, including promoted fields of
+// embedded structs without a json name
+func structFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			embedded := f.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				fields = append(fields, structFields(embedded)...)
+			}
+			continue
+		}
+		if !f.IsExported() || name == "" {
+			continue // Untagged fields are not part of the API models
+		}
+
+		fields = append(fields, jsonField{
+			name:     name,
+			typ:      f.Type,
+			optional: slices.Contains(strings.Split(opts, ","), "omitempty"),
+		})
+	}
+	return fields
+}
+
+// joinPath appends a key to a JSON path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// driftModel returns the value the response of a book part is compared with. Content is
+// either a document or a list of chapters.
+func driftModel(v any) any {
+	if content, ok := v.(*models.Content); ok {
+		if content.Document != nil {
+			return content.Document
+		}
+		return content.Chapters
+	}
+	return v
+}
+
+// checkDrift compares a book part response with its model and passes fields that were not
+// reported before to the drift handler, so every change is reported once per client
+func (c *SlimClient) checkDrift(id, part string, data []byte, v any) {
+	c.driftMu.Lock()
+	handler := c.driftHandler
+	c.driftMu.Unlock()
+	if handler == nil {
+		return
+	}
+
+	drift, err := DetectDrift(data, driftModel(v))
+	if err != nil {
+		return // Already decoded successfully, so this cannot happen
+	}
+	drift.BookID = id
+	drift.Part = part
+
+	c.driftMu.Lock()
+	if c.driftSeen == nil {
+		c.driftSeen = make(map[string]bool)
+	}
+	reported := func(kind string) func(string) bool {
+		return func(path string) bool {
+			key := part + " " + kind + " " + path
+			seen := c.driftSeen[key]
+			c.driftSeen[key] = true
+			return seen
+		}
+	}
+	drift.Unknown = slices.DeleteFunc(drift.Unknown, reported("unknown"))
+	drift.Missing = slices.DeleteFunc(drift.Missing, reported("missing"))
+	c.driftMu.Unlock()
+
+	if !drift.Empty() {
+		handler(drift)
+	}
+}
+
+// SetDriftHandler sets the function receiving schema drift of fetched books; nil disables
+// drift detection. By default drift is logged.
+func (c *SlimClient) SetDriftHandler(handler func(*SchemaDrift)) {
+	c.driftMu.Lock()
+	defer c.driftMu.Unlock()
+	c.driftHandler = handler
+}
+
+// logDrift logs schema drift: new fields as warnings since they may carry content the
+// converters ignore, missing fields at debug level since most model fields are optional
+func logDrift(drift *SchemaDrift) {
+	level := slog.LevelDebug
+	if len(drift.Unknown) > 0 {
+		level = slog.LevelWarn
+	}
+	slog.Default().Log(context.Background(), level, "API response differs from models",
+		"id", drift.BookID, "part", drift.Part, "unknown", drift.Unknown, "missing", drift.Missing)
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/kjanat/slimacademy/internal/models"
+)
+
+func TestDetectDrift(t *testing.T) {
+	type child struct {
+		Name string `json:"name"`
+		Note string `json:"note,omitempty"`
+	}
+	type parent struct {
+		ID       int64            `json:"id"`
+		Title    string           `json:"title"`
+		Children []child          `json:"children"`
+		ByKey    map[string]child `json:"byKey"`
+		Ignored  string           `json:"-"`
+	}
+
+	data := []byte(`{
+		"id": 1,
+		"children": [{"name": "a", "colour": "red"}, {"name": "b"}],
+		"byKey": {"x": {"shape": "round"}},
+		"extra": {"nested": true}
+	}`)
+
+	drift, err := DetectDrift(data, &parent{})
+	if err != nil {
+		t.Fatalf("DetectDrift() failed: %v", err)
+	}
+
+	expectedUnknown := []string{"byKey.*.shape", "children[].colour", "extra"}
+	if !slices.Equal(drift.Unknown, expectedUnknown) {
+		t.Errorf("Expected unknown %v, got %v", expectedUnknown, drift.Unknown)
+	}
+	// "children[].name" is present in one of the objects; omitempty fields are optional
+	expectedMissing := []string{"byKey.*.name", "title"}
+	if !slices.Equal(drift.Missing, expectedMissing) {
+		t.Errorf("Expected missing %v, got %v", expectedMissing, drift.Missing)
+	}
+}
+
+func TestDetectDrift_Models(t *testing.T) {
+	// Custom unmarshalled values such as times and flags are not inspected
+	data := []byte(`[{"id": 1, "summaryId": 2, "title": "Intro", "isFree": "1", "isSupplement": 0,
+		"isLocked": false, "isVisible": 1, "parentChapterId": null, "gDocsChapterId": "g",
+		"sortIndex": 0, "subChapters": []}]`)
+
+	drift, err := DetectDrift(data, []models.Chapter{})
+	if err != nil {
+		t.Fatalf("DetectDrift() failed: %v", err)
+	}
+	if !drift.Empty() {
+		t.Errorf("Expected chapters to match the model, got %+v", drift)
+	}
+
+	if _, err := DetectDrift([]byte("{"), models.Book{}); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
+
+func TestSlimClient_DriftReportedOnce(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodeJSON(t, w, map[string]any{"documentId": "doc", "aiSummary": "new"})
+	}))
+	defer server.Close()
+
+	client := createAuthenticatedClient(t, server.URL)
+	var reports []*SchemaDrift
+	client.SetDriftHandler(func(d *SchemaDrift) { reports = append(reports, d) })
+
+	for _, id := range []string{"1", "2"} {
+		content, err := client.GetContent(context.Background(), id)
+		if err != nil {
+			t.Fatalf("GetContent() failed: %v", err)
+		}
+		if content.Document == nil || content.Document.DocumentID != "doc" {
+			t.Errorf("Expected decoded document, got %+v", content)
+		}
+	}
+
+	if len(reports) != 1 {
+		t.Fatalf("Expected drift to be reported once, got %d reports", len(reports))
+	}
+	report := reports[0]
+	if report.BookID != "1" || report.Part != PartContent {
+		t.Errorf("Unexpected report %+v", report)
+	}
+	if !slices.Equal(report.Unknown, []string{"aiSummary"}) {
+		t.Errorf("Expected unknown aiSummary, got %v", report.Unknown)
+	}
+	if !slices.Contains(report.Missing, "revisionId") {
+		t.Errorf("Expected missing revisionId, got %v", report.Missing)
+	}
+
+	client.SetDriftHandler(nil)
+	if _, err := client.GetContent(context.Background(), "3"); err != nil {
+		t.Fatalf("GetContent() without drift handler failed: %v", err)
+	}
+}
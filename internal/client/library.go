@@ -0,0 +1,225 @@
+package client
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// DefaultFetchWorkers is the number of books fetched in parallel when no worker count is given
+const DefaultFetchWorkers = 4
+
+// ProgressKind identifies a library fetch progress event
+type ProgressKind string
+
+const (
+	ProgressLibrary    ProgressKind = "library" // Library listing fetched; Total is set
+	ProgressBookStart  ProgressKind = "start"   // A worker started fetching a book
+	ProgressBookPart   ProgressKind = "part"    // One of the book's files was fetched; Part is set
+	ProgressBookDone   ProgressKind = "done"    // The book was fetched and handed to OnBook
+	ProgressBookFailed ProgressKind = "failed"  // The book could not be fetched; Error is set
+)
+
+// Book parts fetched for every book
+const (
+	PartSummary  = "summary"
+	PartChapters = "chapters"
+	PartContent  = "content"
+	PartNotes    = "notes"
+)
+
+// BookParts is the number of parts fetched per book
+const BookParts = 4
+
+// ProgressEvent reports the progress of a library fetch
+type ProgressEvent struct {
+	Kind   ProgressKind `json:"event"`
+	Index  int          `json:"index"` // Position of the book in the library
+	Total  int          `json:"total"`
+	BookID string       `json:"id,omitempty"`
+	Title  string       `json:"title,omitempty"`
+	Part   string       `json:"part,omitempty"`
+	Parts  int          `json:"parts,omitempty"` // Parts fetched so far for the book
+	Error  string       `json:"error,omitempty"`
+}
+
+// LibraryFetchOptions configures FetchLibrary
+type LibraryFetchOptions struct {
+	// Workers is the number of books fetched in parallel (default DefaultFetchWorkers)
+	Workers int
+	// Books restricts the fetch to these library entries; nil fetches the whole library
+	Books []LibrarySummary
+	// Progress receives progress events. Calls are serialised, so it need not be safe for
+	// concurrent use, but it should return quickly.
+	Progress func(ProgressEvent)
+	// OnBook receives every fetched book, one at a time, in completion order. An error marks
+	// the book as failed.
+	OnBook func(index int, book *BookData) error
+}
+
+// bookResult is the outcome of fetching one library book
+type bookResult struct {
+	index int
+	book  *BookData
+	err   error
+}
+
+// FetchLibrary fetches all books of the library, or opts.Books, with a pool of workers, handing each book to
+// opts.OnBook as soon as it is complete. Books that fail are reported through a
+// *LibraryFetchError. When ctx is cancelled no new books are started and the context error is
+// returned once the books in flight have finished or been aborted.
+func (c *SlimClient) FetchLibrary(ctx context.Context, opts LibraryFetchOptions) error {
+	if err := c.EnsureAuthenticated(ctx); err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	summaries := opts.Books
+	if summaries == nil {
+		library, err := c.GetLibrary(ctx)
+		if err != nil {
+			return err
+		}
+		summaries = library.Summaries
+	}
+
+	total := len(summaries)
+
+	var progressMu sync.Mutex
+	emit := func(event ProgressEvent) {
+		if opts.Progress == nil {
+			return
+		}
+		event.Total = total
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		opts.Progress(event)
+	}
+
+	emit(ProgressEvent{Kind: ProgressLibrary})
+	if total == 0 {
+		return nil
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultFetchWorkers
+	}
+	workers = min(workers, total)
+
+	indexes := make(chan int)
+	results := make(chan bookResult, workers)
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				summary := summaries[index]
+				id := fmt.Sprintf("%d", summary.ID)
+				emit(ProgressEvent{Kind: ProgressBookStart, Index: index, BookID: id, Title: summary.Title})
+
+				var partsMu sync.Mutex
+				parts := 0
+				book, err := c.fetchBookData(ctx, id, func(part string) {
+					partsMu.Lock()
+					parts++
+					done := parts
+					partsMu.Unlock()
+					emit(ProgressEvent{Kind: ProgressBookPart, Index: index, BookID: id, Title: summary.Title, Part: part, Parts: done})
+				})
+				results <- bookResult{index: index, book: book, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(indexes)
+		for index := range summaries {
+			select {
+			case indexes <- index:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var failures []*BookFetchError
+	for result := range results {
+		summary := summaries[result.index]
+		id := fmt.Sprintf("%d", summary.ID)
+
+		err := result.err
+		if err == nil && opts.OnBook != nil {
+			err = opts.OnBook(result.index, result.book)
+		}
+
+		if err != nil {
+			if ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+				continue // Aborted by cancellation, not a failure of the book
+			}
+			failures = append(failures, &BookFetchError{ID: id, Title: summary.Title, Err: err})
+			emit(ProgressEvent{Kind: ProgressBookFailed, Index: result.index, BookID: id, Title: summary.Title, Error: err.Error()})
+			continue
+		}
+
+		emit(ProgressEvent{Kind: ProgressBookDone, Index: result.index, BookID: id, Title: cmp.Or(result.book.Title, summary.Title), Parts: BookParts})
+	}
+
+	var fetchErr error
+	if len(failures) > 0 {
+		fetchErr = &LibraryFetchError{Total: total, Failures: failures}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return errors.Join(fmt.Errorf("library fetch interrupted: %w", err), fetchErr)
+	}
+
+	return fetchErr
+}
+
+// fetchBookData fetches the summary, chapters, content and notes of a book in parallel,
+// calling onPart as each part arrives. The first failure cancels the remaining requests.
+func (c *SlimClient) fetchBookData(ctx context.Context, id string, onPart func(part string)) (*BookData, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	book := newBookData(id)
+
+	var wg sync.WaitGroup
+	var errOnce sync.Once
+	var firstErr error
+	for _, p := range book.parts() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			raw, err := c.getBookPart(ctx, id, p.part, p.dest)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			*p.raw = raw
+			if onPart != nil {
+				onPart(p.part)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	book.Title = book.Summary.Title
+	return book, nil
+}
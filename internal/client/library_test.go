@@ -0,0 +1,268 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newLibraryServer serves a library of count books, calling hook before every book request
+func newLibraryServer(t *testing.T, count int, hook func(r *http.Request) bool) *httptest.Server {
+	summaries := make([]LibrarySummary, count)
+	for i := range summaries {
+		summaries[i] = LibrarySummary{ID: i + 1, Title: fmt.Sprintf("Book %d", i+1)}
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/summary/library" {
+			encodeJSON(t, w, LibraryResponse{Summaries: summaries, Total: count})
+			return
+		}
+		if hook != nil && !hook(r) {
+			return
+		}
+
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/summary/"), "/")
+		if len(parts) == 1 {
+			id, _ := strconv.Atoi(parts[0])
+			encodeJSON(t, w, map[string]any{"id": id, "title": "Book " + parts[0]})
+			return
+		}
+		encodeJSON(t, w, []any{})
+	}))
+}
+
+func TestSlimClient_FetchLibrary_Progress(t *testing.T) {
+	server := newLibraryServer(t, 3, nil)
+	defer server.Close()
+
+	client := createAuthenticatedClient(t, server.URL)
+
+	var events []ProgressEvent
+	var books []string
+	err := client.FetchLibrary(context.Background(), LibraryFetchOptions{
+		Workers:  2,
+		Progress: func(e ProgressEvent) { events = append(events, e) },
+		OnBook: func(index int, book *BookData) error {
+			books = append(books, book.Title)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("FetchLibrary() failed: %v", err)
+	}
+
+	if len(books) != 3 {
+		t.Errorf("Expected 3 books, got %v", books)
+	}
+
+	counts := make(map[ProgressKind]int)
+	for _, e := range events {
+		counts[e.Kind]++
+		if e.Total != 3 {
+			t.Errorf("Expected total 3 in %+v", e)
+		}
+	}
+	if events[0].Kind != ProgressLibrary {
+		t.Errorf("Expected library event first, got %q", events[0].Kind)
+	}
+	expected := map[ProgressKind]int{ProgressLibrary: 1, ProgressBookStart: 3, ProgressBookPart: 12, ProgressBookDone: 3}
+	for kind, n := range expected {
+		if counts[kind] != n {
+			t.Errorf("Expected %d %q events, got %d", n, kind, counts[kind])
+		}
+	}
+}
+
+func TestSlimClient_FetchLibrary_BoundedWorkers(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	var books sync.Map
+	server := newLibraryServer(t, 6, func(r *http.Request) bool {
+		id := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/summary/"), "/")[0]
+		if _, loaded := books.LoadOrStore(id, true); !loaded {
+			n := inFlight.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			inFlight.Add(-1)
+		}
+		return true
+	})
+	defer server.Close()
+
+	client := createAuthenticatedClient(t, server.URL)
+	client.SetRateLimiter(nil)
+
+	if err := client.FetchLibrary(context.Background(), LibraryFetchOptions{Workers: 2}); err != nil {
+		t.Fatalf("FetchLibrary() failed: %v", err)
+	}
+	if got := peak.Load(); got > 2 {
+		t.Errorf("Expected at most 2 books in flight, got %d", got)
+	}
+}
+
+func TestSlimClient_FetchLibrary_OnBookError(t *testing.T) {
+	server := newLibraryServer(t, 2, nil)
+	defer server.Close()
+
+	client := createAuthenticatedClient(t, server.URL)
+
+	var failed []ProgressEvent
+	err := client.FetchLibrary(context.Background(), LibraryFetchOptions{
+		Progress: func(e ProgressEvent) {
+			if e.Kind == ProgressBookFailed {
+				failed = append(failed, e)
+			}
+		},
+		OnBook: func(index int, book *BookData) error {
+			if book.ID == "2" {
+				return errors.New("disk full")
+			}
+			return nil
+		},
+	})
+
+	var libErr *LibraryFetchError
+	if !errors.As(err, &libErr) {
+		t.Fatalf("Expected LibraryFetchError, got %v", err)
+	}
+	if len(libErr.Failures) != 1 || libErr.Failures[0].ID != "2" {
+		t.Errorf("Expected book 2 to fail, got %v", libErr)
+	}
+	if len(failed) != 1 || failed[0].Error != "disk full" {
+		t.Errorf("Expected one failed event with the OnBook error, got %+v", failed)
+	}
+}
+
+func TestSlimClient_FetchLibrary_Cancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := newLibraryServer(t, 5, func(r *http.Request) bool {
+		if strings.HasPrefix(r.URL.Path, "/api/summary/2") {
+			cancel()
+			<-r.Context().Done()
+			return false
+		}
+		return true
+	})
+	defer server.Close()
+
+	client := createAuthenticatedClient(t, server.URL)
+
+	var saved []string
+	err := client.FetchLibrary(ctx, LibraryFetchOptions{
+		Workers: 1,
+		OnBook: func(index int, book *BookData) error {
+			saved = append(saved, book.ID)
+			return nil
+		},
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	var libErr *LibraryFetchError
+	if errors.As(err, &libErr) {
+		t.Errorf("Expected cancelled books not to be reported as failures, got %v", libErr)
+	}
+	if len(saved) != 1 || saved[0] != "1" {
+		t.Errorf("Expected only book 1 to complete, got %v", saved)
+	}
+}
+
+func TestSlimClient_FetchAllBookData_Parallel(t *testing.T) {
+	var inFlight atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if inFlight.Add(1) == 4 {
+			close(release)
+		}
+		select {
+		case <-release:
+		case <-time.After(2 * time.Second):
+		}
+		if strings.Count(r.URL.Path, "/") == 3 {
+			encodeJSON(t, w, map[string]any{"title": "Parallel"})
+			return
+		}
+		encodeJSON(t, w, []any{})
+	}))
+	defer server.Close()
+
+	client := createAuthenticatedClient(t, server.URL)
+
+	book, err := client.FetchAllBookData(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("FetchAllBookData() failed: %v", err)
+	}
+	if book.Title != "Parallel" {
+		t.Errorf("Expected title 'Parallel', got %q", book.Title)
+	}
+	select {
+	case <-release:
+	default:
+		t.Error("Expected all 4 parts to be requested in parallel")
+	}
+}
+
+func TestSlimClient_FetchLibrary_Books(t *testing.T) {
+	var libraryRequests atomic.Int32
+	server := newLibraryServer(t, 3, func(r *http.Request) bool {
+		if !strings.HasPrefix(r.URL.Path, "/api/summary/2") {
+			t.Errorf("Unexpected request for %s", r.URL.Path)
+		}
+		return true
+	})
+	defer server.Close()
+
+	client := createAuthenticatedClient(t, server.URL)
+	client.httpClient.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path == "/api/summary/library" {
+			libraryRequests.Add(1)
+		}
+		return http.DefaultTransport.RoundTrip(r)
+	})
+
+	var fetched []string
+	err := client.FetchLibrary(context.Background(), LibraryFetchOptions{
+		Books: []LibrarySummary{{ID: 2, Title: "Book 2"}},
+		OnBook: func(index int, book *BookData) error {
+			fetched = append(fetched, book.ID)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("FetchLibrary() failed: %v", err)
+	}
+	if len(fetched) != 1 || fetched[0] != "2" {
+		t.Errorf("Expected only book 2 to be fetched, got %v", fetched)
+	}
+	if got := libraryRequests.Load(); got != 0 {
+		t.Errorf("Expected the library listing not to be requested, got %d requests", got)
+	}
+
+	// An empty selection fetches nothing
+	if err := client.FetchLibrary(context.Background(), LibraryFetchOptions{Books: []LibrarySummary{}}); err != nil {
+		t.Fatalf("FetchLibrary() with no books failed: %v", err)
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
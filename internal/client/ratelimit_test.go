@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_reserve(t *testing.T) {
+	now := time.Now()
+	limiter := NewRateLimiter(10, 2)
+	limiter.last = now
+
+	// Burst tokens are available immediately
+	for i := range 2 {
+		if wait := limiter.reserve(now); wait != 0 {
+			t.Errorf("reserve %d: expected no wait within burst, got %v", i, wait)
+		}
+	}
+
+	// Further callers queue up at the refill rate
+	if wait := limiter.reserve(now); wait != 100*time.Millisecond {
+		t.Errorf("Expected 100ms wait, got %v", wait)
+	}
+	if wait := limiter.reserve(now); wait != 200*time.Millisecond {
+		t.Errorf("Expected 200ms wait, got %v", wait)
+	}
+
+	// Refill is capped at the burst size
+	later := now.Add(10 * time.Second)
+	for range 2 {
+		if wait := limiter.reserve(later); wait != 0 {
+			t.Errorf("Expected refilled bucket, got wait %v", wait)
+		}
+	}
+	if wait := limiter.reserve(later); wait == 0 {
+		t.Error("Expected bucket to be capped at burst size")
+	}
+}
+
+func TestRateLimiter_Wait(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		var limiter *RateLimiter
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Errorf("nil limiter Wait() failed: %v", err)
+		}
+		if err := NewRateLimiter(0, 1).Wait(context.Background()); err != nil {
+			t.Errorf("zero rate Wait() failed: %v", err)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		limiter := NewRateLimiter(0.001, 1)
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("first Wait() failed: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		if err := limiter.Wait(ctx); err == nil {
+			t.Error("Expected Wait() to fail when the context expires")
+		}
+	})
+}
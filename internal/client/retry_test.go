@@ -0,0 +1,253 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newRetryTestClient creates a client with a fast retry policy and no rate limit
+func newRetryTestClient(t *testing.T, serverURL string, attempts int) *SlimClient {
+	client := NewSlimClient(t.TempDir())
+	client.baseURL = serverURL
+	client.SetRetryPolicy(RetryPolicy{MaxAttempts: attempts, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond})
+	client.SetRateLimiter(nil)
+	return client
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	tests := []struct {
+		retry    int
+		expected time.Duration
+	}{
+		{0, 0},
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{5, time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Backoff(tt.retry); got != tt.expected {
+			t.Errorf("Backoff(%d) = %v, expected %v", tt.retry, got, tt.expected)
+		}
+	}
+
+	policy.Jitter = 0.5
+	for range 100 {
+		got := policy.Backoff(2)
+		if got < 100*time.Millisecond || got > 200*time.Millisecond {
+			t.Fatalf("Backoff(2) with jitter = %v, expected within [100ms, 200ms]", got)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"seconds", "3", 3 * time.Second, true},
+		{"http date", now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second, true},
+		{"past date", now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"empty", "", 0, false},
+		{"negative", "-1", 0, false},
+		{"garbage", "soon", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value, now)
+			if got != tt.expected || ok != tt.ok {
+				t.Errorf("parseRetryAfter(%q) = %v, %v, expected %v, %v", tt.value, got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+func TestSlimClient_doRequest_RetriesTransientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			writeResponse(t, w, []byte(`{"ok": true}`))
+		}
+	}))
+	defer server.Close()
+
+	client := newRetryTestClient(t, server.URL, 3)
+	req, err := client.newRequest(context.Background(), "GET", "/", nil)
+	if err != nil {
+		t.Fatalf("newRequest() failed: %v", err)
+	}
+
+	body, err := client.doRequest(req)
+	if err != nil {
+		t.Fatalf("doRequest() failed: %v", err)
+	}
+	if string(body) != `{"ok": true}` {
+		t.Errorf("Unexpected body %q", body)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+}
+
+func TestSlimClient_doRequest_GivesUp(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := newRetryTestClient(t, server.URL, 3)
+	req, _ := client.newRequest(context.Background(), "GET", "/", nil)
+
+	_, err := client.doRequest(req)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusBadGateway || apiErr.Attempts != 3 {
+		t.Errorf("Expected 502 after 3 attempts, got %d after %d", apiErr.StatusCode, apiErr.Attempts)
+	}
+	if got := calls.Load(); got != 3 {
+		t.Errorf("Expected 3 attempts, got %d", got)
+	}
+}
+
+func TestSlimClient_doRequest_DoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := newRetryTestClient(t, server.URL, 3)
+	req, _ := client.newRequest(context.Background(), "GET", "/", nil)
+
+	if _, err := client.doRequest(req); err == nil {
+		t.Fatal("Expected doRequest() to fail on 404")
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("Expected a single attempt for 404, got %d", got)
+	}
+}
+
+func TestSlimClient_doRequest_HonoursRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	var first time.Time
+	var elapsed time.Duration
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			first = time.Now()
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		elapsed = time.Since(first)
+		writeResponse(t, w, []byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := newRetryTestClient(t, server.URL, 2)
+	client.SetRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Second})
+	req, _ := client.newRequest(context.Background(), "GET", "/", nil)
+
+	if _, err := client.doRequest(req); err != nil {
+		t.Fatalf("doRequest() failed: %v", err)
+	}
+	if elapsed < time.Second {
+		t.Errorf("Expected retry to wait for Retry-After (1s), waited %v", elapsed)
+	}
+}
+
+func TestSlimClient_doRequest_GivesUpOnLongRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "86400")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := newRetryTestClient(t, server.URL, 3)
+	req, _ := client.newRequest(context.Background(), "GET", "/", nil)
+
+	start := time.Now()
+	_, err := client.doRequest(req)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.RetryAfter != 24*time.Hour {
+		t.Fatalf("Expected APIError with the Retry-After delay, got %v", err)
+	}
+	if got := calls.Load(); got != 1 || time.Since(start) > 5*time.Second {
+		t.Errorf("Expected no retry beyond MaxDelay, got %d attempts in %v", got, time.Since(start))
+	}
+}
+
+func TestSlimClient_doRequest_ReplaysBody(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"grant_type":"x"}` {
+			t.Errorf("Attempt %d got body %q", calls.Load()+1, body)
+		}
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		writeResponse(t, w, []byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := newRetryTestClient(t, server.URL, 2)
+	req, _ := client.newRequest(context.Background(), "POST", "/api/auth/login", bytes.NewReader([]byte(`{"grant_type":"x"}`)))
+
+	if _, err := client.doRequest(req); err != nil {
+		t.Fatalf("doRequest() failed: %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("Expected 2 attempts, got %d", got)
+	}
+}
+
+func TestSlimClient_doRequest_CancelledDuringBackoff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := newRetryTestClient(t, server.URL, 3)
+	client.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Minute})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := client.newRequest(ctx, "GET", "/", nil)
+
+	start := time.Now()
+	_, err := client.doRequest(req)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("Expected cancellation to interrupt the Retry-After wait")
+	}
+}
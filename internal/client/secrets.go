@@ -0,0 +1,244 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/kjanat/slimacademy/internal/config"
+)
+
+// Names of the secrets kept in a SecretStore
+const (
+	SecretToken    = "token" // TokenInfo as JSON
+	SecretUsername = "username"
+	SecretPassword = "password"
+	SecretDeviceID = "device_id" // Generated per install, see TokenStore.DeviceID
+)
+
+// ErrSecretNotFound is returned by SecretStore.Get for secrets that are not stored
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretStore keeps the login credentials and API token
+type SecretStore interface {
+	// Get returns a secret or ErrSecretNotFound
+	Get(name string) (string, error)
+	// Set stores a secret, replacing any previous value
+	Set(name, value string) error
+	// Delete removes a secret; deleting a missing secret is not an error
+	Delete(name string) error
+	// Describe tells where the secrets are kept
+	Describe() string
+}
+
+// NewSecretStore creates the secret store selected by cfg. It returns nil for the file backend,
+// which keeps the token in the output directory and reads credentials from the .env file.
+func NewSecretStore(cfg *config.AuthConfig) (SecretStore, error) {
+	if cfg == nil {
+		cfg = config.DefaultAuthConfig()
+	}
+
+	switch cfg.SecretStore {
+	case config.SecretStoreFile, "":
+		return nil, nil
+	case config.SecretStoreEncrypted:
+		path, err := cfg.EncryptedFilePath()
+		if err != nil {
+			return nil, err
+		}
+		passphrase := os.Getenv(cfg.PassphraseEnv)
+		if passphrase == "" {
+			return nil, fmt.Errorf("the encrypted secret store needs a passphrase in $%s", cfg.PassphraseEnv)
+		}
+		return NewEncryptedFileStore(path, passphrase), nil
+	case config.SecretStoreEnv:
+		return NewEnvSecretStore(), nil
+	case config.SecretStoreHelper:
+		return NewHelperSecretStore(cfg.Helper)
+	default:
+		return nil, fmt.Errorf("unknown secret store %q", cfg.SecretStore)
+	}
+}
+
+// checkPrivate returns an error when a file holding secrets can be read by other users
+func checkPrivate(path string) error {
+	if runtime.GOOS == "windows" {
+		return nil // Permission bits do not reflect Windows ACLs
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if perm := info.Mode().Perm(); perm&0o077 != 0 {
+		return fmt.Errorf("permissions %04o for %s are too open, run: chmod 600 %s", perm, path, path)
+	}
+	return nil
+}
+
+// EnvSecretStore reads credentials from the SLIM_USERNAME and SLIM_PASSWORD environment variables
+// and keeps the token in memory only, so that nothing is written to disk. The variables are
+// prefixed because Windows always sets USERNAME to the account name.
+type EnvSecretStore struct {
+	mu     sync.Mutex
+	memory map[string]string
+}
+
+// NewEnvSecretStore creates an environment-only secret store
+func NewEnvSecretStore() *EnvSecretStore {
+	return &EnvSecretStore{memory: make(map[string]string)}
+}
+
+// envSecretVariables maps secrets to the environment variables they are read from
+var envSecretVariables = map[string]string{
+	SecretUsername: "SLIM_USERNAME",
+	SecretPassword: "SLIM_PASSWORD",
+}
+
+func (s *EnvSecretStore) Get(name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if value, ok := s.memory[name]; ok {
+		return value, nil
+	}
+	if value := os.Getenv(envSecretVariables[name]); envSecretVariables[name] != "" && value != "" {
+		return value, nil
+	}
+	return "", ErrSecretNotFound
+}
+
+func (s *EnvSecretStore) Set(name, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.memory[name] = value
+	return nil
+}
+
+func (s *EnvSecretStore) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.memory, name)
+	return nil
+}
+
+func (s *EnvSecretStore) Describe() string {
+	return "environment variables (token kept in memory)"
+}
+
+// HelperSecretStore delegates to an external credential helper, similar to git credential
+// helpers. The helper is run as `<command> get|store|erase` and reads key=value lines from
+// stdin, ended by a blank line: service=slimacademy, name=<secret> and, for store,
+// value=<secret>. For get it prints value=<secret>, or nothing when the secret is unknown.
+// A non-zero exit status fails the operation. Secrets are cached in memory after the first
+// Get, so the helper runs once per secret.
+type HelperSecretStore struct {
+	command []string
+
+	mu    sync.Mutex
+	cache map[string]string // Secrets read from the helper; "" for secrets it does not know
+}
+
+// NewHelperSecretStore creates a store for a helper command line. The command is split on
+// whitespace and run without a shell.
+func NewHelperSecretStore(command string) (*HelperSecretStore, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("no credential helper command configured")
+	}
+	return &HelperSecretStore{command: args, cache: make(map[string]string)}, nil
+}
+
+// run executes the helper for an operation and returns its output attributes
+func (s *HelperSecretStore) run(operation, name, value string) (map[string]string, error) {
+	if strings.ContainsAny(value, "\r\n") {
+		return nil, fmt.Errorf("secret %s cannot be passed to the credential helper: contains a newline", name)
+	}
+
+	var input bytes.Buffer
+	fmt.Fprintf(&input, "service=slimacademy\nname=%s\n", name)
+	if operation == "store" {
+		fmt.Fprintf(&input, "value=%s\n", value)
+	}
+	input.WriteString("\n")
+
+	args := append(s.command[1:len(s.command):len(s.command)], operation)
+	cmd := exec.Command(s.command[0], args...)
+	cmd.Stdin = &input
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("credential helper %s failed: %w: %s", operation, err, msg)
+		}
+		return nil, fmt.Errorf("credential helper %s failed: %w", operation, err)
+	}
+
+	attrs := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			break
+		}
+		if key, val, ok := strings.Cut(line, "="); ok {
+			attrs[key] = val
+		}
+	}
+	return attrs, scanner.Err()
+}
+
+func (s *HelperSecretStore) Get(name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.cache[name]
+	if !ok {
+		attrs, err := s.run("get", name, "")
+		if err != nil {
+			return "", err
+		}
+		value = attrs["value"]
+		s.cache[name] = value
+	}
+	if value == "" {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
+
+func (s *HelperSecretStore) Set(name, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.cache, name)
+	_, err := s.run("store", name, value)
+	return err
+}
+
+func (s *HelperSecretStore) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.cache, name)
+	_, err := s.run("erase", name, "")
+	return err
+}
+
+func (s *HelperSecretStore) Describe() string {
+	return "credential helper " + strings.Join(s.command, " ")
+}
+
+// isNotExist reports whether err means a file or secret does not exist
+func isNotExist(err error) bool {
+	return errors.Is(err, fs.ErrNotExist) || errors.Is(err, ErrSecretNotFound)
+}
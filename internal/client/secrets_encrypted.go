@@ -0,0 +1,226 @@
+package client
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	encryptedStoreVersion = 1
+	encryptedStoreKDF     = "pbkdf2-sha256"
+
+	// defaultKDFIterations follows the OWASP recommendation for PBKDF2-HMAC-SHA256
+	defaultKDFIterations = 600_000
+)
+
+// encryptedEnvelope is the on-disk format of the encrypted store. The secrets are sealed as a
+// JSON object with AES-256-GCM, using a key derived from the passphrase and salt.
+type encryptedEnvelope struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// additionalData binds the key derivation parameters to the ciphertext
+func (e *encryptedEnvelope) additionalData() []byte {
+	return fmt.Appendf(nil, "slim-secrets:v%d:%s:%d", e.Version, e.KDF, e.Iterations)
+}
+
+// EncryptedFileStore keeps secrets in a single file encrypted with a passphrase
+type EncryptedFileStore struct {
+	path       string
+	passphrase string
+	iterations int
+
+	// mu guards the decrypted secrets, loaded on first use
+	mu      sync.Mutex
+	secrets map[string]string
+	salt    []byte
+	key     []byte
+}
+
+// NewEncryptedFileStore creates a store for the encrypted file at path. The file is created
+// on the first Set.
+func NewEncryptedFileStore(path, passphrase string) *EncryptedFileStore {
+	return &EncryptedFileStore{
+		path:       path,
+		passphrase: passphrase,
+		iterations: defaultKDFIterations,
+	}
+}
+
+// deriveKey derives the AES-256 key for a salt
+func (s *EncryptedFileStore) deriveKey(salt []byte, iterations int) ([]byte, error) {
+	key, err := pbkdf2.Key(sha256.New, s.passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return key, nil
+}
+
+// load decrypts the store file; callers must hold mu
+func (s *EncryptedFileStore) load() error {
+	if s.secrets != nil {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.secrets = make(map[string]string)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read secret store: %w", err)
+	}
+	if err := checkPrivate(s.path); err != nil {
+		return fmt.Errorf("refusing to use secret store: %w", err)
+	}
+
+	var envelope encryptedEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return fmt.Errorf("failed to parse secret store %s: %w", s.path, err)
+	}
+	if envelope.Version != encryptedStoreVersion || envelope.KDF != encryptedStoreKDF {
+		return fmt.Errorf("unsupported secret store %s: version %d, kdf %q", s.path, envelope.Version, envelope.KDF)
+	}
+
+	key, err := s.deriveKey(envelope.Salt, envelope.Iterations)
+	if err != nil {
+		return err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	plaintext, err := aead.Open(nil, envelope.Nonce, envelope.Ciphertext, envelope.additionalData())
+	if err != nil {
+		return fmt.Errorf("failed to decrypt secret store %s: wrong passphrase or corrupted file", s.path)
+	}
+
+	secrets := make(map[string]string)
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return fmt.Errorf("failed to parse decrypted secrets: %w", err)
+	}
+
+	s.secrets, s.salt, s.key = secrets, envelope.Salt, key
+	s.iterations = envelope.Iterations
+	return nil
+}
+
+// save encrypts the secrets and atomically replaces the store file; callers must hold mu
+func (s *EncryptedFileStore) save() error {
+	if s.key == nil {
+		s.salt = make([]byte, 16)
+		if _, err := rand.Read(s.salt); err != nil {
+			return fmt.Errorf("failed to generate salt: %w", err)
+		}
+		key, err := s.deriveKey(s.salt, s.iterations)
+		if err != nil {
+			return err
+		}
+		s.key = key
+	}
+
+	aead, err := newAEAD(s.key)
+	if err != nil {
+		return err
+	}
+	plaintext, err := json.Marshal(s.secrets)
+	if err != nil {
+		return fmt.Errorf("failed to marshal secrets: %w", err)
+	}
+
+	envelope := encryptedEnvelope{
+		Version:    encryptedStoreVersion,
+		KDF:        encryptedStoreKDF,
+		Iterations: s.iterations,
+		Salt:       s.salt,
+		Nonce:      make([]byte, aead.NonceSize()),
+	}
+	if _, err := rand.Read(envelope.Nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	envelope.Ciphertext = aead.Seal(nil, envelope.Nonce, plaintext, envelope.additionalData())
+
+	data, err := json.MarshalIndent(envelope, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal secret store: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create secret store directory: %w", err)
+	}
+	tmpFile := s.path + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to write secret store: %w", err)
+	}
+	if err := os.Rename(tmpFile, s.path); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to write secret store: %w", err)
+	}
+	return nil
+}
+
+// newAEAD creates the AES-256-GCM cipher for a key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func (s *EncryptedFileStore) Get(name string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return "", err
+	}
+	value, ok := s.secrets[name]
+	if !ok {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
+
+func (s *EncryptedFileStore) Set(name, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+	s.secrets[name] = value
+	return s.save()
+}
+
+func (s *EncryptedFileStore) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+	if _, ok := s.secrets[name]; !ok {
+		return nil
+	}
+	delete(s.secrets, name)
+	return s.save()
+}
+
+func (s *EncryptedFileStore) Describe() string {
+	return "encrypted file " + s.path
+}
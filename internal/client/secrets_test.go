@@ -0,0 +1,359 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kjanat/slimacademy/internal/config"
+)
+
+// newTestEncryptedStore returns an encrypted store with a cheap key derivation
+func newTestEncryptedStore(path, passphrase string) *EncryptedFileStore {
+	store := NewEncryptedFileStore(path, passphrase)
+	store.iterations = 1000
+	return store
+}
+
+func TestEncryptedFileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slim", "secrets.enc")
+	store := newTestEncryptedStore(path, "correct horse")
+
+	if _, err := store.Get(SecretToken); !errors.Is(err, ErrSecretNotFound) {
+		t.Fatalf("Expected ErrSecretNotFound from an empty store, got %v", err)
+	}
+	if err := store.Set(SecretPassword, "hunter2"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected store file: %v", err)
+	}
+	if strings.Contains(string(data), "hunter2") {
+		t.Error("Expected secrets to be encrypted on disk")
+	}
+	if runtime.GOOS != "windows" {
+		if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+			t.Errorf("Expected store file mode 0600, got %04o", info.Mode().Perm())
+		}
+	}
+
+	t.Run("reopen", func(t *testing.T) {
+		value, err := newTestEncryptedStore(path, "correct horse").Get(SecretPassword)
+		if err != nil || value != "hunter2" {
+			t.Errorf("Expected stored password, got %q, %v", value, err)
+		}
+	})
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		_, err := newTestEncryptedStore(path, "wrong").Get(SecretPassword)
+		if err == nil || !strings.Contains(err.Error(), "wrong passphrase") {
+			t.Errorf("Expected wrong passphrase error, got %v", err)
+		}
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		tampered := filepath.Join(t.TempDir(), "secrets.enc")
+		modified := strings.Replace(string(data), `"iterations": 1000`, `"iterations": 1001`, 1)
+		if err := os.WriteFile(tampered, []byte(modified), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := newTestEncryptedStore(tampered, "correct horse").Get(SecretPassword); err == nil {
+			t.Error("Expected changed parameters to fail decryption")
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := store.Delete(SecretPassword); err != nil {
+			t.Fatalf("Delete() failed: %v", err)
+		}
+		if err := store.Delete(SecretPassword); err != nil {
+			t.Errorf("Expected deleting a missing secret to succeed, got %v", err)
+		}
+		if _, err := newTestEncryptedStore(path, "correct horse").Get(SecretPassword); !errors.Is(err, ErrSecretNotFound) {
+			t.Errorf("Expected deleted secret to be gone, got %v", err)
+		}
+	})
+}
+
+func TestEncryptedFileStore_Permissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not enforced on Windows")
+	}
+
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+	if err := newTestEncryptedStore(path, "pass").Set(SecretUsername, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := newTestEncryptedStore(path, "pass").Get(SecretUsername)
+	if err == nil || !strings.Contains(err.Error(), "too open") {
+		t.Errorf("Expected permission error, got %v", err)
+	}
+}
+
+func TestEnvSecretStore(t *testing.T) {
+	t.Setenv("SLIM_USERNAME", "env@example.com")
+	t.Setenv("SLIM_PASSWORD", "")
+	t.Setenv("USERNAME", "os-account")
+	t.Setenv("PASSWORD", "os-password")
+
+	store := NewEnvSecretStore()
+	if value, err := store.Get(SecretUsername); err != nil || value != "env@example.com" {
+		t.Errorf("Expected username from SLIM_USERNAME, got %q, %v", value, err)
+	}
+	if _, err := store.Get(SecretPassword); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Expected empty SLIM_PASSWORD to be not found, got %v", err)
+	}
+
+	if err := store.Set(SecretToken, "{}"); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := store.Get(SecretToken); value != "{}" {
+		t.Errorf("Expected token kept in memory, got %q", value)
+	}
+	_ = store.Delete(SecretToken)
+	if _, err := store.Get(SecretToken); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Expected token to be deleted, got %v", err)
+	}
+}
+
+// writeHelperScript writes a credential helper that keeps secrets as files in dir
+func writeHelperScript(t *testing.T, dir string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the test helper is a shell script")
+	}
+
+	script := filepath.Join(dir, "helper.sh")
+	content := `#!/bin/sh
+while IFS='=' read -r key value; do
+	[ -z "$key" ] && break
+	eval "in_$key=\$value"
+done
+case "$1" in
+get) [ -f "` + dir + `/$in_name" ] && printf 'value=%s\n' "$(cat "` + dir + `/$in_name")" ;;
+store) printf '%s' "$in_value" > "` + dir + `/$in_name" ;;
+erase) rm -f "` + dir + `/$in_name" ;;
+*) echo "unknown operation $1" >&2; exit 1 ;;
+esac
+exit 0
+`
+	if err := os.WriteFile(script, []byte(content), 0700); err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+func TestHelperSecretStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewHelperSecretStore("sh " + writeHelperScript(t, dir))
+	if err != nil {
+		t.Fatalf("NewHelperSecretStore() failed: %v", err)
+	}
+
+	if _, err := store.Get(SecretToken); !errors.Is(err, ErrSecretNotFound) {
+		t.Fatalf("Expected ErrSecretNotFound, got %v", err)
+	}
+	if err := store.Set(SecretToken, `{"token":"abc"}`); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if value, err := store.Get(SecretToken); err != nil || value != `{"token":"abc"}` {
+		t.Errorf("Expected stored token, got %q, %v", value, err)
+	}
+	if err := store.Delete(SecretToken); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	if _, err := store.Get(SecretToken); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Expected erased token to be gone, got %v", err)
+	}
+
+	if err := store.Set(SecretPassword, "two\nlines"); err == nil {
+		t.Error("Expected values with newlines to be rejected")
+	}
+	if _, err := NewHelperSecretStore("  "); err == nil {
+		t.Error("Expected error for empty helper command")
+	}
+
+	failing, _ := NewHelperSecretStore("false")
+	if _, err := failing.Get(SecretToken); err == nil || !strings.Contains(err.Error(), "credential helper get failed") {
+		t.Errorf("Expected helper failure, got %v", err)
+	}
+}
+
+func TestHelperSecretStore_Cache(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewHelperSecretStore("sh " + writeHelperScript(t, dir))
+	if err != nil {
+		t.Fatalf("NewHelperSecretStore() failed: %v", err)
+	}
+	if err := store.Set(SecretUsername, "alice"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if value, err := store.Get(SecretUsername); err != nil || value != "alice" {
+		t.Fatalf("Expected stored username, got %q, %v", value, err)
+	}
+
+	// Later reads come from the cache without running the helper
+	if err := os.Remove(filepath.Join(dir, SecretUsername)); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := store.Get(SecretUsername); err != nil || value != "alice" {
+		t.Errorf("Expected cached username, got %q, %v", value, err)
+	}
+
+	// Set and Delete clear the cached value
+	if err := store.Set(SecretUsername, "bob"); err != nil {
+		t.Fatalf("Set() failed: %v", err)
+	}
+	if value, err := store.Get(SecretUsername); err != nil || value != "bob" {
+		t.Errorf("Expected updated username, got %q, %v", value, err)
+	}
+	if err := store.Delete(SecretUsername); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	if _, err := store.Get(SecretUsername); !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("Expected deleted username to be gone, got %v", err)
+	}
+}
+
+func TestTokenStore_SecretStore(t *testing.T) {
+	ts := NewSecretTokenStore(NewEnvSecretStore())
+	if _, err := ts.LoadToken(); err == nil || !strings.Contains(err.Error(), "please login first") {
+		t.Errorf("Expected missing token error, got %v", err)
+	}
+
+	info := &TokenInfo{Token: "secret", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := ts.SaveToken(info); err != nil {
+		t.Fatalf("SaveToken() failed: %v", err)
+	}
+	if token, err := ts.GetValidToken(); err != nil || token != "secret" {
+		t.Errorf("Expected token from secret store, got %q, %v", token, err)
+	}
+	if err := ts.ClearToken(); err != nil || ts.IsTokenValid() {
+		t.Errorf("Expected token to be cleared, got %v", err)
+	}
+}
+
+func TestTokenStore_RejectsOpenTokenFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not enforced on Windows")
+	}
+
+	ts := NewTokenStore(t.TempDir())
+	if err := ts.SaveToken(&TokenInfo{Token: "secret", ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(ts.tokenFile, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ts.LoadToken(); err == nil || !strings.Contains(err.Error(), "too open") {
+		t.Errorf("Expected permission error, got %v", err)
+	}
+}
+
+func TestCredentialManager_SecretStore(t *testing.T) {
+	t.Setenv("SLIM_USERNAME", "")
+	t.Setenv("SLIM_PASSWORD", "")
+
+	envFile := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(envFile, []byte("USERNAME=file@example.com\nPASSWORD=filepass\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	store := newTestEncryptedStore(filepath.Join(t.TempDir(), "secrets.enc"), "pass")
+	cm := NewSecretCredentialManager(store, envFile)
+
+	// Credentials missing from the store come from .env
+	creds, err := cm.LoadCredentials()
+	if err != nil || creds.Username != "file@example.com" {
+		t.Fatalf("Expected credentials from .env, got %+v, %v", creds, err)
+	}
+
+	if err := cm.StoreCredentials(&Credentials{Username: "store@example.com", Password: "storepass"}); err != nil {
+		t.Fatalf("StoreCredentials() failed: %v", err)
+	}
+	creds, err = cm.LoadCredentials()
+	if err != nil || creds.Username != "store@example.com" || creds.Password != "storepass" {
+		t.Errorf("Expected credentials from the store, got %+v, %v", creds, err)
+	}
+
+	if err := cm.ForgetCredentials(); err != nil {
+		t.Fatalf("ForgetCredentials() failed: %v", err)
+	}
+	if creds, _ := cm.LoadCredentials(); creds.Username != "file@example.com" {
+		t.Errorf("Expected forgotten credentials to fall back to .env, got %+v", creds)
+	}
+}
+
+func TestNewSecretStore(t *testing.T) {
+	t.Setenv("TEST_SLIM_PASSPHRASE", "")
+
+	if store, err := NewSecretStore(config.DefaultAuthConfig()); err != nil || store != nil {
+		t.Errorf("Expected no secret store for the file backend, got %v, %v", store, err)
+	}
+	if store, err := NewSecretStore(&config.AuthConfig{SecretStore: config.SecretStoreEnv}); err != nil || store == nil {
+		t.Errorf("Expected env store, got %v, %v", store, err)
+	}
+
+	encrypted := &config.AuthConfig{
+		SecretStore:   config.SecretStoreEncrypted,
+		EncryptedFile: filepath.Join(t.TempDir(), "secrets.enc"),
+		PassphraseEnv: "TEST_SLIM_PASSPHRASE",
+	}
+	if _, err := NewSecretStore(encrypted); err == nil || !strings.Contains(err.Error(), "$TEST_SLIM_PASSPHRASE") {
+		t.Errorf("Expected missing passphrase error, got %v", err)
+	}
+	t.Setenv("TEST_SLIM_PASSPHRASE", "pass")
+	if store, err := NewSecretStore(encrypted); err != nil || !strings.Contains(store.Describe(), "secrets.enc") {
+		t.Errorf("Expected encrypted store, got %v, %v", store, err)
+	}
+
+	if _, err := NewSecretStore(&config.AuthConfig{SecretStore: "keychain"}); err == nil {
+		t.Error("Expected error for unknown secret store")
+	}
+}
+
+func TestSlimClient_LoginStoresCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encodeJSON(t, w, LoginResponse{AccessToken: "new-token", TokenType: "Bearer", ExpiresIn: 3600})
+	}))
+	defer server.Close()
+
+	tempDir := t.TempDir()
+	envFile := createCredentialsFile(t, tempDir, "user@example.com", "password123")
+
+	client := NewSlimClient(tempDir)
+	client.SetBaseURL(server.URL)
+	store := newTestEncryptedStore(filepath.Join(tempDir, "secrets.enc"), "pass")
+	client.SetSecretStore(store, tempDir, envFile)
+
+	if err := client.Login(context.Background()); err != nil {
+		t.Fatalf("Login() failed: %v", err)
+	}
+
+	if !strings.Contains(client.SecretsLocation(), "encrypted file") {
+		t.Errorf("Expected encrypted store location, got %q", client.SecretsLocation())
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, ".token_info")); !os.IsNotExist(err) {
+		t.Error("Expected no token file when a secret store is used")
+	}
+	if username, _ := store.Get(SecretUsername); username != "user@example.com" {
+		t.Errorf("Expected credentials to be stored after login, got %q", username)
+	}
+	if info, err := client.GetTokenInfo(); err != nil || info.Token != "new-token" {
+		t.Errorf("Expected token in the secret store, got %+v, %v", info, err)
+	}
+}
@@ -1,6 +1,12 @@
 package client
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/kjanat/slimacademy/internal/models"
+)
 
 // Credentials holds the authentication information
 type Credentials struct {
@@ -18,6 +24,15 @@ type LoginRequest struct {
 	Password     string `json:"password"`
 }
 
+// RefreshRequest is the refresh grant payload sent to the login endpoint
+type RefreshRequest struct {
+	GrantType    string `json:"grant_type"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	DeviceID     string `json:"device_id"`
+	RefreshToken string `json:"refresh_token"`
+}
+
 // LoginResponse represents the authentication response
 type LoginResponse struct {
 	AccessToken  string `json:"access_token"`
@@ -61,26 +76,77 @@ type LibrarySummary struct {
 	LastOpenedAt   *string  `json:"lastOpenedAt"`
 	ReadProgress   *int     `json:"readProgress"`
 	ReadPercentage *float64 `json:"readPercentage"`
+	AvailableDate  *string  `json:"availableDate"`
+	PageCount      *int     `json:"pageCount"`
 	// Add other fields as needed
 }
 
 // BookData holds all the data for a single book
 type BookData struct {
-	ID       string `json:"id"`
-	Title    string `json:"title"`
-	Summary  any    `json:"summary"`
-	Chapters any    `json:"chapters"`
-	Content  any    `json:"content"`
-	Notes    any    `json:"notes"`
+	ID       string           `json:"id"`
+	Title    string           `json:"title"`
+	Summary  *models.Book     `json:"summary"`
+	Chapters []models.Chapter `json:"chapters"`
+	Content  *models.Content  `json:"content"`
+	Notes    []models.Note    `json:"notes"`
+
+	// Raw holds the responses the typed fields were decoded from
+	Raw RawBookData `json:"-"`
+}
+
+// RawBookData holds the API responses of a book exactly as received, so that they can be
+// archived without losing fields the models do not know about
+type RawBookData struct {
+	Summary  json.RawMessage
+	Chapters json.RawMessage
+	Content  json.RawMessage
+	Notes    json.RawMessage
 }
 
 // APIError represents an API error response
 type APIError struct {
-	StatusCode int    `json:"status_code"`
-	Message    string `json:"message"`
-	Response   string `json:"response"`
+	StatusCode int           `json:"status_code"`
+	Message    string        `json:"message"`
+	Response   string        `json:"response"`
+	Attempts   int           `json:"attempts,omitempty"`
+	RetryAfter time.Duration `json:"retry_after,omitempty"`
 }
 
 func (e *APIError) Error() string {
 	return e.Message
 }
+
+// BookFetchError records a library book that could not be fetched
+type BookFetchError struct {
+	ID    string
+	Title string
+	Err   error
+}
+
+func (e *BookFetchError) Error() string {
+	return fmt.Sprintf("book %s (%s): %v", e.ID, e.Title, e.Err)
+}
+
+func (e *BookFetchError) Unwrap() error {
+	return e.Err
+}
+
+// LibraryFetchError is returned together with the successfully fetched books when some books
+// of the library could not be fetched
+type LibraryFetchError struct {
+	Total    int
+	Failures []*BookFetchError
+}
+
+func (e *LibraryFetchError) Error() string {
+	return fmt.Sprintf("failed to fetch %d of %d books", len(e.Failures), e.Total)
+}
+
+// Unwrap returns the individual book failures
+func (e *LibraryFetchError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f
+	}
+	return errs
+}
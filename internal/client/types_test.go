@@ -5,6 +5,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/kjanat/slimacademy/internal/models"
 )
 
 func TestCredentials_JSONMarshalUnmarshal(t *testing.T) {
@@ -429,51 +431,25 @@ func TestLibraryResponse_JSONMarshalUnmarshal(t *testing.T) {
 }
 
 func TestBookData_JSONMarshalUnmarshal(t *testing.T) {
-	// Create complex nested data structures
-	summary := map[string]interface{}{
-		"id":          123,
-		"title":       "Test Book",
-		"description": "A test book for testing",
-	}
-
-	chapters := []interface{}{
-		map[string]interface{}{
-			"id":    1,
-			"title": "Chapter 1",
+	original := &BookData{
+		ID:    "123",
+		Title: "Test Book",
+		Summary: &models.Book{
+			ID:          123,
+			Title:       "Test Book",
+			Description: "A test book for testing",
 		},
-		map[string]interface{}{
-			"id":    2,
-			"title": "Chapter 2",
+		Chapters: []models.Chapter{
+			{ID: 1, Title: "Chapter 1"},
+			{ID: 2, Title: "Chapter 2"},
 		},
-	}
-
-	content := map[string]interface{}{
-		"documentId": "123",
-		"body": map[string]interface{}{
-			"content": []interface{}{
-				map[string]interface{}{
-					"type": "paragraph",
-					"text": "This is test content",
-				},
-			},
+		Content: &models.Content{
+			Document: &models.Document{DocumentID: "123", Title: "Test Book"},
 		},
-	}
-
-	notes := []interface{}{
-		map[string]interface{}{
-			"id":    1,
-			"note":  "Test note",
-			"color": "yellow",
+		Notes: []models.Note{
+			{ID: 1, Content: "Test note"},
 		},
-	}
-
-	original := &BookData{
-		ID:       "123",
-		Title:    "Test Book",
-		Summary:  summary,
-		Chapters: chapters,
-		Content:  content,
-		Notes:    notes,
+		Raw: RawBookData{Summary: json.RawMessage(`{"id":123}`)},
 	}
 
 	// Marshal to JSON
@@ -496,29 +472,23 @@ func TestBookData_JSONMarshalUnmarshal(t *testing.T) {
 		t.Errorf("Expected title %q, got %q", original.Title, unmarshaled.Title)
 	}
 
-	// Check that complex fields are preserved (as maps/slices)
-	if unmarshaled.Summary == nil {
-		t.Error("Expected summary to be preserved")
-	}
-	if unmarshaled.Chapters == nil {
-		t.Error("Expected chapters to be preserved")
+	// Check that the typed parts are preserved
+	if unmarshaled.Summary == nil || unmarshaled.Summary.Title != "Test Book" {
+		t.Errorf("Expected summary title to be preserved, got %+v", unmarshaled.Summary)
 	}
-	if unmarshaled.Content == nil {
-		t.Error("Expected content to be preserved")
+	if len(unmarshaled.Chapters) != 2 || unmarshaled.Chapters[1].Title != "Chapter 2" {
+		t.Errorf("Expected 2 chapters, got %+v", unmarshaled.Chapters)
 	}
-	if unmarshaled.Notes == nil {
-		t.Error("Expected notes to be preserved")
+	if unmarshaled.Content == nil || unmarshaled.Content.Document == nil || unmarshaled.Content.Document.DocumentID != "123" {
+		t.Errorf("Expected content document to be preserved, got %+v", unmarshaled.Content)
 	}
-
-	// Verify nested structure is preserved
-	summaryMap := unmarshaled.Summary.(map[string]interface{}) //nolint:sloppyTypeAssert
-	if title, exists := summaryMap["title"]; !exists || title != "Test Book" {
-		t.Error("Expected summary title to be preserved")
+	if len(unmarshaled.Notes) != 1 || unmarshaled.Notes[0].Content != "Test note" {
+		t.Errorf("Expected notes to be preserved, got %+v", unmarshaled.Notes)
 	}
 
-	chaptersSlice := unmarshaled.Chapters.([]interface{}) //nolint:gocritic
-	if len(chaptersSlice) != 2 {
-		t.Errorf("Expected 2 chapters, got %d", len(chaptersSlice))
+	// Raw responses are for archival and not part of the JSON form
+	if unmarshaled.Raw.Summary != nil {
+		t.Errorf("Expected raw responses to be omitted, got %s", unmarshaled.Raw.Summary)
 	}
 }
 
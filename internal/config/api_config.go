@@ -0,0 +1,50 @@
+package config
+
+// APIConfig holds the SlimAcademy API endpoint and the identity the client presents to it
+type APIConfig struct {
+	// BaseURL is the API server, e.g. a local mock server for integration tests
+	BaseURL  string `json:"baseURL" yaml:"baseURL"`
+	ClientID string `json:"clientID" yaml:"clientID"`
+
+	// DeviceID identifies this installation at login; empty generates one per install and
+	// keeps it next to the token
+	DeviceID string `json:"deviceID" yaml:"deviceID"`
+
+	// Browser headers sent with every request
+	Origin    string `json:"origin" yaml:"origin"`
+	Referer   string `json:"referer" yaml:"referer"`
+	UserAgent string `json:"userAgent" yaml:"userAgent"`
+}
+
+// apiEnvVariables are the environment variables that override APIConfig settings
+var apiEnvVariables = []struct {
+	name  string
+	field func(*APIConfig) *string
+}{
+	{"SLIM_API_URL", func(c *APIConfig) *string { return &c.BaseURL }},
+	{"SLIM_CLIENT_ID", func(c *APIConfig) *string { return &c.ClientID }},
+	{"SLIM_DEVICE_ID", func(c *APIConfig) *string { return &c.DeviceID }},
+	{"SLIM_ORIGIN", func(c *APIConfig) *string { return &c.Origin }},
+	{"SLIM_REFERER", func(c *APIConfig) *string { return &c.Referer }},
+	{"SLIM_USER_AGENT", func(c *APIConfig) *string { return &c.UserAgent }},
+}
+
+// DefaultAPIConfig returns the production API settings, matching the SlimAcademy web app
+func DefaultAPIConfig() *APIConfig {
+	return &APIConfig{
+		BaseURL:   "https://api.slimacademy.nl",
+		ClientID:  "slim_api",
+		Origin:    "https://app.slimacademy.nl",
+		Referer:   "https://app.slimacademy.nl/",
+		UserAgent: "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/137.0.0.0 Safari/537.36",
+	}
+}
+
+// applyEnv overrides settings with the non-empty SLIM_* environment variables
+func (c *APIConfig) applyEnv(getenv func(string) string) {
+	for _, v := range apiEnvVariables {
+		if value := getenv(v.name); value != "" {
+			*v.field(c) = value
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Secret store backends for AuthConfig.SecretStore
+const (
+	SecretStoreFile      = "file"      // Token in the output directory, credentials from .env (default)
+	SecretStoreEncrypted = "encrypted" // Token and credentials in a passphrase encrypted file
+	SecretStoreEnv       = "env"       // Credentials from the environment only, token kept in memory
+	SecretStoreHelper    = "helper"    // External credential helper command
+)
+
+// AuthConfig holds configuration for storing API credentials and tokens
+type AuthConfig struct {
+	// SecretStore selects the backend: file, encrypted, env or helper
+	SecretStore string `json:"secretStore" yaml:"secretStore"`
+
+	// EnvFile is read for credentials that are not in the secret store (not used by env)
+	EnvFile string `json:"envFile" yaml:"envFile"`
+
+	// EncryptedFile is the path of the encrypted store; empty uses the user config directory
+	EncryptedFile string `json:"encryptedFile" yaml:"encryptedFile"`
+	// PassphraseEnv names the environment variable holding the passphrase of the encrypted store
+	PassphraseEnv string `json:"passphraseEnv" yaml:"passphraseEnv"`
+
+	// Helper is the credential helper command, run with get, store or erase appended
+	Helper string `json:"helper" yaml:"helper"`
+}
+
+// DefaultAuthConfig returns the default authentication configuration, which keeps the token
+// next to the fetched books and reads credentials from .env
+func DefaultAuthConfig() *AuthConfig {
+	return &AuthConfig{
+		SecretStore:   SecretStoreFile,
+		EnvFile:       ".env",
+		PassphraseEnv: "SLIM_PASSPHRASE",
+	}
+}
+
+// EncryptedFilePath returns the path of the encrypted store
+func (c *AuthConfig) EncryptedFilePath() (string, error) {
+	if c.EncryptedFile != "" {
+		return c.EncryptedFile, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "slim", "secrets.enc"), nil
+}
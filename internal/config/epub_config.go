@@ -18,11 +18,13 @@ type EPUBConfig struct {
 	CustomMetadata map[string]string `json:"customMetadata" yaml:"customMetadata"`
 
 	// EPUB structure
-	Version       string `json:"version" yaml:"version"`
-	ChapterSplit  bool   `json:"chapterSplit" yaml:"chapterSplit"`
-	ChapterPrefix string `json:"chapterPrefix" yaml:"chapterPrefix"`
-	GenerateTOC   bool   `json:"generateTOC" yaml:"generateTOC"`
-	TOCDepth      int    `json:"tocDepth"`
+	Version         string `json:"version" yaml:"version"`
+	ChapterSplit    bool   `json:"chapterSplit" yaml:"chapterSplit"`
+	SplitLevel      int    `json:"splitLevel" yaml:"splitLevel"`           // Deepest heading level that starts a new chapter file
+	SplitOnChapters bool   `json:"splitOnChapters" yaml:"splitOnChapters"` // Split at book chapter titles instead of heading level
+	ChapterPrefix   string `json:"chapterPrefix" yaml:"chapterPrefix"`
+	GenerateTOC     bool   `json:"generateTOC" yaml:"generateTOC"`
+	TOCDepth        int    `json:"tocDepth"`
 
 	// CSS and styling
 	IncludeCSS bool   `json:"includeCSS"`
@@ -33,9 +35,10 @@ type EPUBConfig struct {
 	HTMLConfig *HTMLConfig `json:"htmlConfig"`
 
 	// Content options
-	IncludeImages    bool `json:"includeImages"`
-	ImageCompression bool `json:"imageCompression"`
-	ImageQuality     int  `json:"imageQuality"`
+	IncludeImages    bool   `json:"includeImages"` // Embed images, downloading those not found in ImageDirectory
+	ImageCompression bool   `json:"imageCompression"`
+	ImageQuality     int    `json:"imageQuality"`   // JPEG quality; PNG images are recompressed losslessly
+	ImageDirectory   string `json:"imageDirectory"` // Local directory searched before downloading images
 
 	// File naming
 	FilenameSanitize  bool `json:"filenameSanitize"`
@@ -58,11 +61,13 @@ func DefaultEPUBConfig() *EPUBConfig {
 		Rights:         "",
 		CustomMetadata: make(map[string]string),
 
-		Version:       "2.0",
-		ChapterSplit:  true,
-		ChapterPrefix: "chapter_",
-		GenerateTOC:   true,
-		TOCDepth:      3,
+		Version:         "2.0",
+		ChapterSplit:    true,
+		SplitLevel:      2,
+		SplitOnChapters: false,
+		ChapterPrefix:   "chapter_",
+		GenerateTOC:     true,
+		TOCDepth:        3,
 
 		IncludeCSS: true,
 		CSSFile:    "styles.css",
@@ -70,9 +75,10 @@ func DefaultEPUBConfig() *EPUBConfig {
 
 		HTMLConfig: DefaultHTMLConfig(),
 
-		IncludeImages:    true,
+		IncludeImages:    false,
 		ImageCompression: false,
 		ImageQuality:     80,
+		ImageDirectory:   "",
 
 		FilenameSanitize:  true,
 		MaxFilenameLength: 100,
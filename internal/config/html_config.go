@@ -36,6 +36,14 @@ type HTMLConfig struct {
 	// Code configuration
 	UseCodeElement bool   `json:"useCodeElement"`
 	CodeClass      string `json:"codeClass"`
+
+	// Notes
+	IncludeNotes bool `json:"includeNotes"` // Render user notes as asides
+
+	// Templates
+	TemplateDir string `json:"templateDir" yaml:"templateDir"` // html/template files replacing the built-in layout, header, toc and footer
+	CSSPath     string `json:"cssPath" yaml:"cssPath"`         // Stylesheet replacing the built-in CSS
+	IncludeTOC  bool   `json:"includeTOC" yaml:"includeTOC"`   // Render a table of contents of the headings
 }
 
 // DefaultHTMLConfig returns a pointer to an HTMLConfig struct populated with standard default values for HTML output formatting and structure.
@@ -63,6 +71,8 @@ func DefaultHTMLConfig() *HTMLConfig {
 
 		UseCodeElement: true,
 		CodeClass:      "",
+
+		IncludeNotes: true,
 	}
 }
 
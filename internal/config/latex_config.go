@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
 )
 
@@ -45,6 +46,9 @@ type LaTeXConfig struct {
 	// Bibliography
 	BibliographyStyle string `json:"bibliographyStyle"`
 	UseBiblatex       bool   `json:"useBiblatex"`
+
+	// Notes
+	IncludeNotes bool `json:"includeNotes"` // Render user notes as footnotes
 }
 
 // DefaultLaTeXConfig returns a LaTeXConfig instance initialized with standard default values for common LaTeX document generation scenarios.
@@ -60,7 +64,7 @@ func DefaultLaTeXConfig() *LaTeXConfig {
 
 		DocumentClass:   "article",
 		DocumentOptions: []string{"11pt", "a4paper"},
-		Packages:        []string{"inputenc", "fontenc", "geometry", "ulem", "soul", "amsmath", "amsfonts", "amssymb", "hyperref"},
+		Packages:        []string{"inputenc", "fontenc", "geometry", "ulem", "soul", "amsmath", "amsfonts", "amssymb", "multirow", "hyperref"},
 
 		UseUTF8:         true,
 		UseGeometry:     true,
@@ -79,6 +83,8 @@ func DefaultLaTeXConfig() *LaTeXConfig {
 
 		BibliographyStyle: "plain",
 		UseBiblatex:       false,
+
+		IncludeNotes: true,
 	}
 }
 
@@ -161,6 +167,11 @@ func (c *LaTeXConfig) GetDocumentPreamble() string {
 		}
 	}
 
+	// Tables use \toprule/\midrule/\bottomrule when booktabs is enabled
+	if c.UseBooktabs && !slices.Contains(c.Packages, "booktabs") {
+		preamble += "\\usepackage{booktabs}\n"
+	}
+
 	return preamble
 }
 
@@ -19,6 +19,12 @@ type Config struct {
 	HTML     *HTMLConfig     `json:"html,omitempty" yaml:"html,omitempty"`
 	LaTeX    *LaTeXConfig    `json:"latex,omitempty" yaml:"latex,omitempty"`
 	EPUB     *EPUBConfig     `json:"epub,omitempty" yaml:"epub,omitempty"`
+	Auth     *AuthConfig     `json:"auth,omitempty" yaml:"auth,omitempty"`
+	API      *APIConfig      `json:"api,omitempty" yaml:"api,omitempty"`
+	Site     *SiteConfig     `json:"site,omitempty" yaml:"site,omitempty"`
+
+	// Transforms are applied to the HAST tree before it is rendered to HTML
+	Transforms *TransformConfig `json:"transforms,omitempty" yaml:"transforms,omitempty"`
 }
 
 // DefaultConfig returns a Config with all default format configurations
@@ -28,6 +34,11 @@ func DefaultConfig() *Config {
 		HTML:     DefaultHTMLConfig(),
 		LaTeX:    DefaultLaTeXConfig(),
 		EPUB:     DefaultEPUBConfig(),
+		Auth:     DefaultAuthConfig(),
+		API:      DefaultAPIConfig(),
+		Site:     DefaultSiteConfig(),
+
+		Transforms: DefaultTransformConfig(),
 	}
 }
 
@@ -44,10 +55,13 @@ func NewLoader() *Loader {
 }
 
 // LoadConfig loads configuration from a file, supporting JSON and YAML formats.
-// The format is determined by the file extension (.json, .yaml, .yml).
+// The format is determined by the file extension (.json, .yaml, .yml). API settings can be
+// overridden with SLIM_* environment variables, also without a file.
 func (l *Loader) LoadConfig(filepath string) (*Config, error) {
 	if filepath == "" {
-		return DefaultConfig(), nil
+		config := DefaultConfig()
+		config.API.applyEnv(os.Getenv)
+		return config, nil
 	}
 
 	data, err := os.ReadFile(filepath)
@@ -55,8 +69,9 @@ func (l *Loader) LoadConfig(filepath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file %s: %w", filepath, err)
 	}
 
-	// Parse into temporary config first
-	var loadedConfig Config
+	// Parse into temporary config first. Auth and API settings are merged field by field, so
+	// that e.g. selecting a secret store keeps the defaults of its other settings.
+	loadedConfig := Config{Auth: DefaultAuthConfig(), API: DefaultAPIConfig()}
 
 	// Determine format by extension
 	ext := strings.ToLower(filepath[strings.LastIndex(filepath, "."):])
@@ -87,6 +102,19 @@ func (l *Loader) LoadConfig(filepath string) (*Config, error) {
 	if loadedConfig.EPUB != nil {
 		config.EPUB = loadedConfig.EPUB
 	}
+	if loadedConfig.Auth != nil {
+		config.Auth = loadedConfig.Auth
+	}
+	if loadedConfig.API != nil {
+		config.API = loadedConfig.API
+	}
+	if loadedConfig.Site != nil {
+		config.Site = loadedConfig.Site
+	}
+	if loadedConfig.Transforms != nil {
+		config.Transforms = loadedConfig.Transforms
+	}
+	config.API.applyEnv(os.Getenv)
 
 	// TODO: Enable validation once validator logic is fixed for defaults
 	// Validate the loaded configuration
@@ -134,6 +162,38 @@ func (l *Loader) ValidateConfig(config *Config) error {
 		}
 	}
 
+	if config.Auth != nil {
+		if result := l.validator.ValidateAuthConfig(config.Auth); !result.Valid {
+			for _, err := range result.Errors {
+				errors = append(errors, fmt.Sprintf("auth: %s", err.Error()))
+			}
+		}
+	}
+
+	if config.API != nil {
+		if result := l.validator.ValidateAPIConfig(config.API); !result.Valid {
+			for _, err := range result.Errors {
+				errors = append(errors, fmt.Sprintf("api: %s", err.Error()))
+			}
+		}
+	}
+
+	if config.Site != nil {
+		if result := l.validator.ValidateSiteConfig(config.Site); !result.Valid {
+			for _, err := range result.Errors {
+				errors = append(errors, fmt.Sprintf("site: %s", err.Error()))
+			}
+		}
+	}
+
+	if config.Transforms != nil {
+		if result := l.validator.ValidateTransformConfig(config.Transforms); !result.Valid {
+			for _, err := range result.Errors {
+				errors = append(errors, fmt.Sprintf("transforms: %s", err.Error()))
+			}
+		}
+	}
+
 	if len(errors) > 0 {
 		return fmt.Errorf("validation errors:\n  - %s", strings.Join(errors, "\n  - "))
 	}
@@ -152,6 +212,8 @@ func (c *Config) GetFormatConfig(format string) interface{} {
 		return c.LaTeX
 	case "epub":
 		return c.EPUB
+	case "site":
+		return c.Site
 	default:
 		return nil
 	}
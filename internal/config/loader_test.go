@@ -134,3 +134,154 @@ func TestConfig_GetFormatConfig(t *testing.T) {
 		t.Error("GetFormatConfig should return nil for invalid format")
 	}
 }
+
+func TestLoader_LoadConfig_Auth(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "auth.json")
+	if err := os.WriteFile(configPath, []byte(`{"auth": {"secretStore": "encrypted"}}`), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	loader := NewLoader()
+	config, err := loader.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig should succeed: %v", err)
+	}
+
+	// Settings that are not given keep their defaults
+	if config.Auth.SecretStore != SecretStoreEncrypted || config.Auth.PassphraseEnv != "SLIM_PASSPHRASE" || config.Auth.EnvFile != ".env" {
+		t.Errorf("Expected auth settings merged with defaults, got %+v", config.Auth)
+	}
+
+	validator := NewValidator()
+	if result := validator.ValidateAuthConfig(config.Auth); !result.Valid {
+		t.Errorf("Expected valid auth config: %+v", result.Errors)
+	}
+	if result := validator.ValidateAuthConfig(&AuthConfig{SecretStore: SecretStoreHelper}); result.Valid {
+		t.Error("Expected helper store without command to be invalid")
+	}
+	if result := validator.ValidateAuthConfig(&AuthConfig{SecretStore: "keychain"}); result.Valid {
+		t.Error("Expected unknown secret store to be invalid")
+	}
+}
+
+func TestLoader_LoadConfig_API(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "api.yaml")
+	content := "api:\n  baseURL: http://localhost:8080\n  deviceID: staging-device\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+	t.Setenv("SLIM_USER_AGENT", "slim-tests")
+
+	loader := NewLoader()
+	config, err := loader.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig should succeed: %v", err)
+	}
+
+	api := config.API
+	if api.BaseURL != "http://localhost:8080" || api.DeviceID != "staging-device" {
+		t.Errorf("Expected API settings from file, got %+v", api)
+	}
+	if api.ClientID != "slim_api" || api.Origin != "https://app.slimacademy.nl" {
+		t.Errorf("Expected other API settings to keep their defaults, got %+v", api)
+	}
+	if api.UserAgent != "slim-tests" {
+		t.Errorf("Expected SLIM_USER_AGENT to override the user agent, got %q", api.UserAgent)
+	}
+
+	// Environment overrides also apply without a config file
+	t.Setenv("SLIM_API_URL", "http://127.0.0.1:9000")
+	config, err = loader.LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig should succeed: %v", err)
+	}
+	if config.API.BaseURL != "http://127.0.0.1:9000" {
+		t.Errorf("Expected SLIM_API_URL to override the base URL, got %q", config.API.BaseURL)
+	}
+
+	validator := NewValidator()
+	if result := validator.ValidateAPIConfig(config.API); !result.Valid {
+		t.Errorf("Expected valid API config: %+v", result.Errors)
+	}
+	if result := validator.ValidateAPIConfig(&APIConfig{BaseURL: "localhost:8080", ClientID: "slim_api"}); result.Valid {
+		t.Error("Expected base URL without scheme to be invalid")
+	}
+	if result := validator.ValidateAPIConfig(&APIConfig{BaseURL: "https://api.example.com"}); result.Valid {
+		t.Error("Expected empty client ID to be invalid")
+	}
+}
+
+func TestLoader_LoadConfig_Transforms(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "transforms.yaml")
+	content := `transforms:
+  passes:
+    - name: external-links
+      options:
+        target: _top
+    - name: wrap-tables
+`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	config, err := NewLoader().LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig should succeed: %v", err)
+	}
+
+	passes := config.Transforms.Passes
+	if len(passes) != 2 || passes[0].Name != TransformExternalLinks || passes[0].Options["target"] != "_top" || passes[1].Name != TransformWrapTables {
+		t.Errorf("Expected passes from file, got %+v", passes)
+	}
+	if len(DefaultConfig().Transforms.Passes) != 0 {
+		t.Error("Expected no transform passes by default")
+	}
+
+	validator := NewValidator()
+	if result := validator.ValidateTransformConfig(config.Transforms); !result.Valid {
+		t.Errorf("Expected valid transform config: %+v", result.Errors)
+	}
+	invalid := &TransformConfig{Passes: []TransformPassConfig{{Name: "minify"}, {Name: TransformAddClass, Options: map[string]string{"tag": "table"}}}}
+	if result := validator.ValidateTransformConfig(invalid); len(result.Errors) != 2 {
+		t.Errorf("Expected unknown pass and missing class to be invalid, got %+v", result.Errors)
+	}
+}
+
+func TestValidator_HTMLTemplates(t *testing.T) {
+	dir := t.TempDir()
+	cssPath := filepath.Join(dir, "style.css")
+	if err := os.WriteFile(cssPath, []byte("body {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := DefaultHTMLConfig()
+	cfg.TemplateDir = dir
+	cfg.CSSPath = cssPath
+	if result := NewValidator().ValidateHTMLConfig(cfg); !result.Valid {
+		t.Errorf("Expected valid template settings: %+v", result.Errors)
+	}
+
+	cfg.TemplateDir = cssPath
+	cfg.CSSPath = filepath.Join(dir, "missing.css")
+	if result := NewValidator().ValidateHTMLConfig(cfg); len(result.Errors) != 2 {
+		t.Errorf("Expected file as template directory and missing stylesheet to be invalid, got %+v", result.Errors)
+	}
+}
+
+func TestValidator_SiteConfig(t *testing.T) {
+	validator := NewValidator()
+
+	if result := validator.ValidateSiteConfig(DefaultSiteConfig()); !result.Valid {
+		t.Errorf("Expected the default site config to be valid: %v", result.Errors)
+	}
+
+	invalid := &SiteConfig{SplitLevel: 7, SearchTextLimit: -1}
+	if result := validator.ValidateSiteConfig(invalid); result.Valid || len(result.Errors) != 2 {
+		t.Errorf("Expected 2 errors, got %v", result.Errors)
+	}
+
+	// The split level is ignored when splitting at chapter titles
+	if result := validator.ValidateSiteConfig(&SiteConfig{SplitOnChapters: true}); !result.Valid {
+		t.Errorf("Expected a valid config, got %v", result.Errors)
+	}
+}
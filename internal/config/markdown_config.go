@@ -25,6 +25,9 @@ type MarkdownConfig struct {
 	// List formatting
 	UnorderedListMarker string `json:"unorderedListMarker" yaml:"unorderedListMarker"`
 	OrderedListMarker   string `json:"orderedListMarker" yaml:"orderedListMarker"`
+
+	// Notes
+	IncludeNotes bool `json:"includeNotes" yaml:"includeNotes"` // Render user notes as footnotes
 }
 
 // DefaultMarkdownConfig returns a MarkdownConfig instance initialized with standard markdown and HTML formatting markers for various text styles.
@@ -47,6 +50,9 @@ func DefaultMarkdownConfig() *MarkdownConfig {
 		// List formatting
 		UnorderedListMarker: "-",
 		OrderedListMarker:   "1.",
+
+		// Notes
+		IncludeNotes: true,
 	}
 }
 
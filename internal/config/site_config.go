@@ -0,0 +1,19 @@
+package config
+
+// SiteConfig holds configuration for multi-page static site output. Pages use the language
+// and stylesheet of the HTML configuration.
+type SiteConfig struct {
+	SplitLevel      int  `json:"splitLevel" yaml:"splitLevel"`           // Deepest heading level that starts a new page
+	SplitOnChapters bool `json:"splitOnChapters" yaml:"splitOnChapters"` // Split at book chapter titles instead of heading level
+	SearchIndex     bool `json:"searchIndex" yaml:"searchIndex"`         // Write search-index.json and a search box
+	SearchTextLimit int  `json:"searchTextLimit" yaml:"searchTextLimit"` // Characters of page text in the search index; 0 for all
+}
+
+// DefaultSiteConfig returns a configuration that starts a page at every level 1 and 2
+// heading and writes a search index
+func DefaultSiteConfig() *SiteConfig {
+	return &SiteConfig{
+		SplitLevel:  2,
+		SearchIndex: true,
+	}
+}
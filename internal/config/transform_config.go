@@ -0,0 +1,47 @@
+package config
+
+// Names of the built-in HAST transform passes
+const (
+	// TransformRenumberHeadings closes gaps between heading levels and shifts the top level
+	// to the "start" option (default 1). With "numbered: true" headings get section numbers.
+	TransformRenumberHeadings = "renumber-headings"
+	// TransformExternalLinks sets the "rel" (default "noopener noreferrer") and "target"
+	// (default "_blank") attributes of links to absolute http(s) URLs
+	TransformExternalLinks = "external-links"
+	// TransformLazyImages sets the "loading" (default "lazy") and "decoding" (default
+	// "async") attributes of images
+	TransformLazyImages = "lazy-images"
+	// TransformWrapTables wraps tables in a horizontally scrolling div with the "class"
+	// option (default "table-wrapper")
+	TransformWrapTables = "wrap-tables"
+	// TransformAddClass adds the "class" option to every element named by the "tag" option
+	TransformAddClass = "add-class"
+)
+
+// TransformPasses lists the built-in transform passes
+var TransformPasses = []string{
+	TransformRenumberHeadings,
+	TransformExternalLinks,
+	TransformLazyImages,
+	TransformWrapTables,
+	TransformAddClass,
+}
+
+// TransformConfig lists the HAST transform passes applied to a document before it is
+// rendered to HTML
+type TransformConfig struct {
+	// Passes run in order; a pass may be listed more than once
+	Passes []TransformPassConfig `json:"passes" yaml:"passes"`
+}
+
+// TransformPassConfig selects a transform pass and its options
+type TransformPassConfig struct {
+	Name    string            `json:"name" yaml:"name"`
+	Options map[string]string `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// DefaultTransformConfig returns a configuration without transform passes, which leaves
+// documents as converted
+func DefaultTransformConfig() *TransformConfig {
+	return &TransformConfig{}
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"fmt"
+	"net/url"
+	"os"
 	"regexp"
 	"slices"
 	"strings"
@@ -100,6 +102,66 @@ func (v *Validator) ValidateHTMLConfig(cfg *HTMLConfig) ValidationResult {
 		}
 	}
 
+	if cfg.TemplateDir != "" {
+		if info, err := os.Stat(cfg.TemplateDir); err != nil || !info.IsDir() {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "TemplateDir",
+				Value:   cfg.TemplateDir,
+				Issue:   "template directory does not exist",
+				Suggest: "point templateDir at a directory with layout.html, header.html, toc.html or footer.html",
+			})
+			result.Valid = false
+		}
+	}
+
+	if cfg.CSSPath != "" {
+		if info, err := os.Stat(cfg.CSSPath); err != nil || info.IsDir() {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   "CSSPath",
+				Value:   cfg.CSSPath,
+				Issue:   "stylesheet does not exist",
+				Suggest: "point cssPath at a CSS file",
+			})
+			result.Valid = false
+		}
+	}
+
+	return result
+}
+
+// ValidateAuthConfig validates authentication configuration
+func (v *Validator) ValidateAuthConfig(cfg *AuthConfig) ValidationResult {
+	result := ValidationResult{Valid: true}
+
+	stores := []string{SecretStoreFile, SecretStoreEncrypted, SecretStoreEnv, SecretStoreHelper}
+	if !slices.Contains(stores, cfg.SecretStore) {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "SecretStore",
+			Value:   cfg.SecretStore,
+			Issue:   "unknown secret store",
+			Suggest: "use one of " + strings.Join(stores, ", "),
+		})
+		result.Valid = false
+	}
+
+	if cfg.SecretStore == SecretStoreEncrypted && cfg.PassphraseEnv == "" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "PassphraseEnv",
+			Issue:   "the encrypted secret store needs a passphrase variable",
+			Suggest: "set passphraseEnv, e.g. SLIM_PASSPHRASE",
+		})
+		result.Valid = false
+	}
+
+	if cfg.SecretStore == SecretStoreHelper && strings.TrimSpace(cfg.Helper) == "" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "Helper",
+			Issue:   "the helper secret store needs a command",
+			Suggest: "set helper to the credential helper command",
+		})
+		result.Valid = false
+	}
+
 	return result
 }
 
@@ -167,6 +229,27 @@ func (v *Validator) ValidateEPUBConfig(cfg *EPUBConfig) ValidationResult {
 		})
 	}
 
+	// Validate image quality
+	if cfg.ImageCompression && (cfg.ImageQuality < 1 || cfg.ImageQuality > 100) {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "ImageQuality",
+			Value:   fmt.Sprintf("%d", cfg.ImageQuality),
+			Issue:   "image quality out of range",
+			Suggest: "use a value between 1 and 100",
+		})
+		result.Valid = false
+	}
+
+	if cfg.ChapterSplit && !cfg.SplitOnChapters && (cfg.SplitLevel < 1 || cfg.SplitLevel > 6) {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "SplitLevel",
+			Value:   fmt.Sprintf("%d", cfg.SplitLevel),
+			Issue:   "split level out of range",
+			Suggest: "use a heading level between 1 and 6",
+		})
+		result.Valid = false
+	}
+
 	return result
 }
 
@@ -392,3 +475,85 @@ func (v *Validator) validateUUID(uuid string) *ValidationError {
 
 	return nil
 }
+
+// ValidateAPIConfig validates the API endpoint configuration
+func (v *Validator) ValidateAPIConfig(cfg *APIConfig) ValidationResult {
+	result := ValidationResult{Valid: true}
+
+	if u, err := url.Parse(cfg.BaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "BaseURL",
+			Value:   cfg.BaseURL,
+			Issue:   "must be an absolute http or https URL",
+			Suggest: "use e.g. https://api.slimacademy.nl or http://localhost:8080",
+		})
+		result.Valid = false
+	}
+
+	if strings.TrimSpace(cfg.ClientID) == "" {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "ClientID",
+			Issue:   "client ID cannot be empty",
+			Suggest: "use slim_api",
+		})
+		result.Valid = false
+	}
+
+	return result
+}
+
+// ValidateTransformConfig validates the HAST transform passes
+func (v *Validator) ValidateTransformConfig(cfg *TransformConfig) ValidationResult {
+	result := ValidationResult{Valid: true}
+
+	for i, pass := range cfg.Passes {
+		if !slices.Contains(TransformPasses, pass.Name) {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("Passes[%d].Name", i),
+				Value:   pass.Name,
+				Issue:   "unknown transform pass",
+				Suggest: "use one of " + strings.Join(TransformPasses, ", "),
+			})
+			result.Valid = false
+			continue
+		}
+
+		if pass.Name == TransformAddClass && (pass.Options["tag"] == "" || pass.Options["class"] == "") {
+			result.Errors = append(result.Errors, ValidationError{
+				Field:   fmt.Sprintf("Passes[%d].Options", i),
+				Issue:   "add-class needs the tag and class options",
+				Suggest: "set e.g. tag: table and class: striped",
+			})
+			result.Valid = false
+		}
+	}
+
+	return result
+}
+
+// ValidateSiteConfig validates static site configuration
+func (v *Validator) ValidateSiteConfig(cfg *SiteConfig) ValidationResult {
+	result := ValidationResult{Valid: true}
+
+	if !cfg.SplitOnChapters && (cfg.SplitLevel < 1 || cfg.SplitLevel > 6) {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "SplitLevel",
+			Value:   fmt.Sprintf("%d", cfg.SplitLevel),
+			Issue:   "split level out of range",
+			Suggest: "use a heading level between 1 and 6",
+		})
+		result.Valid = false
+	}
+
+	if cfg.SearchTextLimit < 0 {
+		result.Errors = append(result.Errors, ValidationError{
+			Field:   "SearchTextLimit",
+			Value:   fmt.Sprintf("%d", cfg.SearchTextLimit),
+			Issue:   "search text limit cannot be negative",
+			Suggest: "use 0 to index the whole page text",
+		})
+		result.Valid = false
+	}
+
+	return result
+}
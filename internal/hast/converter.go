@@ -2,6 +2,9 @@ package hast
 
 import (
 	"fmt"
+	"iter"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/kjanat/slimacademy/internal/streaming"
@@ -17,6 +20,10 @@ type EventToHASTConverter struct {
 	current Node
 	// Options for conversion
 	options ConversionOptions
+	// Notes referenced so far, used to number them
+	noteCount int
+	// Asides of the notes referenced in the open block
+	pendingNotes []*Element
 }
 
 // ConversionOptions provides configuration for the conversion process
@@ -53,22 +60,45 @@ func NewEventToHASTConverter(options ConversionOptions) *EventToHASTConverter {
 
 // Convert processes a stream of events and returns a HAST tree
 func (c *EventToHASTConverter) Convert(events []streaming.Event) (*Root, error) {
-	c.reset()
+	return c.ConvertSeq(slices.Values(events))
+}
+
+// ConvertSeq builds a HAST tree from an event sequence, one event at a time
+func (c *EventToHASTConverter) ConvertSeq(events iter.Seq[streaming.Event]) (*Root, error) {
+	c.Reset()
 
-	for _, event := range events {
-		if err := c.processEvent(event); err != nil {
-			return nil, fmt.Errorf("error processing event %v: %w", event.Kind, err)
+	for event := range events {
+		if err := c.Add(event); err != nil {
+			return nil, err
 		}
 	}
 
 	return c.root, nil
 }
 
-// reset prepares the converter for a new document
-func (c *EventToHASTConverter) reset() {
+// Add appends a single event to the tree being built
+func (c *EventToHASTConverter) Add(event streaming.Event) error {
+	if c.current == nil {
+		c.current = c.root
+	}
+	if err := c.processEvent(event); err != nil {
+		return fmt.Errorf("error processing event %v: %w", event.Kind, err)
+	}
+	return nil
+}
+
+// Tree returns the tree built so far; it is complete once EndDoc has been added
+func (c *EventToHASTConverter) Tree() *Root {
+	return c.root
+}
+
+// Reset discards the tree so the converter can build a new document
+func (c *EventToHASTConverter) Reset() {
 	c.elementStack = c.elementStack[:0]
 	c.root = NewRoot()
 	c.current = c.root
+	c.noteCount = 0
+	c.pendingNotes = nil
 }
 
 // processEvent handles a single streaming event
@@ -114,6 +144,25 @@ func (c *EventToHASTConverter) processEvent(event streaming.Event) error {
 		return c.handleText(event)
 	case streaming.Image:
 		return c.handleImage(event)
+	case streaming.PageBreak:
+		return c.handlePageBreak(event)
+	case streaming.HorizontalRule:
+		return c.handleHorizontalRule(event)
+	case streaming.SectionBreak:
+		return c.handleSectionBreak(event)
+	case streaming.AutoText:
+		// Page numbers and counts have no meaning outside paged media
+		return nil
+	case streaming.StartHeader:
+		return c.handleStartRunningBlock("header", "running-header")
+	case streaming.StartFooter:
+		return c.handleStartRunningBlock("footer", "running-footer")
+	case streaming.EndHeader, streaming.EndFooter:
+		return c.handleEndRunningBlock(event)
+	case streaming.Note:
+		return c.handleNote(event)
+	case streaming.TableOfContents:
+		return c.handleTableOfContents(event)
 	default:
 		return fmt.Errorf("unknown event kind: %v", event.Kind)
 	}
@@ -173,6 +222,7 @@ func (c *EventToHASTConverter) handleStartParagraph(event streaming.Event) error
 
 func (c *EventToHASTConverter) handleEndParagraph(event streaming.Event) error {
 	c.popElement()
+	c.addPendingNotes()
 	return nil
 }
 
@@ -214,16 +264,42 @@ func (c *EventToHASTConverter) handleEndHeading(event streaming.Event) error {
 	}
 
 	c.popElement()
+	c.addPendingNotes()
 	return nil
 }
 
 // List handlers
 
 func (c *EventToHASTConverter) handleStartList(event streaming.Event) error {
-	// For now, assume unordered lists (could be enhanced with list type detection)
-	ul := NewElement("ul")
-	c.addToCurrentParent(ul)
-	c.pushElement(ul)
+	if !event.ListOrdered && !event.ListGlyph.Ordered() {
+		ul := NewElement("ul")
+		switch event.ListGlyph {
+		case streaming.GlyphCircle:
+			ul.SetProperty("style", "list-style-type: circle;")
+		case streaming.GlyphSquare:
+			ul.SetProperty("style", "list-style-type: square;")
+		}
+		c.addToCurrentParent(ul)
+		c.pushElement(ul)
+		return nil
+	}
+
+	ol := NewElement("ol")
+	switch event.ListGlyph {
+	case streaming.GlyphLowerAlpha:
+		ol.SetProperty("type", "a")
+	case streaming.GlyphUpperAlpha:
+		ol.SetProperty("type", "A")
+	case streaming.GlyphLowerRoman:
+		ol.SetProperty("type", "i")
+	case streaming.GlyphUpperRoman:
+		ol.SetProperty("type", "I")
+	}
+	if event.ListStart > 1 {
+		ol.SetProperty("start", event.ListStart)
+	}
+	c.addToCurrentParent(ol)
+	c.pushElement(ol)
 	return nil
 }
 
@@ -240,6 +316,7 @@ func (c *EventToHASTConverter) handleStartListItem(event streaming.Event) error
 }
 
 func (c *EventToHASTConverter) handleEndListItem(event streaming.Event) error {
+	c.addPendingNotes()
 	c.popElement()
 	return nil
 }
@@ -252,41 +329,92 @@ func (c *EventToHASTConverter) handleStartTable(event streaming.Event) error {
 		table.SetProperty("style", "border-collapse: collapse; width: 100%; margin: 20px 0;")
 	}
 	c.addToCurrentParent(table)
+
+	if len(event.TableColumnWidths) > 0 {
+		colgroup := NewElement("colgroup")
+		for _, width := range event.TableColumnWidths {
+			col := NewElement("col")
+			if width > 0 {
+				col.SetProperty("style", fmt.Sprintf("width: %spt;", strconv.FormatFloat(width, 'f', -1, 64)))
+			}
+			AddChild(colgroup, col)
+		}
+		AddChild(table, colgroup)
+	}
+
 	c.pushElement(table)
 	return nil
 }
 
 func (c *EventToHASTConverter) handleEndTable(event streaming.Event) error {
+	if current := c.getCurrentElement(); current != nil && isTableSection(current.TagName) {
+		c.popElement()
+	}
 	c.popElement()
 	return nil
 }
 
 func (c *EventToHASTConverter) handleStartTableRow(event streaming.Event) error {
+	c.openTableSection(event.TableHeader)
+
 	tr := NewElement("tr")
 	c.addToCurrentParent(tr)
 	c.pushElement(tr)
 	return nil
 }
 
+// openTableSection makes sure the row is added to a thead or tbody element.
+// Header rows after the body has started stay in the body.
+func (c *EventToHASTConverter) openTableSection(header bool) {
+	current := c.getCurrentElement()
+	if current == nil {
+		return
+	}
+
+	switch current.TagName {
+	case "table":
+		section := "tbody"
+		if header {
+			section = "thead"
+		}
+		tableSection := NewElement(section)
+		AddChild(current, tableSection)
+		c.pushElement(tableSection)
+	case "thead":
+		if header {
+			return
+		}
+		c.popElement()
+		tbody := NewElement("tbody")
+		c.addToCurrentParent(tbody)
+		c.pushElement(tbody)
+	}
+}
+
+// isTableSection reports whether tagName groups table rows
+func isTableSection(tagName string) bool {
+	return tagName == "thead" || tagName == "tbody"
+}
+
 func (c *EventToHASTConverter) handleEndTableRow(event streaming.Event) error {
 	c.popElement()
 	return nil
 }
 
 func (c *EventToHASTConverter) handleStartTableCell(event streaming.Event) error {
-	// Determine if this is a header cell (could be enhanced with better detection)
+	// Rows in thead hold header cells; the stack is table > section > tr
 	tagName := "td"
-
-	// Simple heuristic: if this is the first row in the table, use th
-	if parent := c.getCurrentElement(); parent != nil && parent.TagName == "tr" {
-		if grandparent := c.getParentElement(); grandparent != nil && grandparent.TagName == "table" {
-			if len(grandparent.Children) == 1 { // First row
-				tagName = "th"
-			}
-		}
+	if section := c.getParentElement(); section != nil && section.TagName == "thead" {
+		tagName = "th"
 	}
 
 	cell := NewElement(tagName)
+	if event.CellColSpan > 1 {
+		cell.SetProperty("colspan", event.CellColSpan)
+	}
+	if event.CellRowSpan > 1 {
+		cell.SetProperty("rowspan", event.CellRowSpan)
+	}
 
 	if c.options.IncludeStyles {
 		style := "border: 1px solid #ddd; padding: 8px;"
@@ -434,6 +562,92 @@ func (c *EventToHASTConverter) handleImage(event streaming.Event) error {
 	return nil
 }
 
+// Break handlers
+
+func (c *EventToHASTConverter) handlePageBreak(event streaming.Event) error {
+	div := NewElement("div")
+	div.SetProperty("class", "page-break")
+	c.addToCurrentParent(div)
+	return nil
+}
+
+func (c *EventToHASTConverter) handleHorizontalRule(event streaming.Event) error {
+	c.addToCurrentParent(NewElement("hr"))
+	return nil
+}
+
+func (c *EventToHASTConverter) handleSectionBreak(event streaming.Event) error {
+	if event.SectionNewPage {
+		return c.handlePageBreak(event)
+	}
+	return nil
+}
+
+// Note handlers
+
+func (c *EventToHASTConverter) handleNote(event streaming.Event) error {
+	c.noteCount++
+	number := strconv.Itoa(c.noteCount)
+
+	ref := NewElement("a")
+	ref.SetProperty("href", "#note-"+number)
+	AddChild(ref, NewText(number))
+	sup := NewElement("sup")
+	sup.SetProperty("class", "note-ref")
+	sup.SetProperty("id", "note-ref-"+number)
+	AddChild(sup, ref)
+	c.addToCurrentParent(sup)
+
+	back := NewElement("a")
+	back.SetProperty("href", "#note-ref-"+number)
+	AddChild(back, NewText(number))
+	aside := NewElement("aside")
+	aside.SetProperty("class", "note")
+	aside.SetProperty("id", "note-"+number)
+	AddChild(aside, back)
+	AddChild(aside, NewText(" "+event.NoteText))
+	c.pendingNotes = append(c.pendingNotes, aside)
+	return nil
+}
+
+// handleTableOfContents adds the table of contents as a navigation list of links to the headings
+func (c *EventToHASTConverter) handleTableOfContents(event streaming.Event) error {
+	nav := NewElement("nav")
+	nav.SetProperty("class", "table-of-contents")
+	c.addToCurrentParent(nav)
+	c.pushElement(nav)
+	for _, listEvent := range streaming.TOCListEvents(event) {
+		if err := c.processEvent(listEvent); err != nil {
+			return err
+		}
+	}
+	c.popElement()
+	return nil
+}
+
+// addPendingNotes adds the asides of the notes referenced in the block just closed
+func (c *EventToHASTConverter) addPendingNotes() {
+	for _, aside := range c.pendingNotes {
+		c.addToCurrentParent(aside)
+	}
+	c.pendingNotes = c.pendingNotes[:0]
+}
+
+// Running header and footer handlers
+
+func (c *EventToHASTConverter) handleStartRunningBlock(tagName, class string) error {
+	element := NewElement(tagName)
+	element.SetProperty("class", class)
+	c.addToCurrentParent(element)
+	c.pushElement(element)
+	return nil
+}
+
+func (c *EventToHASTConverter) handleEndRunningBlock(event streaming.Event) error {
+	c.popElement()
+	return nil
+}
+
 // Helper methods for element stack management
 
 func (c *EventToHASTConverter) pushElement(element *Element) {
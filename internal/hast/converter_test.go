@@ -1,8 +1,10 @@
 package hast
 
 import (
+	"slices"
 	"strings"
 	"testing"
+	"unique"
 
 	"github.com/kjanat/slimacademy/internal/streaming"
 )
@@ -183,7 +185,7 @@ func TestEventToHASTConverter(t *testing.T) {
 			t.Fatalf("Error rendering HAST: %v", err)
 		}
 
-		expected := `<img src="test.jpg" alt="Test Image" style="max-width: 100%; height: auto;" />`
+		expected := `<img alt="Test Image" src="test.jpg" style="max-width: 100%; height: auto;" />`
 		if !contains(html, expected) {
 			t.Errorf("Expected %q in output, got: %s", expected, html)
 		}
@@ -225,7 +227,7 @@ func TestEventToHASTConverter(t *testing.T) {
 		events := []streaming.Event{
 			{Kind: streaming.StartDoc},
 			{Kind: streaming.StartTable},
-			{Kind: streaming.StartTableRow},
+			{Kind: streaming.StartTableRow, TableHeader: true},
 			{Kind: streaming.StartTableCell},
 			{Kind: streaming.Text, TextContent: "Header 1"},
 			{Kind: streaming.EndTableCell},
@@ -257,9 +259,12 @@ func TestEventToHASTConverter(t *testing.T) {
 			t.Fatalf("Error rendering HAST: %v", err)
 		}
 
-		// First row should use th elements, second row should use td elements
-		if !contains(html, "<th") {
-			t.Error("Expected th elements for first row")
+		// Header row should use th elements in thead, data rows td elements in tbody
+		if !contains(html, "<thead><tr><th") {
+			t.Error("Expected th elements in thead for header row")
+		}
+		if !contains(html, "</thead><tbody><tr><td") {
+			t.Error("Expected tbody after thead")
 		}
 		if !contains(html, "<td") {
 			t.Error("Expected td elements for data rows")
@@ -464,3 +469,148 @@ func TestConversionOptions(t *testing.T) {
 func contains(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
+
+func TestEventToHASTConverter_TableSpans(t *testing.T) {
+	events := []streaming.Event{
+		{Kind: streaming.StartDoc, Title: "Spans"},
+		{Kind: streaming.StartTable, TableColumns: 2, TableRows: 2, TableHasSpans: true, TableColumnWidths: []float64{90.5, 0}},
+		{Kind: streaming.StartTableRow, TableHeader: true},
+		{Kind: streaming.StartTableCell, CellRowSpan: 1, CellColSpan: 2},
+		{Kind: streaming.Text, TextContent: "Header"},
+		{Kind: streaming.EndTableCell},
+		{Kind: streaming.EndTableRow},
+		{Kind: streaming.StartTableRow},
+		{Kind: streaming.StartTableCell, CellRowSpan: 1, CellColSpan: 1},
+		{Kind: streaming.Text, TextContent: "A"},
+		{Kind: streaming.EndTableCell},
+		{Kind: streaming.StartTableCell, CellRowSpan: 1, CellColSpan: 1},
+		{Kind: streaming.Text, TextContent: "B"},
+		{Kind: streaming.EndTableCell},
+		{Kind: streaming.EndTableRow},
+		{Kind: streaming.EndTable},
+		{Kind: streaming.EndDoc},
+	}
+
+	options := DefaultConversionOptions()
+	options.IncludeStyles = false
+	root, err := NewEventToHASTConverter(options).Convert(events)
+	if err != nil {
+		t.Fatalf("Error converting events: %v", err)
+	}
+
+	html, err := NewHTMLRenderer().RenderToHTML(root)
+	if err != nil {
+		t.Fatalf("Error rendering HAST: %v", err)
+	}
+
+	for _, expected := range []string{`<col style="width: 90.5pt;" />`, `<th colspan="2">Header</th>`, `<td>A</td>`} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, html)
+		}
+	}
+}
+
+func TestEventToHASTConverter_NestedOrderedLists(t *testing.T) {
+	events := []streaming.Event{
+		{Kind: streaming.StartDoc, Title: "Lists"},
+		{Kind: streaming.StartList, ListOrdered: true, ListGlyph: streaming.GlyphUpperRoman, ListStart: 2},
+		{Kind: streaming.StartListItem},
+		{Kind: streaming.Text, TextContent: "Two"},
+		{Kind: streaming.StartList, ListLevel: 1, ListGlyph: streaming.GlyphSquare},
+		{Kind: streaming.StartListItem},
+		{Kind: streaming.Text, TextContent: "Nested"},
+		{Kind: streaming.EndListItem},
+		{Kind: streaming.EndList},
+		{Kind: streaming.EndListItem},
+		{Kind: streaming.EndList},
+		{Kind: streaming.EndDoc},
+	}
+
+	root, err := NewEventToHASTConverter(DefaultConversionOptions()).Convert(events)
+	if err != nil {
+		t.Fatalf("Error converting events: %v", err)
+	}
+	html, err := NewHTMLRenderer().RenderToHTML(root)
+	if err != nil {
+		t.Fatalf("Error rendering HAST: %v", err)
+	}
+
+	for _, expected := range []string{`type="I"`, `start="2"`, `<li>Two<ul style="list-style-type: square;"><li>Nested</li></ul></li></ol>`} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, html)
+		}
+	}
+}
+
+func TestEventToHASTConverter_TableOfContents(t *testing.T) {
+	events := []streaming.Event{
+		{Kind: streaming.StartDoc, Title: "Anatomy"},
+		{Kind: streaming.TableOfContents, TOCEntries: []streaming.TOCEntry{
+			{Level: 2, Text: "Bones", AnchorID: "bones"},
+			{Level: 3, Text: "Skull", AnchorID: "skull"},
+		}},
+		{Kind: streaming.StartParagraph},
+		{Kind: streaming.Text, TextContent: "Body"},
+		{Kind: streaming.EndParagraph},
+		{Kind: streaming.EndDoc},
+	}
+
+	root, err := NewEventToHASTConverter(DefaultConversionOptions()).Convert(events)
+	if err != nil {
+		t.Fatalf("Error converting events: %v", err)
+	}
+	html, err := NewHTMLRenderer().RenderToHTML(root)
+	if err != nil {
+		t.Fatalf("Error rendering HAST: %v", err)
+	}
+
+	expected := `<nav class="table-of-contents"><ul><li><a href="#bones">Bones</a></li><li><a href="#skull">Skull</a></li></ul></nav><p>Body</p>`
+	if !strings.Contains(html, expected) {
+		t.Errorf("Expected %q in output:\n%s", expected, html)
+	}
+}
+
+func TestEventToHASTConverter_Incremental(t *testing.T) {
+	events := []streaming.Event{
+		{Kind: streaming.StartDoc, Title: "Incremental"},
+		{Kind: streaming.StartHeading, Level: 1, AnchorID: "intro", HeadingText: unique.Make("Intro")},
+		{Kind: streaming.Text, TextContent: "Intro"},
+		{Kind: streaming.EndHeading, Level: 1},
+		{Kind: streaming.StartParagraph},
+		{Kind: streaming.Text, TextContent: "Body"},
+		{Kind: streaming.EndParagraph},
+		{Kind: streaming.EndDoc},
+	}
+
+	converter := NewEventToHASTConverter(DefaultConversionOptions())
+	expected, err := converter.Convert(events)
+	if err != nil {
+		t.Fatalf("Error converting events: %v", err)
+	}
+	want, _ := NewHTMLRenderer().RenderToHTML(expected)
+
+	// Events added one at a time build the same tree
+	builder := NewEventToHASTConverter(DefaultConversionOptions())
+	for _, event := range events {
+		if err := builder.Add(event); err != nil {
+			t.Fatalf("Add(%v) failed: %v", event.Kind, err)
+		}
+	}
+	if got, _ := NewHTMLRenderer().RenderToHTML(builder.Tree()); got != want {
+		t.Errorf("Expected Add to build the same tree:\n%s\n%s", want, got)
+	}
+
+	// ConvertSeq starts a new document
+	root, err := builder.ConvertSeq(slices.Values(events[:4]))
+	if err != nil {
+		t.Fatalf("ConvertSeq() failed: %v", err)
+	}
+	got, _ := NewHTMLRenderer().RenderToHTML(root)
+	if strings.Contains(got, "Body") || !strings.Contains(got, "Intro") {
+		t.Errorf("Expected only the heading after ConvertSeq, got: %s", got)
+	}
+
+	if err := builder.Add(streaming.Event{Kind: streaming.EventKind(255)}); err == nil {
+		t.Error("Expected error for unknown event kind")
+	}
+}
@@ -170,27 +170,43 @@ func (e *Element) HasProperty(key string) bool {
 
 // Custom JSON marshaling to handle the Node interface properly
 func (r *Root) MarshalJSON() ([]byte, error) {
-	type Alias Root
+	// Children is required by the hast specification, so it is never omitted
 	return json.Marshal(&struct {
-		Type string `json:"type"`
-		*Alias
+		Type     string `json:"type"`
+		Children []Node `json:"children"`
 	}{
-		Type:  "root",
-		Alias: (*Alias)(r),
+		Type:     "root",
+		Children: nonNilChildren(r.Children),
 	})
 }
 
 func (e *Element) MarshalJSON() ([]byte, error) {
-	type Alias Element
+	// Properties and children are required by the hast specification, so they are never omitted
+	properties := e.Properties
+	if properties == nil {
+		properties = map[string]any{}
+	}
 	return json.Marshal(&struct {
-		Type string `json:"type"`
-		*Alias
+		Type       string         `json:"type"`
+		TagName    string         `json:"tagName"`
+		Properties map[string]any `json:"properties"`
+		Children   []Node         `json:"children"`
 	}{
-		Type:  "element",
-		Alias: (*Alias)(e),
+		Type:       "element",
+		TagName:    e.TagName,
+		Properties: properties,
+		Children:   nonNilChildren(e.Children),
 	})
 }
 
+// nonNilChildren returns children, or an empty list so that it marshals as []
+func nonNilChildren(children []Node) []Node {
+	if children == nil {
+		return []Node{}
+	}
+	return children
+}
+
 func (t *Text) MarshalJSON() ([]byte, error) {
 	type Alias Text
 	return json.Marshal(&struct {
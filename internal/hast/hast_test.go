@@ -180,7 +180,7 @@ func TestHTMLRenderer(t *testing.T) {
 			t.Fatalf("Error rendering void element: %v", err)
 		}
 
-		expected := `<img src="test.jpg" alt="Test image" />`
+		expected := `<img alt="Test image" src="test.jpg" />`
 		if html != expected {
 			t.Errorf("Expected %q, got %q", expected, html)
 		}
@@ -283,7 +283,7 @@ func TestHASTHelperFunctions(t *testing.T) {
 			t.Fatalf("Error rendering image: %v", err)
 		}
 
-		expected := `<img src="test.jpg" alt="Test Image" />`
+		expected := `<img alt="Test Image" src="test.jpg" />`
 		if html != expected {
 			t.Errorf("Expected %q, got %q", expected, html)
 		}
@@ -0,0 +1,66 @@
+package hast
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseHTML parses an HTML fragment into a tree. It reads the markup the writers generate:
+// well-formed HTML with void elements such as <br> left open and HTML entities. Element and
+// attribute names are kept as written, so properties use attribute names such as class.
+func ParseHTML(fragment string) (*Root, error) {
+	decoder := xml.NewDecoder(strings.NewReader(fragment))
+	decoder.Strict = false
+	decoder.AutoClose = xml.HTMLAutoClose
+	decoder.Entity = xml.HTMLEntity
+
+	root := NewRoot()
+	var open []*Element
+	add := func(node Node) {
+		if n := len(open); n > 0 {
+			open[n-1].Children = append(open[n-1].Children, node)
+		} else {
+			root.Children = append(root.Children, node)
+		}
+	}
+
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse HTML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			element := NewElement(qualifiedName(t.Name))
+			for _, attr := range t.Attr {
+				element.SetProperty(qualifiedName(attr.Name), attr.Value)
+			}
+			add(element)
+			open = append(open, element)
+		case xml.EndElement:
+			if len(open) > 0 {
+				open = open[:len(open)-1]
+			}
+		case xml.CharData:
+			add(NewText(string(t)))
+		case xml.Comment:
+			add(NewComment(strings.TrimSpace(string(t))))
+		}
+	}
+	return root, nil
+}
+
+// qualifiedName returns a name with its prefix, such as epub:type
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
@@ -0,0 +1,60 @@
+package hast
+
+import "testing"
+
+func TestParseHTML(t *testing.T) {
+	fragment := `<div class="document-body">
+    <p>Fish &amp; chips&nbsp;<br>to go<img src="a.png" alt="A" /></p>
+    <!-- note -->
+    <aside epub:type="footnote">1</aside>
+</div>
+`
+	root, err := ParseHTML(fragment)
+	if err != nil {
+		t.Fatalf("ParseHTML() failed: %v", err)
+	}
+	if len(root.Children) != 2 {
+		t.Fatalf("Expected the div and a trailing newline, got %d children", len(root.Children))
+	}
+	div, ok := root.Children[0].(*Element)
+	if !ok || div.TagName != "div" || !HasClass(div, "document-body") {
+		t.Fatalf("Expected the document body div, got %+v", root.Children[0])
+	}
+
+	p := div.Children[1].(*Element)
+	if text := p.Children[0].(*Text).Value; text != "Fish & chips\u00a0" {
+		t.Errorf("Expected decoded entities, got %q", text)
+	}
+	if br := p.Children[1].(*Element); br.TagName != "br" || len(br.Children) != 0 {
+		t.Errorf("Expected an empty br element, got %+v", br)
+	}
+	if img := p.Children[3].(*Element); img.TagName != "img" || img.Properties["src"] != "a.png" {
+		t.Errorf("Expected the image in the paragraph, got %+v", img)
+	}
+	if comment, ok := div.Children[3].(*Comment); !ok || comment.Value != "note" {
+		t.Errorf("Expected the comment, got %+v", div.Children[3])
+	}
+	if aside := div.Children[5].(*Element); aside.Properties["epub:type"] != "footnote" {
+		t.Errorf("Expected the prefixed attribute to keep its prefix, got %+v", aside.Properties)
+	}
+
+	html, err := NewHTMLRenderer().RenderToHTML(root)
+	if err != nil {
+		t.Fatalf("Error rendering HAST: %v", err)
+	}
+	expected := `<div class="document-body">
+    <p>Fish &amp; chips` + "\u00a0" + `<br />to go<img alt="A" src="a.png" /></p>
+    <!-- note -->
+    <aside epub:type="footnote">1</aside>
+</div>
+`
+	if html != expected {
+		t.Errorf("Expected the fragment to render back as\n%s\ngot\n%s", expected, html)
+	}
+}
+
+func TestParseHTML_Malformed(t *testing.T) {
+	if _, err := ParseHTML(`<p class="x>unterminated`); err == nil {
+		t.Error("Expected an error for malformed markup")
+	}
+}
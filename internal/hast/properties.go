@@ -0,0 +1,163 @@
+package hast
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// propertyNames maps HTML attributes whose hast property name is not their plain name
+var propertyNames = map[string]string{
+	"class":           "className",
+	"for":             "htmlFor",
+	"colspan":         "colSpan",
+	"rowspan":         "rowSpan",
+	"tabindex":        "tabIndex",
+	"readonly":        "readOnly",
+	"maxlength":       "maxLength",
+	"accept-charset":  "acceptCharset",
+	"http-equiv":      "httpEquiv",
+	"referrerpolicy":  "referrerPolicy",
+	"crossorigin":     "crossOrigin",
+	"srcset":          "srcSet",
+	"datetime":        "dateTime",
+	"contenteditable": "contentEditable",
+}
+
+// attributeNames is the inverse of propertyNames
+var attributeNames = func() map[string]string {
+	names := make(map[string]string, len(propertyNames))
+	for attribute, property := range propertyNames {
+		names[property] = attribute
+	}
+	return names
+}()
+
+// PropertyName returns the hast property name of an HTML attribute, as used by unified and
+// rehype: class becomes className, data-foo-bar becomes dataFooBar and so on
+func PropertyName(attribute string) string {
+	if property, ok := propertyNames[attribute]; ok {
+		return property
+	}
+	for _, prefix := range []string{"data-", "aria-"} {
+		if rest, ok := strings.CutPrefix(attribute, prefix); ok && rest != "" {
+			return strings.TrimSuffix(prefix, "-") + camelCase(rest)
+		}
+	}
+	return attribute
+}
+
+// AttributeName returns the HTML attribute for a hast property name; it is the inverse of
+// PropertyName, and attribute names are returned unchanged
+func AttributeName(property string) string {
+	if attribute, ok := attributeNames[property]; ok {
+		return attribute
+	}
+	for _, prefix := range []string{"data", "aria"} {
+		rest, ok := strings.CutPrefix(property, prefix)
+		if ok && rest != "" && unicode.IsUpper(rune(rest[0])) {
+			return prefix + "-" + kebabCase(rest)
+		}
+	}
+	return property
+}
+
+// NormalizeProperties rewrites the properties of every element below node to hast property
+// names, so that the tree can be consumed by unified tooling. className values become lists
+// of class names. HTMLRenderer accepts both forms.
+func NormalizeProperties(node Node) {
+	walk(node, func(element *Element) {
+		if len(element.Properties) == 0 {
+			return
+		}
+
+		properties := make(map[string]any, len(element.Properties))
+		for key, value := range element.Properties {
+			name := PropertyName(key)
+			if s, ok := value.(string); ok && name == "className" {
+				value = strings.Fields(s)
+			}
+			properties[name] = value
+		}
+		element.Properties = properties
+	})
+}
+
+// walk calls fn for every element below node, parents first
+func walk(node Node, fn func(*Element)) {
+	switch n := node.(type) {
+	case *Root:
+		for _, child := range n.Children {
+			walk(child, fn)
+		}
+	case *Element:
+		fn(n)
+		for _, child := range n.Children {
+			walk(child, fn)
+		}
+	}
+}
+
+// camelCase turns foo-bar into FooBar
+func camelCase(s string) string {
+	var b strings.Builder
+	for part := range strings.SplitSeq(s, "-") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]))
+		b.WriteString(part[1:])
+	}
+	return b.String()
+}
+
+// kebabCase turns FooBar into foo-bar
+func kebabCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('-')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// ClassNames returns the classes of an element, whether set as a class attribute or as a
+// className list
+func ClassNames(element *Element) []string {
+	var classes []string
+	for _, key := range []string{"class", "className"} {
+		switch v := element.Properties[key].(type) {
+		case string:
+			classes = append(classes, strings.Fields(v)...)
+		case []string:
+			classes = append(classes, v...)
+		}
+	}
+	return classes
+}
+
+// HasClass reports whether an element has a class
+func HasClass(element *Element, class string) bool {
+	return slices.Contains(ClassNames(element), class)
+}
+
+// AddClass adds classes to an element, keeping the property form it already uses
+func AddClass(element *Element, classes ...string) {
+	for _, class := range classes {
+		if class == "" || HasClass(element, class) {
+			continue
+		}
+		if list, ok := element.Properties["className"].([]string); ok {
+			element.SetProperty("className", append(list, class))
+		} else if existing, ok := element.Properties["class"].(string); ok && existing != "" {
+			element.SetProperty("class", existing+" "+class)
+		} else {
+			element.SetProperty("class", class)
+		}
+	}
+}
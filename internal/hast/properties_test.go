@@ -0,0 +1,74 @@
+package hast
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPropertyNames(t *testing.T) {
+	tests := []struct {
+		attribute string
+		property  string
+	}{
+		{"class", "className"},
+		{"for", "htmlFor"},
+		{"colspan", "colSpan"},
+		{"data-bachelor-year", "dataBachelorYear"},
+		{"aria-label", "ariaLabel"},
+		{"href", "href"},
+		{"data", "data"},
+	}
+
+	for _, tt := range tests {
+		if got := PropertyName(tt.attribute); got != tt.property {
+			t.Errorf("PropertyName(%q) = %q, expected %q", tt.attribute, got, tt.property)
+		}
+		if got := AttributeName(tt.property); got != tt.attribute {
+			t.Errorf("AttributeName(%q) = %q, expected %q", tt.property, got, tt.attribute)
+		}
+	}
+
+	if got := AttributeName("database"); got != "database" {
+		t.Errorf("Expected lowercase names to be kept, got %q", got)
+	}
+}
+
+func TestNormalizeProperties(t *testing.T) {
+	root := NewRoot()
+	div := NewElement("div")
+	div.SetProperty("class", "document-metadata wide")
+	div.SetProperty("data-description", "About")
+	cell := NewElement("td")
+	cell.SetProperty("colspan", 2)
+	AddChild(div, cell)
+	AddChild(root, div)
+
+	before, _ := NewHTMLRenderer().RenderToHTML(root)
+	NormalizeProperties(root)
+
+	if classes, ok := div.GetProperty("className"); !ok || !slices.Equal(classes.([]string), []string{"document-metadata", "wide"}) {
+		t.Errorf("Expected className list, got %v", div.Properties)
+	}
+	if _, ok := div.GetProperty("dataDescription"); !ok {
+		t.Errorf("Expected dataDescription, got %v", div.Properties)
+	}
+	if span, _ := cell.GetProperty("colSpan"); span != 2 {
+		t.Errorf("Expected colSpan 2, got %v", cell.Properties)
+	}
+
+	// The normalized tree renders to the same HTML
+	if after, _ := NewHTMLRenderer().RenderToHTML(root); after != before {
+		t.Errorf("Expected the same HTML after normalizing:\n%s\n%s", before, after)
+	}
+
+	data, err := json.Marshal(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"className":["document-metadata","wide"]`) ||
+		!strings.Contains(string(data), `"tagName":"td","properties":{"colSpan":2},"children":[]`) {
+		t.Errorf("Unexpected JSON: %s", data)
+	}
+}
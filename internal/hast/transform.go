@@ -0,0 +1,362 @@
+package hast
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/kjanat/slimacademy/internal/config"
+)
+
+// Pass rewrites a HAST tree in place
+type Pass interface {
+	// Name identifies the pass in errors
+	Name() string
+	// Transform rewrites the tree
+	Transform(root *Root) error
+}
+
+// Transformer applies an ordered list of passes to HAST trees
+type Transformer struct {
+	passes []Pass
+}
+
+// NewTransformer creates a transformer running passes in order
+func NewTransformer(passes ...Pass) *Transformer {
+	return &Transformer{passes: passes}
+}
+
+// NewTransformerFromConfig creates a transformer for the passes listed in cfg
+func NewTransformerFromConfig(cfg *config.TransformConfig) (*Transformer, error) {
+	t := NewTransformer()
+	if cfg == nil {
+		return t, nil
+	}
+
+	for _, passConfig := range cfg.Passes {
+		pass, err := NewPass(passConfig.Name, passConfig.Options)
+		if err != nil {
+			return nil, err
+		}
+		t.passes = append(t.passes, pass)
+	}
+	return t, nil
+}
+
+// Passes returns the passes of the transformer
+func (t *Transformer) Passes() []Pass {
+	return slices.Clone(t.passes)
+}
+
+// Transform applies all passes to root
+func (t *Transformer) Transform(root *Root) error {
+	for _, pass := range t.passes {
+		if err := pass.Transform(root); err != nil {
+			return fmt.Errorf("transform %s failed: %w", pass.Name(), err)
+		}
+	}
+	return nil
+}
+
+// fragmentTag is the tag of the elements that hold each fragment passed to TransformHTML
+const fragmentTag = "slim-fragment"
+
+// TransformHTML applies all passes once to HTML fragments, such as the chapters of a book, as
+// if they were a single document, so that passes numbering headings continue from one fragment
+// to the next. It returns the rendered fragments and the headings of the transformed document.
+// Without passes the fragments are returned unchanged with no headings.
+func (t *Transformer) TransformHTML(fragments ...string) ([]string, []HeadingInfo, error) {
+	if len(t.passes) == 0 {
+		return fragments, nil, nil
+	}
+
+	root := NewRoot()
+	wrappers := make([]*Element, len(fragments))
+	for i, fragment := range fragments {
+		tree, err := ParseHTML(fragment)
+		if err != nil {
+			return nil, nil, err
+		}
+		wrappers[i] = NewElement(fragmentTag)
+		wrappers[i].Children = tree.Children
+		AddChild(root, wrappers[i])
+	}
+	if err := t.Transform(root); err != nil {
+		return nil, nil, err
+	}
+
+	renderer := NewHTMLRenderer()
+	rendered := make([]string, len(wrappers))
+	for i, wrapper := range wrappers {
+		html, err := renderer.RenderToHTML(&Root{Children: wrapper.Children})
+		if err != nil {
+			return nil, nil, err
+		}
+		rendered[i] = html
+	}
+	return rendered, Headings(root), nil
+}
+
+// HeadingInfo describes a heading of a tree
+type HeadingInfo struct {
+	ID    string
+	Level int
+	Text  string
+}
+
+// Headings returns the h1-h6 elements below node in document order
+func Headings(node Node) []HeadingInfo {
+	var headings []HeadingInfo
+	walk(node, func(element *Element) {
+		if level := headingLevel(element); level > 0 {
+			id, _ := element.Properties["id"].(string)
+			headings = append(headings, HeadingInfo{ID: id, Level: level, Text: textContent(element)})
+		}
+	})
+	return headings
+}
+
+// textContent returns the text below node
+func textContent(node Node) string {
+	var text strings.Builder
+	var collect func(node Node)
+	collect = func(node Node) {
+		switch n := node.(type) {
+		case *Text:
+			text.WriteString(n.Value)
+		case *Element:
+			for _, child := range n.Children {
+				collect(child)
+			}
+		}
+	}
+	collect(node)
+	return text.String()
+}
+
+// NewPass creates a built-in pass by name; see the Transform* constants in the config package
+// for the passes and their options
+func NewPass(name string, options map[string]string) (Pass, error) {
+	switch name {
+	case config.TransformRenumberHeadings:
+		start, err := strconv.Atoi(option(options, "start", "1"))
+		if err != nil || start < 1 || start > 6 {
+			return nil, fmt.Errorf("%s: start must be a heading level from 1 to 6", name)
+		}
+		numbered, err := strconv.ParseBool(option(options, "numbered", "false"))
+		if err != nil {
+			return nil, fmt.Errorf("%s: numbered must be true or false", name)
+		}
+		return RenumberHeadings(start, numbered), nil
+	case config.TransformExternalLinks:
+		return ExternalLinks(option(options, "rel", "noopener noreferrer"), option(options, "target", "_blank")), nil
+	case config.TransformLazyImages:
+		return LazyImages(option(options, "loading", "lazy"), option(options, "decoding", "async")), nil
+	case config.TransformWrapTables:
+		return WrapTables(option(options, "class", "table-wrapper")), nil
+	case config.TransformAddClass:
+		if options["tag"] == "" || options["class"] == "" {
+			return nil, fmt.Errorf("%s: the tag and class options are required", name)
+		}
+		return AddClassPass(options["tag"], strings.Fields(options["class"])...), nil
+	default:
+		return nil, fmt.Errorf("unknown transform pass %q", name)
+	}
+}
+
+// option returns an option, or def when it is not set. An option set to an empty string
+// stays empty, which turns the attribute off.
+func option(options map[string]string, key, def string) string {
+	if value, ok := options[key]; ok {
+		return value
+	}
+	return def
+}
+
+// ElementVisitor adapts a function to a Visitor that only visits elements
+type ElementVisitor func(*Element) error
+
+func (f ElementVisitor) VisitRoot(*Root) error         { return nil }
+func (f ElementVisitor) VisitElement(e *Element) error { return f(e) }
+func (f ElementVisitor) VisitText(*Text) error         { return nil }
+func (f ElementVisitor) VisitComment(*Comment) error   { return nil }
+
+// visitorPass is a pass that visits every element with a TreeWalker
+type visitorPass struct {
+	name  string
+	visit ElementVisitor
+}
+
+func (p *visitorPass) Name() string {
+	return p.name
+}
+
+func (p *visitorPass) Transform(root *Root) error {
+	walker := NewTreeWalker()
+	walker.AddVisitor(p.visit)
+	return walker.Walk(root)
+}
+
+// headingLevel returns the level of an h1-h6 element, or 0 for other elements
+func headingLevel(element *Element) int {
+	if len(element.TagName) != 2 || element.TagName[0] != 'h' {
+		return 0
+	}
+	level := int(element.TagName[1] - '0')
+	if level < 1 || level > 6 {
+		return 0
+	}
+	return level
+}
+
+// renumberHeadings maps the heading levels used in a document to consecutive levels
+type renumberHeadings struct {
+	start    int
+	numbered bool
+}
+
+// RenumberHeadings returns a pass that shifts the highest heading level to start and closes
+// gaps, so that h2, h4 becomes h1, h2 for start 1. With numbered, each heading is prefixed
+// with its section number, e.g. "1.2", in a span with the heading-number class.
+func RenumberHeadings(start int, numbered bool) Pass {
+	return &renumberHeadings{start: start, numbered: numbered}
+}
+
+func (p *renumberHeadings) Name() string {
+	return config.TransformRenumberHeadings
+}
+
+func (p *renumberHeadings) Transform(root *Root) error {
+	var headings []*Element
+	walker := NewTreeWalker()
+	walker.AddVisitor(ElementVisitor(func(element *Element) error {
+		if headingLevel(element) > 0 {
+			headings = append(headings, element)
+		}
+		return nil
+	}))
+	if err := walker.Walk(root); err != nil {
+		return err
+	}
+
+	var used []int
+	for _, heading := range headings {
+		if level := headingLevel(heading); !slices.Contains(used, level) {
+			used = append(used, level)
+		}
+	}
+	slices.Sort(used)
+
+	var counters []int
+	for _, heading := range headings {
+		level := min(p.start+slices.Index(used, headingLevel(heading)), 6)
+		heading.TagName = "h" + strconv.Itoa(level)
+
+		if p.numbered {
+			depth := level - p.start + 1
+			counters = append(counters, make([]int, max(0, depth-len(counters)))...)[:depth]
+			counters[depth-1]++
+
+			parts := make([]string, depth)
+			for i, n := range counters {
+				parts[i] = strconv.Itoa(n)
+			}
+			number := NewElement("span")
+			number.SetProperty("class", "heading-number")
+			AddChild(number, NewText(strings.Join(parts, ".")+" "))
+			heading.Children = append([]Node{number}, heading.Children...)
+		}
+	}
+	return nil
+}
+
+// ExternalLinks returns a pass that sets rel and target on links to absolute http(s) URLs.
+// Attributes already set are kept, and empty values are not set.
+func ExternalLinks(rel, target string) Pass {
+	return &visitorPass{name: config.TransformExternalLinks, visit: func(element *Element) error {
+		if element.TagName != "a" {
+			return nil
+		}
+		href, _ := element.GetProperty("href")
+		u, err := url.Parse(fmt.Sprint(href))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil
+		}
+		setDefault(element, "rel", rel)
+		setDefault(element, "target", target)
+		return nil
+	}}
+}
+
+// LazyImages returns a pass that sets loading and decoding on images, unless already set
+func LazyImages(loading, decoding string) Pass {
+	return &visitorPass{name: config.TransformLazyImages, visit: func(element *Element) error {
+		if element.TagName == "img" {
+			setDefault(element, "loading", loading)
+			setDefault(element, "decoding", decoding)
+		}
+		return nil
+	}}
+}
+
+// wrapTables wraps tables in a horizontally scrolling div
+type wrapTables struct {
+	class string
+}
+
+// WrapTables returns a pass that wraps each table in a div with class that scrolls
+// horizontally, so wide tables do not widen the page
+func WrapTables(class string) Pass {
+	return &wrapTables{class: class}
+}
+
+func (p *wrapTables) Name() string {
+	return config.TransformWrapTables
+}
+
+func (p *wrapTables) Transform(root *Root) error {
+	p.wrap(root.Children)
+
+	walker := NewTreeWalker()
+	walker.AddVisitor(ElementVisitor(func(element *Element) error {
+		if element.TagName != "div" || !HasClass(element, p.class) {
+			p.wrap(element.Children)
+		}
+		return nil
+	}))
+	return walker.Walk(root)
+}
+
+// wrap replaces the tables in children by wrapped tables
+func (p *wrapTables) wrap(children []Node) {
+	for i, child := range children {
+		table, ok := child.(*Element)
+		if !ok || table.TagName != "table" {
+			continue
+		}
+		wrapper := NewElement("div")
+		AddClass(wrapper, p.class)
+		wrapper.SetProperty("style", "overflow-x: auto;")
+		AddChild(wrapper, table)
+		children[i] = wrapper
+	}
+}
+
+// AddClassPass returns a pass that adds classes to every element with tagName
+func AddClassPass(tagName string, classes ...string) Pass {
+	return &visitorPass{name: config.TransformAddClass, visit: func(element *Element) error {
+		if element.TagName == tagName {
+			AddClass(element, classes...)
+		}
+		return nil
+	}}
+}
+
+// setDefault sets a property unless it is already set or value is empty
+func setDefault(element *Element, key, value string) {
+	if value != "" && !element.HasProperty(key) {
+		element.SetProperty(key, value)
+	}
+}
@@ -0,0 +1,197 @@
+package hast
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kjanat/slimacademy/internal/config"
+)
+
+// transformTestTree builds a small document with headings, a link, an image and a table
+func transformTestTree() *Root {
+	root := NewRoot()
+	AddChild(root, Heading(2, "Cells"))
+	p := Paragraph("See ")
+	AddChild(p, Link("https://example.com/cells", "example"))
+	AddChild(p, Link("#membrane", "below"))
+	AddChild(root, p)
+	AddChild(root, Heading(4, "Membrane"))
+	AddChild(root, Image("cell.png", "A cell"))
+	AddChild(root, Heading(4, "Nucleus"))
+	AddChild(root, Heading(2, "Tissues"))
+	section := NewElement("section")
+	AddChild(section, Table([]string{"Tissue"}, [][]string{{"Muscle"}}))
+	AddChild(root, section)
+	AddChild(root, Table([]string{"Organ"}, [][]string{{"Heart"}}))
+	return root
+}
+
+func renderTransformed(t *testing.T, transformer *Transformer) string {
+	t.Helper()
+	root := transformTestTree()
+	if err := transformer.Transform(root); err != nil {
+		t.Fatalf("Transform() failed: %v", err)
+	}
+	html, err := NewHTMLRenderer().RenderToHTML(root)
+	if err != nil {
+		t.Fatalf("Error rendering HAST: %v", err)
+	}
+	return html
+}
+
+func TestTransformPasses(t *testing.T) {
+	tests := []struct {
+		name       string
+		pass       Pass
+		expected   []string
+		unexpected []string
+	}{
+		{
+			name:     "renumber headings",
+			pass:     RenumberHeadings(1, false),
+			expected: []string{"<h1>Cells</h1>", "<h2>Membrane</h2>", "<h2>Nucleus</h2>", "<h1>Tissues</h1>"},
+		},
+		{
+			name: "numbered headings",
+			pass: RenumberHeadings(2, true),
+			expected: []string{
+				`<h2><span class="heading-number">1 </span>Cells</h2>`,
+				`<h3><span class="heading-number">1.1 </span>Membrane</h3>`,
+				`<h3><span class="heading-number">1.2 </span>Nucleus</h3>`,
+				`<h2><span class="heading-number">2 </span>Tissues</h2>`,
+			},
+		},
+		{
+			name:       "external links",
+			pass:       ExternalLinks("noopener", "_blank"),
+			expected:   []string{`<a href="https://example.com/cells" rel="noopener" target="_blank">`},
+			unexpected: []string{`<a href="#membrane" rel=`},
+		},
+		{
+			name:     "lazy images",
+			pass:     LazyImages("lazy", ""),
+			expected: []string{`<img alt="A cell" loading="lazy" src="cell.png" />`},
+		},
+		{
+			name: "wrap tables",
+			pass: WrapTables("scroll"),
+			expected: []string{
+				`<section><div class="scroll" style="overflow-x: auto;"><table>`,
+				`</section><div class="scroll" style="overflow-x: auto;"><table>`,
+			},
+		},
+		{
+			name:     "add class",
+			pass:     AddClassPass("table", "striped", "compact"),
+			expected: []string{`<table class="striped compact">`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			html := renderTransformed(t, NewTransformer(tt.pass))
+			for _, expected := range tt.expected {
+				if !strings.Contains(html, expected) {
+					t.Errorf("Expected %q in output:\n%s", expected, html)
+				}
+			}
+			for _, unexpected := range tt.unexpected {
+				if strings.Contains(html, unexpected) {
+					t.Errorf("Did not expect %q in output:\n%s", unexpected, html)
+				}
+			}
+		})
+	}
+}
+
+func TestTransformer_Order(t *testing.T) {
+	// Tables are wrapped once, even when the pass runs twice, and classes added afterwards
+	// apply to the wrapper
+	html := renderTransformed(t, NewTransformer(WrapTables("scroll"), WrapTables("scroll"), AddClassPass("div", "wide")))
+	if strings.Count(html, "<div") != 2 || !strings.Contains(html, `<div class="scroll wide"`) {
+		t.Errorf("Expected two wrapped tables with the added class:\n%s", html)
+	}
+}
+
+func TestNewTransformerFromConfig(t *testing.T) {
+	transformer, err := NewTransformerFromConfig(&config.TransformConfig{Passes: []config.TransformPassConfig{
+		{Name: config.TransformRenumberHeadings, Options: map[string]string{"start": "2"}},
+		{Name: config.TransformExternalLinks, Options: map[string]string{"target": ""}},
+		{Name: config.TransformLazyImages},
+	}})
+	if err != nil {
+		t.Fatalf("NewTransformerFromConfig() failed: %v", err)
+	}
+	if len(transformer.Passes()) != 3 {
+		t.Fatalf("Expected 3 passes, got %d", len(transformer.Passes()))
+	}
+
+	html := renderTransformed(t, transformer)
+	for _, expected := range []string{"<h2>Cells</h2>", `rel="noopener noreferrer">`, `decoding="async" loading="lazy"`} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, html)
+		}
+	}
+
+	for _, pass := range []config.TransformPassConfig{
+		{Name: "minify"},
+		{Name: config.TransformRenumberHeadings, Options: map[string]string{"start": "7"}},
+		{Name: config.TransformAddClass, Options: map[string]string{"tag": "table"}},
+	} {
+		if _, err := NewTransformerFromConfig(&config.TransformConfig{Passes: []config.TransformPassConfig{pass}}); err == nil {
+			t.Errorf("Expected error for %+v", pass)
+		}
+	}
+}
+
+func TestTransformer_TransformHTML(t *testing.T) {
+	fragment := `<h2 id="cells">Cells</h2>
+<p><a href="https://example.com">example</a> <img src="cell.png" alt="A cell" /></p>
+`
+	if html, headings, err := NewTransformer().TransformHTML(fragment); err != nil || html[0] != fragment || headings != nil {
+		t.Errorf("Expected the fragment unchanged without passes, got %q, %v", html, err)
+	}
+
+	transformer := NewTransformer(RenumberHeadings(1, false), ExternalLinks("noopener", "_blank"), LazyImages("lazy", ""))
+	html, _, err := transformer.TransformHTML(fragment)
+	if err != nil {
+		t.Fatalf("TransformHTML() failed: %v", err)
+	}
+	for _, expected := range []string{
+		`<h1 id="cells">Cells</h1>`,
+		`<a href="https://example.com" rel="noopener" target="_blank">example</a>`,
+		`<img alt="A cell" loading="lazy" src="cell.png" />`,
+	} {
+		if !strings.Contains(html[0], expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, html[0])
+		}
+	}
+}
+
+func TestTransformer_TransformHTML_Fragments(t *testing.T) {
+	// Numbering continues across fragments, and levels are mapped for the whole document
+	chapters := []string{
+		`<h2 id="bones">Bones</h2><h3 id="skull">Skull</h3>`,
+		`<h2 id="muscles">Muscles</h2>`,
+		`<h3 id="heart">Heart</h3>`,
+	}
+	html, headings, err := NewTransformer(RenumberHeadings(1, true)).TransformHTML(chapters...)
+	if err != nil {
+		t.Fatalf("TransformHTML() failed: %v", err)
+	}
+
+	expected := []string{
+		`<h1 id="bones"><span class="heading-number">1 </span>Bones</h1><h2 id="skull"><span class="heading-number">1.1 </span>Skull</h2>`,
+		`<h1 id="muscles"><span class="heading-number">2 </span>Muscles</h1>`,
+		`<h2 id="heart"><span class="heading-number">2.1 </span>Heart</h2>`,
+	}
+	for i := range expected {
+		if html[i] != expected[i] {
+			t.Errorf("Fragment %d: expected\n%s\ngot\n%s", i, expected[i], html[i])
+		}
+	}
+
+	if len(headings) != 4 || headings[3] != (HeadingInfo{ID: "heart", Level: 2, Text: "2.1 Heart"}) {
+		t.Errorf("Unexpected headings: %+v", headings)
+	}
+}
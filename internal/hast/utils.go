@@ -3,7 +3,9 @@ package hast
 import (
 	"fmt"
 	"html/template"
+	"maps"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -115,18 +117,13 @@ func (r *HTMLRenderer) renderComment(comment *Comment, builder *strings.Builder)
 
 // renderProperties renders HTML attributes from HAST properties
 func (r *HTMLRenderer) renderProperties(properties map[string]any, builder *strings.Builder) error {
-	for key, value := range properties {
+	// Sorted so that the same tree always renders to the same HTML
+	for _, key := range slices.Sorted(maps.Keys(properties)) {
+		value := properties[key]
 		builder.WriteString(" ")
 
-		// Handle special properties
-		switch key {
-		case "className":
-			builder.WriteString("class")
-		case "htmlFor":
-			builder.WriteString("for")
-		default:
-			builder.WriteString(key)
-		}
+		// Accept both hast property names and plain attribute names
+		builder.WriteString(AttributeName(key))
 
 		// Convert value to string and escape it
 		strValue := r.propertyValueToString(value)
@@ -0,0 +1,376 @@
+// Package mockapi serves book directories from disk through the SlimAcademy API endpoints, so
+// that fetching and syncing can be developed and tested without network access.
+package mockapi
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	mathrand "math/rand/v2"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kjanat/slimacademy/internal/parser"
+)
+
+// Options configures a mock server
+type Options struct {
+	// SourceDir holds the book directories to serve, as written by slim fetch
+	SourceDir string
+
+	// Username and Password are the accepted credentials; empty accepts any login
+	Username string
+	Password string
+
+	// TokenTTL is the lifetime of issued access tokens (default: one hour)
+	TokenTTL time.Duration
+
+	// Latency delays every response
+	Latency time.Duration
+
+	// ErrorRate is the fraction of library and book requests answered with ErrorStatus
+	ErrorRate float64
+	// ErrorStatus is the status of injected errors (default: 503)
+	ErrorStatus int
+	// RetryAfter, when set, is sent with injected errors
+	RetryAfter time.Duration
+	// FailBooks lists book IDs whose requests always fail with 500
+	FailBooks []string
+
+	// Seed makes error injection reproducible; zero uses a random seed
+	Seed uint64
+}
+
+// Stats counts the requests handled by a server
+type Stats struct {
+	Logins         int // Password logins
+	Refreshes      int // Refresh token grants
+	Requests       int // Authenticated API requests, including rejected ones
+	Unauthorized   int // Requests rejected for a missing, unknown or expired token
+	InjectedErrors int // Requests failed by ErrorRate or FailBooks
+}
+
+// book is a book directory served by the server
+type book struct {
+	id  string
+	dir string
+}
+
+// Server is an http.Handler implementing the API endpoints used by the client
+type Server struct {
+	opts  Options
+	books map[string]book
+	order []string // Book IDs in library order
+
+	// mu guards the issued tokens, the random source and the stats
+	mu            sync.Mutex
+	tokens        map[string]time.Time // Access token expiry
+	refreshTokens map[string]string    // Refresh token to username
+	rng           *mathrand.Rand
+	stats         Stats
+}
+
+// summaryFile matches the book metadata file, named after the book ID
+var summaryFile = regexp.MustCompile(`^(\d+)\.json$`)
+
+// New creates a server for the books in opts.SourceDir
+func New(opts Options) (*Server, error) {
+	if opts.TokenTTL <= 0 {
+		opts.TokenTTL = time.Hour
+	}
+	if opts.ErrorStatus == 0 {
+		opts.ErrorStatus = http.StatusServiceUnavailable
+	}
+
+	seed := opts.Seed
+	if seed == 0 {
+		seed = mathrand.Uint64()
+	}
+
+	s := &Server{
+		opts:          opts,
+		books:         make(map[string]book),
+		tokens:        make(map[string]time.Time),
+		refreshTokens: make(map[string]string),
+		rng:           mathrand.New(mathrand.NewPCG(seed, seed)),
+	}
+	if err := s.loadBooks(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// loadBooks indexes the book directories by the ID in their metadata file name
+func (s *Server) loadBooks() error {
+	dirs, err := parser.NewBookParser().FindAllBooks(s.opts.SourceDir)
+	if err != nil {
+		return fmt.Errorf("failed to find books: %w", err)
+	}
+
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return fmt.Errorf("failed to read book directory: %w", err)
+		}
+		for _, entry := range entries {
+			match := summaryFile.FindStringSubmatch(entry.Name())
+			if match == nil {
+				continue
+			}
+			if existing, ok := s.books[match[1]]; ok {
+				return fmt.Errorf("book %s found in both %s and %s", match[1], existing.dir, dir)
+			}
+			s.books[match[1]] = book{id: match[1], dir: dir}
+			s.order = append(s.order, match[1])
+		}
+	}
+
+	slices.SortFunc(s.order, func(a, b string) int {
+		x, _ := strconv.Atoi(a)
+		y, _ := strconv.Atoi(b)
+		return x - y
+	})
+	return nil
+}
+
+// Books returns the IDs of the served books
+func (s *Server) Books() []string {
+	return slices.Clone(s.order)
+}
+
+// Stats returns the request counters
+func (s *Server) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+// ExpireTokens expires all issued access tokens, as if their lifetime had passed. Refresh
+// tokens stay valid.
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for token := range s.tokens {
+		s.tokens[token] = time.Time{}
+	}
+}
+
+// bookPartFiles maps the book endpoint suffixes to the files in a book directory
+var bookPartFiles = map[string]string{
+	"chapters":   "chapters.json",
+	"content":    "content.json",
+	"list-notes": "list-notes.json",
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.opts.Latency > 0 {
+		select {
+		case <-time.After(s.opts.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	if path == "/api/auth/login" {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		s.handleLogin(w, r)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !s.authorize(r) {
+		writeError(w, http.StatusUnauthorized, "invalid or expired token")
+		return
+	}
+
+	switch {
+	case path == "/api/user/profile":
+		writeJSON(w, map[string]any{"id": 1, "username": s.username(), "email": s.username(), "name": "Mock User"})
+	case path == "/api/summary/library":
+		if s.injectError(w, "") {
+			return
+		}
+		s.handleLibrary(w)
+	case strings.HasPrefix(path, "/api/summary/"):
+		id, part, _ := strings.Cut(strings.TrimPrefix(path, "/api/summary/"), "/")
+		if s.injectError(w, id) {
+			return
+		}
+		s.handleBookPart(w, id, part)
+	default:
+		writeError(w, http.StatusNotFound, "not found")
+	}
+}
+
+// loginRequest accepts both the password and the refresh token grant
+type loginRequest struct {
+	GrantType    string `json:"grant_type"`
+	Username     string `json:"username"`
+	Password     string `json:"password"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	var req loginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid login request")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	username := req.Username
+	switch req.GrantType {
+	case "external_password":
+		if s.opts.Username != "" && (req.Username != s.opts.Username || req.Password != s.opts.Password) {
+			writeError(w, http.StatusUnauthorized, "invalid credentials")
+			return
+		}
+		s.stats.Logins++
+	case "refresh_token":
+		var ok bool
+		if username, ok = s.refreshTokens[req.RefreshToken]; !ok {
+			writeError(w, http.StatusUnauthorized, "invalid refresh token")
+			return
+		}
+		delete(s.refreshTokens, req.RefreshToken) // Refresh tokens are rotated
+		s.stats.Refreshes++
+	default:
+		writeError(w, http.StatusBadRequest, "unsupported grant type")
+		return
+	}
+
+	accessToken, refreshToken := randomToken(), randomToken()
+	s.tokens[accessToken] = time.Now().Add(s.opts.TokenTTL)
+	s.refreshTokens[refreshToken] = username
+
+	writeJSON(w, map[string]any{
+		"access_token":  accessToken,
+		"token_type":    "Bearer",
+		"expires_in":    int(s.opts.TokenTTL.Seconds()),
+		"refresh_token": refreshToken,
+	})
+}
+
+// authorize checks the bearer token of a request
+func (s *Server) authorize(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stats.Requests++
+	expiresAt, known := s.tokens[token]
+	if !ok || !known || !time.Now().Before(expiresAt) {
+		s.stats.Unauthorized++
+		return false
+	}
+	return true
+}
+
+// injectError fails the request according to ErrorRate and FailBooks
+func (s *Server) injectError(w http.ResponseWriter, id string) bool {
+	s.mu.Lock()
+	status := 0
+	if id != "" && slices.Contains(s.opts.FailBooks, id) {
+		status = http.StatusInternalServerError
+	} else if s.opts.ErrorRate > 0 && s.rng.Float64() < s.opts.ErrorRate {
+		status = s.opts.ErrorStatus
+	}
+	if status != 0 {
+		s.stats.InjectedErrors++
+	}
+	s.mu.Unlock()
+
+	if status == 0 {
+		return false
+	}
+	if s.opts.RetryAfter > 0 && status != http.StatusInternalServerError {
+		w.Header().Set("Retry-After", strconv.Itoa(int(s.opts.RetryAfter.Round(time.Second).Seconds())))
+	}
+	writeError(w, status, "injected error")
+	return true
+}
+
+func (s *Server) handleLibrary(w http.ResponseWriter) {
+	summaries := make([]json.RawMessage, 0, len(s.order))
+	for _, id := range s.order {
+		data, err := os.ReadFile(filepath.Join(s.books[id].dir, id+".json"))
+		if err != nil {
+			slog.Warn("failed to read book summary", "id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to read library")
+			return
+		}
+		summaries = append(summaries, data)
+	}
+
+	writeJSON(w, map[string]any{"summaries": summaries, "total": len(summaries)})
+}
+
+func (s *Server) handleBookPart(w http.ResponseWriter, id, part string) {
+	b, ok := s.books[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "summary not found")
+		return
+	}
+
+	name := id + ".json"
+	if part != "" {
+		if name, ok = bookPartFiles[part]; !ok {
+			writeError(w, http.StatusNotFound, "not found")
+			return
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(b.dir, name))
+	if err != nil {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+// username returns the name reported by the profile endpoint
+func (s *Server) username() string {
+	if s.opts.Username != "" {
+		return s.opts.Username
+	}
+	return "mock@example.com"
+}
+
+// randomToken returns an opaque token
+func randomToken() string {
+	return rand.Text()
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("failed to write response", "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
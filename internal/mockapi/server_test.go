@@ -0,0 +1,211 @@
+package mockapi_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/kjanat/slimacademy/internal/client"
+	"github.com/kjanat/slimacademy/internal/mockapi"
+	"github.com/kjanat/slimacademy/internal/source"
+)
+
+// writeBook writes a minimal book directory as saved by slim fetch
+func writeBook(t *testing.T, sourceDir, id, title string) {
+	t.Helper()
+
+	dir := filepath.Join(sourceDir, title)
+	files := map[string]string{
+		id + ".json":      fmt.Sprintf(`{"id": %s, "title": %q, "description": "Served by the mock API"}`, id, title),
+		"chapters.json":   fmt.Sprintf(`[{"id": 1, "summaryId": %s, "title": "Intro"}]`, id),
+		"content.json":    fmt.Sprintf(`{"documentId": "doc-%s", "revisionId": "rev-1", "title": %q}`, id, title),
+		"list-notes.json": `[]`,
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// startServer serves two books and returns the server with a client for it
+func startServer(t *testing.T, opts mockapi.Options) (*mockapi.Server, *client.SlimClient, string) {
+	t.Helper()
+
+	sourceDir := t.TempDir()
+	writeBook(t, sourceDir, "12", "Biology")
+	writeBook(t, sourceDir, "7", "Anatomy")
+	opts.SourceDir = sourceDir
+
+	server, err := mockapi.New(opts)
+	if err != nil {
+		t.Fatalf("New() failed: %v", err)
+	}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	t.Setenv("SLIM_USERNAME", "student@example.com")
+	t.Setenv("SLIM_PASSWORD", "secret")
+
+	apiClient := client.NewSlimClient(t.TempDir())
+	apiClient.SetSecretStore(client.NewEnvSecretStore(), "", "")
+	apiClient.SetBaseURL(httpServer.URL)
+	apiClient.SetRetryPolicy(client.RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond})
+	apiClient.SetDriftHandler(nil)
+	return server, apiClient, sourceDir
+}
+
+func TestServer_FetchLibrary(t *testing.T) {
+	server, apiClient, sourceDir := startServer(t, mockapi.Options{Username: "student@example.com", Password: "secret"})
+
+	if books := server.Books(); !slices.Equal(books, []string{"7", "12"}) {
+		t.Errorf("Expected books in ID order, got %v", books)
+	}
+
+	outputDir := t.TempDir()
+	manager := source.NewSourceManager(outputDir)
+	err := apiClient.FetchLibrary(context.Background(), client.LibraryFetchOptions{
+		OnBook: func(_ int, book *client.BookData) error { return manager.SaveBookData(book) },
+	})
+	if err != nil {
+		t.Fatalf("FetchLibrary() failed: %v", err)
+	}
+
+	// The fetched files are the files that were served
+	for _, name := range []string{"7.json", "chapters.json", "content.json", "list-notes.json"} {
+		served, _ := os.ReadFile(filepath.Join(sourceDir, "Anatomy", name))
+		fetched, err := os.ReadFile(filepath.Join(outputDir, "Anatomy", name))
+		if err != nil {
+			t.Fatalf("Expected %s to be fetched: %v", name, err)
+		}
+		if !bytes.Equal(bytes.Join(bytes.Fields(served), nil), bytes.Join(bytes.Fields(fetched), nil)) {
+			t.Errorf("Expected %s to match the source:\n%s\n%s", name, served, fetched)
+		}
+	}
+
+	if stats := server.Stats(); stats.Logins != 1 || stats.Unauthorized != 0 {
+		t.Errorf("Expected a single login, got %+v", stats)
+	}
+}
+
+func TestServer_Login(t *testing.T) {
+	_, apiClient, _ := startServer(t, mockapi.Options{Username: "student@example.com", Password: "other"})
+
+	err := apiClient.Login(context.Background())
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected wrong password to be rejected with 401, got %v", err)
+	}
+}
+
+func TestServer_RequiresToken(t *testing.T) {
+	server, err := mockapi.New(mockapi.Options{SourceDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"/api/summary/library", "/api/summary/1/content", "/api/user/profile"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer unknown")
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 for %s, got %d", path, rec.Code)
+		}
+	}
+	if stats := server.Stats(); stats.Unauthorized != 3 {
+		t.Errorf("Expected 3 unauthorized requests, got %+v", stats)
+	}
+}
+
+func TestServer_TokenExpiry(t *testing.T) {
+	server, apiClient, _ := startServer(t, mockapi.Options{})
+
+	ctx := context.Background()
+	if err := apiClient.Login(ctx); err != nil {
+		t.Fatalf("Login() failed: %v", err)
+	}
+	if _, err := apiClient.GetSummary(ctx, "12"); err != nil {
+		t.Fatalf("GetSummary() failed: %v", err)
+	}
+
+	// The client still considers its token valid; the 401 makes it use the refresh token
+	server.ExpireTokens()
+	summary, err := apiClient.GetSummary(ctx, "12")
+	if err != nil {
+		t.Fatalf("GetSummary() after expiry failed: %v", err)
+	}
+	if summary.Title != "Biology" {
+		t.Errorf("Expected Biology, got %q", summary.Title)
+	}
+
+	if stats := server.Stats(); stats.Logins != 1 || stats.Refreshes != 1 || stats.Unauthorized != 1 {
+		t.Errorf("Expected one login, one refresh and one rejected request, got %+v", stats)
+	}
+}
+
+func TestServer_ErrorInjection(t *testing.T) {
+	t.Run("error rate", func(t *testing.T) {
+		server, apiClient, _ := startServer(t, mockapi.Options{ErrorRate: 1, ErrorStatus: http.StatusTooManyRequests, Seed: 1})
+
+		if err := apiClient.Login(context.Background()); err != nil {
+			t.Fatalf("Login() failed: %v", err)
+		}
+
+		_, err := apiClient.GetContent(context.Background(), "7")
+		var apiErr *client.APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests || apiErr.Attempts != 2 {
+			t.Errorf("Expected 429 after 2 attempts, got %v", err)
+		}
+		if stats := server.Stats(); stats.InjectedErrors != 2 {
+			t.Errorf("Expected 2 injected errors, got %+v", stats)
+		}
+	})
+
+	t.Run("failing book", func(t *testing.T) {
+		_, apiClient, _ := startServer(t, mockapi.Options{FailBooks: []string{"12"}})
+
+		var fetched []string
+		err := apiClient.FetchLibrary(context.Background(), client.LibraryFetchOptions{
+			OnBook: func(_ int, book *client.BookData) error {
+				fetched = append(fetched, book.ID)
+				return nil
+			},
+		})
+
+		var libErr *client.LibraryFetchError
+		if !errors.As(err, &libErr) || len(libErr.Failures) != 1 || libErr.Failures[0].ID != "12" {
+			t.Fatalf("Expected book 12 to fail, got %v", err)
+		}
+		if !slices.Equal(fetched, []string{"7"}) {
+			t.Errorf("Expected book 7 to be fetched, got %v", fetched)
+		}
+	})
+}
+
+func TestServer_Latency(t *testing.T) {
+	_, apiClient, _ := startServer(t, mockapi.Options{Latency: 200 * time.Millisecond})
+	apiClient.SetRetryPolicy(client.RetryPolicy{MaxAttempts: 1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if err := apiClient.Login(ctx); err == nil {
+		t.Error("Expected login to time out")
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("Expected the request to be cancelled, took %v", elapsed)
+	}
+}
@@ -153,6 +153,7 @@ type Book struct {
 	// Additional fields populated from separate JSON files
 	Chapters        []Chapter         `json:"-"` // From chapters.json
 	Content         *Content          `json:"-"` // From content.json
+	Notes           []Note            `json:"-"` // From list-notes.json
 	InlineObjectMap map[string]string `json:"-"` // Computed map of inline object ID to image URL
 }
 
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Note represents a user note from list-notes.json. A note is anchored to a position in the
+// document body when it has a start index, and otherwise to its chapter.
+type Note struct {
+	ID         int64       `json:"id"`
+	SummaryID  int64       `json:"summaryId"`
+	ChapterID  *int64      `json:"chapterId"`
+	Content    string      `json:"content"`
+	StartIndex *int64      `json:"startIndex"`
+	EndIndex   *int64      `json:"endIndex"`
+	CreatedAt  *CustomTime `json:"createdAt"`
+	UpdatedAt  *CustomTime `json:"updatedAt"`
+}
+
+// Text returns the note content with surrounding whitespace removed
+func (n Note) Text() string {
+	return strings.TrimSpace(n.Content)
+}
+
+// UnmarshalNotes unmarshals JSON data into notes
+func UnmarshalNotes(data []byte) ([]Note, error) {
+	var notes []Note
+	err := json.Unmarshal(data, &notes)
+	return notes, err
+}
@@ -68,6 +68,12 @@ func (p *BookParser) ParseBook(bookDirPath string) (*models.Book, error) {
 		return nil, fmt.Errorf("failed to parse chapters: %w", err)
 	}
 
+	// Parse notes
+	notesPath := filepath.Join(bookDirPath, "list-notes.json")
+	if err := p.parseNotes(notesPath, book); err != nil {
+		return nil, fmt.Errorf("failed to parse notes: %w", err)
+	}
+
 	// Parse content
 	if err := p.parseContent(contentPath, book); err != nil {
 		return nil, fmt.Errorf("failed to parse content: %w", err)
@@ -76,6 +82,21 @@ func (p *BookParser) ParseBook(bookDirPath string) (*models.Book, error) {
 	return book, nil
 }
 
+// AssembleBook combines book parts decoded elsewhere, e.g. fetched from the API, into a book
+// ready for streaming. The summary is copied and the inline object map is built as by ParseBook.
+func (p *BookParser) AssembleBook(summary *models.Book, chapters []models.Chapter, content *models.Content, notes []models.Note) *models.Book {
+	book := &models.Book{}
+	if summary != nil {
+		*book = *summary
+	}
+	book.Chapters = chapters
+	book.Content = content
+	book.Notes = notes
+
+	p.buildInlineObjectMap(book)
+	return book
+}
+
 // parseBookStreaming parses a book using streaming for large content files
 func (p *BookParser) parseBookStreaming(bookDirPath string) (*models.Book, error) {
 	book := &models.Book{}
@@ -111,6 +132,12 @@ func (p *BookParser) parseBookStreaming(bookDirPath string) (*models.Book, error
 		return nil, fmt.Errorf("failed to parse chapters: %w", err)
 	}
 
+	// Parse notes
+	notesPath := filepath.Join(bookDirPath, "list-notes.json")
+	if err := p.parseNotes(notesPath, book); err != nil {
+		return nil, fmt.Errorf("failed to parse notes: %w", err)
+	}
+
 	// Parse content with streaming
 	contentPath := filepath.Join(bookDirPath, "content.json")
 	if err := p.parseContentStreaming(contentPath, book); err != nil {
@@ -163,6 +190,30 @@ func (p *BookParser) parseChapters(filePath string, book *models.Book) error {
 	return nil
 }
 
+func (p *BookParser) parseNotes(filePath string, book *models.Book) error {
+	if _, err := os.Stat(filePath); err != nil {
+		if os.IsNotExist(err) {
+			return nil // Notes file is optional
+		}
+		return fmt.Errorf("failed to check notes file: %w", err)
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open notes file: %w", err)
+	}
+	defer file.Close()
+
+	var notes []models.Note
+	decoder := json.NewDecoder(file)
+	if err := decoder.Decode(&notes); err != nil {
+		return fmt.Errorf("failed to unmarshal notes: %w", err)
+	}
+
+	book.Notes = notes
+	return nil
+}
+
 func (p *BookParser) parseContent(filePath string, book *models.Book) error {
 	if _, err := os.Stat(filePath); err != nil {
 		if os.IsNotExist(err) {
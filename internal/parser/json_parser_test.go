@@ -171,6 +171,47 @@ func TestBookParser_ParseChapters(t *testing.T) {
 	}
 }
 
+func TestBookParser_ParseNotes(t *testing.T) {
+	parser := NewBookParser()
+	tempDir := testutils.CreateTempDir(t)
+
+	notesFile := filepath.Join(tempDir, "list-notes.json")
+	notesJSON := `[
+		{"id": 1, "summaryId": 123, "chapterId": 10, "content": "Check this", "createdAt": "2024-01-02 10:00:00"},
+		{"id": 2, "summaryId": 123, "content": " Key sentence ", "startIndex": 42, "endIndex": 58}
+	]`
+	if err := os.WriteFile(notesFile, []byte(notesJSON), 0644); err != nil {
+		t.Fatalf("Failed to write notes file: %v", err)
+	}
+
+	book := &models.Book{}
+	if err := parser.parseNotes(notesFile, book); err != nil {
+		t.Fatalf("BookParser.parseNotes() error = %v", err)
+	}
+
+	if len(book.Notes) != 2 {
+		t.Fatalf("Expected 2 notes, got %d", len(book.Notes))
+	}
+	if book.Notes[0].ChapterID == nil || *book.Notes[0].ChapterID != 10 {
+		t.Errorf("Expected first note to be anchored to chapter 10, got %v", book.Notes[0].ChapterID)
+	}
+	if book.Notes[1].StartIndex == nil || *book.Notes[1].StartIndex != 42 {
+		t.Errorf("Expected second note to start at index 42, got %v", book.Notes[1].StartIndex)
+	}
+	if got := book.Notes[1].Text(); got != "Key sentence" {
+		t.Errorf("Expected trimmed note text, got %q", got)
+	}
+
+	// The notes file is optional
+	book = &models.Book{}
+	if err := parser.parseNotes(filepath.Join(tempDir, "missing.json"), book); err != nil {
+		t.Errorf("Expected missing notes file to be skipped, got %v", err)
+	}
+	if book.Notes != nil {
+		t.Errorf("Expected no notes, got %v", book.Notes)
+	}
+}
+
 func TestBookParser_ParseContent(t *testing.T) {
 	parser := NewBookParser()
 
@@ -453,6 +494,54 @@ func TestBookParser_InlineObjectMapping(t *testing.T) {
 	}
 }
 
+func TestBookParser_AssembleBook(t *testing.T) {
+	parser := NewBookParser()
+
+	summary := &models.Book{
+		ID:     123,
+		Title:  "Fetched Book",
+		Images: []models.BookImage{{ObjectID: "kix.image", ImageURL: "/uploads/image.png"}},
+	}
+	content := &models.Content{
+		Document: &models.Document{
+			DocumentID: "doc-123",
+			InlineObjects: map[string]models.InlineObject{
+				"kix.inline": {
+					InlineObjectProperties: models.InlineObjectProperties{
+						EmbeddedObject: models.EmbeddedObject{
+							ImageProperties: &models.ImageProperties{ContentURI: "//cdn.example.com/inline.png"},
+						},
+					},
+				},
+			},
+		},
+	}
+	chapters := []models.Chapter{{ID: 1, Title: "Intro"}}
+	notes := []models.Note{{ID: 1, Content: "Remember"}}
+
+	book := parser.AssembleBook(summary, chapters, content, notes)
+
+	if book == summary {
+		t.Error("Expected the summary to be copied")
+	}
+	if book.Title != "Fetched Book" || len(book.Chapters) != 1 || len(book.Notes) != 1 || book.Content != content {
+		t.Errorf("Expected all parts to be assembled, got %+v", book)
+	}
+	if summary.Chapters != nil {
+		t.Error("Expected the summary to be left unchanged")
+	}
+
+	expectedMappings := map[string]string{
+		"kix.image":  "https://api.slimacademy.nl/uploads/image.png",
+		"kix.inline": "https://cdn.example.com/inline.png",
+	}
+	for objectID, expectedURL := range expectedMappings {
+		if actual := book.InlineObjectMap[objectID]; actual != expectedURL {
+			t.Errorf("Expected URL %s for object %s, got %q", expectedURL, objectID, actual)
+		}
+	}
+}
+
 // Test Academic Metadata Parsing
 func TestBookParser_AcademicMetadata(t *testing.T) {
 	parser := NewBookParser()
@@ -808,8 +897,8 @@ func TestBookParser_MetadataFileDetection(t *testing.T) {
 		filePath := filepath.Join(tempDir, file)
 		var content string
 		switch file {
-		case "chapters.json":
-			content = `[]` // Chapters should be an array
+		case "chapters.json", "list-notes.json":
+			content = `[]` // Chapters and notes should be arrays
 		case "content.json":
 			content = `{"documentId": "", "body": {"content": []}}` // Minimal valid content
 		default:
@@ -0,0 +1,88 @@
+// Package pipeline converts books fetched from the SlimAcademy API in one step, building the
+// book in memory and streaming it into the writers without writing source files first.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kjanat/slimacademy/internal/client"
+	"github.com/kjanat/slimacademy/internal/config"
+	"github.com/kjanat/slimacademy/internal/models"
+	"github.com/kjanat/slimacademy/internal/parser"
+	"github.com/kjanat/slimacademy/internal/source"
+	"github.com/kjanat/slimacademy/internal/streaming"
+	"github.com/kjanat/slimacademy/internal/writers"
+)
+
+// Options configures ConvertFromAPI
+type Options struct {
+	Formats []string
+	Config  *config.Config
+	Stream  streaming.StreamOptions
+
+	// CacheDir, when set, receives the raw API responses in the source directory layout, so the
+	// book can be converted again later with the regular parser
+	CacheDir string
+}
+
+// Result is a book converted by ConvertFromAPI
+type Result struct {
+	Book    *models.Book
+	Outputs []writers.OutputResult
+}
+
+// FetchBook fetches a book and assembles it in memory, including the inline object map. With
+// cacheDir set the raw responses are also written to cacheDir/<id>/.
+func FetchBook(ctx context.Context, apiClient *client.SlimClient, id, cacheDir string) (*models.Book, error) {
+	data, err := apiClient.FetchAllBookData(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch book %s: %w", id, err)
+	}
+
+	if cacheDir != "" {
+		if _, err := source.NewSourceManager(cacheDir).SaveBook(id, data, ""); err != nil {
+			return nil, fmt.Errorf("failed to cache book %s: %w", id, err)
+		}
+	}
+
+	return parser.NewBookParser().AssembleBook(data.Summary, data.Chapters, data.Content, data.Notes), nil
+}
+
+// Render streams a book through writers for all formats. The caller flushes the returned
+// MultiWriter and must close it.
+func Render(ctx context.Context, book *models.Book, formats []string, cfg *config.Config, opts streaming.StreamOptions) (*writers.MultiWriter, error) {
+	multiWriter, err := writers.NewMultiWriter(ctx, formats, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	streamer := streaming.NewStreamer(opts)
+	if err := multiWriter.ProcessEvents(streamer.Stream(ctx, book)); err != nil {
+		multiWriter.Close()
+		return nil, fmt.Errorf("failed to process events: %w", err)
+	}
+
+	return multiWriter, nil
+}
+
+// ConvertFromAPI fetches a book and converts it into all requested formats
+func ConvertFromAPI(ctx context.Context, apiClient *client.SlimClient, id string, opts Options) (*Result, error) {
+	book, err := FetchBook(ctx, apiClient, id, opts.CacheDir)
+	if err != nil {
+		return nil, err
+	}
+
+	multiWriter, err := Render(ctx, book, opts.Formats, opts.Config, opts.Stream)
+	if err != nil {
+		return nil, err
+	}
+	defer multiWriter.Close()
+
+	outputs, err := multiWriter.FlushAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversion results: %w", err)
+	}
+
+	return &Result{Book: book, Outputs: outputs}, nil
+}
@@ -0,0 +1,125 @@
+package pipeline
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kjanat/slimacademy/internal/client"
+	"github.com/kjanat/slimacademy/internal/config"
+	"github.com/kjanat/slimacademy/internal/streaming"
+)
+
+// bookResponses are the API responses for book 42
+var bookResponses = map[string]string{
+	"/api/summary/42":            `{"id": 42, "title": "Pipeline Book"}`,
+	"/api/summary/42/chapters":   `[{"id": 1, "title": "Intro"}]`,
+	"/api/summary/42/list-notes": `[]`,
+	"/api/summary/42/content": `{
+		"documentId": "doc-42",
+		"title": "Pipeline Book",
+		"inlineObjects": {
+			"kix.img": {"inlineObjectProperties": {"embeddedObject": {"imageProperties": {"contentUri": "/uploads/img.png"}}}}
+		},
+		"body": {"content": [
+			{"paragraph": {"elements": [{"textRun": {"content": "Streamed without source files\n"}}]}},
+			{"paragraph": {"elements": [{"inlineObjectElement": {"inlineObjectId": "kix.img"}}]}}
+		]}
+	}`,
+}
+
+// newTestClient returns a client logged in to a server serving bookResponses
+func newTestClient(t *testing.T) *client.SlimClient {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := bookResponses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	tokenDir := t.TempDir()
+	err := client.NewTokenStore(tokenDir).SaveToken(&client.TokenInfo{
+		Token:     "test-token",
+		TokenType: "Bearer",
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("Failed to save token: %v", err)
+	}
+
+	apiClient := client.NewSlimClient(tokenDir)
+	apiClient.SetBaseURL(server.URL)
+	apiClient.SetDriftHandler(nil)
+	return apiClient
+}
+
+func TestFetchBook(t *testing.T) {
+	book, err := FetchBook(context.Background(), newTestClient(t), "42", "")
+	if err != nil {
+		t.Fatalf("FetchBook() failed: %v", err)
+	}
+
+	if book.Title != "Pipeline Book" || len(book.Chapters) != 1 || book.Content == nil || book.Content.Document == nil {
+		t.Errorf("Expected assembled book, got %+v", book)
+	}
+	if url := book.InlineObjectMap["kix.img"]; url != "https://api.slimacademy.nl/uploads/img.png" {
+		t.Errorf("Expected inline object map to be built, got %q", url)
+	}
+}
+
+func TestConvertFromAPI(t *testing.T) {
+	cacheDir := t.TempDir()
+
+	result, err := ConvertFromAPI(context.Background(), newTestClient(t), "42", Options{
+		Formats:  []string{"markdown", "html"},
+		Config:   config.DefaultConfig(),
+		Stream:   streaming.DefaultStreamOptions(),
+		CacheDir: cacheDir,
+	})
+	if err != nil {
+		t.Fatalf("ConvertFromAPI() failed: %v", err)
+	}
+
+	if len(result.Outputs) != 2 {
+		t.Fatalf("Expected 2 outputs, got %d", len(result.Outputs))
+	}
+	for _, output := range result.Outputs {
+		if !strings.Contains(string(output.Data), "Streamed without source files") {
+			t.Errorf("Expected %s output to contain the book text:\n%s", output.Format, output.Data)
+		}
+	}
+
+	// The cached responses are the API payloads, readable by the regular parser
+	data, err := os.ReadFile(filepath.Join(cacheDir, "42", "content.json"))
+	if err != nil {
+		t.Fatalf("Expected cached content: %v", err)
+	}
+	if !strings.Contains(string(data), `"inlineObjects"`) {
+		t.Errorf("Expected raw content to be cached, got %s", data)
+	}
+	for _, name := range []string{"42.json", "chapters.json", "list-notes.json"} {
+		if _, err := os.Stat(filepath.Join(cacheDir, "42", name)); err != nil {
+			t.Errorf("Expected %s to be cached: %v", name, err)
+		}
+	}
+}
+
+func TestConvertFromAPI_FetchError(t *testing.T) {
+	apiClient := newTestClient(t)
+	apiClient.SetRetryPolicy(client.RetryPolicy{MaxAttempts: 1})
+
+	_, err := ConvertFromAPI(context.Background(), apiClient, "7", Options{Formats: []string{"markdown"}})
+	if err == nil || !strings.Contains(err.Error(), "failed to fetch book 7") {
+		t.Errorf("Expected fetch error, got %v", err)
+	}
+}
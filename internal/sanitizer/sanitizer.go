@@ -59,6 +59,7 @@ func (s *Sanitizer) Sanitize(book *models.Book) *Result {
 	s.sanitizeContent(sanitized)
 	s.sanitizeChapters(sanitized)
 	s.sanitizeImages(sanitized)
+	s.sanitizeNotes(sanitized)
 
 	return &Result{
 		Book:     sanitized,
@@ -171,6 +172,20 @@ func (s *Sanitizer) sanitizeChapters(book *models.Book) {
 	}
 }
 
+// sanitizeNotes cleans the text of user notes
+func (s *Sanitizer) sanitizeNotes(book *models.Book) {
+	for i := range book.Notes {
+		note := &book.Notes[i]
+		location := fmt.Sprintf("notes[%d]", i)
+
+		cleaned := s.sanitizeText(note.Content)
+		if cleaned != note.Content {
+			s.addWarning(location, "note content sanitized", note.Content, cleaned)
+			note.Content = cleaned
+		}
+	}
+}
+
 // sanitizeImages validates image references
 func (s *Sanitizer) sanitizeImages(book *models.Book) {
 	for i := range book.Images {
@@ -378,6 +393,12 @@ func (s *Sanitizer) deepCopyBook(book *models.Book) *models.Book {
 	// Deep copy Chapters
 	copy.Chapters = s.deepCopyChapters(book.Chapters)
 
+	// Copy Notes
+	if book.Notes != nil {
+		copy.Notes = make([]models.Note, len(book.Notes))
+		copy.Notes = append(copy.Notes[:0], book.Notes...)
+	}
+
 	// Deep copy Content
 	copy.Content = s.deepCopyContent(book.Content)
 
@@ -4,6 +4,7 @@
 package source
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -41,28 +42,37 @@ func (sm *SourceManager) SaveBookData(bookData *client.BookData) error {
 		return fmt.Errorf("failed to create book directory %s: %w", bookPath, err)
 	}
 
-	// Save summary data as {id}.json (matching existing format)
-	summaryFile := filepath.Join(bookPath, fmt.Sprintf("%s.json", bookData.ID))
-	if err := sm.saveJSONFile(summaryFile, bookData.Summary); err != nil {
-		return fmt.Errorf("failed to save summary file: %w", err)
-	}
-
-	// Save chapters.json
-	chaptersFile := filepath.Join(bookPath, "chapters.json")
-	if err := sm.saveJSONFile(chaptersFile, bookData.Chapters); err != nil {
-		return fmt.Errorf("failed to save chapters file: %w", err)
-	}
-
-	// Save content.json
-	contentFile := filepath.Join(bookPath, "content.json")
-	if err := sm.saveJSONFile(contentFile, bookData.Content); err != nil {
-		return fmt.Errorf("failed to save content file: %w", err)
-	}
+	return sm.saveBookFiles(bookPath, bookData)
+}
 
-	// Save list-notes.json (matching bash script naming)
-	notesFile := filepath.Join(bookPath, "list-notes.json")
-	if err := sm.saveJSONFile(notesFile, bookData.Notes); err != nil {
-		return fmt.Errorf("failed to save notes file: %w", err)
+// saveBookFiles writes the summary, chapters, content and notes files of a book into bookPath.
+// The API responses are archived as received; the typed models are only written for books
+// that were not fetched from the API.
+func (sm *SourceManager) saveBookFiles(bookPath string, bookData *client.BookData) error {
+	files := []struct {
+		name  string
+		part  string
+		raw   json.RawMessage
+		typed any
+	}{
+		// Summary as {id}.json and notes as list-notes.json, matching the bash script
+		{fmt.Sprintf("%s.json", bookData.ID), client.PartSummary, bookData.Raw.Summary, bookData.Summary},
+		{"chapters.json", client.PartChapters, bookData.Raw.Chapters, bookData.Chapters},
+		{"content.json", client.PartContent, bookData.Raw.Content, bookData.Content},
+		{"list-notes.json", client.PartNotes, bookData.Raw.Notes, bookData.Notes},
+	}
+
+	for _, f := range files {
+		path := filepath.Join(bookPath, f.name)
+		var err error
+		if len(f.raw) > 0 {
+			err = sm.saveRawJSONFile(path, f.raw)
+		} else {
+			err = sm.saveJSONFile(path, f.typed)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save %s file: %w", f.part, err)
+		}
 	}
 
 	return nil
@@ -92,6 +102,20 @@ func (sm *SourceManager) saveJSONFile(filepath string, data any) error {
 	return nil
 }
 
+// saveRawJSONFile saves JSON data pretty-printed without decoding it, so no field is lost
+func (sm *SourceManager) saveRawJSONFile(filepath string, data json.RawMessage) error {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return fmt.Errorf("failed to format JSON data: %w", err)
+	}
+
+	if err := os.WriteFile(filepath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", filepath, err)
+	}
+
+	return nil
+}
+
 // sanitizeDirectoryName cleans up a title to be used as a directory name
 func (sm *SourceManager) sanitizeDirectoryName(title string) string {
 	// Replace problematic characters with safe alternatives
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -23,52 +24,66 @@ func createTempDir(t *testing.T) string {
 	return dir
 }
 
-// Test helper to create mock book data
+// Test helper to create mock book data as decoded from API responses
 func createMockBookData(id, title string) *client.BookData {
-	return &client.BookData{
-		ID:    id,
-		Title: title,
-		Summary: map[string]any{
-			"id":          id,
+	summaryID, _ := strconv.Atoi(id)
+	raw := client.RawBookData{
+		Summary: mustMarshal(map[string]any{
+			"id":          summaryID,
 			"title":       title,
 			"description": fmt.Sprintf("Description for %s", title),
 			"author":      "Test Author",
-		},
-		Chapters: []map[string]any{
+		}),
+		Chapters: mustMarshal([]map[string]any{
 			{
-				"id":      "1",
-				"title":   "Chapter 1",
-				"content": "Content of chapter 1",
+				"id":        1,
+				"summaryId": summaryID,
+				"title":     "Chapter 1",
 			},
 			{
-				"id":      "2",
-				"title":   "Chapter 2",
-				"content": "Content of chapter 2",
-			},
-		},
-		Content: map[string]any{
-			"metadata": map[string]any{
-				"total_chapters": 2,
-				"word_count":     1500,
+				"id":        2,
+				"summaryId": summaryID,
+				"title":     "Chapter 2",
 			},
-			"structure": []map[string]any{
-				{"type": "chapter", "id": "1"},
-				{"type": "chapter", "id": "2"},
+		}),
+		Content: mustMarshal(map[string]any{
+			"documentId": "doc-" + id,
+			"revisionId": "rev-1",
+			"title":      title,
+			"body": map[string]any{
+				"content": []map[string]any{
+					{"startIndex": 0, "endIndex": 10},
+				},
 			},
-		},
-		Notes: []map[string]any{
+		}),
+		Notes: mustMarshal([]map[string]any{
 			{
-				"id":      "note1",
+				"id":      1,
 				"content": "First note",
 				"page":    1,
 			},
 			{
-				"id":      "note2",
+				"id":      2,
 				"content": "Second note",
 				"page":    15,
 			},
-		},
+		}),
+	}
+
+	book, err := client.DecodeBookData(id, raw)
+	if err != nil {
+		panic(err)
 	}
+	return book
+}
+
+// mustMarshal encodes test data as JSON
+func mustMarshal(v any) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return data
 }
 
 // Test helper to verify JSON file contents
@@ -145,10 +160,10 @@ func TestSourceManager_SaveBookData(t *testing.T) {
 
 		// Verify all expected files exist and have correct content
 		files := map[string]any{
-			"3631.json":       bookData.Summary,
-			"chapters.json":   bookData.Chapters,
-			"content.json":    bookData.Content,
-			"list-notes.json": bookData.Notes,
+			"3631.json":       bookData.Raw.Summary,
+			"chapters.json":   bookData.Raw.Chapters,
+			"content.json":    bookData.Raw.Content,
+			"list-notes.json": bookData.Raw.Notes,
 		}
 
 		for filename, expectedData := range files {
@@ -161,6 +176,39 @@ func TestSourceManager_SaveBookData(t *testing.T) {
 		}
 	})
 
+	t.Run("save book without raw responses", func(t *testing.T) {
+		bookData := createMockBookData("4242", "Typed Only")
+		bookData.Raw = client.RawBookData{}
+
+		if err := sm.SaveBookData(bookData); err != nil {
+			t.Fatalf("SaveBookData failed: %v", err)
+		}
+
+		// The typed models are written instead
+		raw := client.RawBookData{}
+		for name, dest := range map[string]*json.RawMessage{
+			"4242.json":       &raw.Summary,
+			"chapters.json":   &raw.Chapters,
+			"content.json":    &raw.Content,
+			"list-notes.json": &raw.Notes,
+		} {
+			data, err := os.ReadFile(filepath.Join(tempDir, "Typed Only", name))
+			if err != nil {
+				t.Fatalf("Expected file %s to exist: %v", name, err)
+			}
+			*dest = data
+		}
+
+		reread, err := client.DecodeBookData("4242", raw)
+		if err != nil {
+			t.Fatalf("Failed to decode saved book: %v", err)
+		}
+		if reread.Title != "Typed Only" || len(reread.Chapters) != 2 || len(reread.Notes) != 2 ||
+			reread.Content.Document == nil || reread.Content.Document.RevisionID != "rev-1" {
+			t.Errorf("Saved book does not match the models: %+v", reread)
+		}
+	})
+
 	t.Run("save book with problematic title", func(t *testing.T) {
 		bookData := createMockBookData("1234", "Book/With\\Bad:Chars*?\"<>|")
 
@@ -224,11 +272,11 @@ func TestSourceManager_SaveBookData(t *testing.T) {
 		}
 
 		// Modify and save again
-		bookData.Summary = map[string]any{
-			"id":          bookData.ID,
+		bookData.Raw.Summary = mustMarshal(map[string]any{
+			"id":          1111,
 			"title":       bookData.Title,
 			"description": "Updated description",
-		}
+		})
 
 		err = sm.SaveBookData(bookData)
 		if err != nil {
@@ -237,7 +285,7 @@ func TestSourceManager_SaveBookData(t *testing.T) {
 
 		// Verify the updated content
 		summaryPath := filepath.Join(tempDir, "Test Book", "1111.json")
-		verifyJSONFile(t, summaryPath, bookData.Summary)
+		verifyJSONFile(t, summaryPath, bookData.Raw.Summary)
 	})
 }
 
@@ -275,7 +323,7 @@ func TestSourceManager_SaveMultipleBooks(t *testing.T) {
 		// Create a mix of valid and invalid books
 		mixedBooks := []*client.BookData{
 			createMockBookData("2001", "Valid Book 1"),
-			{ID: "2002", Title: "Invalid Book", Summary: make(chan int)}, // Invalid JSON
+			{ID: "2002", Title: "Invalid Book", Raw: client.RawBookData{Summary: json.RawMessage(`{"id":`)}}, // Invalid JSON
 			createMockBookData("2003", "Valid Book 2"),
 		}
 
@@ -0,0 +1,300 @@
+package source
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/kjanat/slimacademy/internal/client"
+	"github.com/kjanat/slimacademy/internal/models"
+)
+
+// ManifestName is the per-book sync manifest file. It has no .json extension so that it is
+// never mistaken for the book metadata file.
+const ManifestName = ".sync-manifest"
+
+// BookManifest records what was fetched for a book, so that later syncs can tell whether the
+// book changed
+type BookManifest struct {
+	ID           string    `json:"id"`
+	Title        string    `json:"title"`
+	RevisionID   string    `json:"revisionId"`
+	ContentHash  string    `json:"contentHash"`
+	MetadataHash string    `json:"metadataHash,omitempty"` // Library entry fingerprint, see MetadataHash
+	FetchedAt    time.Time `json:"fetchedAt"`
+
+	Dir string `json:"-"` // Book directory, set when loading
+}
+
+// SyncStatus is the outcome of syncing a book
+type SyncStatus string
+
+const (
+	SyncAdded     SyncStatus = "added"
+	SyncUpdated   SyncStatus = "updated"
+	SyncUnchanged SyncStatus = "unchanged"
+)
+
+// SyncEntry is a book listed in a sync report
+type SyncEntry struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Dir   string `json:"dir"`
+}
+
+// SyncReport lists the books added, updated, left unchanged and removed by a sync
+type SyncReport struct {
+	Added     []SyncEntry `json:"added"`
+	Updated   []SyncEntry `json:"updated"`
+	Unchanged []SyncEntry `json:"unchanged"`
+	Removed   []SyncEntry `json:"removed"` // Books no longer in the library
+	Pruned    bool        `json:"pruned"`  // Whether removed books were deleted from disk
+}
+
+// SyncPlan compares the library with the books on disk. Fetch lists the library entries that
+// are new or whose metadata changed; the fetched books are then handed to SaveBook.
+type SyncPlan struct {
+	Fetch []client.LibrarySummary
+
+	sm        *SourceManager
+	manifests map[string]*BookManifest
+	metadata  map[string]string // Metadata hash per book ID
+	report    SyncReport
+}
+
+// MetadataHash fingerprints the fields of a library entry that change when the book is
+// republished. Reading progress is ignored.
+func MetadataHash(summary client.LibrarySummary) string {
+	stable := struct {
+		ID            int     `json:"id"`
+		Title         string  `json:"title"`
+		Description   string  `json:"description"`
+		AvailableDate *string `json:"availableDate"`
+		PageCount     *int    `json:"pageCount"`
+	}{summary.ID, summary.Title, summary.Description, summary.AvailableDate, summary.PageCount}
+
+	data, _ := json.Marshal(stable)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadManifests returns the sync manifests of all books in the source directory by book ID.
+// Books without a manifest are not included.
+func (sm *SourceManager) LoadManifests() (map[string]*BookManifest, error) {
+	books, err := sm.ListExistingBooks()
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := make(map[string]*BookManifest)
+	for _, name := range books {
+		bookPath := filepath.Join(sm.sourceDir, name)
+		data, err := os.ReadFile(filepath.Join(bookPath, ManifestName))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read sync manifest of %s: %w", name, err)
+		}
+
+		var manifest BookManifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("failed to parse sync manifest of %s: %w", name, err)
+		}
+		manifest.Dir = bookPath
+		manifests[manifest.ID] = &manifest
+	}
+
+	return manifests, nil
+}
+
+// PlanSync compares the library with the books on disk. The library does not report revisions,
+// so books are skipped by their metadata hash; SaveBook compares the content of fetched books.
+func (sm *SourceManager) PlanSync(library []client.LibrarySummary) (*SyncPlan, error) {
+	manifests, err := sm.LoadManifests()
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &SyncPlan{
+		sm:        sm,
+		manifests: manifests,
+		metadata:  make(map[string]string),
+	}
+
+	inLibrary := make(map[string]bool)
+	for _, summary := range library {
+		id := strconv.Itoa(summary.ID)
+		inLibrary[id] = true
+		hash := MetadataHash(summary)
+		plan.metadata[id] = hash
+
+		manifest := manifests[id]
+		if manifest == nil || manifest.MetadataHash != hash || !manifest.complete() {
+			plan.Fetch = append(plan.Fetch, summary)
+			continue
+		}
+		plan.report.Unchanged = append(plan.report.Unchanged, manifest.entry())
+	}
+
+	for id, manifest := range manifests {
+		if !inLibrary[id] {
+			plan.report.Removed = append(plan.report.Removed, manifest.entry())
+		}
+	}
+	slices.SortFunc(plan.report.Removed, func(a, b SyncEntry) int { return compareIDs(a.ID, b.ID) })
+
+	return plan, nil
+}
+
+// SaveBook writes a fetched book and records whether it was added, updated or unchanged.
+// It is not safe for concurrent use.
+func (p *SyncPlan) SaveBook(book *client.BookData) (SyncStatus, error) {
+	previous := p.manifests[book.ID]
+
+	dirName := book.ID
+	if previous != nil {
+		dirName = filepath.Base(previous.Dir)
+	}
+
+	manifest, err := p.sm.SaveBook(dirName, book, p.metadata[book.ID])
+	if err != nil {
+		return "", err
+	}
+
+	status := SyncUpdated
+	switch {
+	case previous == nil:
+		status = SyncAdded
+		p.report.Added = append(p.report.Added, manifest.entry())
+	case previous.RevisionID == manifest.RevisionID && previous.ContentHash == manifest.ContentHash &&
+		previous.Title == manifest.Title:
+		status = SyncUnchanged
+		p.report.Unchanged = append(p.report.Unchanged, manifest.entry())
+	default:
+		p.report.Updated = append(p.report.Updated, manifest.entry())
+	}
+
+	p.manifests[book.ID] = manifest
+	return status, nil
+}
+
+// Prune deletes the books that are no longer in the library
+func (p *SyncPlan) Prune() error {
+	for _, entry := range p.report.Removed {
+		if err := os.RemoveAll(entry.Dir); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", entry.Dir, err)
+		}
+		delete(p.manifests, entry.ID)
+	}
+	p.report.Pruned = true
+	return nil
+}
+
+// Report returns the sync report with every list sorted by book ID
+func (p *SyncPlan) Report() *SyncReport {
+	report := p.report
+	for _, entries := range [][]SyncEntry{report.Added, report.Updated, report.Unchanged} {
+		slices.SortFunc(entries, func(a, b SyncEntry) int { return compareIDs(a.ID, b.ID) })
+	}
+	return &report
+}
+
+// SaveBook writes a book into dirName below the source directory followed by its sync manifest.
+// The old manifest is removed first, so an interrupted write is fetched again by the next sync.
+func (sm *SourceManager) SaveBook(dirName string, book *client.BookData, metadataHash string) (*BookManifest, error) {
+	bookPath := filepath.Join(sm.sourceDir, dirName)
+	if err := os.MkdirAll(bookPath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create book directory %s: %w", bookPath, err)
+	}
+
+	manifestPath := filepath.Join(bookPath, ManifestName)
+	if err := os.Remove(manifestPath); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove sync manifest: %w", err)
+	}
+
+	if err := sm.saveBookFiles(bookPath, book); err != nil {
+		return nil, err
+	}
+
+	contentHash, err := hashContent(book)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash content: %w", err)
+	}
+
+	manifest := &BookManifest{
+		ID:           book.ID,
+		Title:        book.Title,
+		RevisionID:   revisionID(book.Content),
+		ContentHash:  contentHash,
+		MetadataHash: metadataHash,
+		FetchedAt:    time.Now().UTC(),
+		Dir:          bookPath,
+	}
+
+	if err := sm.saveJSONFile(manifestPath, manifest); err != nil {
+		return nil, fmt.Errorf("failed to save sync manifest: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// complete reports whether the files of the book are still on disk
+func (m *BookManifest) complete() bool {
+	for _, name := range []string{m.ID + ".json", "chapters.json", "content.json"} {
+		if _, err := os.Stat(filepath.Join(m.Dir, name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// entry returns the report entry for the book
+func (m *BookManifest) entry() SyncEntry {
+	return SyncEntry{ID: m.ID, Title: m.Title, Dir: m.Dir}
+}
+
+// revisionID returns the revisionId of fetched document content
+func revisionID(content *models.Content) string {
+	if content == nil || content.Document == nil {
+		return ""
+	}
+	return content.Document.RevisionID
+}
+
+// hashContent fingerprints the content of a book. The raw response is hashed in canonical form,
+// with sorted keys and no indentation, so that formatting differences do not count as changes.
+func hashContent(book *client.BookData) (string, error) {
+	var content any = book.Content
+	if len(book.Raw.Content) > 0 {
+		var decoded any
+		if err := json.Unmarshal(book.Raw.Content, &decoded); err != nil {
+			return "", err
+		}
+		content = decoded
+	}
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// compareIDs orders numeric book IDs numerically and anything else lexically
+func compareIDs(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	if errA == nil && errB == nil {
+		return na - nb
+	}
+	return cmp.Compare(a, b)
+}
@@ -0,0 +1,262 @@
+package source
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/kjanat/slimacademy/internal/client"
+)
+
+// syncBook creates book data with the given document revision
+func syncBook(id int, title, revision string) *client.BookData {
+	raw := createMockBookData(strconv.Itoa(id), title).Raw
+	raw.Content = mustMarshal(map[string]any{"documentId": "doc", "revisionId": revision})
+	book, err := client.DecodeBookData(strconv.Itoa(id), raw)
+	if err != nil {
+		panic(err)
+	}
+	return book
+}
+
+func librarySummary(id int, title string, pages int) client.LibrarySummary {
+	return client.LibrarySummary{ID: id, Title: title, PageCount: &pages}
+}
+
+func summaryIDs(summaries []client.LibrarySummary) []int {
+	var ids []int
+	for _, s := range summaries {
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+func entryIDs(entries []SyncEntry) []string {
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestMetadataHash(t *testing.T) {
+	base := librarySummary(1, "Anatomy", 10)
+
+	progress := 50
+	read := base
+	read.ReadProgress = &progress
+	if MetadataHash(read) != MetadataHash(base) {
+		t.Error("Expected reading progress to be ignored")
+	}
+
+	republished := librarySummary(1, "Anatomy", 12)
+	if MetadataHash(republished) == MetadataHash(base) {
+		t.Error("Expected page count change to change the hash")
+	}
+}
+
+func TestSourceManager_SaveBook(t *testing.T) {
+	sm := NewSourceManager(t.TempDir())
+
+	manifest, err := sm.SaveBook("7", syncBook(7, "Anatomy", "rev-1"), "meta")
+	if err != nil {
+		t.Fatalf("SaveBook() failed: %v", err)
+	}
+
+	for _, name := range []string{"7.json", "chapters.json", "content.json", "list-notes.json", ManifestName} {
+		if _, err := os.Stat(filepath.Join(manifest.Dir, name)); err != nil {
+			t.Errorf("Expected %s to be written: %v", name, err)
+		}
+	}
+
+	if manifest.RevisionID != "rev-1" || manifest.ContentHash == "" || manifest.MetadataHash != "meta" {
+		t.Errorf("Unexpected manifest %+v", manifest)
+	}
+	if manifest.FetchedAt.IsZero() {
+		t.Error("Expected fetch time to be recorded")
+	}
+
+	manifests, err := sm.LoadManifests()
+	if err != nil {
+		t.Fatalf("LoadManifests() failed: %v", err)
+	}
+	loaded := manifests["7"]
+	if loaded == nil {
+		t.Fatal("Expected manifest for book 7")
+	}
+	if loaded.Dir != manifest.Dir || loaded.ContentHash != manifest.ContentHash || loaded.Title != "Anatomy" {
+		t.Errorf("Loaded manifest %+v does not match saved %+v", loaded, manifest)
+	}
+
+	// Books without a manifest are not tracked
+	if err := sm.SaveBookData(createMockBookData("8", "Untracked")); err != nil {
+		t.Fatalf("SaveBookData() failed: %v", err)
+	}
+	manifests, _ = sm.LoadManifests()
+	if len(manifests) != 1 {
+		t.Errorf("Expected only tracked books, got %d manifests", len(manifests))
+	}
+}
+
+func TestSourceManager_PlanSync(t *testing.T) {
+	dir := t.TempDir()
+	sm := NewSourceManager(dir)
+
+	// Initial sync: everything is new
+	library := []client.LibrarySummary{
+		librarySummary(1, "Anatomy", 10),
+		librarySummary(2, "Biology", 20),
+		librarySummary(3, "Chemistry", 30),
+	}
+	plan, err := sm.PlanSync(library)
+	if err != nil {
+		t.Fatalf("PlanSync() failed: %v", err)
+	}
+	if got := summaryIDs(plan.Fetch); len(got) != 3 {
+		t.Fatalf("Expected all books to be fetched, got %v", got)
+	}
+	for _, s := range plan.Fetch {
+		status, err := plan.SaveBook(syncBook(s.ID, s.Title, "rev-1"))
+		if err != nil {
+			t.Fatalf("SaveBook() failed: %v", err)
+		}
+		if status != SyncAdded {
+			t.Errorf("Expected book %d to be added, got %s", s.ID, status)
+		}
+	}
+	if report := plan.Report(); len(report.Added) != 3 {
+		t.Errorf("Expected 3 added books, got %v", entryIDs(report.Added))
+	}
+
+	// Second sync: book 2 was republished, book 3 left the library, book 4 is new
+	library = []client.LibrarySummary{
+		librarySummary(1, "Anatomy", 10),
+		librarySummary(2, "Biology", 22),
+		librarySummary(4, "Dentistry", 40),
+	}
+	plan, err = sm.PlanSync(library)
+	if err != nil {
+		t.Fatalf("PlanSync() failed: %v", err)
+	}
+	got := summaryIDs(plan.Fetch)
+	if len(got) != 2 || got[0] != 2 || got[1] != 4 {
+		t.Fatalf("Expected books 2 and 4 to be fetched, got %v", got)
+	}
+
+	if _, err := plan.SaveBook(syncBook(2, "Biology", "rev-2")); err != nil {
+		t.Fatalf("SaveBook() failed: %v", err)
+	}
+	if _, err := plan.SaveBook(syncBook(4, "Dentistry", "rev-1")); err != nil {
+		t.Fatalf("SaveBook() failed: %v", err)
+	}
+
+	report := plan.Report()
+	if ids := entryIDs(report.Added); len(ids) != 1 || ids[0] != "4" {
+		t.Errorf("Expected book 4 added, got %v", ids)
+	}
+	if ids := entryIDs(report.Updated); len(ids) != 1 || ids[0] != "2" {
+		t.Errorf("Expected book 2 updated, got %v", ids)
+	}
+	if ids := entryIDs(report.Unchanged); len(ids) != 1 || ids[0] != "1" {
+		t.Errorf("Expected book 1 unchanged, got %v", ids)
+	}
+	if ids := entryIDs(report.Removed); len(ids) != 1 || ids[0] != "3" {
+		t.Errorf("Expected book 3 removed, got %v", ids)
+	}
+
+	// Removed books stay on disk until pruned
+	removedDir := report.Removed[0].Dir
+	if _, err := os.Stat(removedDir); err != nil {
+		t.Errorf("Expected removed book to be kept without prune: %v", err)
+	}
+	if err := plan.Prune(); err != nil {
+		t.Fatalf("Prune() failed: %v", err)
+	}
+	if _, err := os.Stat(removedDir); !os.IsNotExist(err) {
+		t.Errorf("Expected removed book to be deleted by prune, got %v", err)
+	}
+	if !plan.Report().Pruned {
+		t.Error("Expected report to record the prune")
+	}
+}
+
+func TestSourceManager_PlanSync_RefetchesIncompleteBooks(t *testing.T) {
+	sm := NewSourceManager(t.TempDir())
+	summary := librarySummary(5, "Ethics", 5)
+
+	manifest, err := sm.SaveBook("5", syncBook(5, "Ethics", "rev-1"), MetadataHash(summary))
+	if err != nil {
+		t.Fatalf("SaveBook() failed: %v", err)
+	}
+
+	plan, _ := sm.PlanSync([]client.LibrarySummary{summary})
+	if len(plan.Fetch) != 0 {
+		t.Fatalf("Expected complete unchanged book to be skipped, got %v", summaryIDs(plan.Fetch))
+	}
+
+	if err := os.Remove(filepath.Join(manifest.Dir, "content.json")); err != nil {
+		t.Fatal(err)
+	}
+	plan, _ = sm.PlanSync([]client.LibrarySummary{summary})
+	if len(plan.Fetch) != 1 {
+		t.Fatal("Expected book with missing content to be fetched again")
+	}
+
+	// Same revision and content after refetching counts as unchanged
+	status, err := plan.SaveBook(syncBook(5, "Ethics", "rev-1"))
+	if err != nil {
+		t.Fatalf("SaveBook() failed: %v", err)
+	}
+	if status != SyncUnchanged {
+		t.Errorf("Expected unchanged status, got %s", status)
+	}
+}
+
+func TestSourceManager_PlanSync_WithoutRevisions(t *testing.T) {
+	sm := NewSourceManager(t.TempDir())
+	summary := librarySummary(6, "Genetics", 8)
+	if _, err := sm.SaveBook("6", syncBook(6, "Genetics", "rev-1"), MetadataHash(summary)); err != nil {
+		t.Fatalf("SaveBook() failed: %v", err)
+	}
+
+	// The library entry carries no revision, so the unchanged entry is not fetched again
+	plan, err := sm.PlanSync([]client.LibrarySummary{summary})
+	if err != nil {
+		t.Fatalf("PlanSync() failed: %v", err)
+	}
+	if len(plan.Fetch) != 0 || len(plan.Report().Unchanged) != 1 {
+		t.Errorf("Expected the book to be reported unchanged, fetching %v", summaryIDs(plan.Fetch))
+	}
+
+	// A changed entry is fetched, and the same content is still reported unchanged
+	summary = librarySummary(6, "Genetics", 9)
+	plan, _ = sm.PlanSync([]client.LibrarySummary{summary})
+	if len(plan.Fetch) != 1 {
+		t.Fatal("Expected the book with changed metadata to be fetched")
+	}
+	if status, _ := plan.SaveBook(syncBook(6, "Genetics", "rev-1")); status != SyncUnchanged {
+		t.Errorf("Expected unchanged status for the same content, got %s", status)
+	}
+}
+
+func TestSyncPlan_SaveBook_KeepsExistingDirectory(t *testing.T) {
+	dir := t.TempDir()
+	sm := NewSourceManager(dir)
+
+	if _, err := sm.SaveBook("Anatomy Notes", syncBook(9, "Anatomy", "rev-1"), ""); err != nil {
+		t.Fatalf("SaveBook() failed: %v", err)
+	}
+
+	plan, _ := sm.PlanSync([]client.LibrarySummary{librarySummary(9, "Anatomy", 1)})
+	if _, err := plan.SaveBook(syncBook(9, "Anatomy", "rev-2")); err != nil {
+		t.Fatalf("SaveBook() failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "9")); !os.IsNotExist(err) {
+		t.Error("Expected the update to reuse the existing book directory")
+	}
+	if updated := plan.Report().Updated; len(updated) != 1 || updated[0].Dir != filepath.Join(dir, "Anatomy Notes") {
+		t.Errorf("Expected update in existing directory, got %+v", updated)
+	}
+}
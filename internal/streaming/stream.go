@@ -5,8 +5,10 @@ package streaming
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"iter"
+	"slices"
 	"strings"
 	"unique"
 
@@ -40,6 +42,17 @@ const (
 	EndFormatting
 	Text
 	Image
+
+	PageBreak
+	HorizontalRule
+	SectionBreak
+	AutoText
+	StartHeader
+	EndHeader
+	StartFooter
+	EndFooter
+	Note
+	TableOfContents
 )
 
 // String returns the string representation of EventKind
@@ -85,6 +98,26 @@ func (k EventKind) String() string {
 		return "Text"
 	case Image:
 		return "Image"
+	case PageBreak:
+		return "PageBreak"
+	case HorizontalRule:
+		return "HorizontalRule"
+	case SectionBreak:
+		return "SectionBreak"
+	case AutoText:
+		return "AutoText"
+	case StartHeader:
+		return "StartHeader"
+	case EndHeader:
+		return "EndHeader"
+	case StartFooter:
+		return "StartFooter"
+	case EndFooter:
+		return "EndFooter"
+	case Note:
+		return "Note"
+	case TableOfContents:
+		return "TableOfContents"
 	default:
 		return "Unknown"
 	}
@@ -131,38 +164,96 @@ type Event struct {
 	AnchorID    string
 
 	// List
-	ListLevel   int
-	ListOrdered bool
+	ListLevel   int       // Nesting depth of the list, 0 for top-level lists
+	ListOrdered bool      // List items are numbered
+	ListGlyph   ListGlyph // Marker style of the list items
+	ListStart   int       // Number of the first item of an ordered list
 
 	// Table
-	TableColumns int
-	TableRows    int
+	TableColumns      int
+	TableRows         int
+	TableColumnWidths []float64 // Column widths in points; 0 means evenly distributed
+	TableHasSpans     bool      // At least one cell spans several rows or columns
+	TableHasBlocks    bool      // At least one cell holds several paragraphs, a list or a nested table
+	CellRowSpan       int       // Rows covered by a table cell; 0 or 1 means no merge
+	CellColSpan       int       // Columns covered by a table cell; 0 or 1 means no merge
+	TableHeader       bool      // The row started by StartTableRow is a header row
 
 	// Formatting
 	Style   StyleFlags
 	LinkURL string
 
+	// Breaks
+	SectionNewPage bool   // The section started by a SectionBreak begins on a new page
+	AutoTextType   string // Kind of generated text, such as PAGE_NUMBER or PAGE_COUNT
+
+	// Notes
+	NoteID   int64  // ID of the user note
+	NoteText string // Text of the user note
+
+	// Table of contents
+	TOCEntries []TOCEntry // Headings listed by a TableOfContents event, in document order
+	TOCNested  bool       // The entries are nested by level instead of listed flat
+
 	// Content
 	TextContent string
 	ImageURL    string
 	ImageAlt    string
 }
 
+// ListGlyph identifies the marker style of a list
+type ListGlyph int
+
+const (
+	GlyphDisc ListGlyph = iota
+	GlyphCircle
+	GlyphSquare
+	GlyphDecimal
+	GlyphLowerAlpha
+	GlyphUpperAlpha
+	GlyphLowerRoman
+	GlyphUpperRoman
+)
+
+// Ordered reports whether the glyph numbers list items
+func (g ListGlyph) Ordered() bool {
+	return g >= GlyphDecimal
+}
+
+// TableHeaderDetection selects how table header rows are detected when the document marks none
+type TableHeaderDetection int
+
+const (
+	// HeaderRowsMarked only treats rows marked as table headers in the document as header rows
+	HeaderRowsMarked TableHeaderDetection = iota
+	// HeaderRowsBoldFirstRow also treats a first row whose text is entirely bold as a header row
+	HeaderRowsBoldFirstRow
+	// HeaderRowsFirstRow always treats the first row of a multi-row table as a header row
+	HeaderRowsFirstRow
+)
+
 // StreamOptions configures event streaming behavior
 type StreamOptions struct {
-	ChunkSize    int  // For chunking huge paragraphs with bytes.Lines
-	MemoryLimit  int  // Maximum memory usage in bytes
-	SkipEmpty    bool // Skip empty content
-	SanitizeText bool // Apply text sanitization
+	ChunkSize    int                  // For chunking huge paragraphs with bytes.Lines
+	MemoryLimit  int                  // Maximum memory usage in bytes
+	SkipEmpty    bool                 // Skip empty content
+	SanitizeText bool                 // Apply text sanitization
+	TableHeaders TableHeaderDetection // Header row detection for tables without marked header rows
+
+	HeadersFooters bool // Stream the document's default header and footer after StartDoc
+
+	TOC TOCOptions // Table of contents generation
 }
 
-// DefaultStreamOptions returns a StreamOptions struct with recommended default settings for chunk size, memory limit, skipping empty content, and text sanitization.
+// DefaultStreamOptions returns a StreamOptions struct with recommended default settings for chunk size, memory limit, skipping empty content, text sanitization and table header detection.
 func DefaultStreamOptions() StreamOptions {
 	return StreamOptions{
 		ChunkSize:    1024,
 		MemoryLimit:  100 * 1024 * 1024, // 100MB
 		SkipEmpty:    true,
 		SanitizeText: true,
+		TableHeaders: HeaderRowsBoldFirstRow,
+		TOC:          DefaultTOCOptions(),
 	}
 }
 
@@ -172,26 +263,19 @@ type Streamer struct {
 	sanitizer      *sanitizer.Sanitizer
 	slugCache      map[string]int // For duplicate slug detection
 	collectedTOC   []TOCEntry     // Collected headings for TOC generation
-	tocHeadingText []string       // Text patterns that indicate TOC placeholder
+	tocPlaceholder bool           // A heading of the document is a TOC placeholder
 	tocEmitted     bool           // Track if TOC has already been emitted
-}
-
-// TOCEntry represents a heading in the table of contents
-type TOCEntry struct {
-	Level    int
-	Text     string
-	AnchorID string
+	notes          *noteAnchors   // Notes of the book being streamed
 }
 
 // NewStreamer returns a new Streamer configured with the provided streaming options.
 func NewStreamer(opts StreamOptions) *Streamer {
 	return &Streamer{
-		options:        opts,
-		sanitizer:      sanitizer.NewSanitizer(),
-		slugCache:      make(map[string]int),
-		collectedTOC:   make([]TOCEntry, 0),
-		tocHeadingText: []string{"inhoudsopgave", "table of contents", "contents", "index"},
-		tocEmitted:     false,
+		options:      opts,
+		sanitizer:    sanitizer.NewSanitizer(),
+		slugCache:    make(map[string]int),
+		collectedTOC: make([]TOCEntry, 0),
+		tocEmitted:   false,
 	}
 }
 
@@ -208,8 +292,10 @@ func (s *Streamer) Stream(ctx context.Context, book *models.Book) iter.Seq[Event
 		}
 
 		// First pass: collect all headings for TOC generation
-		s.collectAllHeadings(ctx, sanitizedBook)
+		s.slugCache = make(map[string]int)
+		s.collectAllHeadings(sanitizedBook)
 		s.tocEmitted = false // Reset TOC emission state for this stream
+		s.notes = newNoteAnchors(sanitizedBook.Notes)
 
 		// Collect image URLs
 		var imageURLs []string
@@ -237,6 +323,14 @@ func (s *Streamer) Stream(ctx context.Context, book *models.Book) iter.Seq[Event
 			return
 		}
 
+		if s.options.HeadersFooters && !s.processHeadersFooters(ctx, sanitizedBook, yield) {
+			return
+		}
+
+		if s.options.TOC.AutoInsert && !s.tocPlaceholder && !s.yieldTOC(ctx, yield) {
+			return
+		}
+
 		// Process content with memory management
 		s.processContent(ctx, sanitizedBook, yield)
 
@@ -248,7 +342,7 @@ func (s *Streamer) Stream(ctx context.Context, book *models.Book) iter.Seq[Event
 // processContent handles the main document content
 func (s *Streamer) processContent(ctx context.Context, book *models.Book, yield func(Event) bool) {
 	chapterMap := s.buildChapterMap(book.Chapters)
-	inListBlock := false
+	lists := &listBlock{}
 
 	// Handle different content types
 	var content []models.StructuralElement
@@ -257,7 +351,9 @@ func (s *Streamer) processContent(ctx context.Context, book *models.Book, yield
 			content = book.Content.Document.Body.Content
 		} else if book.Content.Chapters != nil {
 			// For chapter-based content, create synthetic paragraphs
-			s.processChapters(ctx, book.Content.Chapters, yield)
+			if s.processChapters(ctx, book.Content.Chapters, yield) {
+				s.processRemainingNotes(ctx, yield)
+			}
 			return
 		}
 	}
@@ -273,34 +369,37 @@ func (s *Streamer) processContent(ctx context.Context, book *models.Book, yield
 		}
 
 		if element.Table != nil {
-			if inListBlock {
-				if !s.yieldEvent(ctx, yield, Event{Kind: EndList}) {
-					return
-				}
-				inListBlock = false
+			if !s.closeLists(ctx, lists, yield) {
+				return
 			}
-			if !s.processTable(ctx, element.Table, yield) {
+			if !s.processTable(ctx, element.Table, book, yield) {
 				return
 			}
 		} else if element.Paragraph != nil {
-			if !s.processParagraph(ctx, element.Paragraph, book, chapterMap, &inListBlock, yield) {
+			s.notes.anchorBefore(element.EndIndex)
+			if !s.processParagraph(ctx, element.Paragraph, book, chapterMap, lists, yield) {
+				return
+			}
+		} else if element.SectionBreak != nil && i > 0 {
+			// Every document body opens with a section break, which is not streamed
+			if !s.processSectionBreak(ctx, element.SectionBreak, lists, yield) {
 				return
 			}
 		}
 	}
 
 	// End any remaining list block
-	if inListBlock {
-		s.yieldEvent(ctx, yield, Event{Kind: EndList})
+	if s.closeLists(ctx, lists, yield) {
+		s.processRemainingNotes(ctx, yield)
 	}
 }
 
 // processParagraph handles paragraph elements with chunking for large content
-func (s *Streamer) processParagraph(ctx context.Context, paragraph *models.Paragraph, book *models.Book, chapterMap map[string]*models.Chapter, inListBlock *bool, yield func(Event) bool) bool {
+func (s *Streamer) processParagraph(ctx context.Context, paragraph *models.Paragraph, book *models.Book, chapterMap map[string]*models.Chapter, lists *listBlock, yield func(Event) bool) bool {
 	// Handle chapter headings
 	if paragraph.ParagraphStyle.HeadingID != nil {
 		if chapter, exists := chapterMap[*paragraph.ParagraphStyle.HeadingID]; exists {
-			return s.processChapterHeading(ctx, chapter, inListBlock, yield)
+			return s.processChapterHeading(ctx, chapter, lists, yield)
 		}
 	}
 
@@ -308,50 +407,223 @@ func (s *Streamer) processParagraph(ctx context.Context, paragraph *models.Parag
 	text := s.extractParagraphText(paragraph)
 	hasInlineObjects := s.hasInlineObjects(paragraph)
 
-	if s.options.SkipEmpty && text == "" && !hasInlineObjects {
-		return true
+	if s.options.SkipEmpty && text == "" && !hasInlineObjects && !s.hasAutoText(paragraph) {
+		// Paragraphs holding nothing but page breaks or horizontal rules stream as block-level breaks
+		return s.processBreaks(ctx, paragraph, lists, yield)
 	}
 
 	// Handle different paragraph types
 	switch {
 	case s.isHeading(paragraph):
-		return s.processHeading(ctx, paragraph, text, inListBlock, yield)
+		return s.processHeading(ctx, paragraph, text, lists, yield)
 	case paragraph.Bullet != nil:
-		return s.processListItem(ctx, paragraph, book, inListBlock, yield)
+		return s.processListItem(ctx, paragraph, book, lists, yield)
 	default:
-		return s.processRegularParagraph(ctx, paragraph, book, inListBlock, yield)
+		return s.processRegularParagraph(ctx, paragraph, book, lists, yield)
 	}
 }
 
+// processBreaks streams the page breaks and horizontal rules of an otherwise empty paragraph
+func (s *Streamer) processBreaks(ctx context.Context, paragraph *models.Paragraph, lists *listBlock, yield func(Event) bool) bool {
+	for _, element := range paragraph.Elements {
+		var kind EventKind
+		switch {
+		case element.PageBreak != nil:
+			kind = PageBreak
+		case element.HorizontalRule != nil:
+			kind = HorizontalRule
+		default:
+			continue
+		}
+		if !s.closeLists(ctx, lists, yield) {
+			return false
+		}
+		if !s.yieldEvent(ctx, yield, Event{Kind: kind}) {
+			return false
+		}
+	}
+	return true
+}
+
+// processSectionBreak closes open lists and emits a section break
+func (s *Streamer) processSectionBreak(ctx context.Context, sectionBreak *models.SectionBreak, lists *listBlock, yield func(Event) bool) bool {
+	if !s.closeLists(ctx, lists, yield) {
+		return false
+	}
+	return s.yieldEvent(ctx, yield, Event{
+		Kind:           SectionBreak,
+		SectionNewPage: sectionBreak.SectionStyle.SectionType == "NEXT_PAGE",
+	})
+}
+
+// processHeadersFooters streams the document's default header and footer, each wrapped in its own start and end events
+func (s *Streamer) processHeadersFooters(ctx context.Context, book *models.Book, yield func(Event) bool) bool {
+	if book.Content == nil || book.Content.Document == nil {
+		return true
+	}
+	doc := book.Content.Document
+
+	if header, ok := doc.Headers[doc.DocumentStyle.DefaultHeaderID]; ok {
+		if !s.processRunningBlock(ctx, header.Content, book, StartHeader, EndHeader, yield) {
+			return false
+		}
+	}
+	if footer, ok := doc.Footers[doc.DocumentStyle.DefaultFooterID]; ok {
+		if !s.processRunningBlock(ctx, footer.Content, book, StartFooter, EndFooter, yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// processRunningBlock streams the content of a header or footer between start and end events.
+// Headers and footers without text, images or generated text are skipped.
+func (s *Streamer) processRunningBlock(ctx context.Context, content []models.StructuralElement, book *models.Book, start, end EventKind, yield func(Event) bool) bool {
+	empty := true
+	for _, element := range content {
+		if paragraph := element.Paragraph; paragraph != nil &&
+			(s.extractParagraphText(paragraph) != "" || s.hasInlineObjects(paragraph) || s.hasAutoText(paragraph)) {
+			empty = false
+			break
+		}
+	}
+	if empty {
+		return true
+	}
+
+	if !s.yieldEvent(ctx, yield, Event{Kind: start}) {
+		return false
+	}
+	if !s.processCellContent(ctx, content, book, yield) {
+		return false
+	}
+	return s.yieldEvent(ctx, yield, Event{Kind: end})
+}
+
+// noteAnchors assigns the notes of a book to the blocks they annotate. Notes with a start index
+// belong to the first paragraph that does not end before it; other notes belong to the heading of
+// their chapter.
+type noteAnchors struct {
+	positioned []models.Note           // Notes with a start index, in document order
+	byChapter  map[int64][]models.Note // Notes without a start index by chapter ID
+	unanchored []models.Note           // Notes with neither a start index nor a chapter
+	pending    []models.Note           // Notes to emit at the end of the next block
+}
+
+// newNoteAnchors sorts notes by the way they are anchored
+func newNoteAnchors(notes []models.Note) *noteAnchors {
+	anchors := &noteAnchors{byChapter: make(map[int64][]models.Note)}
+	for _, note := range notes {
+		switch {
+		case note.Text() == "":
+			continue
+		case note.StartIndex != nil:
+			anchors.positioned = append(anchors.positioned, note)
+		case note.ChapterID != nil:
+			anchors.byChapter[*note.ChapterID] = append(anchors.byChapter[*note.ChapterID], note)
+		default:
+			anchors.unanchored = append(anchors.unanchored, note)
+		}
+	}
+	slices.SortStableFunc(anchors.positioned, func(a, b models.Note) int {
+		return cmp.Compare(*a.StartIndex, *b.StartIndex)
+	})
+	return anchors
+}
+
+// anchorBefore queues the positioned notes that start before the end index of the next block
+func (a *noteAnchors) anchorBefore(endIndex int64) {
+	if a == nil {
+		return
+	}
+	n := 0
+	for n < len(a.positioned) && *a.positioned[n].StartIndex < endIndex {
+		n++
+	}
+	a.pending = append(a.pending, a.positioned[:n]...)
+	a.positioned = a.positioned[n:]
+}
+
+// anchorChapter queues the notes of a chapter for its heading
+func (a *noteAnchors) anchorChapter(chapterID int64) {
+	if a == nil {
+		return
+	}
+	a.pending = append(a.pending, a.byChapter[chapterID]...)
+	delete(a.byChapter, chapterID)
+}
+
+// remaining returns the notes that were never anchored, ordered by ID for stable output
+func (a *noteAnchors) remaining() []models.Note {
+	if a == nil {
+		return nil
+	}
+	notes := append(a.pending, a.positioned...)
+	notes = append(notes, a.unanchored...)
+	for _, chapterNotes := range a.byChapter {
+		notes = append(notes, chapterNotes...)
+	}
+	slices.SortStableFunc(notes, func(x, y models.Note) int {
+		return cmp.Compare(x.ID, y.ID)
+	})
+	*a = noteAnchors{}
+	return notes
+}
+
+// yieldNotes emits the notes queued for the block being closed
+func (s *Streamer) yieldNotes(ctx context.Context, yield func(Event) bool) bool {
+	if s.notes == nil {
+		return true
+	}
+	for _, note := range s.notes.pending {
+		if !s.yieldEvent(ctx, yield, Event{Kind: Note, NoteID: note.ID, NoteText: note.Text()}) {
+			return false
+		}
+	}
+	s.notes.pending = s.notes.pending[:0]
+	return true
+}
+
+// processRemainingNotes emits the notes without a streamed anchor in a closing paragraph
+func (s *Streamer) processRemainingNotes(ctx context.Context, yield func(Event) bool) bool {
+	notes := s.notes.remaining()
+	if len(notes) == 0 {
+		return true
+	}
+	if !s.yieldEvent(ctx, yield, Event{Kind: StartParagraph}) {
+		return false
+	}
+	s.notes.pending = notes
+	if !s.yieldNotes(ctx, yield) {
+		return false
+	}
+	return s.yieldEvent(ctx, yield, Event{Kind: EndParagraph})
+}
+
 // processChapterHeading handles chapter-based headings
-func (s *Streamer) processChapterHeading(ctx context.Context, chapter *models.Chapter, inListBlock *bool, yield func(Event) bool) bool {
+func (s *Streamer) processChapterHeading(ctx context.Context, chapter *models.Chapter, lists *listBlock, yield func(Event) bool) bool {
 	trimmedTitle := strings.TrimSpace(chapter.Title)
 	if s.options.SkipEmpty && trimmedTitle == "" {
 		return true
 	}
 
-	if *inListBlock {
-		if !s.yieldEvent(ctx, yield, Event{Kind: EndList}) {
-			return false
-		}
-		*inListBlock = false
+	if !s.closeLists(ctx, lists, yield) {
+		return false
 	}
 
+	s.notes.anchorChapter(chapter.ID)
 	return s.yieldHeading(ctx, 2, trimmedTitle, yield)
 }
 
 // processHeading handles regular headings
-func (s *Streamer) processHeading(ctx context.Context, paragraph *models.Paragraph, text string, inListBlock *bool, yield func(Event) bool) bool {
+func (s *Streamer) processHeading(ctx context.Context, paragraph *models.Paragraph, text string, lists *listBlock, yield func(Event) bool) bool {
 	trimmedText := strings.TrimSpace(text)
 	if s.options.SkipEmpty && trimmedText == "" {
 		return true
 	}
 
-	if *inListBlock {
-		if !s.yieldEvent(ctx, yield, Event{Kind: EndList}) {
-			return false
-		}
-		*inListBlock = false
+	if !s.closeLists(ctx, lists, yield) {
+		return false
 	}
 
 	level := s.getHeadingLevel(paragraph.ParagraphStyle.NamedStyleType)
@@ -376,7 +648,6 @@ func (s *Streamer) yieldHeading(ctx context.Context, level int, text string, yie
 			Kind:        Text,
 			TextContent: text,
 		},
-		{Kind: EndHeading},
 	}
 
 	// Emit the heading events
@@ -385,200 +656,160 @@ func (s *Streamer) yieldHeading(ctx context.Context, level int, text string, yie
 			return false
 		}
 	}
+	if !s.yieldNotes(ctx, yield) || !s.yieldEvent(ctx, yield, Event{Kind: EndHeading}) {
+		return false
+	}
 
-	// If this is a TOC placeholder, emit the collected TOC as a list (only once)
+	// If this is a TOC placeholder, emit the collected TOC (only once)
 	if isTOCPlaceholder && !s.tocEmitted {
-		s.tocEmitted = true
-		return s.yieldTOCList(ctx, yield)
+		return s.yieldTOC(ctx, yield)
 	}
 
 	return true
 }
 
-// isTOCHeading checks if the heading text indicates a table of contents placeholder
-func (s *Streamer) isTOCHeading(text string) bool {
-	lowerText := strings.ToLower(strings.TrimSpace(text))
-	for _, pattern := range s.tocHeadingText {
-		if lowerText == pattern {
-			return true
-		}
-	}
-	return false
-}
-
-// collectAllHeadings performs a preliminary pass to collect all headings for TOC generation
-// First pass: collect all headings to generate a complete TOC
-// This enables dynamic TOC insertion at placeholder locations
-func (s *Streamer) collectAllHeadings(ctx context.Context, book *models.Book) {
-	s.collectedTOC = make([]TOCEntry, 0)  // Reset TOC collection
-	seenHeadings := make(map[string]bool) // Track seen headings to prevent duplicates
-
-	chapterMap := s.buildChapterMap(book.Chapters)
-
-	// Handle different content types
-	var content []models.StructuralElement
-	if book.Content != nil {
-		if book.Content.Document != nil {
-			content = book.Content.Document.Body.Content
-		} else if book.Content.Chapters != nil {
-			// For chapter-based content, collect from chapters
-			s.collectChapterHeadings(book.Content.Chapters, seenHeadings)
-			return
-		}
-	}
-
-	// Process document content to collect headings
-	for _, element := range content {
-		if element.Paragraph != nil {
-			// Handle chapter headings
-			if element.Paragraph.ParagraphStyle.HeadingID != nil {
-				if chapter, exists := chapterMap[*element.Paragraph.ParagraphStyle.HeadingID]; exists {
-					text := strings.TrimSpace(chapter.Title)
-					if text != "" && !s.isTOCHeading(text) && !seenHeadings[text] {
-						anchorID := utils.SlugifyWithCache(text, s.slugCache)
-						s.collectedTOC = append(s.collectedTOC, TOCEntry{
-							Level:    2,
-							Text:     text,
-							AnchorID: anchorID,
-						})
-						seenHeadings[text] = true
-					}
-				}
-			}
-
-			// Handle regular headings
-			if s.isHeading(element.Paragraph) {
-				text := s.extractParagraphText(element.Paragraph)
-				text = strings.TrimSpace(text)
-				if text != "" && !s.isTOCHeading(text) && !seenHeadings[text] {
-					level := s.getHeadingLevel(element.Paragraph.ParagraphStyle.NamedStyleType)
-					anchorID := utils.SlugifyWithCache(text, s.slugCache)
-					s.collectedTOC = append(s.collectedTOC, TOCEntry{
-						Level:    level,
-						Text:     text,
-						AnchorID: anchorID,
-					})
-					seenHeadings[text] = true
-				}
-			}
-		}
-	}
-}
-
-// collectChapterHeadings collects headings from chapter-based content
-func (s *Streamer) collectChapterHeadings(chapters []models.Chapter, seenHeadings map[string]bool) {
-	for _, chapter := range chapters {
-		s.collectChapterHeadingsRecursive(&chapter, 2, seenHeadings)
-	}
+// listBlock tracks the lists opened by consecutive list item paragraphs. Every open list has an
+// open item, so items at a deeper nesting level are emitted inside it.
+type listBlock struct {
+	listID string
+	levels []int64         // Bullet nesting level of each open list, outermost first
+	counts map[listKey]int // Items emitted per list level, so interrupted lists keep numbering
 }
 
-// collectChapterHeadingsRecursive recursively collects headings from chapters
-func (s *Streamer) collectChapterHeadingsRecursive(chapter *models.Chapter, depth int, seenHeadings map[string]bool) {
-	text := strings.TrimSpace(chapter.Title)
-	if text != "" && !s.isTOCHeading(text) && !seenHeadings[text] {
-		anchorID := utils.SlugifyWithCache(text, s.slugCache)
-		s.collectedTOC = append(s.collectedTOC, TOCEntry{
-			Level:    depth,
-			Text:     text,
-			AnchorID: anchorID,
-		})
-		seenHeadings[text] = true
-	}
-
-	// Process subchapters recursively
-	for _, subChapter := range chapter.SubChapters {
-		s.collectChapterHeadingsRecursive(&subChapter, depth+1, seenHeadings)
-	}
+// listKey identifies one nesting level of a document list
+type listKey struct {
+	listID string
+	level  int64
 }
 
-// yieldTOCList emits the collected table of contents as a list structure
-func (s *Streamer) yieldTOCList(ctx context.Context, yield func(Event) bool) bool {
-	if len(s.collectedTOC) == 0 {
-		return true // No TOC entries to emit
+// processListItem handles bullet list items, opening and closing nested lists as the nesting level changes
+func (s *Streamer) processListItem(ctx context.Context, paragraph *models.Paragraph, book *models.Book, lists *listBlock, yield func(Event) bool) bool {
+	bullet := paragraph.Bullet
+	level := int64(0)
+	if bullet.NestingLevel != nil {
+		level = max(0, *bullet.NestingLevel)
 	}
 
-	// Start the TOC list
-	if !s.yieldEvent(ctx, yield, Event{Kind: StartList, ListOrdered: false}) {
-		return false
+	// Items of another list start a new list block
+	if len(lists.levels) > 0 && lists.listID != bullet.ListID {
+		if !s.closeLists(ctx, lists, yield) {
+			return false
+		}
 	}
 
-	// Emit each TOC entry as a list item with a link
-	for _, entry := range s.collectedTOC {
-		// Start list item
-		if !s.yieldEvent(ctx, yield, Event{Kind: StartListItem}) {
+	// Close lists nested deeper than this item
+	for n := len(lists.levels); n > 0 && lists.levels[n-1] > level; n-- {
+		if !s.closeList(ctx, lists, yield) {
 			return false
 		}
+	}
 
-		// Start link formatting
-		if !s.yieldEvent(ctx, yield, Event{
-			Kind:    StartFormatting,
-			Style:   Link,
-			LinkURL: "#" + entry.AnchorID,
-		}) {
+	if n := len(lists.levels); n > 0 && lists.levels[n-1] == level {
+		if !s.yieldEvent(ctx, yield, Event{Kind: EndListItem}) {
 			return false
 		}
-
-		// Link text
+	} else {
+		if lists.counts == nil {
+			lists.counts = make(map[listKey]int)
+		}
+		key := listKey{listID: bullet.ListID, level: level}
+		glyph, start := listGlyph(book, bullet.ListID, level)
 		if !s.yieldEvent(ctx, yield, Event{
-			Kind:        Text,
-			TextContent: entry.Text,
+			Kind:        StartList,
+			ListLevel:   len(lists.levels),
+			ListOrdered: glyph.Ordered(),
+			ListGlyph:   glyph,
+			ListStart:   start + lists.counts[key],
 		}) {
 			return false
 		}
+		lists.listID = bullet.ListID
+		lists.levels = append(lists.levels, level)
+	}
 
-		// End link formatting
-		if !s.yieldEvent(ctx, yield, Event{Kind: EndFormatting, Style: Link}) {
-			return false
+	// Sub-lists restart their numbering below every new item
+	for key := range lists.counts {
+		if key.listID == bullet.ListID && key.level > level {
+			delete(lists.counts, key)
 		}
+	}
+	lists.counts[listKey{listID: bullet.ListID, level: level}]++
 
-		// End list item
-		if !s.yieldEvent(ctx, yield, Event{Kind: EndListItem}) {
-			return false
-		}
+	if !s.yieldEvent(ctx, yield, Event{Kind: StartListItem}) {
+		return false
 	}
 
-	// End the TOC list
+	return s.processParagraphContent(ctx, paragraph, book, yield) && s.yieldNotes(ctx, yield)
+}
+
+// closeList closes the innermost open list together with its open item
+func (s *Streamer) closeList(ctx context.Context, lists *listBlock, yield func(Event) bool) bool {
+	lists.levels = lists.levels[:len(lists.levels)-1]
+	if !s.yieldEvent(ctx, yield, Event{Kind: EndListItem}) {
+		return false
+	}
 	return s.yieldEvent(ctx, yield, Event{Kind: EndList})
 }
 
-// processListItem handles bullet list items
-func (s *Streamer) processListItem(ctx context.Context, paragraph *models.Paragraph, book *models.Book, inListBlock *bool, yield func(Event) bool) bool {
-	if !*inListBlock {
-		if !s.yieldEvent(ctx, yield, Event{
-			Kind:        StartList,
-			ListLevel:   0,
-			ListOrdered: false,
-		}) {
+// closeLists closes all open lists of the list block
+func (s *Streamer) closeLists(ctx context.Context, lists *listBlock, yield func(Event) bool) bool {
+	for len(lists.levels) > 0 {
+		if !s.closeList(ctx, lists, yield) {
 			return false
 		}
-		*inListBlock = true
 	}
+	return true
+}
 
-	if !s.yieldEvent(ctx, yield, Event{Kind: StartListItem}) {
-		return false
+// listGlyph resolves the marker style and start number of a list nesting level from the document's list definitions
+func listGlyph(book *models.Book, listID string, level int64) (ListGlyph, int) {
+	if book == nil || book.Content == nil || book.Content.Document == nil {
+		return GlyphDisc, 1
+	}
+	list, ok := book.Content.Document.Lists[listID]
+	if !ok || level >= int64(len(list.ListProperties.NestingLevels)) {
+		return GlyphDisc, 1
+	}
+	nesting := list.ListProperties.NestingLevels[level]
+	// A start number of 0 is treated as 1
+	start := max(1, int(nesting.StartNumber))
+
+	if nesting.GlyphType != nil {
+		switch *nesting.GlyphType {
+		case "DECIMAL", "ZERO_DECIMAL":
+			return GlyphDecimal, start
+		case "ALPHA":
+			return GlyphLowerAlpha, start
+		case "UPPER_ALPHA":
+			return GlyphUpperAlpha, start
+		case "ROMAN":
+			return GlyphLowerRoman, start
+		case "UPPER_ROMAN":
+			return GlyphUpperRoman, start
+		}
 	}
-
-	if !s.processParagraphContent(ctx, paragraph, book, yield) {
-		return false
+	if nesting.GlyphSymbol != nil {
+		switch *nesting.GlyphSymbol {
+		case "○", "◦":
+			return GlyphCircle, 1
+		case "■", "▪", "□", "◆", "◇":
+			return GlyphSquare, 1
+		}
 	}
-
-	return s.yieldEvent(ctx, yield, Event{Kind: EndListItem})
+	return GlyphDisc, 1
 }
 
 // processRegularParagraph handles standard paragraphs
-func (s *Streamer) processRegularParagraph(ctx context.Context, paragraph *models.Paragraph, book *models.Book, inListBlock *bool, yield func(Event) bool) bool {
-	if *inListBlock {
-		if !s.yieldEvent(ctx, yield, Event{Kind: EndList}) {
-			return false
-		}
-		*inListBlock = false
+func (s *Streamer) processRegularParagraph(ctx context.Context, paragraph *models.Paragraph, book *models.Book, lists *listBlock, yield func(Event) bool) bool {
+	if !s.closeLists(ctx, lists, yield) {
+		return false
 	}
 
 	if !s.yieldEvent(ctx, yield, Event{Kind: StartParagraph}) {
 		return false
 	}
 
-	if !s.processParagraphContent(ctx, paragraph, book, yield) {
+	if !s.processParagraphContent(ctx, paragraph, book, yield) || !s.yieldNotes(ctx, yield) {
 		return false
 	}
 
@@ -598,6 +829,18 @@ func (s *Streamer) processParagraphContent(ctx context.Context, paragraph *model
 			if !s.processInlineImage(ctx, element.InlineObjectElement, book, yield) {
 				return false
 			}
+		} else if element.PageBreak != nil {
+			if !s.yieldEvent(ctx, yield, Event{Kind: PageBreak}) {
+				return false
+			}
+		} else if element.HorizontalRule != nil {
+			if !s.yieldEvent(ctx, yield, Event{Kind: HorizontalRule}) {
+				return false
+			}
+		} else if element.AutoText != nil {
+			if !s.yieldEvent(ctx, yield, Event{Kind: AutoText, AutoTextType: element.AutoText.Type}) {
+				return false
+			}
 		}
 	}
 
@@ -685,33 +928,45 @@ func (s *Streamer) buildChapterMap(chapters []models.Chapter) map[string]*models
 }
 
 // processTable handles table content
-func (s *Streamer) processTable(ctx context.Context, table *models.Table, yield func(Event) bool) bool {
+func (s *Streamer) processTable(ctx context.Context, table *models.Table, book *models.Book, yield func(Event) bool) bool {
 	if len(table.TableRows) == 0 {
 		return true
 	}
 
+	spans, covered := tableCellSpans(table)
+	headerRows := s.tableHeaderRows(table)
+
 	if !s.yieldEvent(ctx, yield, Event{
-		Kind:         StartTable,
-		TableColumns: int(table.Columns),
-		TableRows:    int(table.Rows),
+		Kind:              StartTable,
+		TableColumns:      int(table.Columns),
+		TableRows:         int(table.Rows),
+		TableColumnWidths: tableColumnWidths(table.TableStyle),
+		TableHasSpans:     len(spans) > 0,
+		TableHasBlocks:    s.tableHasBlocks(table),
 	}) {
 		return false
 	}
 
-	for _, row := range table.TableRows {
-		if !s.yieldEvent(ctx, yield, Event{Kind: StartTableRow}) {
+	for r, row := range table.TableRows {
+		if !s.yieldEvent(ctx, yield, Event{Kind: StartTableRow, TableHeader: r < headerRows}) {
 			return false
 		}
-		for _, cell := range row.TableCells {
-			if !s.yieldEvent(ctx, yield, Event{Kind: StartTableCell}) {
+		for c, cell := range row.TableCells {
+			// Cells merged into a neighbour are rendered by the spanning cell
+			if covered[[2]int{r, c}] {
+				continue
+			}
+
+			span := spans[[2]int{r, c}]
+			if !s.yieldEvent(ctx, yield, Event{
+				Kind:        StartTableCell,
+				CellRowSpan: max(1, span[0]),
+				CellColSpan: max(1, span[1]),
+			}) {
 				return false
 			}
-			for _, element := range cell.Content {
-				if element.Paragraph != nil {
-					if !s.processParagraphContent(ctx, element.Paragraph, nil, yield) {
-						return false
-					}
-				}
+			if !s.processCellContent(ctx, cell.Content, book, yield) {
+				return false
 			}
 			if !s.yieldEvent(ctx, yield, Event{Kind: EndTableCell}) {
 				return false
@@ -725,18 +980,197 @@ func (s *Streamer) processTable(ctx context.Context, table *models.Table, yield
 	return s.yieldEvent(ctx, yield, Event{Kind: EndTable})
 }
 
+// processCellContent streams the paragraphs, list items and nested tables of a table cell, header or footer.
+// Headings inside cells are streamed as regular paragraphs.
+func (s *Streamer) processCellContent(ctx context.Context, content []models.StructuralElement, book *models.Book, yield func(Event) bool) bool {
+	lists := &listBlock{}
+
+	for _, element := range content {
+		switch {
+		case element.Table != nil:
+			if !s.closeLists(ctx, lists, yield) {
+				return false
+			}
+			if !s.processTable(ctx, element.Table, book, yield) {
+				return false
+			}
+		case element.Paragraph != nil:
+			paragraph := element.Paragraph
+			if s.options.SkipEmpty && s.extractParagraphText(paragraph) == "" && !s.hasInlineObjects(paragraph) && !s.hasAutoText(paragraph) {
+				continue
+			}
+			if paragraph.Bullet != nil {
+				if !s.processListItem(ctx, paragraph, book, lists, yield) {
+					return false
+				}
+			} else if !s.processRegularParagraph(ctx, paragraph, book, lists, yield) {
+				return false
+			}
+		}
+	}
+
+	return s.closeLists(ctx, lists, yield)
+}
+
+// tableHasBlocks reports whether any cell of the table holds more than a single line of inline
+// content: several non-empty paragraphs, a list item or a nested table
+func (s *Streamer) tableHasBlocks(table *models.Table) bool {
+	for _, row := range table.TableRows {
+		for _, cell := range row.TableCells {
+			paragraphs := 0
+			for _, element := range cell.Content {
+				if element.Table != nil {
+					return true
+				}
+				paragraph := element.Paragraph
+				if paragraph == nil || (s.extractParagraphText(paragraph) == "" && !s.hasInlineObjects(paragraph)) {
+					continue
+				}
+				if paragraph.Bullet != nil {
+					return true
+				}
+				paragraphs++
+			}
+			if paragraphs > 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tableCellSpans returns the row and column spans of merged cells keyed by {row, cell index}, and the
+// cells covered by them. Google Docs keeps covered cells in the grid, so covered positions are only
+// derived when every row has one cell per column.
+func tableCellSpans(table *models.Table) (map[[2]int][2]int, map[[2]int]bool) {
+	spans := make(map[[2]int][2]int)
+	covered := make(map[[2]int]bool)
+
+	rows := len(table.TableRows)
+	columns := int(table.Columns)
+	fullGrid := columns > 0
+	for _, row := range table.TableRows {
+		if len(row.TableCells) != columns {
+			fullGrid = false
+		}
+	}
+
+	for r, row := range table.TableRows {
+		for c, cell := range row.TableCells {
+			position := [2]int{r, c}
+			if covered[position] {
+				continue
+			}
+
+			style := cell.TableCellStyle
+			rowSpan := max(1, int(style.RowSpan))
+			colSpan := max(1, int(style.ColumnSpan))
+			if fullGrid {
+				rowSpan = min(rowSpan, rows-r)
+				colSpan = min(colSpan, columns-c)
+			}
+			if rowSpan == 1 && colSpan == 1 {
+				continue
+			}
+
+			spans[position] = [2]int{rowSpan, colSpan}
+			if !fullGrid {
+				continue
+			}
+			for dr := range rowSpan {
+				for dc := range colSpan {
+					if dr > 0 || dc > 0 {
+						covered[[2]int{r + dr, c + dc}] = true
+					}
+				}
+			}
+		}
+	}
+
+	return spans, covered
+}
+
+// tableHeaderRows returns the number of leading header rows. Rows marked as table headers in the
+// document take precedence; otherwise the configured heuristic decides whether the first row is a header.
+func (s *Streamer) tableHeaderRows(table *models.Table) int {
+	marked := 0
+	for _, row := range table.TableRows {
+		if isHeader, _ := row.TableRowStyle.TableHeader.(bool); !isHeader {
+			break
+		}
+		marked++
+	}
+	if marked > 0 || len(table.TableRows) < 2 {
+		return marked
+	}
+
+	switch s.options.TableHeaders {
+	case HeaderRowsFirstRow:
+		return 1
+	case HeaderRowsBoldFirstRow:
+		if isBoldRow(table.TableRows[0]) {
+			return 1
+		}
+	}
+	return 0
+}
+
+// isBoldRow reports whether a row has text and all of it is bold
+func isBoldRow(row models.TableRow) bool {
+	hasText := false
+	for _, cell := range row.TableCells {
+		for _, element := range cell.Content {
+			if element.Paragraph == nil {
+				continue
+			}
+			for _, run := range element.Paragraph.Elements {
+				if run.TextRun == nil || strings.TrimSpace(run.TextRun.Content) == "" {
+					continue
+				}
+				if run.TextRun.TextStyle.Bold == nil || !*run.TextRun.TextStyle.Bold {
+					return false
+				}
+				hasText = true
+			}
+		}
+	}
+	return hasText
+}
+
+// tableColumnWidths returns fixed column widths in points, or nil when all columns are evenly distributed
+func tableColumnWidths(style models.TableStyle) []float64 {
+	widths := make([]float64, len(style.TableColumnProperties))
+	fixed := false
+	for i, column := range style.TableColumnProperties {
+		if column.WidthType != "FIXED_WIDTH" || column.Width == nil || column.Width.Magnitude <= 0 {
+			continue
+		}
+		if column.Width.Unit != "" && column.Width.Unit != "PT" {
+			continue
+		}
+		widths[i] = column.Width.Magnitude
+		fixed = true
+	}
+	if !fixed {
+		return nil
+	}
+	return widths
+}
+
 // processChapters handles chapter-based content structure
-func (s *Streamer) processChapters(ctx context.Context, chapters []models.Chapter, yield func(Event) bool) {
+func (s *Streamer) processChapters(ctx context.Context, chapters []models.Chapter, yield func(Event) bool) bool {
 	for _, chapter := range chapters {
 		if !s.processChapter(ctx, &chapter, 2, yield) {
-			return
+			return false
 		}
 	}
+	return true
 }
 
 // processChapter handles individual chapter with proper hierarchical depth
 func (s *Streamer) processChapter(ctx context.Context, chapter *models.Chapter, depth int, yield func(Event) bool) bool {
 	// Process main chapter at the current depth
+	s.notes.anchorChapter(chapter.ID)
 	if !s.yieldHeading(ctx, depth, chapter.Title, yield) {
 		return false
 	}
@@ -772,6 +1206,16 @@ func (s *Streamer) hasInlineObjects(paragraph *models.Paragraph) bool {
 	return false
 }
 
+// hasAutoText checks if paragraph contains generated text such as page numbers
+func (s *Streamer) hasAutoText(paragraph *models.Paragraph) bool {
+	for _, element := range paragraph.Elements {
+		if element.AutoText != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // isHeading checks if paragraph is a heading
 func (s *Streamer) isHeading(paragraph *models.Paragraph) bool {
 	return strings.HasPrefix(paragraph.ParagraphStyle.NamedStyleType, "HEADING_")
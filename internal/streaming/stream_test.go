@@ -2,6 +2,9 @@ package streaming
 
 import (
 	"context"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
@@ -36,9 +39,11 @@ func TestDefaultStreamOptions(t *testing.T) {
 		MemoryLimit:  100 * 1024 * 1024,
 		SkipEmpty:    true,
 		SanitizeText: true,
+		TableHeaders: HeaderRowsBoldFirstRow,
+		TOC:          DefaultTOCOptions(),
 	}
 
-	if opts != expected {
+	if !reflect.DeepEqual(opts, expected) {
 		t.Errorf("DefaultStreamOptions() = %+v, want %+v", opts, expected)
 	}
 }
@@ -1332,3 +1337,464 @@ func createLargeTestBook(paragraphCount int) *models.Book {
 		},
 	}
 }
+
+func TestStreamer_Stream_TableSpans(t *testing.T) {
+	streamer := NewStreamer(DefaultStreamOptions())
+	ctx := context.Background()
+
+	cell := func(text string, rowSpan, colSpan int64) models.TableCell {
+		return models.TableCell{
+			TableCellStyle: models.TableCellStyle{RowSpan: rowSpan, ColumnSpan: colSpan},
+			Content: []models.StructuralElement{
+				{Paragraph: &models.Paragraph{Elements: []models.ParagraphElement{{TextRun: &models.TextRun{Content: text}}}}},
+			},
+		}
+	}
+
+	// A 3x3 grid where "Merged" covers two columns and "Tall" covers two rows
+	book := &models.Book{
+		Title: "Span Book",
+		Content: &models.Content{
+			Document: &models.Document{
+				Body: models.Body{
+					Content: []models.StructuralElement{
+						{
+							Table: &models.Table{
+								Rows:    3,
+								Columns: 3,
+								TableRows: []models.TableRow{
+									{TableCells: []models.TableCell{cell("Merged", 1, 2), cell("", 1, 1), cell("C", 1, 1)}},
+									{TableCells: []models.TableCell{cell("Tall", 2, 1), cell("D", 1, 1), cell("E", 1, 1)}},
+									{TableCells: []models.TableCell{cell("", 1, 1), cell("F", 1, 1), cell("G", 1, 1)}},
+								},
+								TableStyle: models.TableStyle{
+									TableColumnProperties: []models.TableColumnProperty{
+										{WidthType: "FIXED_WIDTH", Width: &models.Dimension{Magnitude: 120, Unit: "PT"}},
+										{WidthType: "EVENLY_DISTRIBUTED"},
+										{WidthType: "EVENLY_DISTRIBUTED"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	events := collectEvents(ctx, streamer, book)
+
+	starts := filterEventsByKind(events, []EventKind{StartTable})
+	if len(starts) != 1 {
+		t.Fatalf("Expected 1 StartTable event, got %d", len(starts))
+	}
+	if !starts[0].TableHasSpans {
+		t.Error("Expected StartTable to report merged cells")
+	}
+	if got := starts[0].TableColumnWidths; len(got) != 3 || got[0] != 120 || got[1] != 0 {
+		t.Errorf("Expected column widths [120 0 0], got %v", got)
+	}
+
+	cells := filterEventsByKind(events, []EventKind{StartTableCell})
+	if len(cells) != 7 {
+		t.Fatalf("Expected covered cells to be skipped leaving 7 cells, got %d", len(cells))
+	}
+	if cells[0].CellColSpan != 2 || cells[0].CellRowSpan != 1 {
+		t.Errorf("Expected first cell to span 2 columns, got rows=%d cols=%d", cells[0].CellRowSpan, cells[0].CellColSpan)
+	}
+	if cells[2].CellRowSpan != 2 || cells[2].CellColSpan != 1 {
+		t.Errorf("Expected row 2 first cell to span 2 rows, got rows=%d cols=%d", cells[2].CellRowSpan, cells[2].CellColSpan)
+	}
+	if cells[1].CellRowSpan != 1 || cells[1].CellColSpan != 1 {
+		t.Errorf("Expected unmerged cell spans of 1, got rows=%d cols=%d", cells[1].CellRowSpan, cells[1].CellColSpan)
+	}
+}
+
+func TestStreamer_Stream_TableHeaderRows(t *testing.T) {
+	bold := true
+	row := func(header any, boldText bool, texts ...string) models.TableRow {
+		cells := make([]models.TableCell, len(texts))
+		for i, text := range texts {
+			run := &models.TextRun{Content: text}
+			if boldText {
+				run.TextStyle.Bold = &bold
+			}
+			cells[i] = models.TableCell{
+				Content: []models.StructuralElement{
+					{Paragraph: &models.Paragraph{Elements: []models.ParagraphElement{{TextRun: run}}}},
+				},
+			}
+		}
+		return models.TableRow{TableCells: cells, TableRowStyle: models.TableRowStyle{TableHeader: header}}
+	}
+	tableBook := func(rows ...models.TableRow) *models.Book {
+		return &models.Book{
+			Title: "Header Book",
+			Content: &models.Content{
+				Document: &models.Document{
+					Body: models.Body{
+						Content: []models.StructuralElement{
+							{Table: &models.Table{Rows: int64(len(rows)), Columns: 2, TableRows: rows}},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		detection TableHeaderDetection
+		book      *models.Book
+		expected  []bool
+	}{
+		{
+			name:      "marked rows",
+			detection: HeaderRowsMarked,
+			book:      tableBook(row(true, false, "A", "B"), row(true, false, "C", "D"), row(nil, false, "E", "F")),
+			expected:  []bool{true, true, false},
+		},
+		{
+			name:      "bold first row",
+			detection: HeaderRowsBoldFirstRow,
+			book:      tableBook(row(nil, true, "A", "B"), row(nil, false, "C", "D")),
+			expected:  []bool{true, false},
+		},
+		{
+			name:      "plain first row is not bold",
+			detection: HeaderRowsBoldFirstRow,
+			book:      tableBook(row(nil, false, "A", "B"), row(nil, false, "C", "D")),
+			expected:  []bool{false, false},
+		},
+		{
+			name:      "bold first row ignored when only marked rows count",
+			detection: HeaderRowsMarked,
+			book:      tableBook(row(nil, true, "A", "B"), row(nil, false, "C", "D")),
+			expected:  []bool{false, false},
+		},
+		{
+			name:      "first row",
+			detection: HeaderRowsFirstRow,
+			book:      tableBook(row(nil, false, "A", "B"), row(nil, false, "C", "D")),
+			expected:  []bool{true, false},
+		},
+		{
+			name:      "single row table has no header",
+			detection: HeaderRowsFirstRow,
+			book:      tableBook(row(nil, true, "A", "B")),
+			expected:  []bool{false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultStreamOptions()
+			opts.TableHeaders = tt.detection
+			events := collectEvents(context.Background(), NewStreamer(opts), tt.book)
+
+			rows := filterEventsByKind(events, []EventKind{StartTableRow})
+			if len(rows) != len(tt.expected) {
+				t.Fatalf("Expected %d rows, got %d", len(tt.expected), len(rows))
+			}
+			for i, want := range tt.expected {
+				if rows[i].TableHeader != want {
+					t.Errorf("Row %d: expected TableHeader %v, got %v", i, want, rows[i].TableHeader)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamer_Stream_TableCellBlocks(t *testing.T) {
+	paragraph := func(text string, bullet bool) models.StructuralElement {
+		p := &models.Paragraph{Elements: []models.ParagraphElement{{TextRun: &models.TextRun{Content: text}}}}
+		if bullet {
+			p.Bullet = &models.Bullet{ListID: "list"}
+		}
+		return models.StructuralElement{Paragraph: p}
+	}
+	cell := func(content ...models.StructuralElement) models.TableCell {
+		return models.TableCell{Content: content}
+	}
+
+	inner := &models.Table{
+		Rows:      1,
+		Columns:   1,
+		TableRows: []models.TableRow{{TableCells: []models.TableCell{cell(paragraph("Inner", false))}}},
+	}
+	book := &models.Book{
+		Title: "Block Book",
+		Content: &models.Content{
+			Document: &models.Document{
+				Body: models.Body{
+					Content: []models.StructuralElement{
+						{
+							Table: &models.Table{
+								Rows:    1,
+								Columns: 2,
+								TableRows: []models.TableRow{
+									{TableCells: []models.TableCell{
+										cell(paragraph("First", false), paragraph("Second", false), paragraph("Item", true)),
+										cell(paragraph("Before", false), models.StructuralElement{Table: inner}),
+									}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	events := collectEvents(context.Background(), NewStreamer(DefaultStreamOptions()), book)
+
+	tables := filterEventsByKind(events, []EventKind{StartTable})
+	if len(tables) != 2 {
+		t.Fatalf("Expected outer and nested StartTable events, got %d", len(tables))
+	}
+	if !tables[0].TableHasBlocks {
+		t.Error("Expected outer table to report block content")
+	}
+	if tables[1].TableHasBlocks {
+		t.Error("Expected nested table with a single paragraph cell to report no block content")
+	}
+
+	var kinds []EventKind
+	for _, event := range events {
+		switch event.Kind {
+		case StartTable, EndTable, StartTableCell, EndTableCell, StartParagraph, StartList, StartListItem, EndList:
+			kinds = append(kinds, event.Kind)
+		}
+	}
+	expected := []EventKind{
+		StartTable,
+		StartTableCell, StartParagraph, StartParagraph, StartList, StartListItem, EndList, EndTableCell,
+		StartTableCell, StartParagraph, StartTable, StartTableCell, StartParagraph, EndTableCell, EndTable, EndTableCell,
+		EndTable,
+	}
+	if !slices.Equal(kinds, expected) {
+		t.Errorf("Expected cell structure %v, got %v", expected, kinds)
+	}
+}
+
+func TestStreamer_Stream_NestedLists(t *testing.T) {
+	glyph := func(value string) *string { return &value }
+	item := func(text, listID string, level int64) models.StructuralElement {
+		return models.StructuralElement{Paragraph: &models.Paragraph{
+			Bullet:   &models.Bullet{ListID: listID, NestingLevel: &level},
+			Elements: []models.ParagraphElement{{TextRun: &models.TextRun{Content: text}}},
+		}}
+	}
+	paragraph := models.StructuralElement{Paragraph: &models.Paragraph{
+		Elements: []models.ParagraphElement{{TextRun: &models.TextRun{Content: "Interruption"}}},
+	}}
+
+	book := &models.Book{
+		Title: "List Book",
+		Content: &models.Content{
+			Document: &models.Document{
+				Lists: map[string]models.List{
+					"numbered": {ListProperties: models.ListProperties{NestingLevels: []models.NestingLevel{
+						{GlyphType: glyph("DECIMAL"), StartNumber: 3},
+						{GlyphType: glyph("ALPHA"), StartNumber: 1},
+					}}},
+					"bullets": {ListProperties: models.ListProperties{NestingLevels: []models.NestingLevel{
+						{GlyphSymbol: glyph("●")},
+						{GlyphSymbol: glyph("○")},
+					}}},
+				},
+				Body: models.Body{Content: []models.StructuralElement{
+					item("One", "numbered", 0),
+					item("One A", "numbered", 1),
+					item("One B", "numbered", 1),
+					item("Two", "numbered", 0),
+					item("Two A", "numbered", 1),
+					paragraph,
+					item("Three", "numbered", 0),
+					item("Bullet", "bullets", 0),
+					item("Sub bullet", "bullets", 1),
+				}},
+			},
+		},
+	}
+
+	events := collectEvents(context.Background(), NewStreamer(DefaultStreamOptions()), book)
+
+	var structure []string
+	var starts []Event
+	for _, event := range events {
+		switch event.Kind {
+		case StartList:
+			starts = append(starts, event)
+			structure = append(structure, "(")
+		case EndList:
+			structure = append(structure, ")")
+		case StartListItem:
+			structure = append(structure, "<")
+		case EndListItem:
+			structure = append(structure, ">")
+		case StartParagraph:
+			structure = append(structure, "P")
+		}
+	}
+	expected := "(<(<><>)><(<>)>)P(<>)(<(<>)>)"
+	if got := strings.Join(structure, ""); got != expected {
+		t.Errorf("Expected list structure %s, got %s", expected, got)
+	}
+
+	want := []struct {
+		level   int
+		ordered bool
+		glyph   ListGlyph
+		start   int
+	}{
+		{0, true, GlyphDecimal, 3},
+		{1, true, GlyphLowerAlpha, 1},
+		{1, true, GlyphLowerAlpha, 1},
+		{0, true, GlyphDecimal, 5}, // Numbering continues after the interruption
+		{0, false, GlyphDisc, 1},
+		{1, false, GlyphCircle, 1},
+	}
+	if len(starts) != len(want) {
+		t.Fatalf("Expected %d StartList events, got %d", len(want), len(starts))
+	}
+	for i, w := range want {
+		got := starts[i]
+		if got.ListLevel != w.level || got.ListOrdered != w.ordered || got.ListGlyph != w.glyph || got.ListStart != w.start {
+			t.Errorf("StartList %d: expected level=%d ordered=%v glyph=%d start=%d, got level=%d ordered=%v glyph=%d start=%d",
+				i, w.level, w.ordered, w.glyph, w.start, got.ListLevel, got.ListOrdered, got.ListGlyph, got.ListStart)
+		}
+	}
+}
+
+func TestStreamer_Stream_BreaksAndHeadersFooters(t *testing.T) {
+	paragraph := func(elements ...models.ParagraphElement) models.StructuralElement {
+		return models.StructuralElement{Paragraph: &models.Paragraph{Elements: elements}}
+	}
+	text := func(content string) models.ParagraphElement {
+		return models.ParagraphElement{TextRun: &models.TextRun{Content: content}}
+	}
+	sectionBreak := func(sectionType string) models.StructuralElement {
+		return models.StructuralElement{SectionBreak: &models.SectionBreak{SectionStyle: models.SectionStyle{SectionType: sectionType}}}
+	}
+
+	book := &models.Book{
+		Title: "Break Book",
+		Content: &models.Content{
+			Document: &models.Document{
+				DocumentStyle: models.DocumentStyle{DefaultHeaderID: "h", DefaultFooterID: "f"},
+				Headers: map[string]models.HeaderFooter{
+					"h": {Content: []models.StructuralElement{paragraph(text("Header"))}},
+				},
+				Footers: map[string]models.HeaderFooter{
+					"f": {Content: []models.StructuralElement{paragraph(
+						text("Page "),
+						models.ParagraphElement{AutoText: &models.AutoText{Type: "PAGE_NUMBER"}},
+					)}},
+				},
+				Body: models.Body{Content: []models.StructuralElement{
+					sectionBreak("CONTINUOUS"),
+					paragraph(text("Intro")),
+					paragraph(models.ParagraphElement{HorizontalRule: &models.HorizontalRule{}}),
+					paragraph(models.ParagraphElement{PageBreak: &models.PageBreak{}}),
+					sectionBreak("NEXT_PAGE"),
+					paragraph(text("Next")),
+				}},
+			},
+		},
+	}
+
+	kinds := func(events []Event) string {
+		var names []string
+		for _, event := range events {
+			names = append(names, event.Kind.String())
+		}
+		return strings.Join(names, ",")
+	}
+
+	events := collectEvents(context.Background(), NewStreamer(DefaultStreamOptions()), book)
+	expected := "StartDoc,StartParagraph,Text,EndParagraph,HorizontalRule,PageBreak,SectionBreak,StartParagraph,Text,EndParagraph,EndDoc"
+	if got := kinds(events); got != expected {
+		t.Errorf("Expected events %s, got %s", expected, got)
+	}
+	for _, event := range events {
+		if event.Kind == SectionBreak && !event.SectionNewPage {
+			t.Error("Expected NEXT_PAGE section break to start a new page")
+		}
+	}
+
+	opts := DefaultStreamOptions()
+	opts.HeadersFooters = true
+	events = collectEvents(context.Background(), NewStreamer(opts), book)
+	expected = "StartDoc,StartHeader,StartParagraph,Text,EndParagraph,EndHeader," +
+		"StartFooter,StartParagraph,Text,AutoText,EndParagraph,EndFooter,StartParagraph"
+	if got := kinds(events); !strings.HasPrefix(got, expected) {
+		t.Errorf("Expected events to start with %s, got %s", expected, got)
+	}
+	for _, event := range events {
+		if event.Kind == AutoText && event.AutoTextType != "PAGE_NUMBER" {
+			t.Errorf("Expected PAGE_NUMBER auto text, got %q", event.AutoTextType)
+		}
+	}
+}
+
+func TestStreamer_Stream_Notes(t *testing.T) {
+	index := func(i int64) *int64 { return &i }
+	paragraph := func(text string, start, end int64) models.StructuralElement {
+		return models.StructuralElement{
+			StartIndex: start,
+			EndIndex:   end,
+			Paragraph: &models.Paragraph{
+				Elements: []models.ParagraphElement{{TextRun: &models.TextRun{Content: text}}},
+			},
+		}
+	}
+	headingID := "h.chapter"
+	heading := paragraph("Chapter", 1, 9)
+	heading.Paragraph.ParagraphStyle.HeadingID = &headingID
+
+	book := &models.Book{
+		Title:    "Note Book",
+		Chapters: []models.Chapter{{ID: 7, Title: "Chapter", GDocsChapterID: headingID}},
+		Content: &models.Content{
+			Document: &models.Document{
+				Body: models.Body{Content: []models.StructuralElement{
+					heading,
+					paragraph("First", 9, 20),
+					paragraph("Second", 20, 30),
+				}},
+			},
+		},
+		Notes: []models.Note{
+			{ID: 1, Content: "On second", StartIndex: index(25)},
+			{ID: 2, Content: "On chapter", ChapterID: index(7)},
+			{ID: 3, Content: "Loose"},
+			{ID: 4, Content: "   "},
+			{ID: 5, Content: "Past the end", StartIndex: index(99)},
+		},
+	}
+
+	events := collectEvents(context.Background(), NewStreamer(DefaultStreamOptions()), book)
+
+	var structure []string
+	for _, event := range events {
+		switch event.Kind {
+		case StartHeading:
+			structure = append(structure, "H(")
+		case EndHeading:
+			structure = append(structure, ")")
+		case StartParagraph:
+			structure = append(structure, "P(")
+		case EndParagraph:
+			structure = append(structure, ")")
+		case Text:
+			structure = append(structure, event.TextContent)
+		case Note:
+			structure = append(structure, "["+strconv.FormatInt(event.NoteID, 10)+":"+event.NoteText+"]")
+		}
+	}
+	expected := "H( Chapter [2:On chapter] ) P( First ) P( Second [1:On second] ) P( [3:Loose] [5:Past the end] )"
+	if got := strings.Join(structure, " "); got != expected {
+		t.Errorf("Expected %s, got %s", expected, got)
+	}
+}
@@ -0,0 +1,282 @@
+package streaming
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kjanat/slimacademy/internal/models"
+	"github.com/kjanat/slimacademy/internal/utils"
+)
+
+// DefaultTOCPlaceholders are the heading texts that mark where the table of contents goes
+var DefaultTOCPlaceholders = []string{"inhoudsopgave", "table of contents", "contents", "index"}
+
+// TOCDedupe selects which repeated headings the table of contents leaves out
+type TOCDedupe int
+
+const (
+	// TOCKeepDuplicates lists every heading, so a "Samenvatting" in every chapter is listed for
+	// each chapter
+	TOCKeepDuplicates TOCDedupe = iota
+	// TOCDedupeSiblings leaves out headings repeating the text of an earlier heading below the
+	// same parent heading
+	TOCDedupeSiblings
+	// TOCDedupeText lists only the first heading with a given text
+	TOCDedupeText
+)
+
+// tocDedupeNames are the names of the TOCDedupe modes, as accepted by ParseTOCDedupe
+var tocDedupeNames = []string{"keep", "siblings", "text"}
+
+// String returns the name of the dedupe mode
+func (d TOCDedupe) String() string {
+	if d >= 0 && int(d) < len(tocDedupeNames) {
+		return tocDedupeNames[d]
+	}
+	return fmt.Sprintf("TOCDedupe(%d)", int(d))
+}
+
+// ParseTOCDedupe returns the dedupe mode with the given name: keep, siblings or text
+func ParseTOCDedupe(name string) (TOCDedupe, error) {
+	if i := slices.Index(tocDedupeNames, strings.ToLower(strings.TrimSpace(name))); i >= 0 {
+		return TOCDedupe(i), nil
+	}
+	return 0, fmt.Errorf("unknown table of contents dedupe mode %q (want %s)", name, strings.Join(tocDedupeNames, ", "))
+}
+
+// TOCOptions configures the table of contents generated by the Streamer. The table of contents
+// is streamed as a TableOfContents event after a placeholder heading, or at the start of the
+// document with AutoInsert.
+type TOCOptions struct {
+	// Placeholders are heading texts, matched case-insensitively, that are followed by the table
+	// of contents and left out of it. Nil uses DefaultTOCPlaceholders; an empty slice matches none.
+	Placeholders []string
+	MaxDepth     int  // Number of heading levels listed, counted from the highest level listed; 0 for all
+	Nested       bool // Nest the entries by heading level instead of listing them flat
+	AutoInsert   bool // Insert the table of contents after StartDoc when no heading is a placeholder
+	Dedupe       TOCDedupe
+}
+
+// DefaultTOCOptions returns options that stream a flat table of contents of all headings after
+// the first placeholder heading
+func DefaultTOCOptions() TOCOptions {
+	return TOCOptions{Placeholders: slices.Clone(DefaultTOCPlaceholders)}
+}
+
+// TOCEntry represents a heading in the table of contents
+type TOCEntry struct {
+	Level    int
+	Text     string
+	AnchorID string
+}
+
+// isTOCHeading checks if the heading text indicates a table of contents placeholder
+func (s *Streamer) isTOCHeading(text string) bool {
+	placeholders := s.options.TOC.Placeholders
+	if placeholders == nil {
+		placeholders = DefaultTOCPlaceholders
+	}
+
+	text = strings.TrimSpace(text)
+	for _, placeholder := range placeholders {
+		if strings.EqualFold(text, strings.TrimSpace(placeholder)) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectAllHeadings performs a preliminary pass over the headings of the book, so that the
+// table of contents can be streamed before the headings it lists. The anchors are generated
+// in the same order as the streamed headings, so they match.
+func (s *Streamer) collectAllHeadings(book *models.Book) {
+	var headings []TOCEntry
+	s.tocPlaceholder = false
+	slugCache := make(map[string]int)
+
+	s.walkHeadings(book, func(level int, text string) {
+		anchorID := utils.SlugifyWithCache(text, slugCache)
+		text = strings.TrimSpace(text)
+		switch {
+		case s.isTOCHeading(text):
+			s.tocPlaceholder = true
+		case text != "":
+			headings = append(headings, TOCEntry{Level: level, Text: text, AnchorID: anchorID})
+		}
+	})
+
+	s.collectedTOC = s.options.TOC.entries(headings)
+}
+
+// walkHeadings calls fn with the level and text of every heading that processContent streams,
+// in the same order
+func (s *Streamer) walkHeadings(book *models.Book, fn func(level int, text string)) {
+	if book.Content == nil {
+		return
+	}
+	if book.Content.Document == nil {
+		if book.Content.Chapters != nil {
+			walkChapterHeadings(book.Content.Chapters, 2, fn)
+		}
+		return
+	}
+
+	chapterMap := s.buildChapterMap(book.Chapters)
+	for _, element := range book.Content.Document.Body.Content {
+		paragraph := element.Paragraph
+		if paragraph == nil {
+			continue
+		}
+
+		// Chapter headings, as in processChapterHeading
+		if id := paragraph.ParagraphStyle.HeadingID; id != nil {
+			if chapter, exists := chapterMap[*id]; exists {
+				if title := strings.TrimSpace(chapter.Title); title != "" || !s.options.SkipEmpty {
+					fn(2, title)
+				}
+				continue
+			}
+		}
+
+		// Regular headings, as in processParagraph and processHeading
+		text := s.extractParagraphText(paragraph)
+		if s.options.SkipEmpty && text == "" {
+			continue
+		}
+		if s.isHeading(paragraph) {
+			fn(s.getHeadingLevel(paragraph.ParagraphStyle.NamedStyleType), text)
+		}
+	}
+}
+
+// walkChapterHeadings calls fn for chapter-based content, as in processChapter
+func walkChapterHeadings(chapters []models.Chapter, depth int, fn func(level int, text string)) {
+	for _, chapter := range chapters {
+		fn(depth, chapter.Title)
+		walkChapterHeadings(chapter.SubChapters, depth+1, fn)
+	}
+}
+
+// entries returns the headings listed in the table of contents, limited to MaxDepth levels and
+// without the duplicates selected by Dedupe
+func (o TOCOptions) entries(headings []TOCEntry) []TOCEntry {
+	if len(headings) == 0 {
+		return nil
+	}
+
+	top := headings[0].Level
+	for _, heading := range headings {
+		top = min(top, heading.Level)
+	}
+
+	type parent struct {
+		level    int
+		anchorID string
+	}
+	var parents []parent
+	seen := make(map[string]bool)
+
+	var entries []TOCEntry
+	for _, heading := range headings {
+		if o.MaxDepth > 0 && heading.Level-top >= o.MaxDepth {
+			continue
+		}
+
+		var key string
+		switch o.Dedupe {
+		case TOCDedupeText:
+			key = heading.Text
+		case TOCDedupeSiblings:
+			for len(parents) > 0 && parents[len(parents)-1].level >= heading.Level {
+				parents = parents[:len(parents)-1]
+			}
+			if len(parents) > 0 {
+				key = parents[len(parents)-1].anchorID
+			}
+			key += "\x00" + heading.Text
+			parents = append(parents, parent{level: heading.Level, anchorID: heading.AnchorID})
+		}
+		if key != "" {
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+
+		entries = append(entries, heading)
+	}
+	return entries
+}
+
+// yieldTOC emits the collected table of contents as a TableOfContents event, once per stream
+func (s *Streamer) yieldTOC(ctx context.Context, yield func(Event) bool) bool {
+	s.tocEmitted = true
+	if len(s.collectedTOC) == 0 {
+		return true // No TOC entries to emit
+	}
+
+	return s.yieldEvent(ctx, yield, Event{
+		Kind:       TableOfContents,
+		TOCEntries: s.collectedTOC,
+		TOCNested:  s.options.TOC.Nested,
+	})
+}
+
+// TOCListEvents returns the events of a bulleted list of links to the entries of a
+// TableOfContents event, for writers without a native table of contents. With TOCNested,
+// deeper headings are listed in lists nested in the item of the heading above them.
+func TOCListEvents(event Event) []Event {
+	if len(event.TOCEntries) == 0 {
+		return nil
+	}
+
+	// Open items of nested lists, outermost first
+	type item struct {
+		level  int
+		nested bool // The item holds an open nested list
+	}
+	var open []item
+
+	// closeItem closes the innermost open item and the nested list it is in, unless the next
+	// entry at level goes into that list
+	events := []Event{{Kind: StartList}}
+	closeItem := func(level int) {
+		events = append(events, Event{Kind: EndListItem})
+		open = open[:len(open)-1]
+		if n := len(open); n > 0 && open[n-1].nested && open[n-1].level >= level {
+			events = append(events, Event{Kind: EndList})
+			open[n-1].nested = false
+		}
+	}
+
+	for _, entry := range event.TOCEntries {
+		if !event.TOCNested {
+			if len(open) > 0 {
+				closeItem(entry.Level)
+			}
+		} else {
+			for len(open) > 0 && open[len(open)-1].level >= entry.Level {
+				closeItem(entry.Level)
+			}
+			if n := len(open); n > 0 && !open[n-1].nested {
+				events = append(events, Event{Kind: StartList, ListLevel: n})
+				open[n-1].nested = true
+			}
+		}
+
+		events = append(events,
+			Event{Kind: StartListItem},
+			Event{Kind: StartFormatting, Style: Link, LinkURL: "#" + entry.AnchorID},
+			Event{Kind: Text, TextContent: entry.Text},
+			Event{Kind: EndFormatting, Style: Link},
+		)
+		open = append(open, item{level: entry.Level})
+	}
+
+	for len(open) > 0 {
+		closeItem(0)
+	}
+	return append(events, Event{Kind: EndList})
+}
@@ -0,0 +1,232 @@
+package streaming
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/kjanat/slimacademy/internal/models"
+)
+
+// tocTestBook returns a book with a heading paragraph per "STYLE:text" pair
+func tocTestBook(headings ...string) *models.Book {
+	var content []models.StructuralElement
+	for i := 0; i+1 < len(headings); i += 2 {
+		content = append(content, models.StructuralElement{
+			Paragraph: &models.Paragraph{
+				Elements:       []models.ParagraphElement{{TextRun: &models.TextRun{Content: headings[i+1]}}},
+				ParagraphStyle: models.ParagraphStyle{NamedStyleType: headings[i]},
+			},
+		})
+	}
+	return &models.Book{
+		ID:      1,
+		Title:   "Samenvatting Anatomie",
+		Content: &models.Content{Document: &models.Document{Body: models.Body{Content: content}}},
+	}
+}
+
+// tocEvents returns the TableOfContents events streamed for book and the index of the first
+func tocEvents(t *testing.T, opts StreamOptions, book *models.Book) ([]Event, int) {
+	t.Helper()
+	events := collectEvents(context.Background(), NewStreamer(opts), book)
+	tocs := filterEventsByKind(events, []EventKind{TableOfContents})
+	index := slices.IndexFunc(events, func(event Event) bool { return event.Kind == TableOfContents })
+	return tocs, index
+}
+
+func TestStreamer_TableOfContents(t *testing.T) {
+	book := tocTestBook(
+		"HEADING_1", "Inhoudsopgave",
+		"HEADING_1", "Anatomie",
+		"HEADING_2", "Samenvatting",
+		"HEADING_1", "Fysiologie",
+		"HEADING_2", "Samenvatting",
+	)
+	streamer := NewStreamer(DefaultStreamOptions())
+
+	for range 2 { // Anchors do not depend on earlier streams
+		events := collectEvents(context.Background(), streamer, book)
+		tocs := filterEventsByKind(events, []EventKind{TableOfContents})
+		if len(tocs) != 1 {
+			t.Fatalf("Expected one TableOfContents event, got %d", len(tocs))
+		}
+		if lists := filterEventsByKind(events, []EventKind{StartList}); len(lists) != 0 {
+			t.Errorf("Expected the table of contents as a single event, got %d lists", len(lists))
+		}
+
+		// The table of contents follows the placeholder heading and lists the other headings,
+		// with the anchors of the streamed headings
+		index := slices.IndexFunc(events, func(event Event) bool { return event.Kind == TableOfContents })
+		if previous := events[index-1]; previous.Kind != EndHeading {
+			t.Errorf("Expected the table of contents after the placeholder heading, got %v", previous.Kind)
+		}
+
+		var anchors []string
+		for _, heading := range filterEventsByKind(events, []EventKind{StartHeading})[1:] {
+			anchors = append(anchors, heading.AnchorID)
+		}
+		var listed []string
+		for _, entry := range tocs[0].TOCEntries {
+			listed = append(listed, entry.AnchorID)
+		}
+		if !slices.Equal(listed, anchors) || !slices.Equal(anchors, []string{"anatomie", "samenvatting", "fysiologie", "samenvatting-1"}) {
+			t.Errorf("Expected entries linking to %v, got %v", anchors, listed)
+		}
+	}
+}
+
+func TestStreamer_TableOfContents_Dedupe(t *testing.T) {
+	book := tocTestBook(
+		"HEADING_1", "Contents",
+		"HEADING_1", "Anatomie",
+		"HEADING_2", "Samenvatting",
+		"HEADING_1", "Fysiologie",
+		"HEADING_2", "Samenvatting",
+		"HEADING_2", "Samenvatting",
+	)
+
+	tests := []struct {
+		dedupe   TOCDedupe
+		expected []string
+	}{
+		{TOCKeepDuplicates, []string{"anatomie", "samenvatting", "fysiologie", "samenvatting-1", "samenvatting-2"}},
+		{TOCDedupeSiblings, []string{"anatomie", "samenvatting", "fysiologie", "samenvatting-1"}},
+		{TOCDedupeText, []string{"anatomie", "samenvatting", "fysiologie"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dedupe.String(), func(t *testing.T) {
+			opts := DefaultStreamOptions()
+			opts.TOC.Dedupe = tt.dedupe
+			tocs, _ := tocEvents(t, opts, book)
+			if len(tocs) != 1 {
+				t.Fatalf("Expected one TableOfContents event, got %d", len(tocs))
+			}
+
+			var anchors []string
+			for _, entry := range tocs[0].TOCEntries {
+				anchors = append(anchors, entry.AnchorID)
+			}
+			if !slices.Equal(anchors, tt.expected) {
+				t.Errorf("Expected entries %v, got %v", tt.expected, anchors)
+			}
+		})
+	}
+}
+
+func TestStreamer_TableOfContents_Options(t *testing.T) {
+	withPlaceholder := tocTestBook(
+		"HEADING_1", "Table of Contents",
+		"HEADING_1", "Anatomie",
+		"HEADING_2", "Botten",
+		"HEADING_3", "Schedel",
+	)
+	withoutPlaceholder := tocTestBook(
+		"HEADING_1", "Anatomie",
+		"HEADING_2", "Botten",
+	)
+
+	t.Run("MaxDepth", func(t *testing.T) {
+		opts := DefaultStreamOptions()
+		opts.TOC.MaxDepth = 2
+		opts.TOC.Nested = true
+		tocs, _ := tocEvents(t, opts, withPlaceholder)
+		if len(tocs) != 1 || len(tocs[0].TOCEntries) != 2 || !tocs[0].TOCNested {
+			t.Fatalf("Expected two nested entries, got %+v", tocs)
+		}
+		if entry := tocs[0].TOCEntries[1]; entry.Text != "Botten" || entry.Level != 3 {
+			t.Errorf("Unexpected entry %+v", entry)
+		}
+	})
+
+	t.Run("NoPlaceholder", func(t *testing.T) {
+		if tocs, _ := tocEvents(t, DefaultStreamOptions(), withoutPlaceholder); len(tocs) != 0 {
+			t.Errorf("Expected no table of contents without a placeholder, got %d", len(tocs))
+		}
+	})
+
+	t.Run("AutoInsert", func(t *testing.T) {
+		opts := DefaultStreamOptions()
+		opts.TOC.AutoInsert = true
+		tocs, index := tocEvents(t, opts, withoutPlaceholder)
+		if len(tocs) != 1 || index != 1 || len(tocs[0].TOCEntries) != 2 {
+			t.Errorf("Expected the table of contents after StartDoc, got %d at %d", len(tocs), index)
+		}
+
+		// A placeholder heading keeps its position
+		tocs, index = tocEvents(t, opts, withPlaceholder)
+		if len(tocs) != 1 || index == 1 {
+			t.Errorf("Expected one table of contents after the placeholder, got %d at %d", len(tocs), index)
+		}
+	})
+
+	t.Run("Placeholders", func(t *testing.T) {
+		opts := DefaultStreamOptions()
+		opts.TOC.Placeholders = []string{"Anatomie"}
+		tocs, _ := tocEvents(t, opts, withPlaceholder)
+		if len(tocs) != 1 || len(tocs[0].TOCEntries) != 3 || tocs[0].TOCEntries[0].Text != "Table of Contents" {
+			t.Errorf("Expected the custom placeholder to be replaced, got %+v", tocs)
+		}
+
+		opts.TOC.Placeholders = []string{}
+		if tocs, _ := tocEvents(t, opts, withPlaceholder); len(tocs) != 0 {
+			t.Errorf("Expected no placeholders to match, got %d", len(tocs))
+		}
+	})
+}
+
+func TestTOCListEvents(t *testing.T) {
+	entries := []TOCEntry{
+		{Level: 2, Text: "Anatomie", AnchorID: "anatomie"},
+		{Level: 3, Text: "Botten", AnchorID: "botten"},
+		{Level: 4, Text: "Schedel", AnchorID: "schedel"},
+		{Level: 2, Text: "Fysiologie", AnchorID: "fysiologie"},
+	}
+
+	// kinds abbreviates the list structure: ( and ) open and close lists, [ and ] items
+	kinds := func(events []Event) string {
+		var result []byte
+		for _, event := range events {
+			switch event.Kind {
+			case StartList:
+				result = append(result, '(')
+			case EndList:
+				result = append(result, ')')
+			case StartListItem:
+				result = append(result, '[')
+			case EndListItem:
+				result = append(result, ']')
+			}
+		}
+		return string(result)
+	}
+
+	flat := TOCListEvents(Event{Kind: TableOfContents, TOCEntries: entries})
+	if got := kinds(flat); got != "([][][][])" {
+		t.Errorf("Expected a flat list, got %s", got)
+	}
+	if link := flat[2]; link.Kind != StartFormatting || link.Style != Link || link.LinkURL != "#anatomie" {
+		t.Errorf("Expected a link to the first heading, got %+v", link)
+	}
+
+	nested := TOCListEvents(Event{Kind: TableOfContents, TOCEntries: entries, TOCNested: true})
+	if got := kinds(nested); got != "([([([])])][])" {
+		t.Errorf("Expected nested lists, got %s", got)
+	}
+
+	if events := TOCListEvents(Event{Kind: TableOfContents}); events != nil {
+		t.Errorf("Expected no events without entries, got %d", len(events))
+	}
+}
+
+func TestParseTOCDedupe(t *testing.T) {
+	for _, dedupe := range []TOCDedupe{TOCKeepDuplicates, TOCDedupeSiblings, TOCDedupeText} {
+		if parsed, err := ParseTOCDedupe(dedupe.String()); err != nil || parsed != dedupe {
+			t.Errorf("ParseTOCDedupe(%q) = %v, %v", dedupe.String(), parsed, err)
+		}
+	}
+	if _, err := ParseTOCDedupe("chapters"); err == nil {
+		t.Error("Expected an error for an unknown mode")
+	}
+}
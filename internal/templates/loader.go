@@ -0,0 +1,66 @@
+package templates
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Names of the templates making up an HTML page. A template directory may replace any of them;
+// the others keep their built-in definition.
+const (
+	LayoutTemplate = "layout" // The whole page, including the other templates
+	HeaderTemplate = "header" // Title, description and metadata of the book
+	TOCTemplate    = "toc"    // Table of contents
+	FooterTemplate = "footer" // Running footer
+)
+
+// templateExtensions are the file extensions read from a template directory
+var templateExtensions = []string{".html", ".tmpl", ".gohtml"}
+
+// LoadTemplateDir creates a template from the html/template files in dir, on top of the
+// built-in template. Each file defines the template named after it without extension, so
+// header.html replaces the header template; files may also {{define}} further templates.
+// All files receive TemplateData.
+func LoadTemplateDir(dir string) (*MinimalTemplate, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template directory: %w", err)
+	}
+
+	tmpl := template.Must(builtinTemplate.Clone())
+	found := false
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || !slices.Contains(templateExtensions, ext) {
+			continue
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template %s: %w", path, err)
+		}
+		if _, err := tmpl.New(strings.TrimSuffix(entry.Name(), ext)).Parse(string(content)); err != nil {
+			return nil, fmt.Errorf("failed to parse template %s: %w", path, err)
+		}
+		found = true
+	}
+	if !found {
+		return nil, fmt.Errorf("no templates (%s) found in %s", strings.Join(templateExtensions, ", "), dir)
+	}
+
+	return &MinimalTemplate{htmlTemplate: tmpl}, nil
+}
+
+// LoadCSS reads a stylesheet for TemplateData.CSS
+func LoadCSS(path string) (template.CSS, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read stylesheet: %w", err)
+	}
+	return template.CSS(content), nil
+}
@@ -0,0 +1,159 @@
+package templates
+
+import (
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kjanat/slimacademy/internal/models"
+)
+
+// writeTemplates writes template files to a new directory
+func writeTemplates(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadTemplateDir(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"header.html": `<header class="faculty">{{.Title}} – {{index .Metadata "Pages"}} pages</header>`,
+		"toc.tmpl":    `<nav>{{range .TOC}}<a href="#{{.Anchor}}">{{.Title}}</a>{{range .Children}}<a href="#{{.Anchor}}">{{.Title}}</a>{{end}}{{end}}</nav>`,
+		"notes.txt":   `{{.Broken`,
+	})
+
+	tmpl, err := LoadTemplateDir(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplateDir() failed: %v", err)
+	}
+
+	html, err := tmpl.Render(TemplateData{
+		Title:    "Anatomy",
+		Language: "nl",
+		Content:  template.HTML("<p>Body</p>"),
+		Metadata: map[string]string{"Pages": "12"},
+		TOC:      NestTOC([]TOCEntry{{Title: "Bones", Level: 1, Anchor: "bones"}, {Title: "Skull", Level: 2, Anchor: "skull"}}),
+	})
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+
+	for _, expected := range []string{
+		`<html lang="nl">`,
+		`<header class="faculty">Anatomy – 12 pages</header>`,
+		`<nav><a href="#bones">Bones</a><a href="#skull">Skull</a></nav>`,
+		"<p>Body</p>",
+		"font-family: -apple-system", // Built-in stylesheet
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, html)
+		}
+	}
+	if strings.Contains(html, `<h1 class="document-title">`) {
+		t.Error("Expected the built-in header to be replaced")
+	}
+
+	// The built-in template is not changed by loading a directory
+	builtin, _ := NewMinimalTemplate().Render(TemplateData{Title: "Anatomy"})
+	if !strings.Contains(builtin, `<h1 class="document-title">Anatomy</h1>`) {
+		t.Errorf("Expected the built-in header, got:\n%s", builtin)
+	}
+}
+
+func TestLoadTemplateDir_Layout(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"layout.html": `<!DOCTYPE html><title>{{.Title}}</title><style>{{.CSS}}</style>{{template "header" .}}{{.Content}}{{template "footer" .}}`,
+	})
+	tmpl, err := LoadTemplateDir(dir)
+	if err != nil {
+		t.Fatalf("LoadTemplateDir() failed: %v", err)
+	}
+
+	html, err := tmpl.Render(TemplateData{Title: "Cells", CSS: "body { color: navy; }", Footer: template.HTML("<p>Page 1</p>")})
+	if err != nil {
+		t.Fatalf("Render() failed: %v", err)
+	}
+	for _, expected := range []string{"<title>Cells</title>", "body { color: navy; }", `<h1 class="document-title">Cells</h1>`, "<p>Page 1</p>    </footer>"} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, html)
+		}
+	}
+}
+
+func TestLoadTemplateDir_Errors(t *testing.T) {
+	tests := map[string]string{
+		"missing directory": filepath.Join(t.TempDir(), "missing"),
+		"no templates":      writeTemplates(t, map[string]string{"README.md": "templates"}),
+		"parse error":       writeTemplates(t, map[string]string{"footer.html": "{{if .Footer}}"}),
+	}
+	for name, dir := range tests {
+		if _, err := LoadTemplateDir(dir); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	if _, err := LoadCSS(filepath.Join(t.TempDir(), "missing.css")); err == nil {
+		t.Error("Expected error for missing stylesheet")
+	}
+}
+
+func TestBuiltinTOC(t *testing.T) {
+	data := TemplateData{
+		Title: "Cells",
+		TOC:   []TOCEntry{{Title: "Membrane", Level: 2, Anchor: "membrane"}},
+	}
+
+	html, _ := NewMinimalTemplate().Render(data)
+	if strings.Contains(html, "table-of-contents") {
+		t.Error("Expected no table of contents without IncludeTOC")
+	}
+
+	data.IncludeTOC = true
+	html, _ = NewMinimalTemplate().Render(data)
+	if !strings.Contains(html, `<a href="#membrane">Membrane</a>`) {
+		t.Errorf("Expected table of contents, got:\n%s", html)
+	}
+}
+
+func TestNestTOC(t *testing.T) {
+	toc := NestTOC([]TOCEntry{
+		{Title: "A", Level: 2},
+		{Title: "A.1", Level: 3},
+		{Title: "A.1.1", Level: 4},
+		{Title: "B", Level: 2},
+		{Title: "Appendix", Level: 1},
+		{Title: "Appendix.1", Level: 3},
+	})
+
+	if len(toc) != 3 || toc[0].Title != "A" || toc[1].Title != "B" || toc[2].Title != "Appendix" {
+		t.Fatalf("Unexpected top level: %+v", toc)
+	}
+	if len(toc[0].Children) != 1 || len(toc[0].Children[0].Children) != 1 || toc[0].Children[0].Children[0].Title != "A.1.1" {
+		t.Errorf("Expected A.1.1 below A.1, got %+v", toc[0])
+	}
+	if len(toc[2].Children) != 1 || toc[2].Children[0].Title != "Appendix.1" {
+		t.Errorf("Expected Appendix.1 below Appendix, got %+v", toc[2])
+	}
+}
+
+func TestChapterTOC(t *testing.T) {
+	chapters := []models.Chapter{
+		{Title: "Bones", SubChapters: []models.Chapter{{Title: "Skull"}}},
+		{Title: "Muscles"},
+	}
+	toc := ChapterTOC(chapters, []TOCEntry{{Title: "Skull", Level: 2, Anchor: "skull"}, {Title: "Bones", Level: 1, Anchor: "bones"}})
+
+	if len(toc) != 2 || toc[0].Anchor != "bones" || toc[1].Anchor != "" {
+		t.Fatalf("Unexpected chapters: %+v", toc)
+	}
+	if skull := toc[0].Children[0]; skull.Anchor != "skull" || skull.Level != 2 {
+		t.Errorf("Expected Skull at level 2 linking to its heading, got %+v", skull)
+	}
+}
@@ -8,28 +8,52 @@ import (
 	"strings"
 )
 
-// MinimalTemplate provides a clean, minimal HTML template system
+// MinimalTemplate provides a clean, minimal HTML template system. The built-in template is
+// made of the layout, header, toc and footer templates; LoadTemplateDir replaces any of them.
 type MinimalTemplate struct {
 	htmlTemplate *template.Template
 }
 
 // NewMinimalTemplate creates a new minimal template instance
 func NewMinimalTemplate() *MinimalTemplate {
-	tmpl := template.Must(template.New("minimal").Parse(minimalHTMLTemplate))
 	return &MinimalTemplate{
-		htmlTemplate: tmpl,
+		htmlTemplate: template.Must(builtinTemplate.Clone()),
 	}
 }
 
-// TemplateData holds data for template rendering
+// builtinTemplate is the parsed built-in template set
+var builtinTemplate = template.Must(template.New("minimal").Parse(minimalHTMLTemplate))
+
+// TemplateData holds the data available to HTML templates. Custom templates receive the same
+// data as the built-in template.
 type TemplateData struct {
-	Title       string
-	Description string
-	Content     template.HTML
-	Metadata    map[string]string
-	HasMetadata bool
-	Generator   string
-	CSS         template.CSS
+	Title       string            // Book title
+	Description string            // Book description
+	Language    string            // Language of the document (default "en")
+	Content     template.HTML     // Rendered document body
+	Metadata    map[string]string // Display metadata, e.g. "Academic Year" and "Pages"
+	HasMetadata bool              // Metadata is not empty; set by Render
+	Generator   string            // Name of the generator (default "Slim Academy")
+	CSS         template.CSS      // Stylesheet (default: the built-in stylesheet)
+	Header      template.HTML     // Running header of the source document, if streamed
+	Footer      template.HTML     // Running footer of the source document, if streamed
+
+	// TOC lists the headings of the document, nested by level
+	TOC []TOCEntry
+	// Chapters is the chapter hierarchy of the book, from its chapter list
+	Chapters []TOCEntry
+	// IncludeTOC makes the built-in toc template render TOC; custom templates may ignore it
+	IncludeTOC bool
+}
+
+// TOCEntry is a heading or chapter in TemplateData
+type TOCEntry struct {
+	Title    string
+	Level    int        // Heading level, or depth in the chapter hierarchy starting at 1
+	Anchor   string     // ID of the heading, without "#"; empty for chapters without a heading
+	Href     string     // Link to the entry on another page, set by the static site
+	Current  bool       // The entry is the page being rendered, set by the static site
+	Children []TOCEntry // Entries nested below this one
 }
 
 // DefaultCSS returns minimal, clean CSS styling
@@ -43,19 +67,23 @@ func (mt *MinimalTemplate) Render(data TemplateData) (string, error) {
 	if data.Generator == "" {
 		data.Generator = "Slim Academy"
 	}
+	if data.Language == "" {
+		data.Language = "en"
+	}
 	if data.CSS == "" {
 		data.CSS = DefaultCSS()
 	}
 	data.HasMetadata = len(data.Metadata) > 0
 
 	var result strings.Builder
-	err := mt.htmlTemplate.Execute(&result, data)
+	err := mt.htmlTemplate.ExecuteTemplate(&result, LayoutTemplate, data)
 	return result.String(), err
 }
 
-// minimalHTMLTemplate is a clean, semantic HTML5 template
-const minimalHTMLTemplate = `<!DOCTYPE html>
-<html lang="en">
+// minimalHTMLTemplate is a clean, semantic HTML5 template. The layout includes the header,
+// toc and footer templates, which can be replaced separately.
+const minimalHTMLTemplate = `{{define "layout"}}<!DOCTYPE html>
+<html lang="{{.Language}}">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
@@ -65,8 +93,20 @@ const minimalHTMLTemplate = `<!DOCTYPE html>
     <style>{{.CSS}}</style>
 </head>
 <body>
+    {{if .Header}}<header class="running-header">
+{{.Header}}    </header>{{end}}
     <main class="document">
-        <header class="document-header">
+        {{template "header" .}}
+        {{- template "toc" .}}
+
+        <div class="document-content">
+            {{.Content}}
+        </div>
+    </main>
+    {{template "footer" .}}
+</body>
+</html>{{end}}
+{{- define "header"}}<header class="document-header">
             <h1 class="document-title">{{.Title}}</h1>
             {{if .Description}}<p class="document-description">{{.Description}}</p>{{end}}
             {{if .HasMetadata}}
@@ -78,14 +118,17 @@ const minimalHTMLTemplate = `<!DOCTYPE html>
                 {{end}}
             </div>
             {{end}}
-        </header>
-
-        <div class="document-content">
-            {{.Content}}
-        </div>
-    </main>
-</body>
-</html>`
+        </header>{{end}}
+{{- define "toc"}}{{if and .IncludeTOC .TOC}}
+        <nav class="table-of-contents">
+            <h2 class="toc-title">Table of Contents</h2>
+            {{template "toc-list" .TOC}}
+        </nav>{{end}}{{end}}
+{{- define "toc-list"}}<ul class="toc-list">{{range .}}
+                <li>{{if .Anchor}}<a href="#{{.Anchor}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}{{if .Children}}{{template "toc-list" .Children}}{{end}}</li>{{end}}
+            </ul>{{end}}
+{{- define "footer"}}{{if .Footer}}<footer class="running-footer">
+{{.Footer}}    </footer>{{end}}{{end}}`
 
 // minimalCSS provides clean, readable styling without complexity
 const minimalCSS = `/* Reset and base styles */
@@ -254,6 +297,39 @@ body {
     font-style: italic;
 }
 
+.document-content hr {
+    border: none;
+    border-top: 1px solid #e5e5e5;
+    margin: 2rem 0;
+}
+
+.document-content .note-ref a {
+    text-decoration: none;
+}
+
+.document-content aside.note {
+    margin: 0.5rem 0 1rem;
+    padding: 0.5rem 1rem;
+    border-left: 3px solid #e5e5e5;
+    color: #555;
+    font-size: 0.875rem;
+}
+
+.running-header,
+.running-footer {
+    max-width: 800px;
+    margin: 0 auto;
+    padding: 0.5rem 1.5rem;
+    color: #666;
+    font-size: 0.875rem;
+    text-align: center;
+}
+
+.running-header p,
+.running-footer p {
+    margin: 0;
+}
+
 /* Responsive design */
 @media (max-width: 768px) {
     .document {
@@ -285,4 +361,17 @@ body {
         color: #000;
         text-decoration: underline;
     }
+
+    .page-break {
+        break-after: page;
+        page-break-after: always;
+    }
+
+    .running-header {
+        position: running(header);
+    }
+
+    .running-footer {
+        position: running(footer);
+    }
 }`
@@ -0,0 +1,330 @@
+package templates
+
+import (
+	"html/template"
+	"strings"
+)
+
+// SiteLink is a link to another page of the static site
+type SiteLink struct {
+	Title string
+	Href  string
+}
+
+// SitePageData holds the data of a page of the static site. The book index page has
+// IsIndex set and lists Contents; chapter pages only have the sidebar.
+type SitePageData struct {
+	BookTitle   string            // Title of the book
+	Title       string            // Title of the page; the book title for the index page
+	Description string            // Book description, shown on the index page
+	Language    string            // Language of the document (default "en")
+	Generator   string            // Name of the generator (default "Slim Academy")
+	Metadata    map[string]string // Display metadata, shown on the index page
+	Content     template.HTML     // Rendered page body
+	Sidebar     []TOCEntry        // Pages of the book, nested by level, with the current page marked
+	Contents    []TOCEntry        // Table of contents of the book, on the index page
+	Prev        *SiteLink         // Previous page, nil on the index page
+	Next        *SiteLink         // Next page, nil on the last page
+	Search      bool              // Render the search box, backed by search-index.js
+	IsIndex     bool
+}
+
+// SiteBook is a book on the library landing page
+type SiteBook struct {
+	Title       string
+	Description string
+	Href        string // Link to the index page of the book
+	Pages       int
+}
+
+// SiteLibraryData holds the data of the library landing page of a batch conversion
+type SiteLibraryData struct {
+	Title     string // Page title (default "Library")
+	Language  string // Language of the page (default "en")
+	Generator string // Name of the generator (default "Slim Academy")
+	Books     []SiteBook
+}
+
+// siteTemplate is the parsed set of static site templates
+var siteTemplate = template.Must(template.New("site").Parse(siteHTMLTemplate))
+
+// RenderSitePage renders a page of the static site
+func RenderSitePage(data SitePageData) (string, error) {
+	if data.Generator == "" {
+		data.Generator = "Slim Academy"
+	}
+	if data.Language == "" {
+		data.Language = "en"
+	}
+
+	var result strings.Builder
+	err := siteTemplate.ExecuteTemplate(&result, "site-page", data)
+	return result.String(), err
+}
+
+// RenderSiteLibrary renders the library landing page linking to the books of a batch conversion
+func RenderSiteLibrary(data SiteLibraryData) (string, error) {
+	if data.Title == "" {
+		data.Title = "Library"
+	}
+	if data.Generator == "" {
+		data.Generator = "Slim Academy"
+	}
+	if data.Language == "" {
+		data.Language = "en"
+	}
+
+	var result strings.Builder
+	err := siteTemplate.ExecuteTemplate(&result, "site-library", struct {
+		SiteLibraryData
+		CSS template.CSS
+	}{data, SiteCSS(DefaultCSS())})
+	return result.String(), err
+}
+
+// SiteCSS returns the stylesheet of the static site: the document stylesheet followed by the
+// layout of the sidebar, pager and search box
+func SiteCSS(document template.CSS) template.CSS {
+	return document + template.CSS(siteLayoutCSS)
+}
+
+// SiteSearchScript returns the script that searches the search index from the search box
+func SiteSearchScript() string {
+	return siteSearchScript
+}
+
+// siteHTMLTemplate renders the pages of the static site and the library landing page
+const siteHTMLTemplate = `{{define "site-page"}}<!DOCTYPE html>
+<html lang="{{.Language}}">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{if .IsIndex}}{{.BookTitle}}{{else}}{{.Title}} · {{.BookTitle}}{{end}}</title>
+    {{if .Description}}<meta name="description" content="{{.Description}}">{{end}}
+    <meta name="generator" content="{{.Generator}}">
+    <link rel="stylesheet" href="style.css">
+</head>
+<body class="site">
+    <aside class="site-sidebar">
+        <a class="site-book-title" href="index.html">{{.BookTitle}}</a>
+        {{- if .Search}}
+        <div class="site-search">
+            <input type="search" id="site-search" placeholder="Search" aria-label="Search this book">
+            <ol id="site-search-results" class="site-search-results"></ol>
+        </div>
+        {{- end}}
+        <nav class="site-toc" aria-label="Pages">
+            {{template "site-toc" .Sidebar}}
+        </nav>
+    </aside>
+    <main class="site-main document">
+        {{- if .IsIndex}}
+        <header class="document-header">
+            <h1 class="document-title">{{.BookTitle}}</h1>
+            {{if .Description}}<p class="document-description">{{.Description}}</p>{{end}}
+            {{if .Metadata}}
+            <div class="document-metadata">
+                {{range $key, $value := .Metadata}}
+                <span class="metadata-item">
+                    <strong>{{$key}}:</strong> {{$value}}
+                </span>
+                {{end}}
+            </div>
+            {{end}}
+        </header>
+        {{- end}}
+        {{- if .Content}}
+
+        <div class="document-content">
+            {{.Content}}
+        </div>
+        {{- end}}
+        {{- if and .IsIndex .Contents}}
+
+        <nav class="table-of-contents">
+            <h2 class="toc-title">Contents</h2>
+            {{template "site-toc" .Contents}}
+        </nav>
+        {{- end}}
+
+        <nav class="site-pager" aria-label="Pages">
+            {{- with .Prev}}
+            <a class="site-prev" rel="prev" href="{{.Href}}">&larr; {{.Title}}</a>
+            {{- end}}
+            {{- with .Next}}
+            <a class="site-next" rel="next" href="{{.Href}}">{{.Title}} &rarr;</a>
+            {{- end}}
+        </nav>
+    </main>
+    {{- if .Search}}
+    <script src="search-index.js"></script>
+    <script src="search.js"></script>
+    {{- end}}
+</body>
+</html>
+{{end}}
+{{- define "site-toc"}}<ul class="toc-list">{{range .}}
+                <li{{if .Current}} class="current"{{end}}>{{if .Href}}<a href="{{.Href}}"{{if .Current}} aria-current="page"{{end}}>{{.Title}}</a>{{else}}{{.Title}}{{end}}{{if .Children}}{{template "site-toc" .Children}}{{end}}</li>{{end}}
+            </ul>{{end}}
+{{- define "site-library"}}<!DOCTYPE html>
+<html lang="{{.Language}}">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>{{.Title}}</title>
+    <meta name="generator" content="{{.Generator}}">
+    <style>{{.CSS}}</style>
+</head>
+<body>
+    <main class="document">
+        <header class="document-header">
+            <h1 class="document-title">{{.Title}}</h1>
+        </header>
+        <ul class="site-library">{{range .Books}}
+            <li>
+                <a href="{{.Href}}">{{.Title}}</a>
+                {{- if .Description}}
+                <p class="document-description">{{.Description}}</p>
+                {{- end}}
+                {{- if .Pages}}
+                <span class="metadata-item">{{.Pages}} pages</span>
+                {{- end}}
+            </li>{{end}}
+        </ul>
+    </main>
+</body>
+</html>
+{{end}}`
+
+// siteLayoutCSS lays out the sidebar, pager and search box of the static site
+const siteLayoutCSS = `
+/* Static site layout */
+body.site {
+    display: flex;
+    align-items: flex-start;
+    max-width: none;
+    margin: 0;
+    padding: 0;
+}
+
+.site-sidebar {
+    position: sticky;
+    top: 0;
+    flex: 0 0 18rem;
+    max-height: 100vh;
+    overflow-y: auto;
+    padding: 1.5rem 1rem;
+    border-right: 1px solid #e0e0e0;
+    font-size: 0.9rem;
+}
+
+.site-book-title {
+    display: block;
+    margin-bottom: 1rem;
+    font-weight: bold;
+}
+
+.site-sidebar .toc-list {
+    padding-left: 1rem;
+}
+
+.site-sidebar li.current > a {
+    font-weight: bold;
+}
+
+.site-main {
+    flex: 1;
+    min-width: 0;
+    max-width: 50rem;
+    padding: 1.5rem 2rem;
+}
+
+.site-search input {
+    width: 100%;
+    padding: 0.3rem;
+}
+
+.site-search-results {
+    padding-left: 1.2rem;
+}
+
+.site-pager {
+    display: flex;
+    justify-content: space-between;
+    gap: 1rem;
+    margin-top: 3rem;
+    padding-top: 1rem;
+    border-top: 1px solid #e0e0e0;
+}
+
+.site-next {
+    margin-left: auto;
+}
+
+.site-library li {
+    margin-bottom: 1rem;
+}
+
+@media (max-width: 48rem) {
+    body.site {
+        display: block;
+    }
+
+    .site-sidebar {
+        position: static;
+        max-height: none;
+        border-right: none;
+        border-bottom: 1px solid #e0e0e0;
+    }
+}
+`
+
+// siteSearchScript searches the pages listed in the search index for all words typed into the
+// search box. search-index.js sets window.searchIndex, which also works for sites opened from
+// disk, where browsers block fetching search-index.json.
+const siteSearchScript = `(function () {
+  var input = document.getElementById("site-search");
+  var results = document.getElementById("site-search-results");
+  if (!input || !results) {
+    return;
+  }
+
+  var pages = null;
+  function load() {
+    if (pages) {
+      return Promise.resolve(pages);
+    }
+    if (window.searchIndex) {
+      pages = window.searchIndex.pages || [];
+      return Promise.resolve(pages);
+    }
+    return fetch("search-index.json")
+      .then(function (response) { return response.json(); })
+      .then(function (index) { pages = index.pages || []; return pages; });
+  }
+
+  input.addEventListener("input", function () {
+    var terms = input.value.toLowerCase().split(/\s+/).filter(Boolean);
+    if (terms.length === 0) {
+      results.replaceChildren();
+      return;
+    }
+    load().then(function (pages) {
+      results.replaceChildren();
+      pages.filter(function (page) {
+        var text = [page.title].concat(page.headings || [], page.text || "").join(" ").toLowerCase();
+        return terms.every(function (term) { return text.indexOf(term) >= 0; });
+      }).slice(0, 20).forEach(function (page) {
+        var link = document.createElement("a");
+        link.href = page.url;
+        link.textContent = page.title;
+        var item = document.createElement("li");
+        item.appendChild(link);
+        results.appendChild(item);
+      });
+    }).catch(function () {
+      results.replaceChildren();
+    });
+  });
+})();
+`
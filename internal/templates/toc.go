@@ -0,0 +1,53 @@
+package templates
+
+import "github.com/kjanat/slimacademy/internal/models"
+
+// NestTOC nests a flat list of headings by level, so that each heading holds the deeper
+// headings following it
+func NestTOC(headings []TOCEntry) []TOCEntry {
+	var nest func(i, level int) ([]TOCEntry, int)
+	nest = func(i, level int) ([]TOCEntry, int) {
+		var entries []TOCEntry
+		for i < len(headings) && headings[i].Level >= level {
+			entry := headings[i]
+			entry.Children, i = nest(i+1, entry.Level+1)
+			entries = append(entries, entry)
+		}
+		return entries, i
+	}
+
+	var entries []TOCEntry
+	for i := 0; i < len(headings); {
+		// Headings above the level of the first heading start a new top-level entry
+		var nested []TOCEntry
+		nested, i = nest(i, headings[i].Level)
+		entries = append(entries, nested...)
+	}
+	return entries
+}
+
+// ChapterTOC converts a chapter hierarchy into entries. Chapters link to the first heading
+// with the same title in headings, if any.
+func ChapterTOC(chapters []models.Chapter, headings []TOCEntry) []TOCEntry {
+	anchors := make(map[string]string)
+	for _, heading := range headings {
+		if _, ok := anchors[heading.Title]; !ok && heading.Anchor != "" {
+			anchors[heading.Title] = heading.Anchor
+		}
+	}
+
+	var convert func(chapters []models.Chapter, level int) []TOCEntry
+	convert = func(chapters []models.Chapter, level int) []TOCEntry {
+		var entries []TOCEntry
+		for _, chapter := range chapters {
+			entries = append(entries, TOCEntry{
+				Title:    chapter.Title,
+				Level:    level,
+				Anchor:   anchors[chapter.Title],
+				Children: convert(chapter.SubChapters, level+1),
+			})
+		}
+		return entries
+	}
+	return convert(chapters, 1)
+}
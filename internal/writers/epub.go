@@ -3,13 +3,19 @@ package writers
 import (
 	"archive/zip"
 	"bytes"
+	"cmp"
+	"context"
 	"crypto/rand"
 	"fmt"
 	"io"
+	"log/slog"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/kjanat/slimacademy/internal/config"
+	"github.com/kjanat/slimacademy/internal/hast"
+	"github.com/kjanat/slimacademy/internal/models"
 	"github.com/kjanat/slimacademy/internal/streaming"
 )
 
@@ -22,6 +28,7 @@ func init() {
 		// Initialize with configuration
 		writer.buffer = &bytes.Buffer{}
 		writer.epubWriter = NewEPUBWriterWithConfig(writer.buffer, cfg.EPUB)
+		writer.epubWriter.htmlWriter.configureTransforms(cfg)
 		return writer
 	}, WriterMetadata{
 		Name:        "EPUB",
@@ -43,6 +50,20 @@ type EPUBWriter struct {
 	chapters       []Chapter
 	currentChapter *Chapter
 	lastError      error
+	inRunningBlock bool // Inside a running header or footer, which reflowable books do not have
+
+	// Chapter splitting and navigation
+	bookChapters  map[string]bool   // Top-level chapter titles from the StartDoc event
+	tocEntries    []epubTOCEntry    // Every heading in document order
+	contents      *streaming.Event  // First TableOfContents event, whose entries the navigation lists
+	anchorFiles   map[string]string // Heading anchor ID to chapter filename
+	usedFilenames map[string]bool
+
+	// Embedded images
+	ctx          context.Context // Conversion context, cancels image downloads
+	imageFetcher ImageFetcher
+	images       []EPUBImage
+	imageByURL   map[string]string // Source URL to href within OEBPS
 }
 
 // Chapter represents a chapter in the EPUB
@@ -53,6 +74,22 @@ type Chapter struct {
 	Content  string
 }
 
+// epubTOCEntry records a heading and the chapter file it was written to
+type epubTOCEntry struct {
+	Level    int
+	Title    string
+	AnchorID string
+	Filename string
+}
+
+// EPUBImage represents an image stored inside the EPUB package
+type EPUBImage struct {
+	ID        string
+	Filename  string // Path relative to OEBPS/
+	MediaType string
+	Data      []byte
+}
+
 // NewEPUBWriter returns a new EPUBWriter that writes an EPUB file to the specified output using default configuration.
 func NewEPUBWriter(output io.Writer) *EPUBWriter {
 	return NewEPUBWriterWithConfig(output, nil)
@@ -66,13 +103,44 @@ func NewEPUBWriterWithConfig(output io.Writer, cfg *config.EPUBConfig) *EPUBWrit
 	}
 	zipWriter := zip.NewWriter(output)
 	return &EPUBWriter{
-		config:     cfg,
-		htmlWriter: NewHTMLWriterWithConfig(cfg.HTMLConfig),
-		zipWriter:  zipWriter,
-		output:     output,
-		uuid:       generateUUID(),
-		chapters:   make([]Chapter, 0),
+		config:        cfg,
+		htmlWriter:    NewHTMLWriterWithConfig(cfg.HTMLConfig),
+		zipWriter:     zipWriter,
+		output:        output,
+		uuid:          generateUUID(),
+		chapters:      make([]Chapter, 0),
+		ctx:           context.Background(),
+		imageFetcher:  newImageFetcher(cfg),
+		imageByURL:    make(map[string]string),
+		anchorFiles:   make(map[string]string),
+		usedFilenames: make(map[string]bool),
+	}
+}
+
+// newImageFetcher returns the image fetcher implied by the configuration, or nil if images are not embedded
+func newImageFetcher(cfg *config.EPUBConfig) ImageFetcher {
+	if !cfg.IncludeImages {
+		return nil
+	}
+	if cfg.ImageDirectory != "" {
+		return ChainImageFetcher{NewLocalImageFetcher(cfg.ImageDirectory), NewHTTPImageFetcher(nil)}
 	}
+	return NewHTTPImageFetcher(nil)
+}
+
+// SetImageFetcher replaces the fetcher used to embed images; nil keeps remote image URLs
+func (w *EPUBWriter) SetImageFetcher(fetcher ImageFetcher) {
+	w.imageFetcher = fetcher
+}
+
+// SetTransformer sets the passes applied to each chapter body
+func (w *EPUBWriter) SetTransformer(transformer *hast.Transformer) {
+	w.htmlWriter.SetTransformer(transformer)
+}
+
+// SetContext sets the context of the conversion; cancelling it aborts image downloads
+func (w *EPUBWriter) SetContext(ctx context.Context) {
+	w.ctx = ctx
 }
 
 // Handle processes a single event
@@ -80,33 +148,23 @@ func (w *EPUBWriter) Handle(event streaming.Event) {
 	switch event.Kind {
 	case streaming.StartDoc:
 		w.title = event.Title
+		w.bookChapters = topLevelChapterTitles(event.Chapters)
 		// Initialize the HTML writer
 		w.htmlWriter.Reset()
 
 	case streaming.StartHeading:
-		// Create a new chapter for each heading
-		if w.currentChapter != nil {
-			// Finalize previous chapter
-			w.currentChapter.Content = w.htmlWriter.Result()
-			w.chapters = append(w.chapters, *w.currentChapter)
-		}
-
-		// Start new chapter
-		w.currentChapter = &Chapter{
-			ID:       event.AnchorID,
-			Title:    event.HeadingText.Value(),
-			Filename: w.config.GetChapterFilename(event.HeadingText.Value(), event.AnchorID),
+		title := event.HeadingText.Value()
+		if w.currentChapter == nil || w.isSplitHeading(event.Level, title) {
+			w.startChapter(title, event.AnchorID)
 		}
-		w.htmlWriter.Reset()
-		w.htmlWriter.Handle(streaming.Event{Kind: streaming.StartDoc, Title: event.HeadingText.Value()})
+		w.recordHeading(event.Level, title, event.AnchorID)
+		w.htmlWriter.Handle(event)
 
 	case streaming.EndDoc:
 		// Finalize last chapter
-		if w.currentChapter != nil {
-			w.htmlWriter.Handle(event)
-			w.currentChapter.Content = w.htmlWriter.Result()
-			w.chapters = append(w.chapters, *w.currentChapter)
-		}
+		w.finishChapter()
+		w.transformChapters()
+		w.rewriteCrossFileLinks()
 
 		// Generate EPUB files
 		if err := w.generateEPUB(); err != nil {
@@ -114,12 +172,207 @@ func (w *EPUBWriter) Handle(event streaming.Event) {
 			w.lastError = err
 		}
 
+	case streaming.Image:
+		if w.inRunningBlock {
+			return
+		}
+		w.ensureChapter()
+		w.htmlWriter.Handle(w.embedImage(event))
+
+	case streaming.TableOfContents:
+		if w.contents == nil {
+			w.contents = &event
+		}
+		if w.inRunningBlock {
+			return
+		}
+		w.ensureChapter()
+		w.htmlWriter.Handle(event)
+
+	case streaming.StartHeader, streaming.StartFooter:
+		w.inRunningBlock = true
+
+	case streaming.EndHeader, streaming.EndFooter:
+		w.inRunningBlock = false
+
 	default:
+		if w.inRunningBlock {
+			return
+		}
 		// Forward all other events to HTML writer
+		w.ensureChapter()
 		w.htmlWriter.Handle(event)
 	}
 }
 
+// topLevelChapterTitles returns the trimmed titles of the book's top-level chapters
+func topLevelChapterTitles(chapters []models.Chapter) map[string]bool {
+	titles := make(map[string]bool, len(chapters))
+	for _, chapter := range chapters {
+		if title := strings.TrimSpace(chapter.Title); title != "" {
+			titles[title] = true
+		}
+	}
+	return titles
+}
+
+// isSplitHeading reports whether a heading starts a new chapter file
+func (w *EPUBWriter) isSplitHeading(level int, title string) bool {
+	if !w.config.ChapterSplit {
+		return false
+	}
+	if w.config.SplitOnChapters && len(w.bookChapters) > 0 {
+		return w.bookChapters[strings.TrimSpace(title)]
+	}
+
+	splitLevel := w.config.SplitLevel
+	if splitLevel <= 0 {
+		splitLevel = 2
+	}
+	return level <= splitLevel
+}
+
+// ensureChapter starts a chapter for content that appears before the first split heading
+func (w *EPUBWriter) ensureChapter() {
+	if w.currentChapter != nil {
+		return
+	}
+	if w.config.ChapterSplit {
+		w.startChapter(w.title, "front")
+	} else {
+		w.startChapter(w.title, "content")
+	}
+}
+
+// startChapter finalizes the current chapter and begins a new chapter file
+func (w *EPUBWriter) startChapter(title, id string) {
+	w.finishChapter()
+
+	if id == "" {
+		id = fmt.Sprintf("%03d", len(w.chapters)+1)
+	}
+	filename := w.config.GetChapterFilename(title, id)
+	if w.usedFilenames[filename] {
+		base := strings.TrimSuffix(filename, ".xhtml")
+		for i := 2; w.usedFilenames[filename]; i++ {
+			filename = fmt.Sprintf("%s_%d.xhtml", base, i)
+		}
+	}
+	w.usedFilenames[filename] = true
+
+	w.currentChapter = &Chapter{
+		ID:       fmt.Sprintf("chapter%03d", len(w.chapters)+1),
+		Title:    title,
+		Filename: filename,
+	}
+	w.htmlWriter.Reset()
+}
+
+// finishChapter stores the rendered body of the current chapter
+func (w *EPUBWriter) finishChapter() {
+	if w.currentChapter == nil {
+		return
+	}
+	w.htmlWriter.closeSection()
+	w.currentChapter.Content = w.htmlWriter.content.String()
+	w.chapters = append(w.chapters, *w.currentChapter)
+	w.currentChapter = nil
+}
+
+// transformChapters applies the transform passes once to all chapters, and gives the navigation
+// the titles of the transformed headings
+func (w *EPUBWriter) transformChapters() {
+	parts := make([]string, len(w.chapters))
+	for i, chapter := range w.chapters {
+		parts[i] = chapter.Content
+	}
+	parts, headings := w.htmlWriter.transformParts(parts)
+	if err := w.htmlWriter.Err(); err != nil {
+		w.lastError = err
+		return
+	}
+	for i := range w.chapters {
+		w.chapters[i].Content = parts[i]
+	}
+
+	titles := make(map[string]string, len(headings))
+	for _, heading := range headings {
+		titles[heading.ID] = strings.TrimSpace(heading.Text)
+	}
+	for i, entry := range w.tocEntries {
+		if title, ok := titles[entry.AnchorID]; ok && entry.AnchorID != "" {
+			w.tocEntries[i].Title = title
+		}
+	}
+}
+
+// recordHeading registers a heading for the navigation document and cross-file link rewriting
+func (w *EPUBWriter) recordHeading(level int, title, anchorID string) {
+	w.tocEntries = append(w.tocEntries, epubTOCEntry{
+		Level:    level,
+		Title:    title,
+		AnchorID: anchorID,
+		Filename: w.currentChapter.Filename,
+	})
+	if anchorID != "" {
+		w.anchorFiles[anchorID] = w.currentChapter.Filename
+	}
+}
+
+// anchorLinkPattern matches fragment-only links produced by the HTML writer
+var anchorLinkPattern = regexp.MustCompile(`href="#([^"]+)"`)
+
+// rewriteCrossFileLinks points fragment links at the chapter file that contains the anchor
+func (w *EPUBWriter) rewriteCrossFileLinks() {
+	for i := range w.chapters {
+		chapter := &w.chapters[i]
+		chapter.Content = anchorLinkPattern.ReplaceAllStringFunc(chapter.Content, func(match string) string {
+			anchorID := anchorLinkPattern.FindStringSubmatch(match)[1]
+			filename, exists := w.anchorFiles[anchorID]
+			if !exists || filename == chapter.Filename {
+				return match
+			}
+			return fmt.Sprintf(`href="%s#%s"`, filename, anchorID)
+		})
+	}
+}
+
+// embedImage fetches the image referenced by the event and rewrites its URL to the packaged copy.
+// The remote URL is kept when no fetcher is configured or the image cannot be resolved.
+func (w *EPUBWriter) embedImage(event streaming.Event) streaming.Event {
+	if w.imageFetcher == nil || event.ImageURL == "" {
+		return event
+	}
+
+	if href, exists := w.imageByURL[event.ImageURL]; exists {
+		event.ImageURL = href
+		return event
+	}
+
+	img, err := w.imageFetcher.Fetch(w.ctx, event.ImageURL)
+	if err != nil {
+		slog.Warn("Failed to embed EPUB image, keeping remote URL", "url", event.ImageURL, "error", err)
+		return event
+	}
+
+	if w.config.ImageCompression {
+		img = compressImage(img, w.config.ImageQuality)
+	}
+
+	id := fmt.Sprintf("img_%03d", len(w.images)+1)
+	href := "images/" + id + imageExtension(img.MediaType)
+	w.images = append(w.images, EPUBImage{
+		ID:        id,
+		Filename:  href,
+		MediaType: img.MediaType,
+		Data:      img.Data,
+	})
+	w.imageByURL[event.ImageURL] = href
+
+	event.ImageURL = href
+	return event
+}
+
 // generateEPUB creates the EPUB file structure
 func (w *EPUBWriter) generateEPUB() error {
 	// Write mimetype file (must be first and uncompressed)
@@ -149,9 +402,30 @@ func (w *EPUBWriter) generateEPUB() error {
 		return err
 	}
 
+	// Write nav.xhtml
+	if w.config.GenerateTOC {
+		if err := w.writeFile("OEBPS/nav.xhtml", w.getNavXHTML()); err != nil {
+			return err
+		}
+	}
+
 	// Write chapter files
 	for _, chapter := range w.chapters {
-		if err := w.writeFile(fmt.Sprintf("OEBPS/%s", chapter.Filename), chapter.Content); err != nil {
+		if err := w.writeFile(fmt.Sprintf("OEBPS/%s", chapter.Filename), w.getChapterXHTML(chapter)); err != nil {
+			return err
+		}
+	}
+
+	// Write images (already compressed formats, so store them as-is)
+	for _, img := range w.images {
+		imageWriter, err := w.zipWriter.CreateHeader(&zip.FileHeader{
+			Name:   "OEBPS/" + img.Filename,
+			Method: zip.Store,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := imageWriter.Write(img.Data); err != nil {
 			return err
 		}
 	}
@@ -199,6 +473,23 @@ func (w *EPUBWriter) getContentOPF() string {
 		spine.WriteString("\n")
 	}
 
+	// Add embedded images to manifest
+	for _, img := range w.images {
+		manifest.WriteString(fmt.Sprintf(`    <item id="%s" href="%s" media-type="%s"/>`,
+			img.ID, img.Filename, img.MediaType))
+		manifest.WriteString("\n")
+	}
+
+	// Add navigation document; the nav property only exists in EPUB 3
+	if w.config.GenerateTOC {
+		navProperties := ""
+		if strings.HasPrefix(w.config.Version, "3") {
+			navProperties = ` properties="nav"`
+		}
+		manifest.WriteString(fmt.Sprintf(`    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml"%s/>`, navProperties))
+		manifest.WriteString("\n")
+	}
+
 	customMeta := w.config.GetCustomMetadataElements()
 
 	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
@@ -222,25 +513,131 @@ func (w *EPUBWriter) getContentOPF() string {
 		customMeta, manifest.String(), spine.String())
 }
 
+// epubTOCNode is a heading in the nested navigation tree
+type epubTOCNode struct {
+	entry    epubTOCEntry
+	children []*epubTOCNode
+}
+
+// navEntries returns the entries of the table of contents, so the navigation follows its depth,
+// dedupe and nesting options, or every heading when the book has no table of contents
+func (w *EPUBWriter) navEntries() []epubTOCEntry {
+	if w.contents == nil {
+		return w.tocEntries
+	}
+
+	// The recorded headings carry the titles of transformed headings
+	titles := make(map[string]string, len(w.tocEntries))
+	for _, entry := range w.tocEntries {
+		titles[entry.AnchorID] = entry.Title
+	}
+
+	entries := make([]epubTOCEntry, 0, len(w.contents.TOCEntries))
+	for _, entry := range w.contents.TOCEntries {
+		filename, ok := w.anchorFiles[entry.AnchorID]
+		if !ok {
+			continue
+		}
+		entries = append(entries, epubTOCEntry{
+			Level:    entry.Level,
+			Title:    cmp.Or(titles[entry.AnchorID], entry.Text),
+			AnchorID: entry.AnchorID,
+			Filename: filename,
+		})
+	}
+	return entries
+}
+
+// buildTOCTree nests the navigation entries by level, keeping at most TOCDepth levels below the
+// shallowest entry. A flat table of contents stays flat.
+func (w *EPUBWriter) buildTOCTree() []*epubTOCNode {
+	entries := w.navEntries()
+	if len(entries) == 0 {
+		return nil
+	}
+	nested := w.contents == nil || w.contents.TOCNested
+
+	minLevel := entries[0].Level
+	for _, entry := range entries {
+		minLevel = min(minLevel, entry.Level)
+	}
+
+	var roots []*epubTOCNode
+	var stack []*epubTOCNode
+	for _, entry := range entries {
+		if w.config.TOCDepth > 0 && entry.Level-minLevel >= w.config.TOCDepth {
+			continue
+		}
+
+		node := &epubTOCNode{entry: entry}
+		if !nested {
+			roots = append(roots, node)
+			continue
+		}
+		for len(stack) > 0 && stack[len(stack)-1].entry.Level >= entry.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, node)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, node)
+		}
+		stack = append(stack, node)
+	}
+
+	return roots
+}
+
+// tocTreeDepth returns the depth of the deepest branch in the navigation tree
+func tocTreeDepth(nodes []*epubTOCNode) int {
+	depth := 0
+	for _, node := range nodes {
+		depth = max(depth, 1+tocTreeDepth(node.children))
+	}
+	return depth
+}
+
+// href returns the link target of a navigation entry
+func (e epubTOCEntry) href() string {
+	if e.AnchorID == "" {
+		return e.Filename
+	}
+	return e.Filename + "#" + e.AnchorID
+}
+
 // getTocNCX returns the toc.ncx content
 func (w *EPUBWriter) getTocNCX() string {
 	var navPoints strings.Builder
 
-	for i, chapter := range w.chapters {
-		navPoints.WriteString(fmt.Sprintf(`    <navPoint id="%s" playOrder="%d">
-      <navLabel>
-        <text>%s</text>
-      </navLabel>
-      <content src="%s"/>
-    </navPoint>`, chapter.ID, i+1, escapeXML(chapter.Title), chapter.Filename))
-		navPoints.WriteString("\n")
+	tree := w.buildTOCTree()
+	if len(tree) == 0 {
+		// Fall back to one entry per chapter file when there are no headings
+		for _, chapter := range w.chapters {
+			tree = append(tree, &epubTOCNode{entry: epubTOCEntry{Title: chapter.Title, Filename: chapter.Filename}})
+		}
 	}
 
+	playOrder := 0
+	var writeNavPoints func(nodes []*epubTOCNode, indent string)
+	writeNavPoints = func(nodes []*epubTOCNode, indent string) {
+		for _, node := range nodes {
+			playOrder++
+			fmt.Fprintf(&navPoints, "%s<navPoint id=\"navpoint-%d\" playOrder=\"%d\">\n", indent, playOrder, playOrder)
+			fmt.Fprintf(&navPoints, "%s  <navLabel>\n%s    <text>%s</text>\n%s  </navLabel>\n",
+				indent, indent, escapeXML(node.entry.Title), indent)
+			fmt.Fprintf(&navPoints, "%s  <content src=\"%s\"/>\n", indent, escapeXML(node.entry.href()))
+			writeNavPoints(node.children, indent+"  ")
+			fmt.Fprintf(&navPoints, "%s</navPoint>\n", indent)
+		}
+	}
+	writeNavPoints(tree, "    ")
+
 	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
 <ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
   <head>
     <meta name="dtb:uid" content="%s"/>
-    <meta name="dtb:depth" content="1"/>
+    <meta name="dtb:depth" content="%d"/>
     <meta name="dtb:totalPageCount" content="0"/>
     <meta name="dtb:maxPageNumber" content="0"/>
   </head>
@@ -249,7 +646,62 @@ func (w *EPUBWriter) getTocNCX() string {
   </docTitle>
   <navMap>
 %s  </navMap>
-</ncx>`, w.uuid, escapeXML(w.title), navPoints.String())
+</ncx>`, w.uuid, max(1, tocTreeDepth(tree)), escapeXML(w.title), navPoints.String())
+}
+
+// getNavXHTML returns the nested navigation document
+func (w *EPUBWriter) getNavXHTML() string {
+	var nav strings.Builder
+
+	var writeList func(nodes []*epubTOCNode, indent string)
+	writeList = func(nodes []*epubTOCNode, indent string) {
+		if len(nodes) == 0 {
+			return
+		}
+		fmt.Fprintf(&nav, "%s<ol>\n", indent)
+		for _, node := range nodes {
+			fmt.Fprintf(&nav, "%s  <li><a href=\"%s\">%s</a>", indent, escapeXML(node.entry.href()), escapeXML(node.entry.Title))
+			if len(node.children) > 0 {
+				nav.WriteString("\n")
+				writeList(node.children, indent+"    ")
+				fmt.Fprintf(&nav, "%s  ", indent)
+			}
+			nav.WriteString("</li>\n")
+		}
+		fmt.Fprintf(&nav, "%s</ol>\n", indent)
+	}
+	writeList(w.buildTOCTree(), "    ")
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="%s">
+<head>
+  <title>%s</title>
+  <link rel="stylesheet" type="text/css" href="styles.css"/>
+</head>
+<body>
+  <nav epub:type="toc" id="toc">
+    <h1>%s</h1>
+%s  </nav>
+</body>
+</html>`, escapeXML(w.config.Language), escapeXML(w.title), escapeXML(w.title), nav.String())
+}
+
+// getChapterXHTML wraps a chapter body in an XHTML document
+func (w *EPUBWriter) getChapterXHTML(chapter Chapter) string {
+	// The HTML writer emits void elements in HTML syntax
+	body := strings.ReplaceAll(chapter.Content, "<br>", "<br/>")
+
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="%s">
+<head>
+  <title>%s</title>
+  <link rel="stylesheet" type="text/css" href="styles.css"/>
+</head>
+<body>
+%s</body>
+</html>`, escapeXML(w.config.Language), escapeXML(chapter.Title), body)
 }
 
 // generateUUID returns a RFC 4122 version 4 UUID for the EPUB.
@@ -299,6 +751,14 @@ func (w *EPUBWriter) Reset() {
 	w.title = ""
 	w.uuid = generateUUID()
 	w.lastError = nil
+	w.inRunningBlock = false
+	w.images = nil
+	w.imageByURL = make(map[string]string)
+	w.bookChapters = nil
+	w.tocEntries = nil
+	w.contents = nil
+	w.anchorFiles = make(map[string]string)
+	w.usedFilenames = make(map[string]bool)
 }
 
 // SetOutput sets the output destination
@@ -372,6 +832,14 @@ func (w *EPUBWriterV2) Handle(event streaming.Event) error {
 		w.stats.TextChars += len(event.TextContent)
 	case streaming.Image:
 		w.stats.Images++
+	case streaming.PageBreak, streaming.HorizontalRule, streaming.SectionBreak, streaming.AutoText:
+		// Breaks and generated text
+	case streaming.Note:
+		// Notes are rendered as asides by the HTML writer
+	case streaming.TableOfContents:
+		// The navigation document lists the entries; the HTML writer renders the inline list
+	case streaming.StartHeader, streaming.EndHeader, streaming.StartFooter, streaming.EndFooter:
+		// Running headers and footers are dropped from reflowable content
 	default:
 		// Log unexpected event types for debugging
 		return fmt.Errorf("unhandled event type: %v", event.Kind)
@@ -396,10 +864,29 @@ func (w *EPUBWriterV2) Flush() ([]byte, error) {
 	return w.binaryData, nil
 }
 
+// SetContext sets the context of the conversion; cancelling it aborts image downloads
+func (w *EPUBWriterV2) SetContext(ctx context.Context) {
+	if w.epubWriter == nil {
+		w.buffer = &bytes.Buffer{}
+		w.epubWriter = NewEPUBWriter(w.buffer)
+	}
+	w.epubWriter.SetContext(ctx)
+}
+
 // Reset clears the writer state for reuse
 func (w *EPUBWriterV2) Reset() {
+	previous := w.epubWriter
 	w.buffer = &bytes.Buffer{}
-	w.epubWriter = NewEPUBWriter(w.buffer)
+	if previous != nil {
+		// Preserve configuration, transforms, context and image fetcher across documents
+		w.epubWriter = NewEPUBWriterWithConfig(w.buffer, previous.config)
+		w.epubWriter.htmlWriter.transformer = previous.htmlWriter.transformer
+		w.epubWriter.htmlWriter.configErr = previous.htmlWriter.configErr
+		w.epubWriter.SetImageFetcher(previous.imageFetcher)
+		w.epubWriter.SetContext(previous.ctx)
+	} else {
+		w.epubWriter = NewEPUBWriter(w.buffer)
+	}
 	w.stats = WriterStats{}
 	w.binaryData = nil
 }
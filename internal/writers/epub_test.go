@@ -5,11 +5,15 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 	"unique"
 
 	"github.com/kjanat/slimacademy/internal/config"
+	"github.com/kjanat/slimacademy/internal/models"
 	"github.com/kjanat/slimacademy/internal/streaming"
 )
 
@@ -353,4 +357,459 @@ func TestConvertBookToZipBinaryHandling(t *testing.T) {
 		t.Error("Binary data was corrupted during ZIP write/read")
 		t.Errorf("Original length: %d, Read length: %d", len(testData), readData.Len())
 	}
-}
\ No newline at end of file
+}
+// readEPUBFiles returns the contents of every file in an EPUB archive
+func readEPUBFiles(t *testing.T, data []byte) map[string]*zip.File {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Generated EPUB is not a valid ZIP archive: %v", err)
+	}
+	files := make(map[string]*zip.File)
+	for _, file := range reader.File {
+		files[file.Name] = file
+	}
+	return files
+}
+
+// readZipFile returns the content of a file in a ZIP archive
+func readZipFile(t *testing.T, file *zip.File) string {
+	t.Helper()
+	rc, err := file.Open()
+	if err != nil {
+		t.Fatalf("Failed to open %s: %v", file.Name, err)
+	}
+	defer rc.Close()
+	buf := new(bytes.Buffer)
+	if _, err := buf.ReadFrom(rc); err != nil {
+		t.Fatalf("Failed to read %s: %v", file.Name, err)
+	}
+	return buf.String()
+}
+
+// TestEPUBWriterEmbedsImages tests that image events are downloaded and packaged
+func TestEPUBWriterEmbedsImages(t *testing.T) {
+	pngData := testPNG(t)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(pngData)
+	}))
+	defer server.Close()
+
+	writer := &EPUBWriterV2{buffer: &bytes.Buffer{}}
+	writer.epubWriter = NewEPUBWriterWithConfig(writer.buffer, config.DefaultEPUBConfig())
+	writer.epubWriter.SetImageFetcher(NewHTTPImageFetcher(server.Client()))
+
+	imageURL := server.URL + "/figure.png"
+	events := []streaming.Event{
+		{Kind: streaming.StartDoc, Title: "Image Book"},
+		{Kind: streaming.StartHeading, Level: 1, HeadingText: unique.Make("Anatomy"), AnchorID: "anatomy"},
+		{Kind: streaming.Text, TextContent: "Anatomy"},
+		{Kind: streaming.EndHeading},
+		{Kind: streaming.StartParagraph},
+		{Kind: streaming.Image, ImageURL: imageURL, ImageAlt: "Figure"},
+		{Kind: streaming.Image, ImageURL: imageURL, ImageAlt: "Figure again"},
+		{Kind: streaming.EndParagraph},
+		{Kind: streaming.EndDoc},
+	}
+	for _, event := range events {
+		if err := writer.Handle(event); err != nil {
+			t.Fatalf("Failed to handle event: %v", err)
+		}
+	}
+
+	data, err := writer.Flush()
+	if err != nil {
+		t.Fatalf("Failed to flush EPUB writer: %v", err)
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected duplicate image URLs to be fetched once, got %d requests", requests)
+	}
+
+	files := readEPUBFiles(t, data)
+	imageFile, ok := files["OEBPS/images/img_001.png"]
+	if !ok {
+		t.Fatal("Expected embedded image OEBPS/images/img_001.png")
+	}
+	if readZipFile(t, imageFile) != string(pngData) {
+		t.Error("Embedded image data does not match fetched data")
+	}
+
+	opf := readZipFile(t, files["OEBPS/content.opf"])
+	if !strings.Contains(opf, `<item id="img_001" href="images/img_001.png" media-type="image/png"/>`) {
+		t.Errorf("Expected image in OPF manifest, got:\n%s", opf)
+	}
+
+	chapter := readZipFile(t, files["OEBPS/chapter_anatomy.xhtml"])
+	if !strings.Contains(chapter, `src="images/img_001.png"`) {
+		t.Error("Expected chapter to reference embedded image")
+	}
+	if strings.Contains(chapter, server.URL) {
+		t.Error("Expected remote image URL to be replaced")
+	}
+}
+
+// TestEPUBWriterImageFallback tests that unresolvable images keep their remote URL
+func TestEPUBWriterImageFallback(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	writer := &EPUBWriterV2{buffer: &bytes.Buffer{}}
+	writer.epubWriter = NewEPUBWriterWithConfig(writer.buffer, config.DefaultEPUBConfig())
+	writer.epubWriter.SetImageFetcher(NewHTTPImageFetcher(server.Client()))
+
+	imageURL := server.URL + "/missing.png"
+	events := []streaming.Event{
+		{Kind: streaming.StartDoc, Title: "Broken Image Book"},
+		{Kind: streaming.StartHeading, Level: 1, HeadingText: unique.Make("Intro"), AnchorID: "intro"},
+		{Kind: streaming.EndHeading},
+		{Kind: streaming.Image, ImageURL: imageURL, ImageAlt: "Missing"},
+		{Kind: streaming.EndDoc},
+	}
+	for _, event := range events {
+		if err := writer.Handle(event); err != nil {
+			t.Fatalf("Failed to handle event: %v", err)
+		}
+	}
+
+	data, err := writer.Flush()
+	if err != nil {
+		t.Fatalf("Failed to flush EPUB writer: %v", err)
+	}
+
+	files := readEPUBFiles(t, data)
+	for name := range files {
+		if strings.HasPrefix(name, "OEBPS/images/") {
+			t.Errorf("Unexpected embedded image %s", name)
+		}
+	}
+	if !strings.Contains(readZipFile(t, files["OEBPS/chapter_intro.xhtml"]), imageURL) {
+		t.Error("Expected remote image URL to be kept")
+	}
+}
+
+// TestEPUBWriterImageCancellation tests that cancelling the conversion aborts image downloads
+func TestEPUBWriterImageCancellation(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	multiWriter, err := NewMultiWriter(ctx, []string{"epub"}, config.DefaultConfig())
+	if err != nil {
+		t.Fatalf("NewMultiWriter() failed: %v", err)
+	}
+	defer multiWriter.Close()
+	writer := multiWriter.writers["epub"].(*EPUBWriterV2)
+	writer.epubWriter.SetImageFetcher(NewHTTPImageFetcher(server.Client()))
+
+	cancel()
+	start := time.Now()
+	for _, event := range []streaming.Event{
+		{Kind: streaming.StartDoc, Title: "Slow Image Book"},
+		{Kind: streaming.Image, ImageURL: server.URL + "/slow.png", ImageAlt: "Slow"},
+	} {
+		if err := writer.Handle(event); err != nil {
+			t.Fatalf("Failed to handle event: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the cancelled download to return at once, took %v", elapsed)
+	}
+	if len(writer.epubWriter.images) != 0 {
+		t.Error("Expected no image to be embedded after cancellation")
+	}
+}
+
+// TestEPUBWriterImagesDisabled tests that images are only fetched when IncludeImages is set
+func TestEPUBWriterImagesDisabled(t *testing.T) {
+	cfg := config.DefaultEPUBConfig()
+
+	writer := NewEPUBWriterWithConfig(&bytes.Buffer{}, cfg)
+	if writer.imageFetcher != nil {
+		t.Error("Expected no image fetcher by default")
+	}
+
+	cfg.IncludeImages = true
+	cfg.ImageDirectory = t.TempDir()
+	writer = NewEPUBWriterWithConfig(&bytes.Buffer{}, cfg)
+	if _, ok := writer.imageFetcher.(ChainImageFetcher); !ok {
+		t.Errorf("Expected chained fetcher when ImageDirectory is set, got %T", writer.imageFetcher)
+	}
+}
+
+// writeEPUB renders events with the given config and returns the archive contents
+func writeEPUB(t *testing.T, cfg *config.EPUBConfig, events []streaming.Event) map[string]string {
+	t.Helper()
+
+	writer := &EPUBWriterV2{buffer: &bytes.Buffer{}}
+	writer.epubWriter = NewEPUBWriterWithConfig(writer.buffer, cfg)
+	for _, event := range events {
+		if err := writer.Handle(event); err != nil {
+			t.Fatalf("Failed to handle event: %v", err)
+		}
+	}
+
+	data, err := writer.Flush()
+	if err != nil {
+		t.Fatalf("Failed to flush EPUB writer: %v", err)
+	}
+
+	contents := make(map[string]string)
+	for name, file := range readEPUBFiles(t, data) {
+		contents[name] = readZipFile(t, file)
+	}
+	return contents
+}
+
+// splitTestEvents returns a document with front matter, two chapters and nested sections
+func splitTestEvents() []streaming.Event {
+	heading := func(level int, title, anchor string) []streaming.Event {
+		return []streaming.Event{
+			{Kind: streaming.StartHeading, Level: level, HeadingText: unique.Make(title), AnchorID: anchor},
+			{Kind: streaming.Text, TextContent: title},
+			{Kind: streaming.EndHeading},
+		}
+	}
+
+	events := []streaming.Event{
+		{Kind: streaming.StartDoc, Title: "Split Book"},
+		{Kind: streaming.StartParagraph},
+		{Kind: streaming.Text, TextContent: "Preface"},
+		{Kind: streaming.EndParagraph},
+	}
+	events = append(events, heading(2, "Anatomy", "anatomy")...)
+	events = append(events, heading(3, "Bones", "bones")...)
+	events = append(events, heading(4, "Skull", "skull")...)
+	events = append(events, heading(2, "Physiology", "physiology")...)
+	events = append(events,
+		streaming.Event{Kind: streaming.StartParagraph},
+		streaming.Event{Kind: streaming.StartFormatting, Style: streaming.Link, LinkURL: "#bones"},
+		streaming.Event{Kind: streaming.Text, TextContent: "see bones"},
+		streaming.Event{Kind: streaming.EndFormatting, Style: streaming.Link},
+		streaming.Event{Kind: streaming.EndParagraph},
+		streaming.Event{Kind: streaming.EndDoc},
+	)
+	return events
+}
+
+// TestEPUBWriterSplitsChapters tests chapter splitting, link rewriting and nested navigation
+func TestEPUBWriterSplitsChapters(t *testing.T) {
+	cfg := config.DefaultEPUBConfig()
+	cfg.TOCDepth = 2
+	files := writeEPUB(t, cfg, splitTestEvents())
+
+	for _, name := range []string{"OEBPS/chapter_front.xhtml", "OEBPS/chapter_anatomy.xhtml", "OEBPS/chapter_physiology.xhtml"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("Expected chapter file %s", name)
+		}
+	}
+	if _, ok := files["OEBPS/chapter_bones.xhtml"]; ok {
+		t.Error("Headings below the split level should not start a new file")
+	}
+
+	anatomy := files["OEBPS/chapter_anatomy.xhtml"]
+	if !strings.Contains(anatomy, "Bones") || !strings.Contains(anatomy, "Skull") {
+		t.Error("Expected subsections in the anatomy chapter")
+	}
+	if !strings.HasPrefix(anatomy, `<?xml`) || !strings.Contains(anatomy, `xmlns="http://www.w3.org/1999/xhtml"`) {
+		t.Error("Expected chapter to be an XHTML document")
+	}
+	if !strings.Contains(files["OEBPS/chapter_front.xhtml"], "Preface") {
+		t.Error("Expected content before the first heading in the front chapter")
+	}
+
+	if !strings.Contains(files["OEBPS/chapter_physiology.xhtml"], `href="chapter_anatomy.xhtml#bones"`) {
+		t.Error("Expected cross-file anchor link to be rewritten")
+	}
+
+	ncx := files["OEBPS/toc.ncx"]
+	if !strings.Contains(ncx, `<meta name="dtb:depth" content="2"/>`) {
+		t.Errorf("Expected NCX depth of 2, got:\n%s", ncx)
+	}
+	if strings.Contains(ncx, "Skull") {
+		t.Error("Expected headings beyond TOCDepth to be omitted from the NCX")
+	}
+	if !strings.Contains(ncx, `<content src="chapter_anatomy.xhtml#bones"/>`) {
+		t.Error("Expected nested NCX entry for subsection")
+	}
+
+	nav := files["OEBPS/nav.xhtml"]
+	if !strings.Contains(nav, `<nav epub:type="toc"`) || !strings.Contains(nav, `<a href="chapter_anatomy.xhtml#bones">Bones</a>`) {
+		t.Errorf("Expected nested navigation document, got:\n%s", nav)
+	}
+	if strings.Contains(files["OEBPS/content.opf"], `properties="nav"`) {
+		t.Error("EPUB 2 manifests should not declare the nav property")
+	}
+}
+
+// TestEPUBWriterNoSplit tests that disabling splitting produces a single content file
+func TestEPUBWriterNoSplit(t *testing.T) {
+	cfg := config.DefaultEPUBConfig()
+	cfg.ChapterSplit = false
+	cfg.Version = "3.0"
+	files := writeEPUB(t, cfg, splitTestEvents())
+
+	chapterFiles := 0
+	for name := range files {
+		if strings.HasPrefix(name, "OEBPS/chapter_") {
+			chapterFiles++
+		}
+	}
+	if chapterFiles != 1 {
+		t.Errorf("Expected 1 chapter file, got %d", chapterFiles)
+	}
+	if strings.Contains(files["OEBPS/chapter_content.xhtml"], `href="chapter_`) {
+		t.Error("Expected in-file anchor links to be left unchanged")
+	}
+	if !strings.Contains(files["OEBPS/content.opf"], `properties="nav"`) {
+		t.Error("Expected EPUB 3 manifest to declare the nav property")
+	}
+}
+
+// TestEPUBWriterSplitOnChapters tests splitting on the book's chapter hierarchy
+func TestEPUBWriterSplitOnChapters(t *testing.T) {
+	cfg := config.DefaultEPUBConfig()
+	cfg.SplitOnChapters = true
+	events := splitTestEvents()
+	events[0].Chapters = []models.Chapter{{Title: "Physiology"}}
+	files := writeEPUB(t, cfg, events)
+
+	if _, ok := files["OEBPS/chapter_anatomy.xhtml"]; ok {
+		t.Error("Expected headings without a matching chapter to stay in the current file")
+	}
+	if _, ok := files["OEBPS/chapter_physiology.xhtml"]; !ok {
+		t.Error("Expected a file for the Physiology chapter")
+	}
+}
+
+// TestEPUBWriterTransforms tests that the transform passes rewrite each chapter
+func TestEPUBWriterTransforms(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Transforms.Passes = []config.TransformPassConfig{
+		{Name: config.TransformRenumberHeadings, Options: map[string]string{"start": "2"}},
+		{Name: config.TransformExternalLinks},
+	}
+	factory, _ := Get("epub")
+	writer := factory(cfg)
+
+	// The passes survive a reset between documents
+	for range 2 {
+		writer.Reset()
+		for _, event := range hastTestEvents() {
+			if err := writer.Handle(event); err != nil {
+				t.Fatalf("Failed to handle event: %v", err)
+			}
+		}
+		data, err := writer.Flush()
+		if err != nil {
+			t.Fatalf("Failed to flush EPUB writer: %v", err)
+		}
+
+		var chapters strings.Builder
+		for name, file := range readEPUBFiles(t, data) {
+			if strings.HasPrefix(name, "OEBPS/chapter") {
+				chapters.WriteString(readZipFile(t, file))
+			}
+		}
+		for _, expected := range []string{
+			`<h2 id="cells">Cells</h2>`,
+			`<a href="https://example.com" rel="noopener noreferrer" target="_blank">More</a>`,
+		} {
+			if !strings.Contains(chapters.String(), expected) {
+				t.Errorf("Expected %q in chapters:\n%s", expected, chapters.String())
+			}
+		}
+	}
+
+	cfg.Transforms.Passes = []config.TransformPassConfig{{Name: "minify"}}
+	writer = factory(cfg)
+	var err error
+	for _, event := range hastTestEvents() {
+		if err = writer.Handle(event); err != nil {
+			break
+		}
+	}
+	if err == nil || !strings.Contains(err.Error(), "minify") {
+		t.Errorf("Expected unknown pass error, got %v", err)
+	}
+}
+
+// TestEPUBWriterNumberedHeadings tests that heading numbers continue across chapter files
+func TestEPUBWriterNumberedHeadings(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Transforms.Passes = []config.TransformPassConfig{
+		{Name: config.TransformRenumberHeadings, Options: map[string]string{"numbered": "true"}},
+	}
+	factory, _ := Get("epub")
+	writer := factory(cfg)
+	for _, event := range splitTestEvents() {
+		if err := writer.Handle(event); err != nil {
+			t.Fatalf("Failed to handle event: %v", err)
+		}
+	}
+	data, err := writer.Flush()
+	if err != nil {
+		t.Fatalf("Failed to flush EPUB writer: %v", err)
+	}
+	files := make(map[string]string)
+	for name, file := range readEPUBFiles(t, data) {
+		files[name] = readZipFile(t, file)
+	}
+
+	for name, expected := range map[string][]string{
+		"OEBPS/chapter_anatomy.xhtml": {
+			`<h1 id="anatomy"><span class="heading-number">1 </span>Anatomy</h1>`,
+			`<h2 id="bones"><span class="heading-number">1.1 </span>Bones</h2>`,
+			`<h3 id="skull"><span class="heading-number">1.1.1 </span>Skull</h3>`,
+		},
+		"OEBPS/chapter_physiology.xhtml": {
+			`<h1 id="physiology"><span class="heading-number">2 </span>Physiology</h1>`,
+			`href="chapter_anatomy.xhtml#bones"`,
+		},
+		"OEBPS/nav.xhtml": {">1 Anatomy</a>", ">1.1 Bones</a>", ">2 Physiology</a>"},
+	} {
+		for _, want := range expected {
+			if !strings.Contains(files[name], want) {
+				t.Errorf("Expected %q in %s:\n%s", want, name, files[name])
+			}
+		}
+	}
+}
+
+// TestEPUBWriterTableOfContentsNavigation tests that the navigation lists the entries of the
+// TableOfContents event, with its nesting
+func TestEPUBWriterTableOfContentsNavigation(t *testing.T) {
+	files := writeEPUB(t, config.DefaultEPUBConfig(), tocEvents())
+	nav, ncx := files["OEBPS/nav.xhtml"], files["OEBPS/toc.ncx"]
+	if strings.Contains(nav, ">Contents</a>") || strings.Contains(ncx, "<text>Contents</text>") {
+		t.Errorf("Expected the placeholder heading to be left out of the navigation:\n%s", nav)
+	}
+	if !strings.Contains(nav, "Bones</a>\n        <ol>") || !strings.Contains(ncx, `<meta name="dtb:depth" content="2"/>`) {
+		t.Errorf("Expected Skull nested below Bones:\n%s\n%s", nav, ncx)
+	}
+
+	// A flat table of contents stays flat
+	events := tocEvents()
+	events[4].TOCNested = false
+	files = writeEPUB(t, config.DefaultEPUBConfig(), events)
+	if ncx := files["OEBPS/toc.ncx"]; !strings.Contains(ncx, `<meta name="dtb:depth" content="1"/>`) || !strings.Contains(ncx, "Skull") {
+		t.Errorf("Expected a flat navigation:\n%s", ncx)
+	}
+
+	// Entries left out by --toc-depth or --toc-dedupe are not listed
+	events[4].TOCEntries = events[4].TOCEntries[:1]
+	files = writeEPUB(t, config.DefaultEPUBConfig(), events)
+	if nav, ncx := files["OEBPS/nav.xhtml"], files["OEBPS/toc.ncx"]; strings.Contains(nav, "Skull") || strings.Contains(ncx, "Skull") {
+		t.Errorf("Expected only the listed entries in the navigation:\n%s\n%s", nav, ncx)
+	}
+}
@@ -0,0 +1,261 @@
+package writers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/kjanat/slimacademy/internal/config"
+	"github.com/kjanat/slimacademy/internal/hast"
+	"github.com/kjanat/slimacademy/internal/streaming"
+)
+
+// init registers the "hast" and "html-hast" writers, which build a HAST tree from the events
+// and output it as unified/rehype JSON or render it to HTML.
+func init() {
+	Register("hast", func(cfg *config.Config) WriterV2 {
+		w := NewHASTWriter(hast.DefaultConversionOptions())
+		w.configureTransforms(cfg)
+		return w
+	}, WriterMetadata{
+		Name:        "HAST",
+		Extension:   ".hast.json",
+		Description: "HTML syntax tree as unified/rehype JSON",
+		MimeType:    "application/json",
+		IsBinary:    false,
+	})
+
+	Register("html-hast", func(cfg *config.Config) WriterV2 {
+		language := ""
+		if cfg != nil && cfg.HTML != nil {
+			language = cfg.HTML.Language
+		}
+		w := NewHASTHTMLWriter(hast.DefaultConversionOptions(), language)
+		w.configureTransforms(cfg)
+		return w
+	}, WriterMetadata{
+		Name:        "HTML (HAST)",
+		Extension:   ".hast.html",
+		Description: "HTML rendered from the HAST tree",
+		MimeType:    "text/html",
+		IsBinary:    false,
+	})
+}
+
+// HASTWriter builds a HAST tree incrementally and outputs it as JSON that unified and rehype
+// can read (hast-util-from-json or JSON.parse), with hast property names such as className.
+// The transform passes run once, before the first flush.
+type HASTWriter struct {
+	builder     *hast.EventToHASTConverter
+	transformer *hast.Transformer
+	transformed bool
+	configErr   error // Invalid transform configuration, reported by Flush
+	err         error
+	stats       WriterStats
+}
+
+// NewHASTWriter creates a HAST JSON writer
+func NewHASTWriter(options hast.ConversionOptions) *HASTWriter {
+	return &HASTWriter{builder: hast.NewEventToHASTConverter(options)}
+}
+
+// Handle adds an event to the tree
+func (w *HASTWriter) Handle(event streaming.Event) error {
+	w.stats.EventsProcessed++
+	countEvent(&w.stats, event)
+
+	if err := w.builder.Add(event); err != nil {
+		w.stats.Errors++
+		w.err = err
+		return err
+	}
+	return nil
+}
+
+// SetTransformer sets the passes applied to the tree before it is output
+func (w *HASTWriter) SetTransformer(transformer *hast.Transformer) {
+	w.transformer = transformer
+}
+
+// configureTransforms sets the transform passes of cfg
+func (w *HASTWriter) configureTransforms(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	w.transformer, w.configErr = hast.NewTransformerFromConfig(cfg.Transforms)
+}
+
+// Tree returns the tree built from the events handled so far
+func (w *HASTWriter) Tree() *hast.Root {
+	return w.builder.Tree()
+}
+
+// finish applies the transform passes and returns the tree
+func (w *HASTWriter) finish() (*hast.Root, error) {
+	if w.configErr != nil {
+		return nil, w.configErr
+	}
+	if w.err != nil {
+		return nil, w.err
+	}
+
+	root := w.builder.Tree()
+	if w.transformer != nil && !w.transformed {
+		if err := w.transformer.Transform(root); err != nil {
+			return nil, err
+		}
+		w.transformed = true
+	}
+	return root, nil
+}
+
+// Flush returns the tree as indented JSON
+func (w *HASTWriter) Flush() ([]byte, error) {
+	root, err := w.finish()
+	if err != nil {
+		return nil, err
+	}
+
+	hast.NormalizeProperties(root)
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal HAST: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+// FlushTo writes the tree as indented JSON to dst
+func (w *HASTWriter) FlushTo(dst io.Writer) (int64, error) {
+	data, err := w.Flush()
+	if err != nil {
+		return 0, err
+	}
+	n, err := dst.Write(data)
+	return int64(n), err
+}
+
+// ContentType returns the MIME type of the output
+func (w *HASTWriter) ContentType() string {
+	return "application/json"
+}
+
+// IsText returns true since this writer outputs text-based content
+func (w *HASTWriter) IsText() bool {
+	return true
+}
+
+// Reset clears the writer state for reuse
+func (w *HASTWriter) Reset() {
+	w.builder.Reset()
+	w.transformed = false
+	w.err = nil
+	w.stats = WriterStats{}
+}
+
+// Stats returns processing statistics
+func (w *HASTWriter) Stats() WriterStats {
+	return w.stats
+}
+
+// HASTHTMLWriter builds a HAST tree incrementally and renders it with hast.HTMLRenderer
+type HASTHTMLWriter struct {
+	*HASTWriter
+	language string
+	renderer *hast.HTMLRenderer
+}
+
+// NewHASTHTMLWriter creates a writer that renders the HAST tree to an HTML document
+func NewHASTHTMLWriter(options hast.ConversionOptions, language string) *HASTHTMLWriter {
+	if language == "" {
+		language = "en"
+	}
+	return &HASTHTMLWriter{
+		HASTWriter: NewHASTWriter(options),
+		language:   language,
+		renderer:   hast.NewHTMLRenderer(),
+	}
+}
+
+// Flush renders the tree as an HTML document
+func (w *HASTHTMLWriter) Flush() ([]byte, error) {
+	html, err := w.render()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(html), nil
+}
+
+// FlushTo renders the tree as an HTML document and writes it to dst
+func (w *HASTHTMLWriter) FlushTo(dst io.Writer) (int64, error) {
+	html, err := w.render()
+	if err != nil {
+		return 0, err
+	}
+	return writeStringTo(dst, html)
+}
+
+// render wraps the tree in a document and renders it
+func (w *HASTHTMLWriter) render() (string, error) {
+	root, err := w.finish()
+	if err != nil {
+		return "", err
+	}
+
+	html, err := w.renderer.RenderToHTML(htmlDocument(root, w.language))
+	if err != nil {
+		return "", fmt.Errorf("failed to render HAST: %w", err)
+	}
+	return "<!DOCTYPE html>\n" + html + "\n", nil
+}
+
+// ContentType returns the MIME type of the output
+func (w *HASTHTMLWriter) ContentType() string {
+	return "text/html"
+}
+
+// htmlDocument wraps the children of root in html, head and body elements. Title elements at
+// the top level move to the head; root itself is left unchanged.
+func htmlDocument(root *hast.Root, language string) *hast.Root {
+	head := hast.NewElement("head")
+	charset := hast.NewElement("meta")
+	charset.SetProperty("charset", "utf-8")
+	hast.AddChild(head, charset)
+	viewport := hast.NewElement("meta")
+	viewport.SetProperty("name", "viewport")
+	viewport.SetProperty("content", "width=device-width, initial-scale=1")
+	hast.AddChild(head, viewport)
+
+	body := hast.NewElement("body")
+	for _, child := range root.Children {
+		if element, ok := child.(*hast.Element); ok && element.TagName == "title" {
+			hast.AddChild(head, element)
+			continue
+		}
+		hast.AddChild(body, child)
+	}
+
+	html := hast.NewElement("html")
+	html.SetProperty("lang", language)
+	hast.AddChild(html, head)
+	hast.AddChild(html, body)
+
+	document := hast.NewRoot()
+	hast.AddChild(document, html)
+	return document
+}
+
+// countEvent updates the content counters of stats for an event
+func countEvent(stats *WriterStats, event streaming.Event) {
+	switch event.Kind {
+	case streaming.Text:
+		stats.TextChars += len(event.TextContent)
+	case streaming.Image:
+		stats.Images++
+	case streaming.StartTable:
+		stats.Tables++
+	case streaming.StartHeading:
+		stats.Headings++
+	case streaming.StartList:
+		stats.Lists++
+	}
+}
@@ -0,0 +1,148 @@
+package writers
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"unique"
+
+	"github.com/kjanat/slimacademy/internal/config"
+	"github.com/kjanat/slimacademy/internal/streaming"
+)
+
+// hastTestEvents is a short document with metadata, a heading and a link
+func hastTestEvents() []streaming.Event {
+	return []streaming.Event{
+		{Kind: streaming.StartDoc, Title: "Cells", Description: "Biology notes"},
+		{Kind: streaming.StartHeading, Level: 1, AnchorID: "cells", HeadingText: unique.Make("Cells")},
+		{Kind: streaming.Text, TextContent: "Cells"},
+		{Kind: streaming.EndHeading, Level: 1},
+		{Kind: streaming.StartParagraph},
+		{Kind: streaming.StartFormatting, Style: streaming.Link, LinkURL: "https://example.com"},
+		{Kind: streaming.Text, TextContent: "More"},
+		{Kind: streaming.EndFormatting, Style: streaming.Link},
+		{Kind: streaming.EndParagraph},
+		{Kind: streaming.EndDoc},
+	}
+}
+
+func TestHASTWriter(t *testing.T) {
+	factory, ok := Get("hast")
+	if !ok {
+		t.Fatal("Expected hast format to be registered")
+	}
+	writer := factory(config.DefaultConfig())
+
+	for _, event := range hastTestEvents() {
+		if err := writer.Handle(event); err != nil {
+			t.Fatalf("Handle() failed: %v", err)
+		}
+	}
+	if stats := writer.Stats(); stats.Headings != 1 || stats.EventsProcessed != 10 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	data, err := writer.Flush()
+	if err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+
+	var tree map[string]any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		t.Fatalf("Expected JSON, got %v:\n%s", err, data)
+	}
+	if tree["type"] != "root" {
+		t.Errorf("Expected a root node, got %v", tree["type"])
+	}
+	for _, expected := range []string{`"className": [`, `"document-metadata"`, `"dataDescription": "Biology notes"`, `"tagName": "h1"`, `"href": "https://example.com"`} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("Expected %s in output:\n%s", expected, data)
+		}
+	}
+
+	// FlushTo writes the same output
+	var buf bytes.Buffer
+	if _, err := writer.(StreamingWriter).FlushTo(&buf); err != nil || !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("Expected FlushTo to match Flush, got %v", err)
+	}
+
+	writer.Reset()
+	if data, _ := writer.Flush(); strings.Contains(string(data), "Cells") {
+		t.Errorf("Expected an empty tree after Reset, got:\n%s", data)
+	}
+}
+
+func TestHASTHTMLWriter(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.HTML.Language = "nl"
+	factory, ok := Get("html-hast")
+	if !ok {
+		t.Fatal("Expected html-hast format to be registered")
+	}
+	writer := factory(cfg)
+
+	for _, event := range hastTestEvents() {
+		if err := writer.Handle(event); err != nil {
+			t.Fatalf("Handle() failed: %v", err)
+		}
+	}
+	data, err := writer.Flush()
+	if err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+
+	html := string(data)
+	for _, expected := range []string{
+		"<!DOCTYPE html>\n<html lang=\"nl\"><head><meta charset=\"utf-8\" />",
+		"<title>Cells</title></head><body>",
+		`<h1 id="cells">Cells</h1>`,
+		`<a href="https://example.com">More</a>`,
+		"</body></html>",
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, html)
+		}
+	}
+	if writer.ContentType() != "text/html" {
+		t.Errorf("Expected text/html, got %s", writer.ContentType())
+	}
+}
+
+func TestHASTHTMLWriter_Transforms(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Transforms.Passes = []config.TransformPassConfig{
+		{Name: config.TransformRenumberHeadings, Options: map[string]string{"start": "2"}},
+		{Name: config.TransformExternalLinks},
+	}
+	factory, _ := Get("html-hast")
+	writer := factory(cfg)
+
+	for _, event := range hastTestEvents() {
+		if err := writer.Handle(event); err != nil {
+			t.Fatalf("Handle() failed: %v", err)
+		}
+	}
+
+	// Flushing twice applies the passes once
+	first, err := writer.Flush()
+	if err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+	second, _ := writer.Flush()
+	if !bytes.Equal(first, second) {
+		t.Errorf("Expected repeated flushes to match:\n%s\n%s", first, second)
+	}
+
+	for _, expected := range []string{`<h2 id="cells">Cells</h2>`, `<a href="https://example.com" rel="noopener noreferrer" target="_blank">`} {
+		if !strings.Contains(string(first), expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, first)
+		}
+	}
+
+	cfg.Transforms.Passes = []config.TransformPassConfig{{Name: "minify"}}
+	writer = factory(cfg)
+	if _, err := writer.Flush(); err == nil || !strings.Contains(err.Error(), "minify") {
+		t.Errorf("Expected unknown pass error, got %v", err)
+	}
+}
@@ -4,10 +4,15 @@ import (
 	"fmt"
 	"html/template"
 	"io"
+	"log/slog"
 	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"unique"
 
 	"github.com/kjanat/slimacademy/internal/config"
+	"github.com/kjanat/slimacademy/internal/hast"
 	"github.com/kjanat/slimacademy/internal/models"
 	"github.com/kjanat/slimacademy/internal/streaming"
 	"github.com/kjanat/slimacademy/internal/templates"
@@ -17,9 +22,9 @@ import (
 // init registers the HTML writer with the writer registry, providing its factory function and metadata.
 func init() {
 	Register("html", func(cfg *config.Config) WriterV2 {
-		return &HTMLWriterV2{
-			HTMLWriter: NewHTMLWriterWithConfig(cfg.HTML),
-		}
+		w := NewHTMLWriterWithConfig(cfg.HTML)
+		w.configureTransforms(cfg)
+		return &HTMLWriterV2{HTMLWriter: w}
 	}, WriterMetadata{
 		Name:        "HTML",
 		Extension:   ".html",
@@ -38,13 +43,39 @@ type HTMLWriter struct {
 	documentData        *templates.TemplateData
 	activeStyle         streaming.StyleFlags
 	linkURL             string
-	inList              bool
-	inListItem          bool
-	inTable             bool
-	tableIsFirstRow     bool
+	lists               []htmlList // Open list elements, outermost first
+	inSection           bool
 	currentHeadingLevel int
 	eventHandlers       map[streaming.EventKind]func(streaming.Event)
-	useMinimalTemplate  bool // Toggle between minimal and complex templates
+	useMinimalTemplate  bool             // Toggle between minimal and complex templates
+	body                *strings.Builder // Document content while a running header or footer is collected
+	noteCount           int              // Notes referenced so far, used to number them
+	pendingNotes        []string         // Asides of notes referenced in the open block
+	css                 template.CSS     // Stylesheet from the configured CSS path, if any
+	headings            []templates.TOCEntry
+	chapters            []models.Chapter
+	transformer         *hast.Transformer // Passes applied to the document body
+	configErr           error             // Invalid transform configuration, reported by Flush
+	transformErr        error             // Failed transform pass, reported by Flush
+
+	// Table handling
+	htmlTableState
+	outerTables []htmlTableState // States of the tables enclosing a nested table
+}
+
+// htmlList tracks an open list element
+type htmlList struct {
+	tag        string
+	itemOpen   bool
+	itemNested bool // The open item holds a nested list, so its closing tag goes on its own line
+}
+
+// htmlTableState tracks the table currently being written
+type htmlTableState struct {
+	inTable        bool
+	inTableCell    bool
+	tableHeaderRow bool   // Current table row is a header row
+	tableSection   string // Open table section element: "thead", "tbody" or ""
 }
 
 // NewHTMLWriter returns a new HTMLWriter instance with default HTML configuration.
@@ -58,16 +89,106 @@ func NewHTMLWriterWithConfig(cfg *config.HTMLConfig) *HTMLWriter {
 		cfg = config.DefaultHTMLConfig()
 	}
 	w := &HTMLWriter{
-		config:       cfg,
-		template:     templates.NewMinimalTemplate(),
-		out:          &strings.Builder{},
-		content:      &strings.Builder{},
-		documentData: &templates.TemplateData{},
+		config:   cfg,
+		template: templates.NewMinimalTemplate(),
+		out:      &strings.Builder{},
+		content:  &strings.Builder{},
 	}
+	w.loadTemplate()
+	w.documentData = w.newDocumentData()
 	w.initEventHandlers()
 	return w
 }
 
+// loadTemplate loads the template directory and stylesheet of the configuration. The built-in
+// template and stylesheet are kept when they cannot be loaded.
+func (w *HTMLWriter) loadTemplate() {
+	if w.config.TemplateDir != "" {
+		tmpl, err := templates.LoadTemplateDir(w.config.TemplateDir)
+		if err != nil {
+			slog.Warn("Using the built-in HTML template", "template_dir", w.config.TemplateDir, "error", err)
+		} else {
+			w.template = tmpl
+		}
+	}
+
+	if w.config.CSSPath != "" {
+		css, err := templates.LoadCSS(w.config.CSSPath)
+		if err != nil {
+			slog.Warn("Using the built-in stylesheet", "css_path", w.config.CSSPath, "error", err)
+		} else {
+			w.css = css
+		}
+	}
+}
+
+// newDocumentData returns the template data set by the configuration
+func (w *HTMLWriter) newDocumentData() *templates.TemplateData {
+	return &templates.TemplateData{
+		Language:   w.config.Language,
+		CSS:        w.css,
+		IncludeTOC: w.config.IncludeTOC,
+	}
+}
+
+// SetTransformer sets the passes applied to the document body before it is rendered
+func (w *HTMLWriter) SetTransformer(transformer *hast.Transformer) {
+	w.transformer = transformer
+}
+
+// configureTransforms sets the transform passes of cfg
+func (w *HTMLWriter) configureTransforms(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	w.transformer, w.configErr = hast.NewTransformerFromConfig(cfg.Transforms)
+}
+
+// transformParts applies the transform passes once to the rendered parts of a document, such
+// as its chapters, so that renumbered headings continue from one part to the next. It returns
+// the transformed parts and their headings; without passes or on failure, which is recorded for
+// Flush, the parts are returned unchanged with no headings.
+func (w *HTMLWriter) transformParts(parts []string) ([]string, []hast.HeadingInfo) {
+	if w.transformer == nil || w.configErr != nil {
+		return parts, nil
+	}
+	transformed, headings, err := w.transformer.TransformHTML(parts...)
+	if err != nil {
+		w.transformErr = err
+		return parts, nil
+	}
+	return transformed, headings
+}
+
+// transformedTOC returns entries with the level and title of the transformed heading with the
+// same anchor, so that the table of contents matches renumbered headings
+func transformedTOC(entries []templates.TOCEntry, headings []hast.HeadingInfo) []templates.TOCEntry {
+	if headings == nil {
+		return entries
+	}
+	byID := make(map[string]hast.HeadingInfo, len(headings))
+	for _, heading := range headings {
+		byID[heading.ID] = heading
+	}
+
+	transformed := slices.Clone(entries)
+	for i, entry := range transformed {
+		if heading, ok := byID[entry.Anchor]; ok && entry.Anchor != "" {
+			transformed[i].Level = heading.Level
+			transformed[i].Title = strings.TrimSpace(heading.Text)
+		}
+	}
+	return transformed
+}
+
+// Err returns the transform configuration or pass error, if any
+func (w *HTMLWriter) Err() error {
+	if w.configErr != nil {
+		return w.configErr
+	}
+	return w.transformErr
+}
+
 // initEventHandlers initializes the event handler map
 func (w *HTMLWriter) initEventHandlers() {
 	w.eventHandlers = map[streaming.EventKind]func(streaming.Event){
@@ -77,20 +198,29 @@ func (w *HTMLWriter) initEventHandlers() {
 		streaming.EndParagraph:    func(streaming.Event) { w.handleEndParagraph() },
 		streaming.StartHeading:    w.handleStartHeading,
 		streaming.EndHeading:      func(streaming.Event) { w.handleEndHeading() },
-		streaming.StartList:       func(streaming.Event) { w.handleStartList() },
+		streaming.StartList:       w.handleStartList,
 		streaming.StartListItem:   func(streaming.Event) { w.handleStartListItem() },
 		streaming.EndListItem:     func(streaming.Event) { w.handleEndListItem() },
 		streaming.EndList:         func(streaming.Event) { w.handleEndList() },
-		streaming.StartTable:      func(streaming.Event) { w.handleStartTable() },
+		streaming.StartTable:      w.handleStartTable,
 		streaming.EndTable:        func(streaming.Event) { w.handleEndTable() },
-		streaming.StartTableRow:   func(streaming.Event) { w.handleStartTableRow() },
+		streaming.StartTableRow:   w.handleStartTableRow,
 		streaming.EndTableRow:     func(streaming.Event) { w.handleEndTableRow() },
-		streaming.StartTableCell:  func(streaming.Event) { w.handleStartTableCell() },
+		streaming.StartTableCell:  w.handleStartTableCell,
 		streaming.EndTableCell:    func(streaming.Event) { w.handleEndTableCell() },
 		streaming.StartFormatting: w.handleStartFormatting,
 		streaming.EndFormatting:   w.handleEndFormatting,
 		streaming.Text:            w.handleText,
 		streaming.Image:           w.handleImage,
+		streaming.PageBreak:       func(streaming.Event) { w.handlePageBreak() },
+		streaming.HorizontalRule:  func(streaming.Event) { w.handleHorizontalRule() },
+		streaming.SectionBreak:    w.handleSectionBreak,
+		streaming.StartHeader:     func(streaming.Event) { w.handleStartRunningBlock() },
+		streaming.EndHeader:       func(streaming.Event) { w.documentData.Header = w.handleEndRunningBlock() },
+		streaming.StartFooter:     func(streaming.Event) { w.handleStartRunningBlock() },
+		streaming.EndFooter:       func(streaming.Event) { w.documentData.Footer = w.handleEndRunningBlock() },
+		streaming.Note:            w.handleNote,
+		streaming.TableOfContents: w.handleTableOfContents,
 	}
 }
 
@@ -106,6 +236,7 @@ func (w *HTMLWriter) handleStartDoc(event streaming.Event) {
 	// Store document metadata for template
 	w.documentData.Title = event.Title
 	w.documentData.Description = event.Description
+	w.chapters = event.Chapters
 	w.documentData.Metadata = make(map[string]string)
 
 	// Collect metadata
@@ -235,10 +366,16 @@ func (w *HTMLWriter) writeTableOfContents(chapters []models.Chapter) {
 // handleEndDoc processes document end events
 func (w *HTMLWriter) handleEndDoc() {
 	// Close document body section
+	w.closeSection()
 	w.content.WriteString("</div>\n")
 
 	// Use template to render final HTML
-	w.documentData.Content = template.HTML(w.content.String())
+	parts, headings := w.transformParts([]string{w.content.String()})
+	content := parts[0]
+	w.documentData.Content = template.HTML(content)
+	w.documentData.TOC = templates.NestTOC(transformedTOC(w.headings, headings))
+	// Chapters are matched to headings by their source titles
+	w.documentData.Chapters = templates.ChapterTOC(w.chapters, w.headings)
 	result, err := w.template.Render(*w.documentData)
 	if err != nil {
 		// Fallback to basic HTML if template fails
@@ -246,7 +383,7 @@ func (w *HTMLWriter) handleEndDoc() {
 		w.out.WriteString("<!DOCTYPE html><html><head><title>")
 		w.out.WriteString(w.escapeHTML(w.documentData.Title))
 		w.out.WriteString("</title></head><body>")
-		w.out.WriteString(w.content.String())
+		w.out.WriteString(content)
 		w.out.WriteString("</body></html>")
 	} else {
 		w.out.WriteString(result)
@@ -256,12 +393,21 @@ func (w *HTMLWriter) handleEndDoc() {
 // handleStartParagraph processes paragraph start events
 func (w *HTMLWriter) handleStartParagraph() {
 	w.closeListItemIfNeeded()
+	if w.inTableCell {
+		w.content.WriteString("<p>")
+		return
+	}
 	w.content.WriteString("    <p>")
 }
 
 // handleEndParagraph processes paragraph end events
 func (w *HTMLWriter) handleEndParagraph() {
+	if w.inTableCell {
+		w.content.WriteString("</p>")
+		return
+	}
 	w.content.WriteString("</p>\n")
+	w.writePendingNotes()
 }
 
 // handleStartHeading processes heading start events
@@ -270,23 +416,41 @@ func (w *HTMLWriter) handleStartHeading(event streaming.Event) {
 
 	// Add semantic section wrapper for major headings
 	if event.Level <= 2 {
+		w.closeSection()
 		w.content.WriteString("    <section class=\"chapter-section\">\n")
+		w.inSection = true
 	}
 
 	w.currentHeadingLevel = event.Level
+	if event.HeadingText != (unique.Handle[string]{}) {
+		w.headings = append(w.headings, templates.TOCEntry{Title: event.HeadingText.Value(), Level: event.Level, Anchor: event.AnchorID})
+	}
 	fmt.Fprintf(w.content, "        <h%d id=\"%s\">", event.Level, event.AnchorID)
 }
 
+// closeSection closes the chapter section opened by the last major heading
+func (w *HTMLWriter) closeSection() {
+	if w.inSection {
+		w.closeListItemIfNeeded()
+		w.content.WriteString("    </section>\n")
+		w.inSection = false
+	}
+}
+
 // handleEndHeading processes heading end events
 func (w *HTMLWriter) handleEndHeading() {
 	fmt.Fprintf(w.content, "</h%d>\n", w.currentHeadingLevel)
+	w.writePendingNotes()
 }
 
 // handleStartListItem processes list item start events
 func (w *HTMLWriter) handleStartListItem() {
 	w.closeListItemIfNeeded()
-	w.content.WriteString("        <li>")
-	w.inListItem = true
+	n := len(w.lists)
+	w.content.WriteString(w.listIndent(max(0, n-1)) + "    <li>")
+	if n > 0 {
+		w.lists[n-1].itemOpen = true
+	}
 }
 
 // handleEndListItem processes list item end events
@@ -294,61 +458,169 @@ func (w *HTMLWriter) handleEndListItem() {
 	w.closeListItemIfNeeded()
 }
 
-// handleStartList processes list start events
-func (w *HTMLWriter) handleStartList() {
-	w.inList = true
-	w.content.WriteString("    <ul>\n")
+// handleStartList processes list start events. Nested lists are written inside the open item of the enclosing list.
+func (w *HTMLWriter) handleStartList(event streaming.Event) {
+	if n := len(w.lists); n > 0 && w.lists[n-1].itemOpen && !w.lists[n-1].itemNested {
+		w.content.WriteString("\n")
+		w.lists[n-1].itemNested = true
+	}
+	tag, attrs := htmlListTag(event)
+	fmt.Fprintf(w.content, "%s<%s%s>\n", w.listIndent(len(w.lists)), tag, attrs)
+	w.lists = append(w.lists, htmlList{tag: tag})
 }
 
 // handleEndList processes list end events
 func (w *HTMLWriter) handleEndList() {
 	w.closeListItemIfNeeded()
-	w.inList = false
-	w.content.WriteString("    </ul>\n")
+	n := len(w.lists)
+	if n == 0 {
+		return
+	}
+	fmt.Fprintf(w.content, "%s</%s>\n", w.listIndent(n-1), w.lists[n-1].tag)
+	w.lists = w.lists[:n-1]
+}
+
+// listIndent returns the indentation of a list element at the given nesting depth
+func (w *HTMLWriter) listIndent(depth int) string {
+	return "    " + strings.Repeat("        ", depth)
+}
+
+// htmlListTag returns the element name and attributes for the list started by event
+func htmlListTag(event streaming.Event) (string, string) {
+	if !event.ListOrdered && !event.ListGlyph.Ordered() {
+		switch event.ListGlyph {
+		case streaming.GlyphCircle:
+			return "ul", ` style="list-style-type: circle;"`
+		case streaming.GlyphSquare:
+			return "ul", ` style="list-style-type: square;"`
+		}
+		return "ul", ""
+	}
+
+	var attrs strings.Builder
+	switch event.ListGlyph {
+	case streaming.GlyphLowerAlpha:
+		attrs.WriteString(` type="a"`)
+	case streaming.GlyphUpperAlpha:
+		attrs.WriteString(` type="A"`)
+	case streaming.GlyphLowerRoman:
+		attrs.WriteString(` type="i"`)
+	case streaming.GlyphUpperRoman:
+		attrs.WriteString(` type="I"`)
+	}
+	if event.ListStart > 1 {
+		fmt.Fprintf(&attrs, ` start="%d"`, event.ListStart)
+	}
+	return "ol", attrs.String()
 }
 
 // handleStartTable processes table start events
-func (w *HTMLWriter) handleStartTable() {
-	w.inTable = true
-	w.tableIsFirstRow = true
+func (w *HTMLWriter) handleStartTable(event streaming.Event) {
+	if w.inTable {
+		w.outerTables = append(w.outerTables, w.htmlTableState)
+	}
+	w.htmlTableState = htmlTableState{inTable: true}
 	w.content.WriteString("    <table style=\"border-collapse: collapse; width: 100%; margin: 20px 0;\">\n")
+	w.writeColumnGroup(event.TableColumnWidths)
+}
+
+// writeColumnGroup writes a colgroup for tables with fixed column widths
+func (w *HTMLWriter) writeColumnGroup(widths []float64) {
+	if len(widths) == 0 {
+		return
+	}
+	w.content.WriteString("        <colgroup>\n")
+	for _, width := range widths {
+		if width > 0 {
+			fmt.Fprintf(w.content, "            <col style=\"width: %spt;\" />\n", formatPoints(width))
+		} else {
+			w.content.WriteString("            <col />\n")
+		}
+	}
+	w.content.WriteString("        </colgroup>\n")
 }
 
 // handleEndTable processes table end events
 func (w *HTMLWriter) handleEndTable() {
-	w.inTable = false
+	w.openTableSection("")
 	w.content.WriteString("    </table>\n")
+
+	w.htmlTableState = htmlTableState{}
+	if n := len(w.outerTables); n > 0 {
+		w.htmlTableState = w.outerTables[n-1]
+		w.outerTables = w.outerTables[:n-1]
+	}
+}
+
+// openTableSection closes the open thead/tbody element and opens section unless it is empty
+func (w *HTMLWriter) openTableSection(section string) {
+	if w.tableSection == section {
+		return
+	}
+	if w.tableSection != "" {
+		fmt.Fprintf(w.content, "        </%s>\n", w.tableSection)
+	}
+	if section != "" {
+		fmt.Fprintf(w.content, "        <%s>\n", section)
+	}
+	w.tableSection = section
 }
 
 // handleStartTableRow processes table row start events
-func (w *HTMLWriter) handleStartTableRow() {
+func (w *HTMLWriter) handleStartTableRow(event streaming.Event) {
+	w.tableHeaderRow = event.TableHeader
+	// Header rows after the body has started stay in the body
+	if event.TableHeader && w.tableSection != "tbody" {
+		w.openTableSection("thead")
+	} else if !event.TableHeader {
+		w.openTableSection("tbody")
+	}
 	w.content.WriteString("        <tr>\n")
 }
 
 // handleEndTableRow processes table row end events
 func (w *HTMLWriter) handleEndTableRow() {
 	w.content.WriteString("        </tr>\n")
-	w.tableIsFirstRow = false
+	w.tableHeaderRow = false
 }
 
 // handleStartTableCell processes table cell start events
-func (w *HTMLWriter) handleStartTableCell() {
+func (w *HTMLWriter) handleStartTableCell(event streaming.Event) {
 	tag := "td"
 	style := "border: 1px solid #ddd; padding: 8px;"
-	if w.tableIsFirstRow {
+	if w.tableHeaderRow {
 		tag = "th"
 		style += " background-color: #f2f2f2; font-weight: bold;"
 	}
-	fmt.Fprintf(w.content, "            <%s style=\"%s\">", tag, style)
+	fmt.Fprintf(w.content, "            <%s%s style=\"%s\">", tag, cellSpanAttributes(event), style)
+	w.inTableCell = true
+}
+
+// cellSpanAttributes returns the colspan and rowspan attributes for a merged table cell
+func cellSpanAttributes(event streaming.Event) string {
+	var attrs strings.Builder
+	if event.CellColSpan > 1 {
+		fmt.Fprintf(&attrs, " colspan=\"%d\"", event.CellColSpan)
+	}
+	if event.CellRowSpan > 1 {
+		fmt.Fprintf(&attrs, " rowspan=\"%d\"", event.CellRowSpan)
+	}
+	return attrs.String()
+}
+
+// formatPoints formats a length in points without trailing zeros
+func formatPoints(points float64) string {
+	return strconv.FormatFloat(points, 'f', -1, 64)
 }
 
 // handleEndTableCell processes table cell end events
 func (w *HTMLWriter) handleEndTableCell() {
 	tag := "td"
-	if w.tableIsFirstRow {
+	if w.tableHeaderRow {
 		tag = "th"
 	}
 	fmt.Fprintf(w.content, "</%s>\n", tag)
+	w.inTableCell = false
 }
 
 // handleStartFormatting processes formatting start events
@@ -412,11 +684,93 @@ func (w *HTMLWriter) handleImage(event streaming.Event) {
 		template.HTMLEscapeString(safeImageURL), w.escapeHTML(event.ImageAlt))
 }
 
+// handlePageBreak processes page break events. The break only takes effect when printing.
+func (w *HTMLWriter) handlePageBreak() {
+	w.writeBlockBreak("<div class=\"page-break\"></div>")
+}
+
+// handleHorizontalRule processes horizontal rule events
+func (w *HTMLWriter) handleHorizontalRule() {
+	w.writeBlockBreak("<hr />")
+}
+
+// handleSectionBreak processes section break events, breaking the page before sections that start on a new page
+func (w *HTMLWriter) handleSectionBreak(event streaming.Event) {
+	if event.SectionNewPage {
+		w.handlePageBreak()
+	}
+}
+
+// writeBlockBreak writes a break element on its own line, or inline within table cells
+func (w *HTMLWriter) writeBlockBreak(element string) {
+	if w.inTableCell {
+		w.content.WriteString(element)
+		return
+	}
+	w.content.WriteString("    " + element + "\n")
+}
+
+// handleStartRunningBlock redirects content into a separate builder while a header or footer is streamed
+func (w *HTMLWriter) handleStartRunningBlock() {
+	w.body = w.content
+	w.content = &strings.Builder{}
+}
+
+// handleEndRunningBlock restores the document content and returns the collected header or footer
+func (w *HTMLWriter) handleEndRunningBlock() template.HTML {
+	if w.body == nil {
+		return ""
+	}
+	block := template.HTML(w.content.String())
+	w.content = w.body
+	w.body = nil
+	return block
+}
+
+// handleNote writes a numbered reference to a note whose aside follows the annotated block
+func (w *HTMLWriter) handleNote(event streaming.Event) {
+	if !w.config.IncludeNotes {
+		return
+	}
+	w.noteCount++
+	fmt.Fprintf(w.content, "<sup class=\"note-ref\" id=\"note-ref-%d\"><a href=\"#note-%d\">%d</a></sup>",
+		w.noteCount, w.noteCount, w.noteCount)
+	w.pendingNotes = append(w.pendingNotes, fmt.Sprintf(
+		"<aside class=\"note\" id=\"note-%d\"><a href=\"#note-ref-%d\">%d</a> %s</aside>",
+		w.noteCount, w.noteCount, w.noteCount, w.escapeHTML(event.NoteText)))
+}
+
+// handleTableOfContents writes the table of contents as a navigation list of links to the headings
+func (w *HTMLWriter) handleTableOfContents(event streaming.Event) {
+	w.closeListItemIfNeeded()
+	w.content.WriteString("    <nav class=\"table-of-contents\">\n")
+	for _, listEvent := range streaming.TOCListEvents(event) {
+		w.Handle(listEvent)
+	}
+	w.content.WriteString("    </nav>\n")
+}
+
+// writePendingNotes writes the asides of the notes referenced in the block just closed
+func (w *HTMLWriter) writePendingNotes() {
+	for _, aside := range w.pendingNotes {
+		w.content.WriteString("    " + aside + "\n")
+	}
+	w.pendingNotes = w.pendingNotes[:0]
+}
+
 // closeListItemIfNeeded closes a list item if one is currently open
 func (w *HTMLWriter) closeListItemIfNeeded() {
-	if w.inListItem {
+	if n := len(w.lists); n > 0 && w.lists[n-1].itemOpen {
+		for _, aside := range w.pendingNotes {
+			w.content.WriteString(aside)
+		}
+		w.pendingNotes = w.pendingNotes[:0]
+		if w.lists[n-1].itemNested {
+			w.content.WriteString(w.listIndent(n-1) + "    ")
+		}
 		w.content.WriteString("</li>\n")
-		w.inListItem = false
+		w.lists[n-1].itemOpen = false
+		w.lists[n-1].itemNested = false
 	}
 }
 
@@ -904,11 +1258,17 @@ func (w *HTMLWriter) Reset() {
 	w.content.Reset()
 	w.activeStyle = 0
 	w.linkURL = ""
-	w.inList = false
-	w.inListItem = false
-	w.inTable = false
-	w.tableIsFirstRow = false
-	w.documentData = &templates.TemplateData{}
+	w.lists = nil
+	w.inSection = false
+	w.htmlTableState = htmlTableState{}
+	w.outerTables = nil
+	w.body = nil
+	w.noteCount = 0
+	w.pendingNotes = nil
+	w.headings = nil
+	w.chapters = nil
+	w.transformErr = nil
+	w.documentData = w.newDocumentData()
 }
 
 // SetOutput sets the output destination (for StreamWriter interface)
@@ -946,9 +1306,20 @@ func (w *HTMLWriterV2) Handle(event streaming.Event) error {
 
 // Flush finalizes any pending operations and returns the result
 func (w *HTMLWriterV2) Flush() ([]byte, error) {
+	if err := w.Err(); err != nil {
+		return nil, err
+	}
 	return []byte(w.Result()), nil
 }
 
+// FlushTo finalizes any pending operations and writes the result to dst
+func (w *HTMLWriterV2) FlushTo(dst io.Writer) (int64, error) {
+	if err := w.Err(); err != nil {
+		return 0, err
+	}
+	return writeStringTo(dst, w.Result())
+}
+
 // ContentType returns the MIME type of the output
 func (w *HTMLWriterV2) ContentType() string {
 	return "text/html"
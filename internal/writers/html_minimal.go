@@ -20,13 +20,21 @@ type MinimalHTMLWriter struct {
 	template            *templates.MinimalTemplate
 	docData             *templates.TemplateData
 	content             *strings.Builder
-	styleStack          []string // Track open formatting tags
-	currentHeadingLevel int      // Track current heading level for proper closing
+	styleStack          []string         // Track open formatting tags
+	currentHeadingLevel int              // Track current heading level for proper closing
+	listTags            []string         // Element names of the open lists
+	body                *strings.Builder // Document content while a running header or footer is collected
+	noteCount           int              // Notes referenced so far, used to number them
+	pendingNotes        []string         // Asides of notes referenced in the open block
 
 	// O(1) duplicate detection with unique.Handle
 	seenURLs    map[unique.Handle[string]]bool // Track URLs for deduplication
 	seenAnchors map[unique.Handle[string]]bool // Track anchor IDs for deduplication
 	seenTexts   map[unique.Handle[string]]int  // Track text content for analytics
+
+	// Table handling
+	htmlTableState
+	outerTables []htmlTableState // States of the tables enclosing a nested table
 }
 
 // NewMinimalHTMLWriter creates a new minimal HTML writer
@@ -99,6 +107,7 @@ func (w *MinimalHTMLWriter) processEvent(event streaming.Event) error {
 		w.content.WriteString("<p>")
 	case streaming.EndParagraph:
 		w.content.WriteString("</p>\n")
+		w.writePendingNotes()
 	case streaming.StartHeading:
 		level := event.Level
 		if level < 1 || level > 6 {
@@ -121,26 +130,61 @@ func (w *MinimalHTMLWriter) processEvent(event streaming.Event) error {
 		}
 	case streaming.EndHeading:
 		fmt.Fprintf(w.content, "</h%d>\n", w.currentHeadingLevel)
+		w.writePendingNotes()
 	case streaming.StartList:
-		w.content.WriteString("<ul>\n")
+		tag, attrs := htmlListTag(event)
+		w.listTags = append(w.listTags, tag)
+		fmt.Fprintf(w.content, "<%s%s>\n", tag, attrs)
 	case streaming.EndList:
-		w.content.WriteString("</ul>\n")
+		tag := "ul"
+		if n := len(w.listTags); n > 0 {
+			tag = w.listTags[n-1]
+			w.listTags = w.listTags[:n-1]
+		}
+		fmt.Fprintf(w.content, "</%s>\n", tag)
 	case streaming.StartListItem:
 		w.content.WriteString("<li>")
 	case streaming.EndListItem:
+		w.writePendingNotes()
 		w.content.WriteString("</li>\n")
 	case streaming.StartTable:
+		if w.inTable {
+			w.outerTables = append(w.outerTables, w.htmlTableState)
+		}
+		w.htmlTableState = htmlTableState{inTable: true}
 		w.content.WriteString("<table>\n")
 	case streaming.EndTable:
+		w.openTableSection("")
 		w.content.WriteString("</table>\n")
+		w.htmlTableState = htmlTableState{}
+		if n := len(w.outerTables); n > 0 {
+			w.htmlTableState = w.outerTables[n-1]
+			w.outerTables = w.outerTables[:n-1]
+		}
 	case streaming.StartTableRow:
+		w.tableHeaderRow = event.TableHeader
+		// Header rows after the body has started stay in the body
+		if event.TableHeader && w.tableSection != "tbody" {
+			w.openTableSection("thead")
+		} else if !event.TableHeader {
+			w.openTableSection("tbody")
+		}
 		w.content.WriteString("<tr>")
 	case streaming.EndTableRow:
+		w.tableHeaderRow = false
 		w.content.WriteString("</tr>\n")
 	case streaming.StartTableCell:
-		w.content.WriteString("<td>")
+		if w.tableHeaderRow {
+			w.content.WriteString("<th>")
+		} else {
+			w.content.WriteString("<td>")
+		}
 	case streaming.EndTableCell:
-		w.content.WriteString("</td>")
+		if w.tableHeaderRow {
+			w.content.WriteString("</th>")
+		} else {
+			w.content.WriteString("</td>")
+		}
 	case streaming.StartFormatting:
 		return w.handleStartFormatting(event)
 	case streaming.EndFormatting:
@@ -152,12 +196,63 @@ func (w *MinimalHTMLWriter) processEvent(event streaming.Event) error {
 		w.content.WriteString(w.escapeHTML(event.TextContent))
 	case streaming.Image:
 		return w.handleImage(event)
+	case streaming.PageBreak:
+		w.content.WriteString("<div class=\"page-break\"></div>\n")
+	case streaming.HorizontalRule:
+		w.content.WriteString("<hr>\n")
+	case streaming.SectionBreak:
+		if event.SectionNewPage {
+			w.content.WriteString("<div class=\"page-break\"></div>\n")
+		}
+	case streaming.AutoText:
+		// Page numbers and counts have no meaning outside paged media
+	case streaming.StartHeader, streaming.StartFooter:
+		w.body = w.content
+		w.content = &strings.Builder{}
+	case streaming.EndHeader:
+		w.docData.Header = w.endRunningBlock()
+	case streaming.EndFooter:
+		w.docData.Footer = w.endRunningBlock()
+	case streaming.Note:
+		if w.config.IncludeNotes {
+			w.noteCount++
+			fmt.Fprintf(w.content, "<sup id=\"note-ref-%d\"><a href=\"#note-%d\">%d</a></sup>", w.noteCount, w.noteCount, w.noteCount)
+			w.pendingNotes = append(w.pendingNotes, fmt.Sprintf("<aside id=\"note-%d\"><a href=\"#note-ref-%d\">%d</a> %s</aside>\n",
+				w.noteCount, w.noteCount, w.noteCount, w.escapeHTML(event.NoteText)))
+		}
+	case streaming.TableOfContents:
+		w.content.WriteString("<nav class=\"table-of-contents\">\n")
+		for _, listEvent := range streaming.TOCListEvents(event) {
+			if err := w.processEvent(listEvent); err != nil {
+				return err
+			}
+		}
+		w.content.WriteString("</nav>\n")
 	default:
 		return fmt.Errorf("unknown event kind: %v", event.Kind)
 	}
 	return nil
 }
 
+// writePendingNotes writes the asides of the notes referenced in the block just closed
+func (w *MinimalHTMLWriter) writePendingNotes() {
+	for _, aside := range w.pendingNotes {
+		w.content.WriteString(aside)
+	}
+	w.pendingNotes = w.pendingNotes[:0]
+}
+
+// endRunningBlock restores the document content and returns the collected header or footer
+func (w *MinimalHTMLWriter) endRunningBlock() template.HTML {
+	if w.body == nil {
+		return ""
+	}
+	block := template.HTML(w.content.String())
+	w.content = w.body
+	w.body = nil
+	return block
+}
+
 // handleStartDoc processes document start events
 func (w *MinimalHTMLWriter) handleStartDoc(event streaming.Event) error {
 	w.docData.Title = event.Title
@@ -350,14 +445,37 @@ func (w *MinimalHTMLWriter) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
+// openTableSection closes the open thead/tbody element and opens section unless it is empty
+func (w *MinimalHTMLWriter) openTableSection(section string) {
+	if w.tableSection == section {
+		return
+	}
+	if w.tableSection != "" {
+		fmt.Fprintf(w.content, "</%s>\n", w.tableSection)
+	}
+	if section != "" {
+		fmt.Fprintf(w.content, "<%s>\n", section)
+	}
+	w.tableSection = section
+}
+
 // Reset resets the writer state
 func (w *MinimalHTMLWriter) Reset() {
+	if w.body != nil {
+		w.content = w.body
+		w.body = nil
+	}
 	w.content.Reset()
 	w.docData = &templates.TemplateData{
 		Metadata: make(map[string]string),
 	}
 	w.styleStack = w.styleStack[:0]
 	w.currentHeadingLevel = 0
+	w.listTags = w.listTags[:0]
+	w.htmlTableState = htmlTableState{}
+	w.outerTables = nil
+	w.noteCount = 0
+	w.pendingNotes = w.pendingNotes[:0]
 
 	// Reset duplicate detection maps
 	clear(w.seenURLs)
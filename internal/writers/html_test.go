@@ -1,8 +1,11 @@
 package writers
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"unique"
 
 	"github.com/kjanat/slimacademy/internal/config"
 	"github.com/kjanat/slimacademy/internal/models"
@@ -262,7 +265,7 @@ func TestHTMLWriter_Tables(t *testing.T) {
 	events := []streaming.Event{
 		{Kind: streaming.StartDoc, Title: "Table Test"},
 		{Kind: streaming.StartTable, TableColumns: 2, TableRows: 2},
-		{Kind: streaming.StartTableRow},
+		{Kind: streaming.StartTableRow, TableHeader: true},
 		{Kind: streaming.StartTableCell},
 		{Kind: streaming.Text, TextContent: "Header 1"},
 		{Kind: streaming.EndTableCell},
@@ -436,7 +439,6 @@ func TestHTMLWriter_Reset(t *testing.T) {
 	// Add some content and state
 	writer.Handle(streaming.Event{Kind: streaming.StartDoc, Title: "Test"})
 	writer.Handle(streaming.Event{Kind: streaming.StartList})
-	writer.inList = true
 	writer.activeStyle = streaming.Bold
 
 	// Reset
@@ -449,7 +451,7 @@ func TestHTMLWriter_Reset(t *testing.T) {
 	if writer.activeStyle != 0 {
 		t.Error("Active style should be cleared after reset")
 	}
-	if writer.inList {
+	if len(writer.lists) != 0 {
 		t.Error("List state should be cleared after reset")
 	}
 	if writer.inTable {
@@ -735,3 +737,473 @@ func BenchmarkHTMLWriter_SlugGeneration(b *testing.B) {
 		_ = writer.slugify(title)
 	}
 }
+
+// spanTableEvents returns a document with a 3x3 table containing a column-spanning and a row-spanning cell
+func spanTableEvents() []streaming.Event {
+	cell := func(text string, rowSpan, colSpan int) []streaming.Event {
+		return []streaming.Event{
+			{Kind: streaming.StartTableCell, CellRowSpan: rowSpan, CellColSpan: colSpan},
+			{Kind: streaming.Text, TextContent: text},
+			{Kind: streaming.EndTableCell},
+		}
+	}
+	row := func(header bool, cells ...[]streaming.Event) []streaming.Event {
+		events := []streaming.Event{{Kind: streaming.StartTableRow, TableHeader: header}}
+		for _, c := range cells {
+			events = append(events, c...)
+		}
+		return append(events, streaming.Event{Kind: streaming.EndTableRow})
+	}
+
+	events := []streaming.Event{
+		{Kind: streaming.StartDoc, Title: "Spans"},
+		{Kind: streaming.StartTable, TableColumns: 3, TableRows: 3, TableHasSpans: true, TableColumnWidths: []float64{120, 0, 0}},
+	}
+	events = append(events, row(true, cell("Merged", 1, 2), cell("C", 1, 1))...)
+	events = append(events, row(false, cell("Tall", 2, 1), cell("D", 1, 1), cell("E", 1, 1))...)
+	events = append(events, row(false, cell("F", 1, 1), cell("G", 1, 1))...)
+	return append(events,
+		streaming.Event{Kind: streaming.EndTable},
+		streaming.Event{Kind: streaming.EndDoc},
+	)
+}
+
+func TestHTMLWriter_TableSpans(t *testing.T) {
+	writer := NewHTMLWriter()
+	for _, event := range spanTableEvents() {
+		writer.Handle(event)
+	}
+	result := writer.Result()
+
+	for _, expected := range []string{
+		`<col style="width: 120pt;" />`,
+		`<th colspan="2" style=`,
+		`<td rowspan="2" style=`,
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, result)
+		}
+	}
+}
+
+func TestHTMLWriter_TableHeaderSections(t *testing.T) {
+	writer := NewHTMLWriter()
+	for _, event := range spanTableEvents() {
+		writer.Handle(event)
+	}
+	result := writer.Result()
+
+	if !strings.Contains(result, "<thead>\n        <tr>\n            <th colspan=\"2\"") {
+		t.Errorf("Expected header row inside thead:\n%s", result)
+	}
+	if !strings.Contains(result, "</thead>\n        <tbody>\n        <tr>\n            <td rowspan=\"2\"") {
+		t.Errorf("Expected body rows inside tbody:\n%s", result)
+	}
+
+	// Tables without header rows get no thead and no th cells
+	writer = NewHTMLWriter()
+	for _, event := range []streaming.Event{
+		{Kind: streaming.StartDoc, Title: "No Header"},
+		{Kind: streaming.StartTable, TableColumns: 1, TableRows: 1},
+		{Kind: streaming.StartTableRow},
+		{Kind: streaming.StartTableCell},
+		{Kind: streaming.Text, TextContent: "Only"},
+		{Kind: streaming.EndTableCell},
+		{Kind: streaming.EndTableRow},
+		{Kind: streaming.EndTable},
+		{Kind: streaming.EndDoc},
+	} {
+		writer.Handle(event)
+	}
+	result = writer.Result()
+
+	if strings.Contains(result, "<thead>") || strings.Contains(result, "<th") {
+		t.Errorf("Expected no header markup:\n%s", result)
+	}
+	if !strings.Contains(result, "<tbody>") || !strings.Contains(result, "</tbody>\n    </table>") {
+		t.Errorf("Expected rows inside tbody:\n%s", result)
+	}
+}
+
+// blockTableEvents returns a table whose cells hold several paragraphs, a list and a nested table
+func blockTableEvents() []streaming.Event {
+	paragraph := func(text string) []streaming.Event {
+		return []streaming.Event{
+			{Kind: streaming.StartParagraph},
+			{Kind: streaming.Text, TextContent: text},
+			{Kind: streaming.EndParagraph},
+		}
+	}
+
+	events := []streaming.Event{
+		{Kind: streaming.StartDoc, Title: "Blocks"},
+		{Kind: streaming.StartTable, TableColumns: 2, TableRows: 1, TableHasBlocks: true},
+		{Kind: streaming.StartTableRow},
+		{Kind: streaming.StartTableCell},
+	}
+	events = append(events, paragraph("First")...)
+	events = append(events, paragraph("Second")...)
+	events = append(events,
+		streaming.Event{Kind: streaming.StartList},
+		streaming.Event{Kind: streaming.StartListItem},
+		streaming.Event{Kind: streaming.Text, TextContent: "Item"},
+		streaming.Event{Kind: streaming.EndListItem},
+		streaming.Event{Kind: streaming.EndList},
+		streaming.Event{Kind: streaming.EndTableCell},
+		streaming.Event{Kind: streaming.StartTableCell},
+		streaming.Event{Kind: streaming.StartTable, TableColumns: 1, TableRows: 1},
+		streaming.Event{Kind: streaming.StartTableRow},
+		streaming.Event{Kind: streaming.StartTableCell},
+	)
+	events = append(events, paragraph("Inner")...)
+	return append(events,
+		streaming.Event{Kind: streaming.EndTableCell},
+		streaming.Event{Kind: streaming.EndTableRow},
+		streaming.Event{Kind: streaming.EndTable},
+		streaming.Event{Kind: streaming.EndTableCell},
+		streaming.Event{Kind: streaming.EndTableRow},
+		streaming.Event{Kind: streaming.EndTable},
+		streaming.Event{Kind: streaming.EndDoc},
+	)
+}
+
+func TestHTMLWriter_TableCellBlocks(t *testing.T) {
+	writer := NewHTMLWriter()
+	for _, event := range blockTableEvents() {
+		writer.Handle(event)
+	}
+	result := writer.Result()
+
+	for _, expected := range []string{
+		`<p>First</p><p>Second</p>    <ul>`,
+		`<li>Item</li>`,
+		`<p>Inner</p></td>`,
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, result)
+		}
+	}
+	if got := strings.Count(result, "<table"); got != 2 {
+		t.Errorf("Expected nested table, got %d tables:\n%s", got, result)
+	}
+	if got := strings.Count(result, "</tbody>"); got != 2 {
+		t.Errorf("Expected both tables to close their tbody, got %d:\n%s", got, result)
+	}
+}
+
+// nestedListEvents returns an ordered list starting at 3 with a lettered sub-list and a circle-bulleted list
+func nestedListEvents() []streaming.Event {
+	item := func(text string) []streaming.Event {
+		return []streaming.Event{
+			{Kind: streaming.StartListItem},
+			{Kind: streaming.Text, TextContent: text},
+		}
+	}
+	endItem := streaming.Event{Kind: streaming.EndListItem}
+
+	events := []streaming.Event{
+		{Kind: streaming.StartDoc, Title: "Lists"},
+		{Kind: streaming.StartList, ListOrdered: true, ListGlyph: streaming.GlyphDecimal, ListStart: 3},
+	}
+	events = append(events, item("Three")...)
+	events = append(events, streaming.Event{Kind: streaming.StartList, ListLevel: 1, ListOrdered: true, ListGlyph: streaming.GlyphLowerAlpha, ListStart: 1})
+	events = append(events, item("Sub A")...)
+	events = append(events, endItem)
+	events = append(events, item("Sub B")...)
+	events = append(events, endItem, streaming.Event{Kind: streaming.EndList}, endItem)
+	events = append(events, item("Four")...)
+	events = append(events, endItem, streaming.Event{Kind: streaming.EndList})
+	events = append(events, streaming.Event{Kind: streaming.StartList, ListGlyph: streaming.GlyphCircle})
+	events = append(events, item("Bullet")...)
+	return append(events, endItem,
+		streaming.Event{Kind: streaming.EndList},
+		streaming.Event{Kind: streaming.EndDoc},
+	)
+}
+
+func TestHTMLWriter_NestedLists(t *testing.T) {
+	writer := NewHTMLWriter()
+	for _, event := range nestedListEvents() {
+		writer.Handle(event)
+	}
+	result := writer.Result()
+
+	for _, expected := range []string{
+		"<ol start=\"3\">\n        <li>Three\n            <ol type=\"a\">\n                <li>Sub A</li>\n                <li>Sub B</li>\n            </ol>\n        </li>\n        <li>Four</li>\n    </ol>",
+		"<ul style=\"list-style-type: circle;\">\n        <li>Bullet</li>\n    </ul>",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, result)
+		}
+	}
+}
+
+// breakEvents returns a document with a running header and footer, a horizontal rule, a page break and a new-page section break
+func breakEvents() []streaming.Event {
+	paragraph := func(text string) []streaming.Event {
+		return []streaming.Event{
+			{Kind: streaming.StartParagraph},
+			{Kind: streaming.Text, TextContent: text},
+			{Kind: streaming.EndParagraph},
+		}
+	}
+
+	events := []streaming.Event{{Kind: streaming.StartDoc, Title: "Breaks"}, {Kind: streaming.StartHeader}}
+	events = append(events, paragraph("Course notes")...)
+	events = append(events, streaming.Event{Kind: streaming.EndHeader}, streaming.Event{Kind: streaming.StartFooter},
+		streaming.Event{Kind: streaming.StartParagraph},
+		streaming.Event{Kind: streaming.Text, TextContent: "Page "},
+		streaming.Event{Kind: streaming.AutoText, AutoTextType: "PAGE_NUMBER"},
+		streaming.Event{Kind: streaming.EndParagraph},
+		streaming.Event{Kind: streaming.EndFooter})
+	events = append(events, paragraph("Before rule")...)
+	events = append(events, streaming.Event{Kind: streaming.HorizontalRule})
+	events = append(events, paragraph("Before break")...)
+	events = append(events, streaming.Event{Kind: streaming.PageBreak})
+	events = append(events, paragraph("New page")...)
+	events = append(events, streaming.Event{Kind: streaming.SectionBreak, SectionNewPage: true})
+	events = append(events, paragraph("New section")...)
+	return append(events, streaming.Event{Kind: streaming.EndDoc})
+}
+
+func TestHTMLWriter_Breaks(t *testing.T) {
+	writer := NewHTMLWriter()
+	for _, event := range breakEvents() {
+		writer.Handle(event)
+	}
+	result := writer.Result()
+
+	for _, expected := range []string{
+		"<header class=\"running-header\">\n    <p>Course notes</p>\n    </header>",
+		"<footer class=\"running-footer\">\n    <p>Page </p>\n    </footer>",
+		"<p>Before rule</p>\n    <hr />\n",
+		"<p>Before break</p>\n    <div class=\"page-break\"></div>\n",
+		"<p>New page</p>\n    <div class=\"page-break\"></div>\n",
+		"break-after: page;",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, result)
+		}
+	}
+	if strings.Index(result, "Course notes") > strings.Index(result, "<main class=\"document\">") {
+		t.Errorf("Expected running header outside the document content:\n%s", result)
+	}
+}
+
+// noteEvents returns a heading and a paragraph that each carry a user note
+func noteEvents() []streaming.Event {
+	return []streaming.Event{
+		{Kind: streaming.StartDoc, Title: "Notes"},
+		{Kind: streaming.StartHeading, Level: 2, AnchorID: "chapter", HeadingText: unique.Make("Chapter")},
+		{Kind: streaming.Text, TextContent: "Chapter"},
+		{Kind: streaming.Note, NoteID: 10, NoteText: "Revise this"},
+		{Kind: streaming.EndHeading},
+		{Kind: streaming.StartParagraph},
+		{Kind: streaming.Text, TextContent: "Body text"},
+		{Kind: streaming.Note, NoteID: 11, NoteText: "Exam <topic>"},
+		{Kind: streaming.EndParagraph},
+		{Kind: streaming.EndDoc},
+	}
+}
+
+func TestHTMLWriter_Notes(t *testing.T) {
+	writer := NewHTMLWriter()
+	for _, event := range noteEvents() {
+		writer.Handle(event)
+	}
+	result := writer.Result()
+
+	for _, expected := range []string{
+		"<h2 id=\"chapter\">Chapter<sup class=\"note-ref\" id=\"note-ref-1\"><a href=\"#note-1\">1</a></sup></h2>\n" +
+			"    <aside class=\"note\" id=\"note-1\"><a href=\"#note-ref-1\">1</a> Revise this</aside>\n",
+		"<p>Body text<sup class=\"note-ref\" id=\"note-ref-2\"><a href=\"#note-2\">2</a></sup></p>\n" +
+			"    <aside class=\"note\" id=\"note-2\"><a href=\"#note-ref-2\">2</a> Exam &lt;topic&gt;</aside>\n",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, result)
+		}
+	}
+
+	cfg := config.DefaultHTMLConfig()
+	cfg.IncludeNotes = false
+	writer = NewHTMLWriterWithConfig(cfg)
+	for _, event := range noteEvents() {
+		writer.Handle(event)
+	}
+	if result := writer.Result(); strings.Contains(result, "Revise this") || strings.Contains(result, "note-ref-") {
+		t.Errorf("Expected notes to be omitted:\n%s", result)
+	}
+}
+
+func TestHTMLWriter_TemplateDir(t *testing.T) {
+	dir := t.TempDir()
+	templateFiles := map[string]string{
+		"header.html": `<header class="faculty-header">{{.Title}}</header>`,
+		"toc.html":    `<ol class="chapters">{{range .Chapters}}<li><a href="#{{.Anchor}}">{{.Title}}</a></li>{{end}}</ol><ul class="headings">{{range .TOC}}<li>{{.Title}}{{len .Children}}</li>{{end}}</ul>`,
+	}
+	for name, content := range templateFiles {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cssPath := filepath.Join(dir, "faculty.css")
+	if err := os.WriteFile(cssPath, []byte("body { color: #003082; }"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultHTMLConfig()
+	cfg.TemplateDir = dir
+	cfg.CSSPath = cssPath
+	cfg.Language = "nl"
+	writer := NewHTMLWriterWithConfig(cfg)
+
+	events := []streaming.Event{
+		{Kind: streaming.StartDoc, Title: "Anatomy", Chapters: []models.Chapter{{Title: "Bones"}, {Title: "Muscles"}}},
+		{Kind: streaming.StartHeading, Level: 1, AnchorID: "bones", HeadingText: unique.Make("Bones")},
+		{Kind: streaming.Text, TextContent: "Bones"},
+		{Kind: streaming.EndHeading},
+		{Kind: streaming.StartHeading, Level: 2, AnchorID: "skull", HeadingText: unique.Make("Skull")},
+		{Kind: streaming.Text, TextContent: "Skull"},
+		{Kind: streaming.EndHeading},
+		{Kind: streaming.EndDoc},
+	}
+	for _, event := range events {
+		writer.Handle(event)
+	}
+
+	result := writer.Result()
+	for _, expected := range []string{
+		`<html lang="nl">`,
+		"body { color: #003082; }",
+		`<header class="faculty-header">Anatomy</header>`,
+		`<ol class="chapters"><li><a href="#bones">Bones</a></li><li><a href="#">Muscles</a></li></ol>`,
+		`<ul class="headings"><li>Bones1</li></ul>`,
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, result)
+		}
+	}
+
+	// A missing template directory falls back to the built-in template
+	cfg.TemplateDir = filepath.Join(dir, "missing")
+	writer = NewHTMLWriterWithConfig(cfg)
+	for _, event := range events {
+		writer.Handle(event)
+	}
+	if !strings.Contains(writer.Result(), `<h1 class="document-title">Anatomy</h1>`) {
+		t.Errorf("Expected the built-in template, got:\n%s", writer.Result())
+	}
+}
+
+// tocEvents is a document whose table of contents lists a chapter and its section
+func tocEvents() []streaming.Event {
+	heading := func(level int, anchor, title string) []streaming.Event {
+		return []streaming.Event{
+			{Kind: streaming.StartHeading, Level: level, AnchorID: anchor, HeadingText: unique.Make(title)},
+			{Kind: streaming.Text, TextContent: title},
+			{Kind: streaming.EndHeading, Level: level},
+		}
+	}
+
+	events := []streaming.Event{{Kind: streaming.StartDoc, Title: "Anatomy"}}
+	events = append(events, heading(2, "contents", "Contents")...)
+	events = append(events, streaming.Event{
+		Kind: streaming.TableOfContents,
+		TOCEntries: []streaming.TOCEntry{
+			{Level: 2, Text: "Bones", AnchorID: "bones"},
+			{Level: 3, Text: "Skull", AnchorID: "skull"},
+		},
+		TOCNested: true,
+	})
+	events = append(events, heading(2, "bones", "Bones")...)
+	events = append(events, heading(3, "skull", "Skull")...)
+	return append(events, streaming.Event{Kind: streaming.EndDoc})
+}
+
+func TestHTMLWriter_TableOfContentsEvent(t *testing.T) {
+	writer := NewHTMLWriter()
+	for _, event := range tocEvents() {
+		writer.Handle(event)
+	}
+	result := writer.Result()
+
+	expected := "<nav class=\"table-of-contents\">\n" +
+		"    <ul>\n" +
+		"        <li><a href=\"#bones\">Bones</a>\n" +
+		"            <ul>\n" +
+		"                <li><a href=\"#skull\">Skull</a></li>\n" +
+		"            </ul>\n" +
+		"        </li>\n" +
+		"    </ul>\n" +
+		"    </nav>\n"
+	if !strings.Contains(result, expected) {
+		t.Errorf("Expected %q in output:\n%s", expected, result)
+	}
+}
+
+func TestHTMLWriter_Transforms(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Transforms.Passes = []config.TransformPassConfig{
+		{Name: config.TransformRenumberHeadings, Options: map[string]string{"start": "2"}},
+		{Name: config.TransformExternalLinks},
+	}
+	factory, _ := Get("html")
+	writer := factory(cfg)
+
+	for _, event := range hastTestEvents() {
+		if err := writer.Handle(event); err != nil {
+			t.Fatalf("Handle() failed: %v", err)
+		}
+	}
+	output, err := writer.Flush()
+	if err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+
+	html := string(output)
+	for _, expected := range []string{
+		`<h2 id="cells">Cells</h2>`,
+		`<a href="https://example.com" rel="noopener noreferrer" target="_blank">More</a>`,
+		`<div class="document-body">`,
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, html)
+		}
+	}
+
+	cfg.Transforms.Passes = []config.TransformPassConfig{{Name: "minify"}}
+	writer = factory(cfg)
+	if _, err := writer.Flush(); err == nil || !strings.Contains(err.Error(), "minify") {
+		t.Errorf("Expected unknown pass error, got %v", err)
+	}
+}
+
+func TestHTMLWriter_TransformsTableOfContents(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.HTML.IncludeTOC = true
+	cfg.Transforms.Passes = []config.TransformPassConfig{
+		{Name: config.TransformRenumberHeadings, Options: map[string]string{"numbered": "true"}},
+	}
+	factory, _ := Get("html")
+	writer := factory(cfg)
+	for _, event := range tocEvents() {
+		if err := writer.Handle(event); err != nil {
+			t.Fatalf("Handle() failed: %v", err)
+		}
+	}
+	output, err := writer.Flush()
+	if err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+
+	// The table of contents lists the renumbered headings
+	html := string(output)
+	for _, expected := range []string{
+		`<h2 id="skull"><span class="heading-number">2.1 </span>Skull</h2>`,
+		`<a href="#skull">2.1 Skull</a>`,
+	} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, html)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package writers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "image/gif" // Register GIF decoder for image.Decode
+)
+
+// maxImageSize caps the number of bytes read for a single image
+const maxImageSize = 32 * 1024 * 1024
+
+// FetchedImage holds the raw bytes and media type of a resolved image
+type FetchedImage struct {
+	Data      []byte
+	MediaType string
+}
+
+// ImageFetcher resolves an image URL from a streaming.Image event to its bytes
+type ImageFetcher interface {
+	// Fetch retrieves the image referenced by imageURL
+	Fetch(ctx context.Context, imageURL string) (*FetchedImage, error)
+}
+
+// HTTPImageFetcher downloads images over HTTP(S)
+type HTTPImageFetcher struct {
+	client *http.Client
+}
+
+// NewHTTPImageFetcher returns an HTTPImageFetcher using the given client, or a client with a 30 second timeout if nil.
+func NewHTTPImageFetcher(client *http.Client) *HTTPImageFetcher {
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &HTTPImageFetcher{client: client}
+}
+
+// Fetch downloads the image at imageURL
+func (f *HTTPImageFetcher) Fetch(ctx context.Context, imageURL string) (*FetchedImage, error) {
+	parsedURL, err := url.Parse(imageURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image URL %q: %w", imageURL, err)
+	}
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported image URL scheme %q", parsedURL.Scheme)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create image request: %w", err)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("image request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("image request failed with status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if len(data) > maxImageSize {
+		return nil, fmt.Errorf("image exceeds maximum size of %d bytes", maxImageSize)
+	}
+
+	return newFetchedImage(data, resp.Header.Get("Content-Type"), parsedURL.Path)
+}
+
+// LocalImageFetcher resolves images from a local directory by the base name of the URL path
+type LocalImageFetcher struct {
+	dir string
+}
+
+// NewLocalImageFetcher returns a LocalImageFetcher that reads images from dir.
+func NewLocalImageFetcher(dir string) *LocalImageFetcher {
+	return &LocalImageFetcher{dir: dir}
+}
+
+// Fetch reads the image whose file name matches the last path segment of imageURL
+func (f *LocalImageFetcher) Fetch(ctx context.Context, imageURL string) (*FetchedImage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	parsedURL, err := url.Parse(imageURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image URL %q: %w", imageURL, err)
+	}
+
+	name := path.Base(parsedURL.Path)
+	if name == "." || name == "/" || name == "" {
+		return nil, fmt.Errorf("image URL %q has no file name", imageURL)
+	}
+
+	data, err := os.ReadFile(filepath.Join(f.dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local image: %w", err)
+	}
+
+	return newFetchedImage(data, "", name)
+}
+
+// ChainImageFetcher tries each fetcher in order and returns the first success
+type ChainImageFetcher []ImageFetcher
+
+// Fetch returns the result of the first fetcher that resolves imageURL
+func (c ChainImageFetcher) Fetch(ctx context.Context, imageURL string) (*FetchedImage, error) {
+	var errs []string
+	for _, fetcher := range c {
+		img, err := fetcher.Fetch(ctx, imageURL)
+		if err == nil {
+			return img, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return nil, fmt.Errorf("no fetcher could resolve %s: %s", imageURL, strings.Join(errs, "; "))
+}
+
+// newFetchedImage determines the media type of data and rejects non-image content
+func newFetchedImage(data []byte, contentType, name string) (*FetchedImage, error) {
+	mediaType := http.DetectContentType(data)
+	if !strings.HasPrefix(mediaType, "image/") {
+		// Sniffing does not recognise SVG; trust the declared or extension-derived type
+		mediaType, _, _ = mime.ParseMediaType(contentType)
+		if !strings.HasPrefix(mediaType, "image/") {
+			mediaType = mime.TypeByExtension(path.Ext(name))
+			mediaType, _, _ = mime.ParseMediaType(mediaType)
+		}
+	}
+	if !strings.HasPrefix(mediaType, "image/") {
+		return nil, fmt.Errorf("content is not an image")
+	}
+	return &FetchedImage{Data: data, MediaType: mediaType}, nil
+}
+
+// compressImage re-encodes JPEG images at quality and PNG images at the best lossless compression,
+// returning the original if re-encoding does not shrink it
+func compressImage(img *FetchedImage, quality int) *FetchedImage {
+	if quality <= 0 || quality > 100 {
+		quality = jpeg.DefaultQuality
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(img.Data))
+	if err != nil {
+		return img
+	}
+
+	var buf bytes.Buffer
+	switch img.MediaType {
+	case "image/jpeg":
+		err = jpeg.Encode(&buf, decoded, &jpeg.Options{Quality: quality})
+	case "image/png":
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		err = encoder.Encode(&buf, decoded)
+	default:
+		return img
+	}
+
+	if err != nil || buf.Len() >= len(img.Data) {
+		return img
+	}
+	return &FetchedImage{Data: buf.Bytes(), MediaType: img.MediaType}
+}
+
+// imageExtension returns the file extension for an image media type
+func imageExtension(mediaType string) string {
+	switch mediaType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/svg+xml":
+		return ".svg"
+	case "image/webp":
+		return ".webp"
+	default:
+		if exts, err := mime.ExtensionsByType(mediaType); err == nil && len(exts) > 0 {
+			return exts[0]
+		}
+		return ".img"
+	}
+}
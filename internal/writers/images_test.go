@@ -0,0 +1,135 @@
+package writers
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testPNG returns a small encoded PNG image
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 4; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 60), G: uint8(y * 60), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestHTTPImageFetcher(t *testing.T) {
+	pngData := testPNG(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/image.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(pngData)
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := NewHTTPImageFetcher(server.Client())
+
+	img, err := fetcher.Fetch(context.Background(), server.URL+"/image.png")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if img.MediaType != "image/png" {
+		t.Errorf("Expected media type image/png, got %s", img.MediaType)
+	}
+	if !bytes.Equal(img.Data, pngData) {
+		t.Error("Fetched image data does not match served data")
+	}
+
+	if _, err := fetcher.Fetch(context.Background(), server.URL+"/missing.png"); err == nil {
+		t.Error("Expected error for missing image")
+	}
+	if _, err := fetcher.Fetch(context.Background(), server.URL+"/page.html"); err == nil {
+		t.Error("Expected error for non-image content")
+	}
+	if _, err := fetcher.Fetch(context.Background(), "/relative/image.png"); err == nil {
+		t.Error("Expected error for relative URL")
+	}
+}
+
+func TestLocalImageFetcher(t *testing.T) {
+	dir := t.TempDir()
+	pngData := testPNG(t)
+	if err := os.WriteFile(filepath.Join(dir, "figure.png"), pngData, 0644); err != nil {
+		t.Fatalf("Failed to write image: %v", err)
+	}
+
+	fetcher := NewLocalImageFetcher(dir)
+
+	img, err := fetcher.Fetch(context.Background(), "https://api.slimacademy.nl/images/figure.png?v=2")
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if img.MediaType != "image/png" {
+		t.Errorf("Expected media type image/png, got %s", img.MediaType)
+	}
+
+	if _, err := fetcher.Fetch(context.Background(), "https://api.slimacademy.nl/images/other.png"); err == nil {
+		t.Error("Expected error for image not in directory")
+	}
+}
+
+func TestChainImageFetcher(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "local.png"), testPNG(t), 0644); err != nil {
+		t.Fatalf("Failed to write image: %v", err)
+	}
+
+	chain := ChainImageFetcher{NewLocalImageFetcher(dir), NewLocalImageFetcher(t.TempDir())}
+	if _, err := chain.Fetch(context.Background(), "https://example.com/local.png"); err != nil {
+		t.Errorf("Expected first fetcher to resolve image: %v", err)
+	}
+	if _, err := chain.Fetch(context.Background(), "https://example.com/absent.png"); err == nil {
+		t.Error("Expected error when no fetcher resolves image")
+	}
+}
+
+func TestCompressImage(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for x := 0; x < 64; x++ {
+		for y := 0; y < 64; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x * 4), G: uint8(y * 4), B: uint8((x + y) * 2), A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatalf("Failed to encode JPEG: %v", err)
+	}
+	original := &FetchedImage{Data: buf.Bytes(), MediaType: "image/jpeg"}
+
+	compressed := compressImage(original, 30)
+	if len(compressed.Data) >= len(original.Data) {
+		t.Errorf("Expected compressed JPEG to be smaller: %d >= %d", len(compressed.Data), len(original.Data))
+	}
+	if compressed.MediaType != "image/jpeg" {
+		t.Errorf("Expected media type to be preserved, got %s", compressed.MediaType)
+	}
+
+	svg := &FetchedImage{Data: []byte("<svg/>"), MediaType: "image/svg+xml"}
+	if compressImage(svg, 30) != svg {
+		t.Error("Expected unsupported formats to be returned unchanged")
+	}
+}
@@ -563,7 +563,8 @@ func createLargeTableBook() *models.Book {
 			}
 		}
 		rows[i] = models.TableRow{
-			TableCells: cells,
+			TableCells:    cells,
+			TableRowStyle: models.TableRowStyle{TableHeader: i == 0},
 		}
 	}
 
@@ -3,6 +3,9 @@ package writers
 import (
 	"fmt"
 	"io"
+	"math"
+	"slices"
+	"strconv"
 	"strings"
 	"unicode/utf8"
 
@@ -31,13 +34,58 @@ type LaTeXWriter struct {
 	out                 *strings.Builder
 	activeStyle         streaming.StyleFlags
 	linkURL             string
-	inList              bool
-	inTable             bool
-	listDepth           int
-	tableColumns        int
-	tableCellCount      int
+	lists               []latexList // Open itemize/enumerate environments, outermost first
 	currentHeadingLevel int
 	currentAnchorID     string
+	headingBody         *strings.Builder // Output builder while a heading with possible footnotes is collected
+	headingNotes        []string         // Footnotes of the open heading, kept out of its TOC entry
+	pendingHeading      *latexHeading    // Heading held back until the next event, which may be a table of contents it titles
+
+	// Running headers and footers are collected in the preamble until the body starts
+	bodyPending  bool             // \begin{document} has not been written yet
+	body         *strings.Builder // Output builder while a header or footer is being collected
+	header       string
+	footer       string
+	usesLastPage bool // A header or footer shows the page count
+
+	// Table handling
+	latexTableState
+	outerTables []latexTableState // States of the tables enclosing a nested table
+}
+
+// latexHeading is a collected heading that has not been written yet
+type latexHeading struct {
+	command  string   // Sectioning command without its brace, such as \section
+	title    string   // Title without footnotes
+	notes    []string // Footnotes of the title
+	anchorID string
+}
+
+// latexList tracks an open itemize or enumerate environment
+type latexList struct {
+	env      string
+	lineOpen bool // The open \item line has not been terminated yet
+}
+
+// latexSectionDepths are the table of contents depths of the standard sectioning commands
+var latexSectionDepths = map[string]int{
+	"part": -1, "chapter": 0, "section": 1, "subsection": 2, "subsubsection": 3, "paragraph": 4, "subparagraph": 5,
+}
+
+// latexDepthNames are the counter and label suffixes of the four LaTeX list nesting levels
+var latexDepthNames = []string{"i", "ii", "iii", "iv"}
+
+// latexTableState tracks the tabular currently being written
+type latexTableState struct {
+	inTable       bool
+	tableColumns  int
+	tableColumn   int             // Column index of the next cell in the current row
+	tableRow      int             // Index of the current row
+	rowCovers     []latexRowCover // Columns occupied by \multirow cells from earlier rows
+	cellSpan      int             // Columns covered by the open cell
+	cellCloser    string          // Braces closing \multicolumn/\multirow for the open cell
+	inTableHeader bool            // Rows written so far are all header rows
+	cellBlocks    int             // Paragraphs, lists and tables written in the current cell
 }
 
 // NewLaTeXWriter returns a new LaTeXWriter initialized with the provided configuration or a default configuration if none is given.
@@ -53,6 +101,13 @@ func NewLaTeXWriter(cfg *config.LaTeXConfig) *LaTeXWriter {
 
 // Handle processes a single event
 func (w *LaTeXWriter) Handle(event streaming.Event) {
+	if event.Kind != streaming.TableOfContents {
+		w.writePendingHeading()
+	}
+	if w.bodyPending && w.body == nil && !isRunningBlockEvent(event.Kind) {
+		w.beginDocument()
+	}
+
 	switch event.Kind {
 	case streaming.StartDoc:
 		title := event.Title
@@ -61,12 +116,47 @@ func (w *LaTeXWriter) Handle(event streaming.Event) {
 	case streaming.EndDoc:
 		w.out.WriteString("\\end{document}\n")
 
+	case streaming.StartHeader, streaming.StartFooter:
+		// Collect the content into its own builder, restored by the end event
+		w.body = w.out
+		w.out = &strings.Builder{}
+
+	case streaming.EndHeader, streaming.EndFooter:
+		w.endRunningBlock(event.Kind)
+
+	case streaming.PageBreak:
+		if !w.inTable {
+			w.out.WriteString("\\newpage\n\n")
+		}
+
+	case streaming.HorizontalRule:
+		if !w.inTable {
+			w.out.WriteString("\\medskip\\hrule\\medskip\n\n")
+		}
+
+	case streaming.SectionBreak:
+		if event.SectionNewPage {
+			w.out.WriteString("\\newpage\n\n")
+		}
+
+	case streaming.AutoText:
+		switch event.AutoTextType {
+		case "PAGE_NUMBER":
+			w.out.WriteString("\\thepage{}")
+		case "PAGE_COUNT":
+			w.out.WriteString("\\pageref{LastPage}")
+			w.usesLastPage = true
+		}
+
 	case streaming.StartParagraph:
 		// Paragraphs are separated by blank lines in LaTeX
+		if w.inTable {
+			w.startCellBlock()
+		}
 
 	case streaming.EndParagraph:
 		// Add appropriate spacing based on context
-		if w.inTable || w.inList {
+		if w.inTable || len(w.lists) > 0 {
 			// Inside table cells or lists, use single newline to avoid excessive spacing
 			w.out.WriteString("\n")
 		} else {
@@ -77,58 +167,72 @@ func (w *LaTeXWriter) Handle(event streaming.Event) {
 	case streaming.StartHeading:
 		w.currentHeadingLevel = event.Level
 		w.currentAnchorID = event.AnchorID
-		sectionCmd := w.getSectionCommand(event.Level)
-		fmt.Fprintf(w.out, "%s{", sectionCmd)
+		// The title is collected so that footnotes can be left out of the table of contents
+		w.headingBody = w.out
+		w.out = &strings.Builder{}
 
 	case streaming.EndHeading:
-		fmt.Fprintf(w.out, "}\n\\label{%s}\n\n", w.currentAnchorID)
+		w.endHeading()
+
+	case streaming.Note:
+		if !w.config.IncludeNotes {
+			break
+		}
+		footnote := fmt.Sprintf("\\footnote{%s}", w.escapeLaTeX(event.NoteText))
+		if w.headingBody != nil {
+			w.headingNotes = append(w.headingNotes, footnote)
+		} else {
+			w.out.WriteString(footnote)
+		}
+
+	case streaming.TableOfContents:
+		w.writeTableOfContents(event)
 
 	case streaming.StartList:
-		w.inList = true
-		w.listDepth++
-		w.out.WriteString("\\begin{itemize}\n")
+		if w.inTable {
+			w.startCellBlock()
+		}
+		w.startList(event)
 
 	case streaming.EndList:
-		w.inList = false
-		w.listDepth--
-		w.out.WriteString("\\end{itemize}\n\n")
+		w.endList()
 
-	case streaming.StartTable:
-		w.inTable = true
-		w.tableColumns = event.TableColumns
-		if w.tableColumns <= 0 {
-			w.tableColumns = 3 // fallback
-		}
-		w.out.WriteString("\\begin{table}[h]\n\\centering\n\\begin{tabular}{")
-		// Use mixed alignment: left for first column, center for others
-		// This provides better readability for most table types
-		if w.tableColumns > 0 {
-			w.out.WriteString("l") // First column left-aligned (usually labels/names)
-			if w.tableColumns > 1 {
-				w.out.WriteString(strings.Repeat("c", w.tableColumns-1)) // Other columns centered
-			}
+	case streaming.StartListItem:
+		fmt.Fprintf(w.out, "%s\\item ", strings.Repeat("  ", len(w.lists)))
+		if n := len(w.lists); n > 0 {
+			w.lists[n-1].lineOpen = true
 		}
-		w.out.WriteString("}\n\\hline\n")
+
+	case streaming.EndListItem:
+		w.endListLine()
+
+	case streaming.StartTable:
+		w.startTable(event)
 
 	case streaming.EndTable:
-		w.inTable = false
-		w.out.WriteString("\\hline\n\\end{tabular}\n\\end{table}\n\n")
+		w.endTable()
 
 	case streaming.StartTableRow:
-		w.tableCellCount = 0
+		w.tableColumn = 0
+		if event.TableHeader && w.tableRow == 0 {
+			w.inTableHeader = true
+		} else if !event.TableHeader && w.inTableHeader {
+			w.inTableHeader = false
+			w.writeTableRule("midrule")
+		}
 
 	case streaming.EndTableRow:
+		w.fillCoveredColumns()
 		w.out.WriteString(" \\\\\n")
+		w.tableRow++
 
 	case streaming.StartTableCell:
-		// Cell content will be handled by other events
+		w.cellBlocks = 0
+		w.startTableCell(event)
 
 	case streaming.EndTableCell:
-		w.tableCellCount++
-		// Don't add " & " after the last cell in a row
-		if w.tableCellCount < w.tableColumns {
-			w.out.WriteString(" & ")
-		}
+		w.out.WriteString(w.cellCloser)
+		w.tableColumn += w.cellSpan
 
 	case streaming.StartFormatting:
 		w.openLaTeXCommand(event.Style, event.LinkURL)
@@ -145,14 +249,7 @@ func (w *LaTeXWriter) Handle(event streaming.Event) {
 		}
 
 	case streaming.Text:
-		text := event.TextContent
-		if w.inList {
-			// Handle list items
-			indent := strings.Repeat("  ", w.listDepth-1)
-			fmt.Fprintf(w.out, "%s\\item %s\n", indent, w.escapeLaTeX(text))
-		} else {
-			w.out.WriteString(w.escapeLaTeX(text))
-		}
+		w.out.WriteString(w.escapeLaTeX(event.TextContent))
 
 	case streaming.Image:
 		fmt.Fprintf(w.out, "\\includegraphics[width=0.8\\textwidth]{%s}",
@@ -160,17 +257,309 @@ func (w *LaTeXWriter) Handle(event streaming.Event) {
 	}
 }
 
-// writeDocumentHeader writes the LaTeX document header
+// startList opens an itemize or enumerate environment with labels and start number matching the list glyph
+func (w *LaTeXWriter) startList(event streaming.Event) {
+	w.endListLine()
+
+	env := "itemize"
+	if event.ListOrdered || event.ListGlyph.Ordered() {
+		env = "enumerate"
+	}
+	depth := 0
+	for _, list := range w.lists {
+		if list.env == env {
+			depth++
+		}
+	}
+
+	indent := strings.Repeat("  ", len(w.lists))
+	fmt.Fprintf(w.out, "%s\\begin{%s}\n", indent, env)
+	w.lists = append(w.lists, latexList{env: env})
+
+	// LaTeX supports four nesting levels per environment
+	if depth >= len(latexDepthNames) {
+		return
+	}
+	name := latexDepthNames[depth]
+	if env == "itemize" {
+		if label := latexItemLabel(event.ListGlyph); label != "" {
+			fmt.Fprintf(w.out, "%s  \\renewcommand{\\labelitem%s}{%s}\n", indent, name, label)
+		}
+		return
+	}
+	if numbering := latexEnumNumbering(event.ListGlyph); numbering != "" {
+		fmt.Fprintf(w.out, "%s  \\renewcommand{\\labelenum%s}{%s{enum%s}.}\n", indent, name, numbering, name)
+	}
+	if event.ListStart > 1 {
+		fmt.Fprintf(w.out, "%s  \\setcounter{enum%s}{%d}\n", indent, name, event.ListStart-1)
+	}
+}
+
+// endList closes the innermost list environment
+func (w *LaTeXWriter) endList() {
+	w.endListLine()
+	n := len(w.lists)
+	if n == 0 {
+		return
+	}
+	fmt.Fprintf(w.out, "%s\\end{%s}\n", strings.Repeat("  ", n-1), w.lists[n-1].env)
+	w.lists = w.lists[:n-1]
+	if n == 1 {
+		w.out.WriteString("\n")
+	}
+}
+
+// endListLine terminates the open \item line of the innermost list
+func (w *LaTeXWriter) endListLine() {
+	if n := len(w.lists); n > 0 && w.lists[n-1].lineOpen {
+		w.out.WriteString("\n")
+		w.lists[n-1].lineOpen = false
+	}
+}
+
+// latexItemLabel returns the itemize label for a bullet glyph, or "" for the default bullet
+func latexItemLabel(glyph streaming.ListGlyph) string {
+	switch glyph {
+	case streaming.GlyphCircle:
+		return "$\\circ$"
+	case streaming.GlyphSquare:
+		return "\\rule[0.3ex]{0.8ex}{0.8ex}"
+	}
+	return ""
+}
+
+// latexEnumNumbering returns the counter representation command for a numbered glyph, or "" for the default
+func latexEnumNumbering(glyph streaming.ListGlyph) string {
+	switch glyph {
+	case streaming.GlyphDecimal:
+		return "\\arabic"
+	case streaming.GlyphLowerAlpha:
+		return "\\alph"
+	case streaming.GlyphUpperAlpha:
+		return "\\Alph"
+	case streaming.GlyphLowerRoman:
+		return "\\roman"
+	case streaming.GlyphUpperRoman:
+		return "\\Roman"
+	}
+	return ""
+}
+
+// startTable opens a tabular. Top-level tables are wrapped in a table float, nested tables
+// are written inline in the enclosing cell.
+func (w *LaTeXWriter) startTable(event streaming.Event) {
+	nested := w.inTable
+	if nested {
+		w.startCellBlock()
+		w.outerTables = append(w.outerTables, w.latexTableState)
+	}
+
+	columns := event.TableColumns
+	if columns <= 0 {
+		columns = 3 // fallback
+	}
+	w.latexTableState = latexTableState{
+		inTable:      true,
+		tableColumns: columns,
+		rowCovers:    make([]latexRowCover, columns),
+	}
+
+	if !nested {
+		w.out.WriteString("\\begin{table}[h]\n\\centering\n")
+	}
+	w.out.WriteString("\\begin{tabular}{")
+	w.out.WriteString(latexColumnSpec(columns, event.TableColumnWidths, event.TableHasBlocks))
+	w.out.WriteString("}\n")
+	w.writeTableRule("toprule")
+}
+
+// endTable closes the current tabular and restores the enclosing table, if any
+func (w *LaTeXWriter) endTable() {
+	w.writeTableRule("bottomrule")
+	w.out.WriteString("\\end{tabular}\n")
+
+	if n := len(w.outerTables); n > 0 {
+		w.latexTableState = w.outerTables[n-1]
+		w.outerTables = w.outerTables[:n-1]
+		return
+	}
+	w.latexTableState = latexTableState{}
+	w.out.WriteString("\\end{table}\n\n")
+}
+
+// startCellBlock separates a paragraph, list or nested table from the previous block in the same cell
+func (w *LaTeXWriter) startCellBlock() {
+	if w.cellBlocks > 0 {
+		w.out.WriteString("\\par ")
+	}
+	w.cellBlocks++
+}
+
+// writeTableRule writes a booktabs rule such as toprule, or \hline when booktabs is disabled
+func (w *LaTeXWriter) writeTableRule(rule string) {
+	if w.config.UseBooktabs {
+		fmt.Fprintf(w.out, "\\%s\n", rule)
+		return
+	}
+	w.out.WriteString("\\hline\n")
+}
+
+// latexRowCover records a \multirow cell that occupies columns in the rows below it
+type latexRowCover struct {
+	firstRow int
+	lastRow  int
+	span     int
+}
+
+// latexColumnSpec returns the tabular column specification, using fixed-width p columns where widths are known.
+// Tables with block content in cells need paragraph columns, so the remaining columns share the line width.
+// Other columns use mixed alignment: left for the first column (usually labels/names), centered for the rest.
+func latexColumnSpec(columns int, widths []float64, blocks bool) string {
+	share := strconv.FormatFloat(math.Round(900/float64(columns))/1000, 'f', -1, 64)
+
+	var spec strings.Builder
+	for i := range columns {
+		switch {
+		case i < len(widths) && widths[i] > 0:
+			fmt.Fprintf(&spec, "p{%spt}", strconv.FormatFloat(widths[i], 'f', -1, 64))
+		case blocks:
+			fmt.Fprintf(&spec, "p{%s\\linewidth}", share)
+		case i == 0:
+			spec.WriteString("l")
+		default:
+			spec.WriteString("c")
+		}
+	}
+	return spec.String()
+}
+
+// startTableCell writes the separator and any \multicolumn/\multirow wrappers for a new cell
+func (w *LaTeXWriter) startTableCell(event streaming.Event) {
+	w.fillCoveredColumns()
+	w.writeCellSeparator()
+
+	colSpan := max(1, event.CellColSpan)
+	if remaining := w.tableColumns - w.tableColumn; remaining > 0 {
+		colSpan = min(colSpan, remaining)
+	}
+	rowSpan := max(1, event.CellRowSpan)
+
+	w.cellSpan = colSpan
+	w.cellCloser = ""
+	if colSpan > 1 {
+		align := "c"
+		if w.tableColumn == 0 {
+			align = "l"
+		}
+		fmt.Fprintf(w.out, "\\multicolumn{%d}{%s}{", colSpan, align)
+		w.cellCloser += "}"
+	}
+	if rowSpan > 1 {
+		fmt.Fprintf(w.out, "\\multirow{%d}{*}{", rowSpan)
+		w.cellCloser += "}"
+		if w.tableColumn < len(w.rowCovers) {
+			w.rowCovers[w.tableColumn] = latexRowCover{
+				firstRow: w.tableRow,
+				lastRow:  w.tableRow + rowSpan - 1,
+				span:     colSpan,
+			}
+		}
+	}
+}
+
+// fillCoveredColumns writes empty cells for columns occupied by a \multirow from an earlier row,
+// stopping at the first column that is not covered
+func (w *LaTeXWriter) fillCoveredColumns() {
+	for w.tableColumn < len(w.rowCovers) {
+		cover := w.rowCovers[w.tableColumn]
+		if cover.span == 0 || cover.firstRow >= w.tableRow || w.tableRow > cover.lastRow {
+			return
+		}
+		w.writeCellSeparator()
+		if cover.span > 1 {
+			fmt.Fprintf(w.out, "\\multicolumn{%d}{c}{}", cover.span)
+		}
+		w.tableColumn += cover.span
+	}
+}
+
+// writeCellSeparator separates a cell from the previous one in the row
+func (w *LaTeXWriter) writeCellSeparator() {
+	if w.tableColumn > 0 {
+		w.out.WriteString(" & ")
+	}
+}
+
+// writeDocumentHeader writes the LaTeX preamble. The document body is started by the first
+// event that is not part of a running header or footer, so those can still configure fancyhdr.
 func (w *LaTeXWriter) writeDocumentHeader(title string) {
 	w.out.WriteString(w.config.GetDocumentPreamble())
 	w.out.WriteString("\n")
 	fmt.Fprintf(w.out, "\\title{%s}\n", w.escapeLaTeX(title))
 	w.out.WriteString("\\author{}\n")
 	w.out.WriteString("\\date{}\n\n")
+	w.bodyPending = true
+}
+
+// beginDocument writes the fancyhdr page style for collected headers and footers and starts the document body
+func (w *LaTeXWriter) beginDocument() {
+	w.bodyPending = false
+
+	if w.header != "" || w.footer != "" {
+		if !slices.Contains(w.config.Packages, "fancyhdr") {
+			w.out.WriteString("\\usepackage{fancyhdr}\n")
+		}
+		if w.usesLastPage && !slices.Contains(w.config.Packages, "lastpage") {
+			w.out.WriteString("\\usepackage{lastpage}\n")
+		}
+		w.out.WriteString("\\pagestyle{fancy}\n\\fancyhf{}\n")
+		if w.header != "" {
+			fmt.Fprintf(w.out, "\\fancyhead[C]{%s}\n", w.header)
+		}
+		if w.footer != "" {
+			fmt.Fprintf(w.out, "\\fancyfoot[C]{%s}\n", w.footer)
+		} else {
+			w.out.WriteString("\\fancyfoot[C]{\\thepage}\n")
+		}
+		w.out.WriteString("\n")
+	}
+
 	w.out.WriteString("\\begin{document}\n")
 	w.out.WriteString("\\maketitle\n\n")
 }
 
+// endRunningBlock stores the collected header or footer content and restores the output builder.
+// Paragraphs become line breaks, since fancyhdr fields cannot hold paragraph breaks.
+func (w *LaTeXWriter) endRunningBlock(kind streaming.EventKind) {
+	if w.body == nil {
+		return
+	}
+	var paragraphs []string
+	for _, paragraph := range strings.Split(w.out.String(), "\n\n") {
+		if text := strings.Join(strings.Fields(paragraph), " "); text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+	}
+	content := strings.Join(paragraphs, " \\\\ ")
+	w.out = w.body
+	w.body = nil
+
+	if kind == streaming.EndHeader {
+		w.header = content
+	} else {
+		w.footer = content
+	}
+}
+
+// isRunningBlockEvent reports whether an event delimits a running header or footer
+func isRunningBlockEvent(kind streaming.EventKind) bool {
+	switch kind {
+	case streaming.StartDoc, streaming.StartHeader, streaming.EndHeader, streaming.StartFooter, streaming.EndFooter:
+		return true
+	}
+	return false
+}
+
 // getSectionCommand returns the appropriate LaTeX section command for the level
 func (w *LaTeXWriter) getSectionCommand(level int) string {
 	return w.config.GetHeadingCommand(level)
@@ -233,6 +622,78 @@ func (w *LaTeXWriter) closeLaTeXCommand(style streaming.StyleFlags) {
 	}
 }
 
+// writeTableOfContents writes a native table of contents. LaTeX lists the sections itself, so the
+// entries only set tocdepth to the sectioning command of the deepest entry. \tableofcontents
+// writes its own title, so the placeholder heading right before it becomes that title instead
+// of a section of its own; its footnotes follow the table of contents.
+func (w *LaTeXWriter) writeTableOfContents(event streaming.Event) {
+	if w.inTable || len(event.TOCEntries) == 0 {
+		w.writePendingHeading()
+		return
+	}
+
+	placeholder := w.pendingHeading
+	w.pendingHeading = nil
+	if placeholder != nil {
+		fmt.Fprintf(w.out, "\\renewcommand{\\contentsname}{%s}\n", placeholder.title)
+	}
+
+	depth, known := 0, false
+	for _, entry := range event.TOCEntries {
+		command := strings.TrimSuffix(strings.TrimPrefix(w.getSectionCommand(entry.Level), "\\"), "{")
+		if d, ok := latexSectionDepths[command]; ok && (!known || d > depth) {
+			depth, known = d, true
+		}
+	}
+	if known {
+		fmt.Fprintf(w.out, "\\setcounter{tocdepth}{%d}\n", depth)
+	}
+	w.out.WriteString("\\tableofcontents\n")
+	if placeholder != nil {
+		fmt.Fprintf(w.out, "\\label{%s}\n", placeholder.anchorID)
+		if len(placeholder.notes) > 0 {
+			fmt.Fprintf(w.out, "%s\n", strings.Join(placeholder.notes, ""))
+		}
+	}
+	w.out.WriteString("\n")
+}
+
+// endHeading records the collected heading title. It is written by writePendingHeading once the
+// next event shows that it is not the title of a table of contents.
+func (w *LaTeXWriter) endHeading() {
+	if w.headingBody == nil {
+		return
+	}
+	title := w.out.String()
+	w.out = w.headingBody
+	w.headingBody = nil
+
+	w.pendingHeading = &latexHeading{
+		command:  strings.TrimSuffix(w.getSectionCommand(w.currentHeadingLevel), "{"),
+		title:    title,
+		notes:    w.headingNotes,
+		anchorID: w.currentAnchorID,
+	}
+	w.headingNotes = nil
+}
+
+// writePendingHeading writes the heading held back by endHeading. Footnotes go into the title only,
+// with the plain title as the short form used by the table of contents.
+func (w *LaTeXWriter) writePendingHeading() {
+	heading := w.pendingHeading
+	if heading == nil {
+		return
+	}
+	w.pendingHeading = nil
+
+	if len(heading.notes) > 0 {
+		fmt.Fprintf(w.out, "%s[{%s}]{%s%s}", heading.command, heading.title, heading.title, strings.Join(heading.notes, ""))
+	} else {
+		fmt.Fprintf(w.out, "%s{%s}", heading.command, heading.title)
+	}
+	fmt.Fprintf(w.out, "\n\\label{%s}\n\n", heading.anchorID)
+}
+
 // escapeLaTeX escapes special LaTeX characters with proper ordering to prevent double-escaping
 func (w *LaTeXWriter) escapeLaTeX(text string) string {
 	// Input validation
@@ -271,6 +732,7 @@ func (w *LaTeXWriter) escapeLaTeX(text string) string {
 
 // Result returns the final LaTeX string
 func (w *LaTeXWriter) Result() string {
+	w.writePendingHeading()
 	return w.out.String()
 }
 
@@ -279,12 +741,18 @@ func (w *LaTeXWriter) Reset() {
 	w.out.Reset()
 	w.activeStyle = 0
 	w.linkURL = ""
-	w.inList = false
-	w.inTable = false
-	w.listDepth = 0
-	w.tableColumns = 0
-	w.tableCellCount = 0
+	w.lists = nil
+	w.latexTableState = latexTableState{}
+	w.outerTables = nil
 	w.currentAnchorID = ""
+	w.headingBody = nil
+	w.headingNotes = nil
+	w.pendingHeading = nil
+	w.bodyPending = false
+	w.body = nil
+	w.header = ""
+	w.footer = ""
+	w.usesLastPage = false
 }
 
 // SetOutput sets the output destination (for StreamWriter interface)
@@ -325,6 +793,11 @@ func (w *LaTeXWriterV2) Flush() ([]byte, error) {
 	return []byte(w.Result()), nil
 }
 
+// FlushTo finalizes any pending operations and writes the result to dst
+func (w *LaTeXWriterV2) FlushTo(dst io.Writer) (int64, error) {
+	return writeStringTo(dst, w.Result())
+}
+
 // ContentType returns the MIME type of the output
 func (w *LaTeXWriterV2) ContentType() string {
 	return "text/x-tex"
@@ -0,0 +1,202 @@
+package writers
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kjanat/slimacademy/internal/config"
+	"github.com/kjanat/slimacademy/internal/streaming"
+)
+
+// TestLaTeXWriter_TableSpans tests \multicolumn, \multirow and fixed column widths
+func TestLaTeXWriter_TableSpans(t *testing.T) {
+	writer := NewLaTeXWriter(nil)
+	for _, event := range spanTableEvents() {
+		writer.Handle(event)
+	}
+	result := writer.Result()
+
+	for _, expected := range []string{
+		`\usepackage{multirow}`,
+		`\begin{tabular}{p{120pt}cc}`,
+		`\multicolumn{2}{l}{Merged} & C \\`,
+		`\multirow{2}{*}{Tall} & D & E \\`,
+		` & F & G \\`,
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, result)
+		}
+	}
+}
+
+// TestLaTeXWriter_TableHeaderRules tests booktabs rules around the header row
+func TestLaTeXWriter_TableHeaderRules(t *testing.T) {
+	writer := NewLaTeXWriter(nil)
+	for _, event := range spanTableEvents() {
+		writer.Handle(event)
+	}
+	result := writer.Result()
+
+	for _, expected := range []string{
+		`\usepackage{booktabs}`,
+		"\\toprule\n\\multicolumn{2}{l}{Merged} & C \\\\\n\\midrule\n",
+		"\\bottomrule\n\\end{tabular}",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, result)
+		}
+	}
+
+	cfg := config.DefaultLaTeXConfig()
+	cfg.UseBooktabs = false
+	writer = NewLaTeXWriter(cfg)
+	for _, event := range spanTableEvents() {
+		writer.Handle(event)
+	}
+	result = writer.Result()
+
+	if strings.Contains(result, "booktabs") || strings.Contains(result, `\midrule`) {
+		t.Errorf("Expected no booktabs rules without UseBooktabs:\n%s", result)
+	}
+	if !strings.Contains(result, "\\hline\n\\multicolumn{2}{l}{Merged} & C \\\\\n\\hline\n") {
+		t.Errorf("Expected \\hline rules around the header row:\n%s", result)
+	}
+}
+
+// TestLaTeXWriter_TableCellBlocks tests paragraph columns, paragraph breaks and nested tabulars in cells
+func TestLaTeXWriter_TableCellBlocks(t *testing.T) {
+	writer := NewLaTeXWriter(nil)
+	for _, event := range blockTableEvents() {
+		writer.Handle(event)
+	}
+	result := writer.Result()
+
+	for _, expected := range []string{
+		`\begin{tabular}{p{0.45\linewidth}p{0.45\linewidth}}`,
+		"First\n\\par Second\n\\par \\begin{itemize}",
+		"& \\begin{tabular}{l}\n\\toprule\nInner\n \\\\\n\\bottomrule\n\\end{tabular}\n \\\\",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, result)
+		}
+	}
+	if got := strings.Count(result, `\begin{table}`); got != 1 {
+		t.Errorf("Expected only the outer table to float, got %d table environments:\n%s", got, result)
+	}
+}
+
+// TestLaTeXWriter_NestedLists tests nested enumerate environments with matching labels and start numbers
+func TestLaTeXWriter_NestedLists(t *testing.T) {
+	writer := NewLaTeXWriter(nil)
+	for _, event := range nestedListEvents() {
+		writer.Handle(event)
+	}
+	result := writer.Result()
+
+	for _, expected := range []string{
+		"\\begin{enumerate}\n  \\renewcommand{\\labelenumi}{\\arabic{enumi}.}\n  \\setcounter{enumi}{2}\n",
+		"  \\item Three\n  \\begin{enumerate}\n    \\renewcommand{\\labelenumii}{\\alph{enumii}.}\n    \\item Sub A\n    \\item Sub B\n  \\end{enumerate}\n  \\item Four\n\\end{enumerate}\n",
+		"\\begin{itemize}\n  \\renewcommand{\\labelitemi}{$\\circ$}\n  \\item Bullet\n\\end{itemize}\n",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, result)
+		}
+	}
+}
+
+func TestLaTeXWriter_Breaks(t *testing.T) {
+	writer := NewLaTeXWriter(nil)
+	for _, event := range breakEvents() {
+		writer.Handle(event)
+	}
+	result := writer.Result()
+
+	for _, expected := range []string{
+		"\\usepackage{fancyhdr}\n",
+		"\\fancyhead[C]{Course notes}\n\\fancyfoot[C]{Page \\thepage{}}\n\n\\begin{document}\n\\maketitle\n",
+		"Before rule\n\n\\medskip\\hrule\\medskip\n\n",
+		"Before break\n\n\\newpage\n\n",
+		"New page\n\n\\newpage\n\n",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, result)
+		}
+	}
+	if strings.Count(result, "Course notes") != 1 {
+		t.Errorf("Expected the header text only in the page style:\n%s", result)
+	}
+}
+
+func TestLaTeXWriter_Notes(t *testing.T) {
+	writer := NewLaTeXWriter(nil)
+	for _, event := range noteEvents() {
+		writer.Handle(event)
+	}
+	result := writer.Result()
+
+	for _, expected := range []string{
+		"\\subsection[{Chapter}]{Chapter\\footnote{Revise this}}\n\\label{chapter}\n\n",
+		"Body text\\footnote{Exam <topic>}\n\n",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, result)
+		}
+	}
+
+	cfg := config.DefaultLaTeXConfig()
+	cfg.IncludeNotes = false
+	writer = NewLaTeXWriter(cfg)
+	for _, event := range noteEvents() {
+		writer.Handle(event)
+	}
+	result = writer.Result()
+	if strings.Contains(result, "\\footnote") || !strings.Contains(result, "\\subsection{Chapter}\n") {
+		t.Errorf("Expected notes to be omitted:\n%s", result)
+	}
+}
+
+func TestLaTeXWriter_TableOfContents(t *testing.T) {
+	writer := NewLaTeXWriter(nil)
+	for _, event := range tocEvents() {
+		writer.Handle(event)
+	}
+	result := writer.Result()
+
+	// The placeholder heading becomes the title of the table of contents instead of a section
+	expected := "\\renewcommand{\\contentsname}{Contents}\n\\setcounter{tocdepth}{3}\n\\tableofcontents\n" +
+		"\\label{contents}\n\n\\subsection{Bones}"
+	if !strings.Contains(result, expected) {
+		t.Errorf("Expected %q in output:\n%s", expected, result)
+	}
+	if strings.Contains(result, "\\subsection{Contents}") {
+		t.Errorf("Expected no section for the placeholder heading:\n%s", result)
+	}
+	if strings.Contains(result, "\\item") {
+		t.Errorf("Expected a native table of contents instead of a list:\n%s", result)
+	}
+
+	// An inserted table of contents without a placeholder keeps the default title
+	events := tocEvents()
+	writer = NewLaTeXWriter(nil)
+	for _, event := range append(events[:1:1], events[4:]...) {
+		writer.Handle(event)
+	}
+	if result := writer.Result(); strings.Contains(result, "contentsname") || !strings.Contains(result, "\\tableofcontents\n\n") {
+		t.Errorf("Expected the default table of contents title:\n%s", result)
+	}
+
+	// Footnotes of the placeholder heading follow the table of contents
+	events = tocEvents()
+	withNote := slices.Concat(events[:3:3], []streaming.Event{{Kind: streaming.Note, NoteText: "See the appendix"}}, events[3:])
+	cfg := config.DefaultLaTeXConfig()
+	cfg.IncludeNotes = true
+	writer = NewLaTeXWriter(cfg)
+	for _, event := range withNote {
+		writer.Handle(event)
+	}
+	expected = "\\tableofcontents\n\\label{contents}\n\\footnote{See the appendix}\n\n\\subsection{Bones}"
+	if result := writer.Result(); !strings.Contains(result, expected) {
+		t.Errorf("Expected %q in output:\n%s", expected, result)
+	}
+}
@@ -2,6 +2,7 @@ package writers
 
 import (
 	"fmt"
+	"html"
 	"io"
 	"strings"
 
@@ -30,17 +31,36 @@ type MarkdownWriter struct {
 	out                 *strings.Builder
 	activeStyle         streaming.StyleFlags
 	linkURL             string
-	inList              bool
-	inListItem          bool
-	inTable             bool
 	currentHeadingLevel int
-	listOrdered         bool
-	listItemNumber      int
+	lists               []markdownList   // Open lists, outermost first
+	body                *strings.Builder // Output builder while a running header or footer is discarded
+	footnotes           []string         // Footnote definitions written at the end of the document
 
 	// Table handling
+	markdownTableState
+	outerTables []markdownTableState // States of the tables enclosing a nested table
+}
+
+// markdownList tracks an open list
+type markdownList struct {
+	ordered     bool
+	number      int    // Number of the next item of an ordered list
+	indent      string // Indentation of the item markers
+	markerWidth int    // Width of the open item's marker, which nested lists are indented by
+	lineOpen    bool   // The open item's line has not been terminated yet
+	htmlTag     string // Element name of a list written inside an HTML table
+}
+
+// markdownTableState tracks the table currently being written
+type markdownTableState struct {
+	inTable              bool
 	tableColumns         int
 	currentColumn        int
 	needsHeaderSeparator bool
+	htmlTable            bool   // Table rendered as HTML because GFM cannot express merged cells or block content
+	tableHeaderRow       bool   // Current row holds header cells
+	tableSection         string // Open thead/tbody element of an HTML table
+	cellBlocks           int    // Paragraphs, lists and tables written in the current cell
 }
 
 // NewMarkdownWriter returns a new MarkdownWriter initialized with the provided configuration or a default configuration if nil.
@@ -82,11 +102,11 @@ func (w *MarkdownWriter) Handle(event streaming.Event) {
 	case streaming.EndTable:
 		w.handleEndTable()
 	case streaming.StartTableRow:
-		w.handleStartTableRow()
+		w.handleStartTableRow(event)
 	case streaming.EndTableRow:
 		w.handleEndTableRow()
 	case streaming.StartTableCell:
-		w.handleStartTableCell()
+		w.handleStartTableCell(event)
 	case streaming.EndTableCell:
 		w.handleEndTableCell()
 	case streaming.StartFormatting:
@@ -97,6 +117,53 @@ func (w *MarkdownWriter) Handle(event streaming.Event) {
 		w.handleText(event)
 	case streaming.Image:
 		w.handleImage(event)
+	case streaming.PageBreak, streaming.HorizontalRule:
+		w.handleThematicBreak()
+	case streaming.SectionBreak:
+		if event.SectionNewPage {
+			w.handleThematicBreak()
+		}
+	case streaming.StartHeader, streaming.StartFooter:
+		// Markdown has no pages, so running headers and footers are dropped
+		w.body = w.out
+		w.out = &strings.Builder{}
+	case streaming.EndHeader, streaming.EndFooter:
+		if w.body != nil {
+			w.out = w.body
+			w.body = nil
+		}
+	case streaming.Note:
+		w.handleNote(event)
+	case streaming.TableOfContents:
+		for _, listEvent := range streaming.TOCListEvents(event) {
+			w.Handle(listEvent)
+		}
+	}
+}
+
+// handleNote writes a footnote reference and keeps the note text for the footnote definitions
+func (w *MarkdownWriter) handleNote(event streaming.Event) {
+	if !w.config.IncludeNotes {
+		return
+	}
+	w.footnotes = append(w.footnotes, w.escapeMarkdown(event.NoteText))
+	fmt.Fprintf(w.out, "[^%d]", len(w.footnotes))
+}
+
+// handleThematicBreak writes a horizontal rule for rules and page breaks. Breaks inside list items
+// and GFM tables cannot be expressed and are dropped.
+func (w *MarkdownWriter) handleThematicBreak() {
+	switch {
+	case w.htmlTable:
+		w.out.WriteString("<hr>")
+	case w.inTable || w.listLineOpen():
+		return
+	default:
+		// A rule directly below a line of text would turn it into a setext heading
+		if out := w.out.String(); out != "" && !strings.HasSuffix(out, "\n\n") {
+			w.out.WriteString("\n\n")
+		}
+		w.out.WriteString("---\n\n")
 	}
 }
 
@@ -105,11 +172,24 @@ func (w *MarkdownWriter) handleStartDoc(event streaming.Event) {
 }
 
 func (w *MarkdownWriter) handleEndDoc() {
-	// Document complete - nothing needed
+	if len(w.footnotes) == 0 {
+		return
+	}
+	if out := w.out.String(); !strings.HasSuffix(out, "\n\n") {
+		w.out.WriteString("\n\n")
+	}
+	for i, text := range w.footnotes {
+		// Continuation lines of a footnote are indented to stay part of it
+		fmt.Fprintf(w.out, "[^%d]: %s\n", i+1, strings.ReplaceAll(text, "\n", "\n    "))
+	}
 }
 
 func (w *MarkdownWriter) handleStartParagraph() {
-	if w.inListItem {
+	if w.inTable {
+		w.startCellBlock()
+		return
+	}
+	if w.listLineOpen() {
 		// In list items, add appropriate spacing for paragraph breaks
 		// This allows multiple paragraphs within a single list item
 		w.out.WriteString("\n\n  ") // Two newlines + indentation for sub-paragraphs
@@ -118,14 +198,18 @@ func (w *MarkdownWriter) handleStartParagraph() {
 }
 
 func (w *MarkdownWriter) handleEndParagraph() {
+	if w.inTable {
+		// Blocks inside cells are separated when the next one starts
+		return
+	}
 	w.out.WriteString("\n\n")
 }
 
 func (w *MarkdownWriter) handleStartHeading(event streaming.Event) {
-	if w.inListItem {
+	if w.listLineOpen() {
 		// Close previous list item before starting a heading
 		w.out.WriteString("\n")
-		w.inListItem = false
+		w.lists[len(w.lists)-1].lineOpen = false
 	}
 	w.currentHeadingLevel = event.Level
 	fmt.Fprintf(w.out, "\n%s ", strings.Repeat("#", event.Level))
@@ -136,34 +220,92 @@ func (w *MarkdownWriter) handleEndHeading() {
 	w.currentHeadingLevel = 0
 }
 
+// startCellBlock separates a paragraph, list or nested table from the previous block in the same cell
+func (w *MarkdownWriter) startCellBlock() {
+	if w.cellBlocks > 0 {
+		w.out.WriteString("<br>")
+	}
+	w.cellBlocks++
+}
+
+// listLineOpen reports whether the innermost list has an item line that is not terminated yet
+func (w *MarkdownWriter) listLineOpen() bool {
+	return len(w.lists) > 0 && w.lists[len(w.lists)-1].lineOpen
+}
+
+// handleStartList opens a list. Nested lists are indented by the width of the enclosing item's marker.
 func (w *MarkdownWriter) handleStartList(event streaming.Event) {
-	w.inList = true
-	w.listOrdered = event.ListOrdered
-	w.listItemNumber = 1
+	if w.htmlTable {
+		w.startCellBlock()
+		tag, attrs := htmlListTag(event)
+		fmt.Fprintf(w.out, "<%s%s>\n", tag, attrs)
+		w.lists = append(w.lists, markdownList{htmlTag: tag})
+		return
+	}
+
+	indent := ""
+	if n := len(w.lists); n > 0 {
+		parent := &w.lists[n-1]
+		indent = parent.indent + strings.Repeat(" ", parent.markerWidth)
+		if parent.lineOpen {
+			w.out.WriteString("\n")
+			parent.lineOpen = false
+		}
+	}
+	w.lists = append(w.lists, markdownList{
+		ordered: event.ListOrdered || event.ListGlyph.Ordered(),
+		number:  max(1, event.ListStart),
+		indent:  indent,
+	})
 	// No output needed - individual items will handle formatting
 }
 
 func (w *MarkdownWriter) handleEndList() {
-	if w.inListItem {
+	n := len(w.lists)
+	if n == 0 {
+		return
+	}
+	list := w.lists[n-1]
+	w.lists = w.lists[:n-1]
+
+	if list.htmlTag != "" {
+		fmt.Fprintf(w.out, "</%s>\n", list.htmlTag)
+		return
+	}
+	if list.lineOpen {
 		// Close the last list item
 		w.out.WriteString("\n")
-		w.inListItem = false
 	}
-	w.inList = false
-	w.listOrdered = false
-	w.listItemNumber = 1
-	w.out.WriteString("\n")
+	// Only top-level lists are followed by a blank line
+	if len(w.lists) == 0 {
+		w.out.WriteString("\n")
+	}
 }
 
 func (w *MarkdownWriter) handleStartListItem() {
-	// Start a new list item - write marker without any active formatting
-	if w.listOrdered {
-		fmt.Fprintf(w.out, "%d. ", w.listItemNumber)
-		w.listItemNumber++
-	} else {
+	if w.htmlTable {
+		w.out.WriteString("<li>")
+		return
+	}
+	if len(w.lists) == 0 {
+		// Items outside a list are written as loose bullets
 		w.out.WriteString("- ")
+		return
 	}
-	w.inListItem = true
+	list := &w.lists[len(w.lists)-1]
+	if list.lineOpen {
+		w.out.WriteString("\n")
+	}
+
+	// Start a new list item - write marker without any active formatting
+	marker := "- "
+	if list.ordered {
+		marker = fmt.Sprintf("%d. ", list.number)
+		list.number++
+	}
+	w.out.WriteString(list.indent + marker)
+	list.markerWidth = len(marker)
+	list.lineOpen = true
 
 	// Now apply any active formatting for the text content only
 	if w.activeStyle != 0 {
@@ -172,64 +314,158 @@ func (w *MarkdownWriter) handleStartListItem() {
 }
 
 func (w *MarkdownWriter) handleEndListItem() {
-	if w.inListItem {
+	if w.htmlTable {
+		w.out.WriteString("</li>\n")
+		return
+	}
+	if w.listLineOpen() {
 		w.out.WriteString("\n")
-		w.inListItem = false
+		w.lists[len(w.lists)-1].lineOpen = false
 	}
 }
 
 func (w *MarkdownWriter) handleStartTable(event streaming.Event) {
-	w.inTable = true
-	w.tableColumns = event.TableColumns
+	nested := w.inTable
+	if nested {
+		w.startCellBlock()
+		w.outerTables = append(w.outerTables, w.markdownTableState)
+	}
+
+	w.markdownTableState = markdownTableState{
+		inTable:              true,
+		tableColumns:         event.TableColumns,
+		needsHeaderSeparator: true,
+		// GFM cells hold a single line of inline content, and nested tables need an HTML parent
+		htmlTable: event.TableHasSpans || event.TableHasBlocks || nested,
+	}
 	if w.tableColumns <= 0 {
 		w.tableColumns = 2 // Default fallback for invalid table structure
 	}
-	w.currentColumn = 0
-	w.needsHeaderSeparator = true
-	w.out.WriteString("\n")
+
+	switch {
+	case nested:
+		w.out.WriteString("<table>\n")
+	case w.htmlTable:
+		w.out.WriteString("\n<table>\n")
+	default:
+		w.out.WriteString("\n")
+	}
 }
 
 func (w *MarkdownWriter) handleEndTable() {
-	w.inTable = false
+	if w.htmlTable {
+		w.openTableSection("")
+		w.out.WriteString("</table>\n")
+	}
+
+	if n := len(w.outerTables); n > 0 {
+		w.markdownTableState = w.outerTables[n-1]
+		w.outerTables = w.outerTables[:n-1]
+		return
+	}
+	w.markdownTableState = markdownTableState{}
 	w.out.WriteString("\n")
 }
 
-func (w *MarkdownWriter) handleStartTableRow() {
+func (w *MarkdownWriter) handleStartTableRow(event streaming.Event) {
+	w.tableHeaderRow = event.TableHeader
+	if w.htmlTable {
+		// Header rows after the body has started stay in the body
+		if event.TableHeader && w.tableSection != "tbody" {
+			w.openTableSection("thead")
+		} else if !event.TableHeader {
+			w.openTableSection("tbody")
+		}
+		w.out.WriteString("<tr>\n")
+		return
+	}
+	// GFM tables require a header row, so tables without one get an empty header
+	if w.needsHeaderSeparator && !event.TableHeader {
+		w.out.WriteString("|")
+		for i := 0; i < w.tableColumns; i++ {
+			w.out.WriteString("   |")
+		}
+		w.out.WriteString("\n")
+		w.writeHeaderSeparator()
+	}
 	w.out.WriteString("|")
 }
 
+// openTableSection closes the open thead/tbody element of an HTML table and opens section unless it is empty
+func (w *MarkdownWriter) openTableSection(section string) {
+	if w.tableSection == section {
+		return
+	}
+	if w.tableSection != "" {
+		fmt.Fprintf(w.out, "</%s>\n", w.tableSection)
+	}
+	if section != "" {
+		fmt.Fprintf(w.out, "<%s>\n", section)
+	}
+	w.tableSection = section
+}
+
+// writeHeaderSeparator writes the GFM delimiter row below the header row
+func (w *MarkdownWriter) writeHeaderSeparator() {
+	w.out.WriteString("|")
+	for i := 0; i < w.tableColumns; i++ {
+		w.out.WriteString(" --- |")
+	}
+	w.out.WriteString("\n")
+	w.needsHeaderSeparator = false
+}
+
 func (w *MarkdownWriter) handleEndTableRow() {
+	if w.htmlTable {
+		w.out.WriteString("</tr>\n")
+		w.tableHeaderRow = false
+		return
+	}
 	w.out.WriteString("\n")
-	// Add header separator after first row
+	// Add header separator after the header row
 	if w.needsHeaderSeparator {
-		for i := 0; i < w.tableColumns; i++ {
-			if i > 0 {
-				w.out.WriteString(" | ")
-			}
-			w.out.WriteString("---")
-		}
-		w.out.WriteString(" |\n")
-		w.needsHeaderSeparator = false
+		w.writeHeaderSeparator()
 	}
+	w.tableHeaderRow = false
 	w.currentColumn = 0 // Reset for next row
 }
 
-func (w *MarkdownWriter) handleStartTableCell() {
-	// Start table cell with proper spacing
-	if w.currentColumn > 0 {
-		w.out.WriteString(" | ")
+func (w *MarkdownWriter) handleStartTableCell(event streaming.Event) {
+	w.cellBlocks = 0
+	if w.htmlTable {
+		fmt.Fprintf(w.out, "<%s%s>", w.htmlCellTag(), cellSpanAttributes(event))
+		return
 	}
+	// Start table cell with proper spacing
+	w.out.WriteString(" ")
 	w.currentColumn++
 }
 
 func (w *MarkdownWriter) handleEndTableCell() {
+	if w.htmlTable {
+		fmt.Fprintf(w.out, "</%s>\n", w.htmlCellTag())
+		return
+	}
 	w.out.WriteString(" |")
 }
 
+// htmlCellTag returns the HTML element name for cells of the current row
+func (w *MarkdownWriter) htmlCellTag() string {
+	if w.tableHeaderRow {
+		return "th"
+	}
+	return "td"
+}
+
 func (w *MarkdownWriter) handleStartFormatting(event streaming.Event) {
+	if w.htmlTable {
+		w.openHTMLTag(event.Style, event.LinkURL)
+		w.activeStyle |= event.Style
+		return
+	}
 	// Store the style but don't open markers yet if we're starting a list item
 	// The markers will be opened after the list marker is written in the Text event
-	if !(w.inList && !w.inListItem) {
+	if !(len(w.lists) > 0 && !w.listLineOpen()) {
 		w.openMarker(event.Style)
 	}
 	w.activeStyle |= event.Style
@@ -239,6 +475,11 @@ func (w *MarkdownWriter) handleStartFormatting(event streaming.Event) {
 }
 
 func (w *MarkdownWriter) handleEndFormatting(event streaming.Event) {
+	if w.htmlTable {
+		w.closeHTMLTag(event.Style)
+		w.activeStyle &^= event.Style
+		return
+	}
 	// Only close markers that were actually opened
 	if w.activeStyle&event.Style != 0 {
 		w.closeMarker(event.Style)
@@ -251,6 +492,10 @@ func (w *MarkdownWriter) handleEndFormatting(event streaming.Event) {
 
 func (w *MarkdownWriter) handleText(event streaming.Event) {
 	text := event.TextContent
+	if w.htmlTable {
+		w.out.WriteString(strings.ReplaceAll(html.EscapeString(text), "\n", "<br>"))
+		return
+	}
 	if w.inTable {
 		// In markdown tables, replace newlines with spaces or preserve as single line
 		text = strings.ReplaceAll(text, "\n", " ")
@@ -259,6 +504,10 @@ func (w *MarkdownWriter) handleText(event streaming.Event) {
 }
 
 func (w *MarkdownWriter) handleImage(event streaming.Event) {
+	if w.htmlTable {
+		fmt.Fprintf(w.out, "<img src=\"%s\" alt=\"%s\">", html.EscapeString(event.ImageURL), html.EscapeString(event.ImageAlt))
+		return
+	}
 	fmt.Fprintf(w.out, "![%s](%s)", w.escapeMarkdown(event.ImageAlt), w.escapeMarkdownURL(event.ImageURL))
 }
 
@@ -334,6 +583,44 @@ func (w *MarkdownWriter) closeMarker(style streaming.StyleFlags) {
 	}
 }
 
+// openHTMLTag opens inline HTML tags for a style inside an HTML table
+func (w *MarkdownWriter) openHTMLTag(style streaming.StyleFlags, linkURL string) {
+	for _, tag := range markdownHTMLTags {
+		if style&tag.style != 0 {
+			w.out.WriteString("<" + tag.name + ">")
+		}
+	}
+	if style&streaming.Link != 0 {
+		fmt.Fprintf(w.out, "<a href=\"%s\">", html.EscapeString(linkURL))
+	}
+}
+
+// closeHTMLTag closes inline HTML tags for a style in reverse order
+func (w *MarkdownWriter) closeHTMLTag(style streaming.StyleFlags) {
+	if style&streaming.Link != 0 {
+		w.out.WriteString("</a>")
+	}
+	for i := len(markdownHTMLTags) - 1; i >= 0; i-- {
+		if style&markdownHTMLTags[i].style != 0 {
+			w.out.WriteString("</" + markdownHTMLTags[i].name + ">")
+		}
+	}
+}
+
+// markdownHTMLTags maps styles to the inline HTML tags used inside HTML tables
+var markdownHTMLTags = []struct {
+	style streaming.StyleFlags
+	name  string
+}{
+	{streaming.Bold, "strong"},
+	{streaming.Italic, "em"},
+	{streaming.Underline, "u"},
+	{streaming.Strike, "del"},
+	{streaming.Highlight, "mark"},
+	{streaming.Sub, "sub"},
+	{streaming.Sup, "sup"},
+}
+
 // safeWrite writes content with zero-width spacing if needed to prevent marker conflicts
 func (w *MarkdownWriter) safeWrite(content string) {
 	if w.needsSpacer(content) {
@@ -425,9 +712,11 @@ func (w *MarkdownWriter) Reset() {
 	w.out.Reset()
 	w.activeStyle = 0
 	w.linkURL = ""
-	w.inList = false
-	w.inListItem = false
-	w.inTable = false
+	w.lists = nil
+	w.markdownTableState = markdownTableState{}
+	w.outerTables = nil
+	w.body = nil
+	w.footnotes = nil
 }
 
 // SetOutput sets the output destination (for StreamWriter interface)
@@ -468,6 +757,11 @@ func (w *MarkdownWriterV2) Flush() ([]byte, error) {
 	return []byte(w.Result()), nil
 }
 
+// FlushTo finalizes any pending operations and writes the result to dst
+func (w *MarkdownWriterV2) FlushTo(dst io.Writer) (int64, error) {
+	return writeStringTo(dst, w.Result())
+}
+
 // ContentType returns the MIME type of the output
 func (w *MarkdownWriterV2) ContentType() string {
 	return "text/markdown"
@@ -1,8 +1,10 @@
 package writers
 
 import (
+	"strings"
 	"testing"
 
+	"github.com/kjanat/slimacademy/internal/config"
 	"github.com/kjanat/slimacademy/internal/streaming"
 )
 
@@ -232,3 +234,150 @@ func findSubstring(text, substr string) int {
 	}
 	return -1
 }
+
+// TestMarkdownWriter_TableSpansFallback tests that tables with merged cells fall back to HTML
+func TestMarkdownWriter_TableSpansFallback(t *testing.T) {
+	writer := NewMarkdownWriter(nil)
+	for _, event := range spanTableEvents() {
+		writer.Handle(event)
+	}
+	result := writer.Result()
+
+	for _, expected := range []string{"<table>", "<thead>", `<th colspan="2">Merged</th>`, "</thead>\n<tbody>", `<td rowspan="2">Tall</td>`, "</table>"} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, result)
+		}
+	}
+	if strings.Contains(result, "---") {
+		t.Error("Expected no GFM table separator for merged cells")
+	}
+}
+
+// TestMarkdownWriter_TableHeaderRows tests the GFM header row for tables with and without header rows
+func TestMarkdownWriter_TableHeaderRows(t *testing.T) {
+	tableEvents := func(header bool) []streaming.Event {
+		return []streaming.Event{
+			{Kind: streaming.StartTable, TableColumns: 2, TableRows: 2},
+			{Kind: streaming.StartTableRow, TableHeader: header},
+			{Kind: streaming.StartTableCell},
+			{Kind: streaming.Text, TextContent: "A"},
+			{Kind: streaming.EndTableCell},
+			{Kind: streaming.StartTableCell},
+			{Kind: streaming.Text, TextContent: "B"},
+			{Kind: streaming.EndTableCell},
+			{Kind: streaming.EndTableRow},
+			{Kind: streaming.StartTableRow},
+			{Kind: streaming.StartTableCell},
+			{Kind: streaming.Text, TextContent: "C"},
+			{Kind: streaming.EndTableCell},
+			{Kind: streaming.StartTableCell},
+			{Kind: streaming.Text, TextContent: "D"},
+			{Kind: streaming.EndTableCell},
+			{Kind: streaming.EndTableRow},
+			{Kind: streaming.EndTable},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		header   bool
+		expected string
+	}{
+		{"header row", true, "| A | B |\n| --- | --- |\n| C | D |\n"},
+		{"no header row", false, "|   |   |\n| --- | --- |\n| A | B |\n| C | D |\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := NewMarkdownWriter(nil)
+			for _, event := range tableEvents(tt.header) {
+				writer.Handle(event)
+			}
+			if result := writer.Result(); !strings.Contains(result, tt.expected) {
+				t.Errorf("Expected %q in output:\n%s", tt.expected, result)
+			}
+		})
+	}
+}
+
+// TestMarkdownWriter_TableCellBlocks tests that block content in cells falls back to an HTML table
+func TestMarkdownWriter_TableCellBlocks(t *testing.T) {
+	writer := NewMarkdownWriter(nil)
+	for _, event := range blockTableEvents() {
+		writer.Handle(event)
+	}
+	result := writer.Result()
+
+	for _, expected := range []string{
+		"<td>First<br>Second<br><ul>\n<li>Item</li>\n</ul>\n</td>",
+		"<td><table>\n<tbody>\n<tr>\n<td>Inner</td>\n</tr>\n</tbody>\n</table>\n</td>",
+	} {
+		if !strings.Contains(result, expected) {
+			t.Errorf("Expected %q in output:\n%s", expected, result)
+		}
+	}
+	if strings.Contains(result, "---") {
+		t.Error("Expected no GFM table separator for block content")
+	}
+}
+
+// TestMarkdownWriter_NestedLists tests start numbers and indentation of nested lists
+func TestMarkdownWriter_NestedLists(t *testing.T) {
+	writer := NewMarkdownWriter(nil)
+	for _, event := range nestedListEvents() {
+		writer.Handle(event)
+	}
+	result := writer.Result()
+
+	expected := "3. Three\n   1. Sub A\n   2. Sub B\n4. Four\n\n- Bullet\n\n"
+	if !strings.Contains(result, expected) {
+		t.Errorf("Expected %q in output:\n%s", expected, result)
+	}
+}
+
+func TestMarkdownWriter_Breaks(t *testing.T) {
+	writer := NewMarkdownWriter(nil)
+	for _, event := range breakEvents() {
+		writer.Handle(event)
+	}
+	result := writer.Result()
+
+	expected := "# Breaks\n\nBefore rule\n\n---\n\nBefore break\n\n---\n\nNew page\n\n---\n\nNew section\n\n"
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestMarkdownWriter_Notes(t *testing.T) {
+	writer := NewMarkdownWriter(nil)
+	for _, event := range noteEvents() {
+		writer.Handle(event)
+	}
+	result := writer.Result()
+
+	expected := "# Notes\n\n\n## Chapter[^1]\n\nBody text[^2]\n\n[^1]: Revise this\n[^2]: Exam <topic>\n"
+	if result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+
+	cfg := config.DefaultMarkdownConfig()
+	cfg.IncludeNotes = false
+	writer = NewMarkdownWriter(cfg)
+	for _, event := range noteEvents() {
+		writer.Handle(event)
+	}
+	if result := writer.Result(); strings.Contains(result, "[^") {
+		t.Errorf("Expected notes to be omitted, got %q", result)
+	}
+}
+
+func TestMarkdownWriter_TableOfContents(t *testing.T) {
+	writer := NewMarkdownWriter(nil)
+	for _, event := range tocEvents() {
+		writer.Handle(event)
+	}
+	expected := "# Anatomy\n\n\n## Contents\n\n- [Bones](#bones)\n  - [Skull](#skull)\n\n\n## Bones\n\n\n### Skull\n\n"
+	if result := writer.Result(); result != expected {
+		t.Errorf("Expected %q, got %q", expected, result)
+	}
+}
@@ -122,6 +122,50 @@ func (w *PlainTextWriter) Handle(event streaming.Event) {
 		w.out.WriteString(":")
 		w.out.WriteString(event.ImageAlt)
 		w.out.WriteString("]")
+
+	case streaming.PageBreak:
+		w.out.WriteString("[PAGE_BREAK]")
+
+	case streaming.HorizontalRule:
+		w.out.WriteString("[HR]")
+
+	case streaming.SectionBreak:
+		w.out.WriteString("[SECTION_BREAK")
+		if event.SectionNewPage {
+			w.out.WriteString(":NEW_PAGE")
+		}
+		w.out.WriteString("]\n")
+
+	case streaming.AutoText:
+		w.out.WriteString("[AUTO_TEXT:")
+		w.out.WriteString(event.AutoTextType)
+		w.out.WriteString("]")
+
+	case streaming.StartHeader:
+		w.out.WriteString("[HEADER_START]\n")
+
+	case streaming.EndHeader:
+		w.out.WriteString("[HEADER_END]\n")
+
+	case streaming.StartFooter:
+		w.out.WriteString("[FOOTER_START]\n")
+
+	case streaming.EndFooter:
+		w.out.WriteString("[FOOTER_END]\n")
+
+	case streaming.Note:
+		w.out.WriteString("[NOTE:")
+		w.out.WriteString(strconv.FormatInt(event.NoteID, 10))
+		w.out.WriteString(":")
+		w.out.WriteString(w.escapeWhitespace(event.NoteText))
+		w.out.WriteString("]")
+
+	case streaming.TableOfContents:
+		w.out.WriteString("[TOC_START]")
+		for _, listEvent := range streaming.TOCListEvents(event) {
+			w.Handle(listEvent)
+		}
+		w.out.WriteString("[TOC_END]\n")
 	}
 }
 
@@ -225,6 +269,11 @@ func (w *PlainTextWriterV2) Flush() ([]byte, error) {
 	return []byte(w.Result()), nil
 }
 
+// FlushTo finalizes any pending operations and writes the result to dst
+func (w *PlainTextWriterV2) FlushTo(dst io.Writer) (int64, error) {
+	return writeStringTo(dst, w.Result())
+}
+
 // ContentType returns the MIME type of the output
 func (w *PlainTextWriterV2) ContentType() string {
 	return "text/plain"
@@ -6,7 +6,9 @@ package writers
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
+	"strings"
 	"sync"
 	"time"
 
@@ -30,6 +32,35 @@ type WriterV2 interface {
 	IsText() bool
 }
 
+// StreamingWriter is an optional extension of WriterV2 for writers that can write their
+// output directly to an io.Writer instead of returning it as a byte slice from Flush.
+// FlushTo is not memory-bounded: writers still render the whole document in memory and
+// only avoid the extra copy Flush makes
+type StreamingWriter interface {
+	WriterV2
+	// FlushTo finalizes any pending operations and writes the result to dst
+	FlushTo(dst io.Writer) (int64, error)
+}
+
+// ContextWriter is an optional extension of WriterV2 for writers that do I/O while handling
+// events, such as downloading images, which should stop when the conversion is cancelled
+type ContextWriter interface {
+	WriterV2
+	// SetContext sets the context of the conversion
+	SetContext(ctx context.Context)
+}
+
+// flushChunkSize is the size of the chunks used to copy rendered output to a destination
+const flushChunkSize = 32 * 1024
+
+// writeStringTo writes s to dst in fixed-size chunks, avoiding a full []byte copy of s.
+// The caller still holds all of s, typically the writer's rendered output, in memory
+func writeStringTo(dst io.Writer, s string) (int64, error) {
+	// Hide strings.Reader's WriterTo so io.CopyBuffer copies through the chunk buffer
+	reader := struct{ io.Reader }{strings.NewReader(s)}
+	return io.CopyBuffer(dst, reader, make([]byte, min(len(s), flushChunkSize)+1))
+}
+
 // WriterStats contains processing statistics for observability
 type WriterStats struct {
 	EventsProcessed  int
@@ -117,18 +148,42 @@ func ListFormats() []string {
 	return formats
 }
 
-// MultiWriter processes events through multiple writers concurrently with error handling
+// eventBufferSize bounds the number of events queued for each writer before the producer blocks
+const eventBufferSize = 256
+
+// MultiWriter processes events through multiple writers concurrently with error handling.
+// Each writer runs in its own goroutine fed by a bounded channel, so a slow writer applies
+// backpressure to the event stream instead of buffering the whole document.
 type MultiWriter struct {
 	writers map[string]WriterV2
+	formats []string // Requested format order, used for deterministic results
+	timings map[string]*writerTiming
 	ctx     context.Context
 	cancel  context.CancelFunc
-	errCh   chan error
+}
+
+// writerTiming tracks the time a single writer spends handling events and flushing
+type writerTiming struct {
+	start time.Time
+	end   time.Time
+	busy  time.Duration
+}
+
+// record adds one unit of work that started at start to the timing
+func (t *writerTiming) record(start time.Time) {
+	if t.start.IsZero() {
+		t.start = start
+	}
+	t.end = time.Now()
+	t.busy += t.end.Sub(start)
 }
 
 // NewMultiWriter creates a MultiWriter that manages multiple WriterV2 instances for the specified formats, using the provided context for lifecycle and cancellation control.
 // Returns an error if any requested format is not registered.
 func NewMultiWriter(ctx context.Context, formats []string, cfg *config.Config) (*MultiWriter, error) {
 	writers := make(map[string]WriterV2)
+	ordered := make([]string, 0, len(formats))
+	timings := make(map[string]*writerTiming)
 
 	// Create writer instances for each format
 	for _, format := range formats {
@@ -136,22 +191,36 @@ func NewMultiWriter(ctx context.Context, formats []string, cfg *config.Config) (
 		if !exists {
 			return nil, fmt.Errorf("unsupported format: %s", format)
 		}
+		if _, duplicate := writers[format]; duplicate {
+			continue
+		}
 		writers[format] = factory(cfg)
+		ordered = append(ordered, format)
+		timings[format] = &writerTiming{}
 	}
 
 	writerCtx, cancel := context.WithCancel(ctx)
+	for _, writer := range writers {
+		if contextWriter, ok := writer.(ContextWriter); ok {
+			contextWriter.SetContext(writerCtx)
+		}
+	}
 
 	return &MultiWriter{
 		writers: writers,
+		formats: ordered,
+		timings: timings,
 		ctx:     writerCtx,
 		cancel:  cancel,
-		errCh:   make(chan error, len(writers)),
 	}, nil
 }
 
-// ProcessEvents drives all writers through the event stream with error propagation
+// ProcessEvents drives all writers through the event stream with error propagation.
+// The first writer error cancels the remaining writers and is returned; cancellation of the
+// parent context stops processing without an error.
 func (mw *MultiWriter) ProcessEvents(eventStream func(yield func(streaming.Event) bool)) error {
-	defer mw.cancel()
+	ctx, cancel := context.WithCancel(mw.ctx)
+	defer cancel()
 
 	// Get logger from context if available
 	logger := slog.Default()
@@ -159,36 +228,49 @@ func (mw *MultiWriter) ProcessEvents(eventStream func(yield func(streaming.Event
 		logger = ctxLogger
 	}
 
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	// Start one goroutine per writer
+	channels := make([]chan streaming.Event, len(mw.formats))
+	for i, format := range mw.formats {
+		channels[i] = make(chan streaming.Event, eventBufferSize)
+		wg.Add(1)
+		go func(events <-chan streaming.Event) {
+			defer wg.Done()
+			if err := mw.runWriter(ctx, format, events); err != nil {
+				fail(err)
+			}
+		}(channels[i])
+	}
+
 	startTime := time.Now()
 	eventCount := 0
 
-	// Process events through all writers
+	// Fan each event out to all writers
 	eventStream(func(event streaming.Event) bool {
 		eventCount++
 
 		// Check if context was cancelled
-		select {
-		case <-mw.ctx.Done():
+		if ctx.Err() != nil {
 			logger.Debug("Event processing cancelled", "events_processed", eventCount)
 			return false // Stop iteration
-		default:
 		}
 
-		// Process event through all writers
-		for format, writer := range mw.writers {
-			if err := writer.Handle(event); err != nil {
-				logger.Error("Writer failed processing event",
-					"format", format,
-					"event_kind", event.Kind.String(),
-					"error", err,
-					"events_processed", eventCount)
-
-				// Send error and cancel all operations
-				select {
-				case mw.errCh <- fmt.Errorf("writer %s failed: %w", format, err):
-				default:
-				}
-				mw.cancel()
+		for _, events := range channels {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				logger.Debug("Event processing cancelled", "events_processed", eventCount)
 				return false // Stop iteration
 			}
 		}
@@ -203,64 +285,185 @@ func (mw *MultiWriter) ProcessEvents(eventStream func(yield func(streaming.Event
 		return true // Continue iteration
 	})
 
+	for _, events := range channels {
+		close(events)
+	}
+	wg.Wait()
+
 	processingTime := time.Since(startTime)
 	logger.Info("Event processing completed",
 		"total_events", eventCount,
 		"processing_time_ms", processingTime.Milliseconds(),
 		"formats", len(mw.writers))
 
-	// Check for errors
-	select {
-	case err := <-mw.errCh:
-		return err
-	default:
-	}
+	return firstErr
+}
+
+// runWriter feeds events from the channel to a single writer until the channel closes or ctx is cancelled
+func (mw *MultiWriter) runWriter(ctx context.Context, format string, events <-chan streaming.Event) error {
+	writer := mw.writers[format]
+	timing := mw.timings[format]
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
 
-	return nil
+			start := time.Now()
+			err := writer.Handle(event)
+			timing.record(start)
+			if err != nil {
+				slog.Error("Writer failed processing event",
+					"format", format,
+					"event_kind", event.Kind.String(),
+					"error", err)
+				return fmt.Errorf("writer %s failed: %w", format, err)
+			}
+		}
+	}
 }
 
 // OutputResult contains the result of a writer operation
 type OutputResult struct {
 	Format      string
-	Data        []byte
+	Data        []byte // Nil when the output was written to a destination by FlushAllTo
+	Size        int64
 	ContentType string
 	IsText      bool
 	Extension   string
 }
 
-// FlushAll finalizes all writers and returns their results
+// FlushAll finalizes all writers concurrently and returns their results in the requested format order
 func (mw *MultiWriter) FlushAll() ([]OutputResult, error) {
-	results := make([]OutputResult, 0, len(mw.writers))
+	results := make([]OutputResult, len(mw.formats))
+	errs := make([]error, len(mw.formats))
+
+	var wg sync.WaitGroup
+	for i, format := range mw.formats {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = mw.flushWriter(format)
+		}()
+	}
+	wg.Wait()
 
-	for format, writer := range mw.writers {
-		data, err := writer.Flush()
+	for _, err := range errs {
 		if err != nil {
-			return nil, fmt.Errorf("flush failed for %s: %w", format, err)
+			return nil, err
 		}
+	}
+
+	return results, nil
+}
+
+// flushWriter finalizes a single writer and builds its result
+func (mw *MultiWriter) flushWriter(format string) (OutputResult, error) {
+	writer := mw.writers[format]
+
+	start := time.Now()
+	data, err := writer.Flush()
+	mw.timings[format].record(start)
+	if err != nil {
+		return OutputResult{}, fmt.Errorf("flush failed for %s: %w", format, err)
+	}
+
+	metadata, exists := GetMetadata(format)
+	if !exists {
+		return OutputResult{}, fmt.Errorf("metadata not found for format: %s", format)
+	}
+
+	return OutputResult{
+		Format:      format,
+		Data:        data,
+		Size:        int64(len(data)),
+		ContentType: writer.ContentType(),
+		IsText:      writer.IsText(),
+		Extension:   metadata.Extension,
+	}, nil
+}
+
+// OutputOpener returns the destination for a writer's output. The result describes the
+// output about to be written and has no Data.
+type OutputOpener func(result OutputResult) (io.Writer, error)
+
+// FlushAllTo finalizes all writers one at a time in the requested format order, writing each
+// output to the destination returned by open. Writers implementing StreamingWriter skip the
+// byte slice Flush returns, but still render their whole output in memory first. The returned
+// results carry sizes but no Data.
+func (mw *MultiWriter) FlushAllTo(open OutputOpener) ([]OutputResult, error) {
+	results := make([]OutputResult, 0, len(mw.formats))
+
+	for _, format := range mw.formats {
+		writer := mw.writers[format]
 
 		metadata, exists := GetMetadata(format)
 		if !exists {
 			return nil, fmt.Errorf("metadata not found for format: %s", format)
 		}
 
-		results = append(results, OutputResult{
+		result := OutputResult{
 			Format:      format,
-			Data:        data,
 			ContentType: writer.ContentType(),
 			IsText:      writer.IsText(),
 			Extension:   metadata.Extension,
-		})
+		}
+
+		start := time.Now()
+		size, err := mw.flushWriterTo(writer, result, open)
+		mw.timings[format].record(start)
+		if err != nil {
+			return nil, fmt.Errorf("flush failed for %s: %w", format, err)
+		}
+
+		result.Size = size
+		results = append(results, result)
 	}
 
 	return results, nil
 }
 
-// GetStats returns combined statistics from all writers
+// flushWriterTo writes a single writer's output to the destination returned by open
+func (mw *MultiWriter) flushWriterTo(writer WriterV2, result OutputResult, open OutputOpener) (int64, error) {
+	if streamingWriter, ok := writer.(StreamingWriter); ok {
+		dst, err := open(result)
+		if err != nil {
+			return 0, err
+		}
+		return streamingWriter.FlushTo(dst)
+	}
+
+	data, err := writer.Flush()
+	if err != nil {
+		return 0, err
+	}
+
+	dst, err := open(result)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := dst.Write(data)
+	return int64(n), err
+}
+
+// GetStats returns combined statistics from all writers, including the time each writer spent
+// handling events and flushing
 func (mw *MultiWriter) GetStats() map[string]WriterStats {
 	stats := make(map[string]WriterStats)
 
 	for format, writer := range mw.writers {
-		stats[format] = writer.Stats()
+		stat := writer.Stats()
+		if timing := mw.timings[format]; !timing.start.IsZero() {
+			stat.ProcessingTimeMs = timing.busy.Milliseconds()
+			stat.StartTime = timing.start.Unix()
+			stat.EndTime = timing.end.Unix()
+		}
+		stats[format] = stat
 	}
 
 	return stats
@@ -1,12 +1,15 @@
 package writers
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"strings"
 	"sync"
 	"testing"
 	"time"
+	"unique"
 
 	"github.com/kjanat/slimacademy/internal/config"
 	"github.com/kjanat/slimacademy/internal/streaming"
@@ -583,6 +586,210 @@ func TestMultiWriter_Close(t *testing.T) {
 	}
 }
 
+// barrierWriter blocks its first event until every writer sharing the barrier has started
+type barrierWriter struct {
+	*MockWriter
+	started *sync.WaitGroup
+	once    sync.Once
+}
+
+func (w *barrierWriter) Handle(event streaming.Event) error {
+	w.once.Do(func() {
+		w.started.Done()
+		w.started.Wait()
+	})
+	return w.MockWriter.Handle(event)
+}
+
+func TestMultiWriter_ProcessEvents_Concurrent(t *testing.T) {
+	testRegistry := NewWriterRegistry()
+	originalRegistry := registry
+	registry = testRegistry
+	defer func() { registry = originalRegistry }()
+
+	// Each writer waits for the other inside Handle, which deadlocks unless they run concurrently
+	var started sync.WaitGroup
+	started.Add(2)
+	factory := func(cfg *config.Config) WriterV2 {
+		return &barrierWriter{MockWriter: NewMockWriter(), started: &started}
+	}
+	Register("left", factory, WriterMetadata{Name: "Left Writer"})
+	Register("right", factory, WriterMetadata{Name: "Right Writer"})
+
+	multiWriter, err := NewMultiWriter(context.Background(), []string{"left", "right"}, &config.Config{})
+	if err != nil {
+		t.Fatalf("Failed to create MultiWriter: %v", err)
+	}
+	defer multiWriter.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- multiWriter.ProcessEvents(func(yield func(streaming.Event) bool) {
+			yield(streaming.Event{Kind: streaming.StartDoc, Title: "Test Document"})
+			yield(streaming.Event{Kind: streaming.EndDoc})
+		})
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("ProcessEvents should not return error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Writers did not run concurrently")
+	}
+
+	for format, stat := range multiWriter.GetStats() {
+		if stat.StartTime == 0 || stat.EndTime < stat.StartTime {
+			t.Errorf("Writer %s should record timing, got start=%d end=%d", format, stat.StartTime, stat.EndTime)
+		}
+	}
+}
+
+func TestMultiWriter_ProcessEvents_ErrorCancelsOthers(t *testing.T) {
+	testRegistry := NewWriterRegistry()
+	originalRegistry := registry
+	registry = testRegistry
+	defer func() { registry = originalRegistry }()
+
+	Register("good", func(cfg *config.Config) WriterV2 { return NewMockWriter() }, WriterMetadata{Name: "Good Writer"})
+	Register("bad", func(cfg *config.Config) WriterV2 {
+		mock := NewMockWriter()
+		mock.SetError(true, 0)
+		return mock
+	}, WriterMetadata{Name: "Bad Writer"})
+
+	multiWriter, err := NewMultiWriter(context.Background(), []string{"good", "bad"}, &config.Config{})
+	if err != nil {
+		t.Fatalf("Failed to create MultiWriter: %v", err)
+	}
+	defer multiWriter.Close()
+
+	const totalEvents = 100000
+	yielded := 0
+	err = multiWriter.ProcessEvents(func(yield func(streaming.Event) bool) {
+		for range totalEvents {
+			yielded++
+			if !yield(streaming.Event{Kind: streaming.Text, TextContent: "x"}) {
+				return
+			}
+		}
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "writer bad failed") {
+		t.Fatalf("Expected error from bad writer, got: %v", err)
+	}
+	if yielded == totalEvents {
+		t.Error("Event stream should stop after the first writer error")
+	}
+}
+
+func TestMultiWriter_FlushAll_Order(t *testing.T) {
+	testRegistry := NewWriterRegistry()
+	originalRegistry := registry
+	registry = testRegistry
+	defer func() { registry = originalRegistry }()
+
+	formats := []string{"zeta", "alpha", "mu", "beta", "omega"}
+	for _, format := range formats {
+		Register(format, func(cfg *config.Config) WriterV2 { return NewMockWriter() }, WriterMetadata{Name: format})
+	}
+
+	for range 10 {
+		multiWriter, err := NewMultiWriter(context.Background(), formats, &config.Config{})
+		if err != nil {
+			t.Fatalf("Failed to create MultiWriter: %v", err)
+		}
+
+		results, err := multiWriter.FlushAll()
+		multiWriter.Close()
+		if err != nil {
+			t.Fatalf("FlushAll should not return error: %v", err)
+		}
+
+		for i, result := range results {
+			if result.Format != formats[i] {
+				t.Fatalf("Result %d: expected format %s, got %s", i, formats[i], result.Format)
+			}
+		}
+	}
+}
+
+func TestMultiWriter_FlushAllTo(t *testing.T) {
+	formats := []string{"markdown", "html", "latex", "plaintext", "epub"}
+	events := []streaming.Event{
+		{Kind: streaming.StartDoc, Title: "Streaming Document"},
+		{Kind: streaming.StartHeading, Level: 2, HeadingText: unique.Make("Section"), AnchorID: "section"},
+		{Kind: streaming.Text, TextContent: "Section"},
+		{Kind: streaming.EndHeading},
+		{Kind: streaming.StartParagraph},
+		{Kind: streaming.Text, TextContent: strings.Repeat("streamed text ", 5000)},
+		{Kind: streaming.EndParagraph},
+		{Kind: streaming.EndDoc},
+	}
+	process := func(multiWriter *MultiWriter) {
+		err := multiWriter.ProcessEvents(func(yield func(streaming.Event) bool) {
+			for _, event := range events {
+				if !yield(event) {
+					break
+				}
+			}
+		})
+		if err != nil {
+			t.Fatalf("ProcessEvents failed: %v", err)
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	buffered, err := NewMultiWriter(context.Background(), formats, cfg)
+	if err != nil {
+		t.Fatalf("Failed to create MultiWriter: %v", err)
+	}
+	defer buffered.Close()
+	process(buffered)
+	expected, err := buffered.FlushAll()
+	if err != nil {
+		t.Fatalf("FlushAll failed: %v", err)
+	}
+
+	streamed, err := NewMultiWriter(context.Background(), formats, cfg)
+	if err != nil {
+		t.Fatalf("Failed to create MultiWriter: %v", err)
+	}
+	defer streamed.Close()
+	process(streamed)
+
+	outputs := make(map[string]*bytes.Buffer)
+	results, err := streamed.FlushAllTo(func(result OutputResult) (io.Writer, error) {
+		if result.Data != nil {
+			t.Errorf("Result for %s should not carry data", result.Format)
+		}
+		outputs[result.Format] = &bytes.Buffer{}
+		return outputs[result.Format], nil
+	})
+	if err != nil {
+		t.Fatalf("FlushAllTo failed: %v", err)
+	}
+
+	if len(results) != len(formats) {
+		t.Fatalf("Expected %d results, got %d", len(formats), len(results))
+	}
+	for i, result := range results {
+		if result.Format != formats[i] {
+			t.Errorf("Result %d: expected format %s, got %s", i, formats[i], result.Format)
+		}
+		if result.Size != int64(outputs[result.Format].Len()) {
+			t.Errorf("Result for %s reports size %d, wrote %d", result.Format, result.Size, outputs[result.Format].Len())
+		}
+		if result.Format == "epub" {
+			continue // EPUB archives embed a random identifier
+		}
+		if !bytes.Equal(outputs[result.Format].Bytes(), expected[i].Data) {
+			t.Errorf("Streamed %s output differs from buffered output", result.Format)
+		}
+	}
+}
+
 // Test concurrent access to registry
 func TestWriterRegistry_ConcurrentAccess(t *testing.T) {
 	testRegistry := NewWriterRegistry()
@@ -0,0 +1,534 @@
+package writers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"unicode/utf8"
+	"unique"
+
+	"github.com/kjanat/slimacademy/internal/config"
+	"github.com/kjanat/slimacademy/internal/models"
+	"github.com/kjanat/slimacademy/internal/streaming"
+	"github.com/kjanat/slimacademy/internal/templates"
+	"github.com/kjanat/slimacademy/internal/utils"
+)
+
+// Files of the static site besides the chapter pages
+const (
+	SiteIndexFile             = "index.html"
+	SiteStylesheetFile        = "style.css"
+	SiteSearchIndexFile       = "search-index.json"
+	SiteSearchIndexScriptFile = "search-index.js" // The index as a script, for sites opened from disk
+	SiteSearchFile            = "search.js"
+)
+
+// init registers the static site writer, which outputs a ZIP archive of HTML pages
+func init() {
+	Register("site", func(cfg *config.Config) WriterV2 {
+		if cfg == nil {
+			return NewSiteWriter(nil, nil)
+		}
+		w := NewSiteWriter(cfg.Site, cfg.HTML)
+		w.htmlWriter.configureTransforms(cfg)
+		return w
+	}, WriterMetadata{
+		Name:        "Static site",
+		Extension:   ".site.zip",
+		Description: "Multi-page HTML site with navigation and search",
+		MimeType:    "application/zip",
+		IsBinary:    true,
+	})
+}
+
+// SiteWriter splits a book into HTML pages at chapter headings, like the EPUB writer splits
+// chapter files, and outputs them as a ZIP archive. Every page has a sidebar listing the pages
+// and links to the previous and next page; index.html shows the book details, the content
+// before the first chapter and the table of contents. search-index.json lists the text of
+// every page for the search box, and search-index.js sets it as window.searchIndex.
+type SiteWriter struct {
+	config     *config.SiteConfig
+	htmlConfig *config.HTMLConfig
+	htmlWriter *HTMLWriter
+
+	title          string
+	description    string
+	metadata       map[string]string
+	chapters       []models.Chapter
+	bookChapters   map[string]bool // Top-level chapter titles from the StartDoc event
+	inRunningBlock bool            // Inside a running header or footer, which pages do not have
+
+	front         *sitePage   // Content before the first page, shown on the index page
+	pages         []*sitePage // Chapter pages in document order
+	current       *sitePage
+	headings      []templates.TOCEntry // Every heading in document order, linked to its page
+	toc           []templates.TOCEntry // The headings as transformed, for the table of contents
+	anchorFiles   map[string]string    // Heading anchor ID to page filename
+	usedFilenames map[string]bool
+	err           error // Transform failure, reported by FlushTo
+	stats         WriterStats
+}
+
+// sitePage is a page of the static site
+type sitePage struct {
+	title    string
+	level    int // Level of the heading starting the page, 0 for the index page
+	filename string
+	content  string
+	headings []string
+	text     strings.Builder // Plain text for the search index
+}
+
+// NewSiteWriter creates a static site writer. The pages use the language and stylesheet of
+// htmlCfg; nil configurations use the defaults.
+func NewSiteWriter(cfg *config.SiteConfig, htmlCfg *config.HTMLConfig) *SiteWriter {
+	if cfg == nil {
+		cfg = config.DefaultSiteConfig()
+	}
+	if htmlCfg == nil {
+		htmlCfg = config.DefaultHTMLConfig()
+	}
+	return &SiteWriter{
+		config:        cfg,
+		htmlConfig:    htmlCfg,
+		htmlWriter:    NewHTMLWriterWithConfig(htmlCfg),
+		anchorFiles:   make(map[string]string),
+		usedFilenames: map[string]bool{SiteIndexFile: true},
+	}
+}
+
+// Handle processes a single event
+func (w *SiteWriter) Handle(event streaming.Event) error {
+	w.stats.EventsProcessed++
+	countEvent(&w.stats, event)
+
+	switch event.Kind {
+	case streaming.StartDoc:
+		w.title = event.Title
+		w.description = event.Description
+		w.chapters = event.Chapters
+		w.bookChapters = topLevelChapterTitles(event.Chapters)
+		// Let the HTML writer collect the display metadata, then drop its document wrapper
+		w.htmlWriter.Reset()
+		w.htmlWriter.Handle(event)
+		w.metadata = w.htmlWriter.documentData.Metadata
+		w.htmlWriter.content.Reset()
+
+	case streaming.EndDoc:
+		w.finishPage()
+		w.transformPages()
+		w.rewriteCrossPageLinks()
+
+	case streaming.StartHeading:
+		title := ""
+		if event.HeadingText != (unique.Handle[string]{}) {
+			title = event.HeadingText.Value()
+		}
+		if w.isSplitHeading(event.Level, title) {
+			w.startPage(title, event.Level)
+		}
+		w.ensurePage()
+		w.recordHeading(event.Level, title, event.AnchorID)
+		w.htmlWriter.Handle(event)
+
+	case streaming.StartHeader, streaming.StartFooter:
+		w.inRunningBlock = true
+
+	case streaming.EndHeader, streaming.EndFooter:
+		w.inRunningBlock = false
+
+	default:
+		if w.inRunningBlock {
+			return nil
+		}
+		w.ensurePage()
+		switch event.Kind {
+		case streaming.Text:
+			w.current.text.WriteString(event.TextContent)
+		case streaming.EndParagraph, streaming.EndHeading, streaming.EndListItem, streaming.EndTableCell:
+			w.current.text.WriteByte(' ')
+		}
+		w.htmlWriter.Handle(event)
+	}
+	return nil
+}
+
+// isSplitHeading reports whether a heading starts a new page
+func (w *SiteWriter) isSplitHeading(level int, title string) bool {
+	if w.config.SplitOnChapters && len(w.bookChapters) > 0 {
+		return w.bookChapters[strings.TrimSpace(title)]
+	}
+
+	splitLevel := w.config.SplitLevel
+	if splitLevel <= 0 {
+		splitLevel = 2
+	}
+	return level <= splitLevel
+}
+
+// ensurePage collects content that appears before the first split heading for the index page
+func (w *SiteWriter) ensurePage() {
+	if w.current != nil {
+		return
+	}
+	w.front = &sitePage{title: w.title, filename: SiteIndexFile}
+	w.current = w.front
+}
+
+// startPage finishes the current page and begins a new page file
+func (w *SiteWriter) startPage(title string, level int) {
+	w.finishPage()
+
+	slug := utils.Slugify(title)
+	if slug == "" {
+		slug = "page"
+	}
+	filename := fmt.Sprintf("%02d-%s.html", len(w.pages)+1, slug)
+	if w.usedFilenames[filename] {
+		base := strings.TrimSuffix(filename, ".html")
+		for i := 2; w.usedFilenames[filename]; i++ {
+			filename = fmt.Sprintf("%s_%d.html", base, i)
+		}
+	}
+	w.usedFilenames[filename] = true
+
+	if title == "" {
+		title = fmt.Sprintf("Page %d", len(w.pages)+1)
+	}
+	w.current = &sitePage{title: title, level: level, filename: filename}
+	w.pages = append(w.pages, w.current)
+	w.htmlWriter.Reset()
+}
+
+// finishPage stores the rendered body of the current page
+func (w *SiteWriter) finishPage() {
+	if w.current == nil {
+		return
+	}
+	w.htmlWriter.closeSection()
+	w.current.content = w.htmlWriter.content.String()
+	w.current = nil
+}
+
+// transformPages applies the transform passes once to all pages, so that renumbered headings
+// continue from one page to the next
+func (w *SiteWriter) transformPages() {
+	pages := w.allPages()
+	parts := make([]string, len(pages))
+	for i, page := range pages {
+		parts[i] = page.content
+	}
+	parts, headings := w.htmlWriter.transformParts(parts)
+	if err := w.htmlWriter.Err(); err != nil {
+		w.err = err
+		return
+	}
+	for i, page := range pages {
+		page.content = parts[i]
+	}
+	w.toc = transformedTOC(w.headings, headings)
+}
+
+// recordHeading registers a heading for the table of contents, the search index and cross-page
+// link rewriting
+func (w *SiteWriter) recordHeading(level int, title, anchorID string) {
+	href := w.current.filename
+	if anchorID != "" {
+		href += "#" + anchorID
+		w.anchorFiles[anchorID] = w.current.filename
+	}
+	if title != "" {
+		w.current.headings = append(w.current.headings, title)
+		w.headings = append(w.headings, templates.TOCEntry{Title: title, Level: level, Anchor: anchorID, Href: href})
+	}
+}
+
+// rewriteCrossPageLinks points fragment links at the page that contains the anchor
+func (w *SiteWriter) rewriteCrossPageLinks() {
+	for _, page := range w.allPages() {
+		page.content = anchorLinkPattern.ReplaceAllStringFunc(page.content, func(match string) string {
+			anchorID := anchorLinkPattern.FindStringSubmatch(match)[1]
+			filename, exists := w.anchorFiles[anchorID]
+			if !exists || filename == page.filename {
+				return match
+			}
+			return fmt.Sprintf(`href="%s#%s"`, filename, anchorID)
+		})
+	}
+}
+
+// allPages returns the index page, if it has content, followed by the chapter pages
+func (w *SiteWriter) allPages() []*sitePage {
+	if w.front == nil {
+		return w.pages
+	}
+	return append([]*sitePage{w.front}, w.pages...)
+}
+
+// Flush returns the site as a ZIP archive
+func (w *SiteWriter) Flush() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := w.FlushTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// FlushTo writes the site as a ZIP archive to dst
+func (w *SiteWriter) FlushTo(dst io.Writer) (int64, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	counter := &countingWriter{w: dst}
+	zipWriter := zip.NewWriter(counter)
+
+	if err := w.writeSite(zipWriter); err != nil {
+		w.stats.Errors++
+		return counter.n, err
+	}
+	if err := zipWriter.Close(); err != nil {
+		return counter.n, fmt.Errorf("failed to close site archive: %w", err)
+	}
+	return counter.n, nil
+}
+
+// writeSite writes all files of the site to zipWriter
+func (w *SiteWriter) writeSite(zipWriter *zip.Writer) error {
+	w.finishPage()
+
+	index, err := w.renderIndex()
+	if err != nil {
+		return err
+	}
+	if err := writeZipFile(zipWriter, SiteIndexFile, index); err != nil {
+		return err
+	}
+
+	for i, page := range w.pages {
+		html, err := w.renderPage(i)
+		if err != nil {
+			return err
+		}
+		if err := writeZipFile(zipWriter, page.filename, html); err != nil {
+			return err
+		}
+	}
+
+	css := w.htmlWriter.css
+	if css == "" {
+		css = templates.DefaultCSS()
+	}
+	if err := writeZipFile(zipWriter, SiteStylesheetFile, string(templates.SiteCSS(css))); err != nil {
+		return err
+	}
+
+	if !w.config.SearchIndex {
+		return nil
+	}
+	if err := writeZipFile(zipWriter, SiteSearchFile, templates.SiteSearchScript()); err != nil {
+		return err
+	}
+	searchIndex, err := w.searchIndex()
+	if err != nil {
+		return err
+	}
+	if err := writeZipFile(zipWriter, SiteSearchIndexFile, searchIndex); err != nil {
+		return err
+	}
+	// json.Marshal escapes <, > and &, so the index cannot close the script element
+	return writeZipFile(zipWriter, SiteSearchIndexScriptFile, "window.searchIndex = "+searchIndex+";\n")
+}
+
+// renderIndex renders the index page of the book
+func (w *SiteWriter) renderIndex() (string, error) {
+	data := w.pageData()
+	data.Title = w.title
+	data.Description = w.description
+	data.Metadata = w.metadata
+	data.Sidebar = w.sidebar(-1)
+	data.Contents = w.contents()
+	data.IsIndex = true
+	if w.front != nil {
+		data.Content = template.HTML(w.front.content)
+	}
+	if len(w.pages) > 0 {
+		data.Next = &templates.SiteLink{Title: w.pages[0].title, Href: w.pages[0].filename}
+	}
+
+	html, err := templates.RenderSitePage(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", SiteIndexFile, err)
+	}
+	return html, nil
+}
+
+// renderPage renders the chapter page at index i
+func (w *SiteWriter) renderPage(i int) (string, error) {
+	page := w.pages[i]
+	data := w.pageData()
+	data.Title = page.title
+	data.Content = template.HTML(page.content)
+	data.Sidebar = w.sidebar(i)
+	if i == 0 {
+		data.Prev = &templates.SiteLink{Title: w.title, Href: SiteIndexFile}
+	} else {
+		data.Prev = &templates.SiteLink{Title: w.pages[i-1].title, Href: w.pages[i-1].filename}
+	}
+	if i+1 < len(w.pages) {
+		data.Next = &templates.SiteLink{Title: w.pages[i+1].title, Href: w.pages[i+1].filename}
+	}
+
+	html, err := templates.RenderSitePage(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", page.filename, err)
+	}
+	return html, nil
+}
+
+// pageData returns the data shared by all pages
+func (w *SiteWriter) pageData() templates.SitePageData {
+	return templates.SitePageData{
+		BookTitle: w.title,
+		Language:  w.htmlConfig.Language,
+		Search:    w.config.SearchIndex,
+	}
+}
+
+// sidebar lists the chapter pages nested by heading level, marking the page at index current
+func (w *SiteWriter) sidebar(current int) []templates.TOCEntry {
+	entries := make([]templates.TOCEntry, len(w.pages))
+	for i, page := range w.pages {
+		entries[i] = templates.TOCEntry{Title: page.title, Level: page.level, Href: page.filename, Current: i == current}
+	}
+	return templates.NestTOC(entries)
+}
+
+// contents returns the table of contents of the index page: the chapter hierarchy of the
+// book, or the headings when the book has no chapter list
+func (w *SiteWriter) contents() []templates.TOCEntry {
+	if len(w.chapters) == 0 {
+		if w.toc != nil {
+			return templates.NestTOC(w.toc)
+		}
+		return templates.NestTOC(w.headings)
+	}
+
+	entries := templates.ChapterTOC(w.chapters, w.headings)
+	var link func(entries []templates.TOCEntry)
+	link = func(entries []templates.TOCEntry) {
+		for i := range entries {
+			if filename, ok := w.anchorFiles[entries[i].Anchor]; ok && entries[i].Anchor != "" {
+				entries[i].Href = filename + "#" + entries[i].Anchor
+			}
+			link(entries[i].Children)
+		}
+	}
+	link(entries)
+	return entries
+}
+
+// siteSearchIndex is the content of search-index.json
+type siteSearchIndex struct {
+	Title string           `json:"title"`
+	Pages []siteSearchPage `json:"pages"`
+}
+
+// siteSearchPage is a page in search-index.json
+type siteSearchPage struct {
+	Title    string   `json:"title"`
+	URL      string   `json:"url"`
+	Headings []string `json:"headings,omitempty"`
+	Text     string   `json:"text"`
+}
+
+// searchIndex returns search-index.json, listing the headings and text of every page
+func (w *SiteWriter) searchIndex() (string, error) {
+	index := siteSearchIndex{Title: w.title, Pages: []siteSearchPage{}}
+	for _, page := range w.allPages() {
+		index.Pages = append(index.Pages, siteSearchPage{
+			Title:    page.title,
+			URL:      page.filename,
+			Headings: page.headings,
+			Text:     w.searchText(page.text.String()),
+		})
+	}
+
+	data, err := json.Marshal(index)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode search index: %w", err)
+	}
+	return string(data), nil
+}
+
+// searchText collapses whitespace in text and cuts it to the configured length
+func (w *SiteWriter) searchText(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	limit := w.config.SearchTextLimit
+	if limit <= 0 || utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	return string([]rune(text)[:limit])
+}
+
+// writeZipFile writes content to a new entry of zipWriter
+func writeZipFile(zipWriter *zip.Writer, name, content string) error {
+	fileWriter, err := zipWriter.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", name, err)
+	}
+	if _, err := io.WriteString(fileWriter, content); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// countingWriter counts the bytes written to w
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// ContentType returns the MIME type of the output
+func (w *SiteWriter) ContentType() string {
+	return "application/zip"
+}
+
+// IsText returns false since the site is a ZIP archive
+func (w *SiteWriter) IsText() bool {
+	return false
+}
+
+// Reset clears the writer state for reuse
+func (w *SiteWriter) Reset() {
+	w.htmlWriter.Reset()
+	w.title = ""
+	w.description = ""
+	w.metadata = nil
+	w.chapters = nil
+	w.bookChapters = nil
+	w.inRunningBlock = false
+	w.front = nil
+	w.pages = nil
+	w.current = nil
+	w.headings = nil
+	w.toc = nil
+	w.anchorFiles = make(map[string]string)
+	w.usedFilenames = map[string]bool{SiteIndexFile: true}
+	w.err = nil
+	w.stats = WriterStats{}
+}
+
+// Stats returns processing statistics
+func (w *SiteWriter) Stats() WriterStats {
+	return w.stats
+}
@@ -0,0 +1,243 @@
+package writers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"unique"
+
+	"github.com/kjanat/slimacademy/internal/config"
+	"github.com/kjanat/slimacademy/internal/models"
+	"github.com/kjanat/slimacademy/internal/streaming"
+)
+
+// siteTestEvents is a book with front matter, two chapters with a section and a link from the
+// first chapter to the section
+func siteTestEvents() []streaming.Event {
+	heading := func(level int, anchor, title string) []streaming.Event {
+		return []streaming.Event{
+			{Kind: streaming.StartHeading, Level: level, AnchorID: anchor, HeadingText: unique.Make(title)},
+			{Kind: streaming.Text, TextContent: title},
+			{Kind: streaming.EndHeading, Level: level},
+		}
+	}
+	paragraph := func(events ...streaming.Event) []streaming.Event {
+		events = append([]streaming.Event{{Kind: streaming.StartParagraph}}, events...)
+		return append(events, streaming.Event{Kind: streaming.EndParagraph})
+	}
+
+	events := []streaming.Event{{
+		Kind:        streaming.StartDoc,
+		Title:       "Anatomy",
+		Description: "Lecture notes",
+		Chapters: []models.Chapter{
+			{Title: "Bones", SubChapters: []models.Chapter{{Title: "Skull"}}},
+			{Title: "Muscles"},
+		},
+	}}
+	events = append(events, paragraph(streaming.Event{Kind: streaming.Text, TextContent: "Read this first."})...)
+	events = append(events, heading(2, "bones", "Bones")...)
+	events = append(events, paragraph(
+		streaming.Event{Kind: streaming.StartFormatting, Style: streaming.Link, LinkURL: "#muscles"},
+		streaming.Event{Kind: streaming.Text, TextContent: "See muscles"},
+		streaming.Event{Kind: streaming.EndFormatting, Style: streaming.Link},
+	)...)
+	events = append(events, heading(3, "skull", "Skull")...)
+	events = append(events, paragraph(streaming.Event{Kind: streaming.Text, TextContent: "The cranium protects the brain."})...)
+	events = append(events, heading(2, "muscles", "Muscles")...)
+	events = append(events, paragraph(streaming.Event{Kind: streaming.Text, TextContent: "Muscles move bones."})...)
+	return append(events, streaming.Event{Kind: streaming.EndDoc})
+}
+
+// readSite returns the files of a site archive by name
+func readSite(t *testing.T, data []byte) map[string]string {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Expected a ZIP archive: %v", err)
+	}
+
+	files := make(map[string]string)
+	for _, file := range reader.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", file.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file.Name, err)
+		}
+		files[file.Name] = string(content)
+	}
+	return files
+}
+
+func TestSiteWriter(t *testing.T) {
+	factory, ok := Get("site")
+	if !ok {
+		t.Fatal("Expected site format to be registered")
+	}
+	writer := factory(config.DefaultConfig())
+
+	for _, event := range siteTestEvents() {
+		if err := writer.Handle(event); err != nil {
+			t.Fatalf("Handle() failed: %v", err)
+		}
+	}
+	if stats := writer.Stats(); stats.Headings != 3 {
+		t.Errorf("Expected 3 headings, got %+v", stats)
+	}
+
+	data, err := writer.Flush()
+	if err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+	files := readSite(t, data)
+
+	for _, name := range []string{"index.html", "01-bones.html", "02-muscles.html", "style.css", "search.js", "search-index.json", "search-index.js"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("Expected %s in site, got %d files", name, len(files))
+		}
+	}
+
+	index := files["index.html"]
+	for _, expected := range []string{
+		`<h1 class="document-title">Anatomy</h1>`,
+		"Read this first.",
+		`<a href="01-bones.html#bones">Bones</a>`,
+		`<a href="01-bones.html#skull">Skull</a>`,
+		`rel="next" href="01-bones.html"`,
+	} {
+		if !strings.Contains(index, expected) {
+			t.Errorf("Expected %s in index page:\n%s", expected, index)
+		}
+	}
+
+	bones := files["01-bones.html"]
+	for _, expected := range []string{
+		`<title>Bones · Anatomy</title>`,
+		`<li class="current"><a href="01-bones.html" aria-current="page">Bones</a></li>`,
+		`rel="prev" href="index.html"`,
+		`rel="next" href="02-muscles.html"`,
+		`href="02-muscles.html#muscles"`, // Link to another page
+		`<h3 id="skull">`,
+	} {
+		if !strings.Contains(bones, expected) {
+			t.Errorf("Expected %s in first page:\n%s", expected, bones)
+		}
+	}
+	if strings.Contains(bones, "Muscles move bones.") || strings.Contains(bones, "Read this first.") {
+		t.Errorf("Expected only the first chapter on its page:\n%s", bones)
+	}
+	if strings.Contains(files["02-muscles.html"], `rel="next"`) {
+		t.Error("Expected no next link on the last page")
+	}
+
+	var search struct {
+		Title string `json:"title"`
+		Pages []struct {
+			Title    string   `json:"title"`
+			URL      string   `json:"url"`
+			Headings []string `json:"headings"`
+			Text     string   `json:"text"`
+		} `json:"pages"`
+	}
+	if err := json.Unmarshal([]byte(files["search-index.json"]), &search); err != nil {
+		t.Fatalf("Expected JSON search index: %v", err)
+	}
+	if search.Title != "Anatomy" || len(search.Pages) != 3 {
+		t.Fatalf("Unexpected search index: %+v", search)
+	}
+	if page := search.Pages[1]; page.URL != "01-bones.html" || len(page.Headings) != 2 || page.Text != "Bones See muscles Skull The cranium protects the brain." {
+		t.Errorf("Unexpected search entry: %+v", page)
+	}
+
+	// The script sets the same index, and the pages load it before the search script
+	if script := files["search-index.js"]; script != "window.searchIndex = "+files["search-index.json"]+";\n" {
+		t.Errorf("Expected search-index.js to set the index, got %q", script)
+	}
+	if !strings.Contains(bones, `<script src="search-index.js"></script>
+    <script src="search.js"></script>`) {
+		t.Errorf("Expected the index script before the search script:\n%s", bones)
+	}
+
+	// FlushTo writes the same files
+	var buf bytes.Buffer
+	if _, err := writer.(StreamingWriter).FlushTo(&buf); err != nil || len(readSite(t, buf.Bytes())) != len(files) {
+		t.Errorf("Expected FlushTo to write the site, got %v", err)
+	}
+
+	writer.Reset()
+	data, err = writer.Flush()
+	if err != nil {
+		t.Fatalf("Flush() after Reset failed: %v", err)
+	}
+	if files := readSite(t, data); strings.Contains(files["index.html"], "Anatomy") || len(files) != 5 {
+		t.Errorf("Expected an empty site after Reset, got %d files", len(files))
+	}
+}
+
+func TestSiteWriter_Transforms(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Transforms.Passes = []config.TransformPassConfig{{Name: config.TransformRenumberHeadings}}
+	factory, _ := Get("site")
+	writer := factory(cfg)
+	for _, event := range siteTestEvents() {
+		if err := writer.Handle(event); err != nil {
+			t.Fatalf("Handle() failed: %v", err)
+		}
+	}
+
+	data, err := writer.Flush()
+	if err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+	bones := readSite(t, data)["01-bones.html"]
+	for _, expected := range []string{`<h1 id="bones">Bones</h1>`, `<h2 id="skull">Skull</h2>`, `href="02-muscles.html#muscles"`} {
+		if !strings.Contains(bones, expected) {
+			t.Errorf("Expected %s in first page:\n%s", expected, bones)
+		}
+	}
+
+	cfg.Transforms.Passes = []config.TransformPassConfig{{Name: "minify"}}
+	writer = factory(cfg)
+	for _, event := range siteTestEvents() {
+		writer.Handle(event)
+	}
+	if _, err := writer.Flush(); err == nil || !strings.Contains(err.Error(), "minify") {
+		t.Errorf("Expected unknown pass error, got %v", err)
+	}
+}
+
+func TestSiteWriter_Config(t *testing.T) {
+	writer := NewSiteWriter(&config.SiteConfig{SplitOnChapters: true, SearchTextLimit: 5}, nil)
+	for _, event := range siteTestEvents() {
+		writer.Handle(event)
+	}
+	writer.config.SearchIndex = false
+
+	data, err := writer.Flush()
+	if err != nil {
+		t.Fatalf("Flush() failed: %v", err)
+	}
+	files := readSite(t, data)
+	if _, ok := files["search-index.json"]; ok {
+		t.Error("Expected no search index")
+	}
+	if strings.Contains(files["index.html"], "site-search") {
+		t.Error("Expected no search box")
+	}
+
+	// Only top-level chapter titles split, so Skull stays on the Bones page
+	if len(files) != 4 || !strings.Contains(files["01-bones.html"], `<h3 id="skull">`) {
+		t.Errorf("Expected the index, two chapter pages and the stylesheet, got %d files", len(files))
+	}
+
+	if text := writer.searchText("  Muscles   move bones. "); text != "Muscl" {
+		t.Errorf("Expected search text cut to 5 characters, got %q", text)
+	}
+}